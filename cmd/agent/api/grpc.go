@@ -32,12 +32,29 @@ import (
 	hostutil "github.com/DataDog/datadog-agent/pkg/util"
 	"github.com/DataDog/datadog-agent/pkg/util/grpc"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
 )
 
 const (
-	taggerStreamSendTimeout = 1 * time.Minute
+	taggerStreamSendTimeout       = 1 * time.Minute
+	workloadmetaStreamSendTimeout = 1 * time.Minute
 )
 
+// workloadmetaStreamSources are the sources streamed by
+// WorkloadmetaStreamEntities, so that clients can merge the entities from the
+// different sources like the store does.
+var workloadmetaStreamSources = []workloadmeta.Source{
+	workloadmeta.SourceRuntime,
+	workloadmeta.SourceNodeOrchestrator,
+	workloadmeta.SourceClusterOrchestrator,
+	workloadmeta.SourceHost,
+}
+
+type workloadmetaSourceBundle struct {
+	source workloadmeta.Source
+	bundle workloadmeta.EventBundle
+}
+
 type server struct {
 	pb.UnimplementedAgentServer
 }
@@ -193,6 +210,97 @@ func (s *serverSecure) TaggerFetchEntity(ctx context.Context, in *pb.FetchEntity
 	}, nil
 }
 
+// WorkloadmetaStreamEntities subscribes to the changes of the entities in the
+// workloadmeta store and streams them to clients as pb.WorkloadmetaStreamResponse
+// events. The first response holds the entities present in the store when the
+// stream starts, and is sent even if the store is empty.
+func (s *serverSecure) WorkloadmetaStreamEntities(in *pb.WorkloadmetaStreamRequest, out pb.AgentSecure_WorkloadmetaStreamEntitiesServer) error {
+	kinds := make([]workloadmeta.Kind, 0, len(in.Kinds))
+	for _, kind := range in.Kinds {
+		kinds = append(kinds, workloadmeta.Kind(kind))
+	}
+
+	store := workloadmeta.GetGlobalStore()
+	ctx := out.Context()
+
+	// the entities are streamed for each source separately, so they are
+	// sent along with the source that collected them
+	bundleCh := make(chan workloadmetaSourceBundle)
+	initialEvents := make([]*pb.WorkloadmetaEvent, 0)
+	for _, source := range workloadmetaStreamSources {
+		ch := store.Subscribe("workloadmeta-stream", workloadmeta.NormalPriority, workloadmeta.NewFilter(kinds, source))
+		defer store.Unsubscribe(ch)
+
+		// Subscribe sends the entities present in the store before
+		// returning, if there are any
+		select {
+		case bundle := <-ch:
+			close(bundle.Ch)
+			initialEvents = append(initialEvents, workloadmeta2PbEvents(bundle.Events, source)...)
+		default:
+		}
+
+		go func(source workloadmeta.Source, ch chan workloadmeta.EventBundle) {
+			// keep reading until the subscription is closed so that
+			// the store is never blocked by this stream
+			for bundle := range ch {
+				close(bundle.Ch)
+				select {
+				case bundleCh <- workloadmetaSourceBundle{source: source, bundle: bundle}:
+				case <-ctx.Done():
+				}
+			}
+		}(source, ch)
+	}
+
+	err := sendWorkloadmetaEvents(out, initialEvents)
+	if err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case b := <-bundleCh:
+			err := sendWorkloadmetaEvents(out, workloadmeta2PbEvents(b.bundle.Events, b.source))
+			if err != nil {
+				return err
+			}
+
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func workloadmeta2PbEvents(events []workloadmeta.Event, source workloadmeta.Source) []*pb.WorkloadmetaEvent {
+	pbEvents := make([]*pb.WorkloadmetaEvent, 0, len(events))
+	for _, event := range events {
+		e, err := pbutils.Workloadmeta2PbEvent(event, source)
+		if err != nil {
+			log.Warnf("can't convert workloadmeta event to protobuf: %s", err)
+			continue
+		}
+
+		pbEvents = append(pbEvents, e)
+	}
+
+	return pbEvents
+}
+
+func sendWorkloadmetaEvents(out pb.AgentSecure_WorkloadmetaStreamEntitiesServer, events []*pb.WorkloadmetaEvent) error {
+	err := grpc.DoWithTimeout(func() error {
+		return out.Send(&pb.WorkloadmetaStreamResponse{
+			Events: events,
+		})
+	}, workloadmetaStreamSendTimeout)
+
+	if err != nil {
+		log.Warnf("error sending workloadmeta event: %s", err)
+	}
+
+	return err
+}
+
 func (s *serverSecure) ClientGetConfigs(ctx context.Context, in *pb.ClientGetConfigsRequest) (*pb.ClientGetConfigsResponse, error) {
 	if s.configService == nil {
 		log.Debug("Remote configuration service not initialized")
@@ -55,6 +55,7 @@ func SetupHandlers(r *mux.Router) *mux.Router {
 	r.HandleFunc("/status", getStatus).Methods("GET")
 	r.HandleFunc("/stream-logs", streamLogs).Methods("POST")
 	r.HandleFunc("/dogstatsd-stats", getDogstatsdStats).Methods("GET")
+	r.HandleFunc("/dogstatsd-cardinality", getDogstatsdCardinality).Methods("GET")
 	r.HandleFunc("/status/formatted", getFormattedStatus).Methods("GET")
 	r.HandleFunc("/status/health", getHealth).Methods("GET")
 	r.HandleFunc("/{component}/status", componentStatusGetterHandler).Methods("GET")
@@ -194,6 +195,48 @@ func getStatus(w http.ResponseWriter, r *http.Request) {
 	w.Write(jsonStats)
 }
 
+func getDogstatsdCardinality(w http.ResponseWriter, r *http.Request) {
+	log.Info("Got a request for the Dogstatsd cardinality limiter stats.")
+
+	if !config.Datadog.GetBool("use_dogstatsd") {
+		w.Header().Set("Content-Type", "application/json")
+		body, _ := json.Marshal(map[string]string{
+			"error":      "Dogstatsd not enabled in the Agent configuration",
+			"error_type": "no server",
+		})
+		w.WriteHeader(400)
+		w.Write(body)
+		return
+	}
+
+	if config.Datadog.GetInt("dogstatsd_context_limit_per_metric") <= 0 && config.Datadog.GetInt("dogstatsd_context_limit_per_origin") <= 0 {
+		w.Header().Set("Content-Type", "application/json")
+		body, _ := json.Marshal(map[string]string{
+			"error":      "Dogstatsd contexts cardinality limits not enabled in the Agent configuration",
+			"error_type": "not enabled",
+		})
+		w.WriteHeader(400)
+		w.Write(body)
+		return
+	}
+
+	if common.DSD == nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+		return
+	}
+
+	jsonStats, err := common.DSD.GetJSONCardinalityStats()
+	if err != nil {
+		log.Errorf("Error getting marshalled Dogstatsd cardinality limiter stats: %s", err)
+		body, _ := json.Marshal(map[string]string{"error": err.Error()})
+		http.Error(w, string(body), 500)
+		return
+	}
+
+	w.Write(jsonStats)
+}
+
 func streamLogs(w http.ResponseWriter, r *http.Request) {
 	log.Info("Got a request for stream logs.")
 	w.Header().Set("Transfer-Encoding", "chunked")
@@ -24,6 +24,7 @@ import (
 
 var (
 	dsdStatsFilePath string
+	dsdCardinality   bool
 )
 
 func init() {
@@ -31,6 +32,7 @@ func init() {
 	dogstatsdStatsCmd.Flags().BoolVarP(&jsonStatus, "json", "j", false, "print out raw json")
 	dogstatsdStatsCmd.Flags().BoolVarP(&prettyPrintJSON, "pretty-json", "p", false, "pretty print JSON")
 	dogstatsdStatsCmd.Flags().StringVarP(&dsdStatsFilePath, "file", "o", "", "Output the dogstatsd-stats command to a file")
+	dogstatsdStatsCmd.Flags().BoolVarP(&dsdCardinality, "cardinality", "c", false, "print the top offenders of the contexts cardinality limits instead of the metrics stats")
 }
 
 var dogstatsdStatsCmd = &cobra.Command{
@@ -67,7 +69,11 @@ func requestDogstatsdStats() error {
 	if err != nil {
 		return err
 	}
-	urlstr := fmt.Sprintf("https://%v:%v/agent/dogstatsd-stats", ipcAddress, config.Datadog.GetInt("cmd_port"))
+	endpoint := "dogstatsd-stats"
+	if dsdCardinality {
+		endpoint = "dogstatsd-cardinality"
+	}
+	urlstr := fmt.Sprintf("https://%v:%v/agent/%s", ipcAddress, config.Datadog.GetInt("cmd_port"), endpoint)
 
 	// Set session token
 	e = util.SetAuthToken()
@@ -101,6 +107,12 @@ func requestDogstatsdStats() error {
 		s = prettyJSON.String()
 	} else if jsonStatus {
 		s = string(r)
+	} else if dsdCardinality {
+		s, e = dogstatsd.FormatCardinalityStats(r)
+		if e != nil {
+			fmt.Printf("Could not format the statistics, the data must be inconsistent. You may want to try the JSON output. Contact the support if you continue having issues.\n")
+			return nil
+		}
 	} else {
 		s, e = dogstatsd.FormatDebugStats(r)
 		if e != nil {
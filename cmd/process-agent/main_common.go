@@ -44,6 +44,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/util/profiling"
 	"github.com/DataDog/datadog-agent/pkg/version"
 	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+	remoteworkloadmeta "github.com/DataDog/datadog-agent/pkg/workloadmeta/remote"
 
 	// register all workloadmeta collectors
 	_ "github.com/DataDog/datadog-agent/pkg/workloadmeta/collectors"
@@ -217,6 +218,11 @@ func runAgent(exit chan struct{}) {
 	log.Infof("running version: %s", agentVersion.GetNumberAndPre())
 
 	// Start workload metadata store before tagger (used for containerCollection)
+	if ddconfig.Datadog.GetBool("process_config.remote_workloadmeta") {
+		if err := workloadmeta.SetGlobalStore(remoteworkloadmeta.NewStore()); err != nil {
+			log.Errorf("failed to use the remote workloadmeta store: %s", err)
+		}
+	}
 	store := workloadmeta.GetGlobalStore()
 	store.Start(mainCtx)
 
@@ -43,6 +43,8 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/util"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 	"github.com/DataDog/datadog-agent/pkg/version"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+	remoteworkloadmeta "github.com/DataDog/datadog-agent/pkg/workloadmeta/remote"
 	ddgostatsd "github.com/DataDog/datadog-go/statsd"
 
 	coreconfig "github.com/DataDog/datadog-agent/pkg/config"
@@ -285,6 +287,16 @@ func RunAgent(ctx context.Context) (err error) {
 		}
 	}
 
+	// Initialize the remote workloadmeta store, used by the compliance telemetry
+	if coreconfig.Datadog.GetBool("security_agent.remote_workloadmeta") {
+		store := remoteworkloadmeta.NewStore()
+		if err := workloadmeta.SetGlobalStore(store); err != nil {
+			log.Errorf("failed to use the remote workloadmeta store: %s", err)
+		} else {
+			store.Start(ctx)
+		}
+	}
+
 	complianceAgent, err := startCompliance(hostname, stopper, statsdClient)
 	if err != nil {
 		return err
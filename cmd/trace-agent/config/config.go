@@ -13,6 +13,7 @@ import (
 	"net/url"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
@@ -88,6 +89,31 @@ func prepareConfig(path string) (*config.AgentConfig, error) {
 	return cfg, nil
 }
 
+// LoadSpanRules reads the span rules from the configuration file at path and from the
+// environment, leaving the loaded configuration unchanged. It is used to reload the rules.
+func LoadSpanRules(path string) ([]config.SpanRule, error) {
+	cfg := coreconfig.NewConfig("datadog", "DD", strings.NewReplacer(".", "_"))
+	coreconfig.InitConfig(cfg)
+	cfg.SetConfigFile(path)
+	if err := cfg.ReadInConfig(); err != nil {
+		return nil, err
+	}
+	return spanRules(cfg)
+}
+
+// spanRules returns the span rules set in cfg.
+func spanRules(cfg coreconfig.Config) ([]config.SpanRule, error) {
+	k := "apm_config.span_rules"
+	if !cfg.IsSet(k) {
+		return nil, nil
+	}
+	var rules []config.SpanRule
+	if err := cfg.UnmarshalKey(k, &rules); err != nil {
+		return nil, fmt.Errorf("Bad format for %q it should be a list of objects with a match expression and an action, error: %v", k, err)
+	}
+	return rules, nil
+}
+
 func containerTagsFunc(cid string) ([]string, error) {
 	return tagger.Tag("container_id://"+cid, collectors.HighCardinality)
 }
@@ -209,6 +235,15 @@ func applyDatadogConfig(c *config.AgentConfig) error {
 		c.MaxRemoteTPS = coreconfig.Datadog.GetFloat64("apm_config.max_remote_traces_per_second")
 	}
 
+	if k := "apm_config.tail_sampling"; coreconfig.Datadog.IsSet(k) {
+		if err := coreconfig.Datadog.UnmarshalKey(k, c.TailSampling); err != nil {
+			log.Errorf("Error reading tail sampling config %q: %v", k, err)
+		}
+	}
+	if k := "apm_config.tail_sampling.enabled"; coreconfig.Datadog.IsSet(k) {
+		c.TailSampling.Enabled = coreconfig.Datadog.GetBool(k)
+	}
+
 	if k := "apm_config.ignore_resources"; coreconfig.Datadog.IsSet(k) {
 		c.Ignore["resource"] = coreconfig.Datadog.GetStringSlice(k)
 	}
@@ -228,6 +263,12 @@ func applyDatadogConfig(c *config.AgentConfig) error {
 		}
 	}
 
+	if rules, err := spanRules(coreconfig.Datadog); err != nil {
+		log.Error(err)
+	} else {
+		c.SpanRules = rules
+	}
+
 	if coreconfig.Datadog.IsSet("bind_host") || coreconfig.Datadog.IsSet("apm_config.apm_non_local_traffic") {
 		if coreconfig.Datadog.IsSet("bind_host") {
 			host := coreconfig.Datadog.GetString("bind_host")
@@ -304,6 +345,13 @@ func applyDatadogConfig(c *config.AgentConfig) error {
 		}
 	}
 
+	if k := "apm_config.stats_extra_tags"; coreconfig.Datadog.IsSet(k) {
+		c.StatsExtraTags = coreconfig.Datadog.GetStringSlice(k)
+	}
+	if k := "apm_config.stats_extra_tags_max_values"; coreconfig.Datadog.IsSet(k) {
+		c.StatsExtraTagsMaxValues = coreconfig.Datadog.GetInt(k)
+	}
+
 	// undocumented
 	if coreconfig.Datadog.IsSet("apm_config.max_cpu_percent") {
 		c.MaxCPU = coreconfig.Datadog.GetFloat64("apm_config.max_cpu_percent") / 100
@@ -320,6 +368,9 @@ func applyDatadogConfig(c *config.AgentConfig) error {
 		if err := coreconfig.Datadog.UnmarshalKey(key, cfg); err != nil {
 			log.Errorf("Error reading writer config %q: %v", key, err)
 		}
+		if cfg.DiskBufferMaxSize > 0 && cfg.DiskBufferPath == "" {
+			cfg.DiskBufferPath = filepath.Join(coreconfig.Datadog.GetString("run_path"), "apm", strings.TrimPrefix(key, "apm_config."))
+		}
 	}
 	if coreconfig.Datadog.IsSet("apm_config.connection_reset_interval") {
 		c.ConnectionResetInterval = getDuration(coreconfig.Datadog.GetInt("apm_config.connection_reset_interval"))
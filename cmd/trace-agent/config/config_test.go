@@ -465,6 +465,11 @@ func TestFullYamlConfig(t *testing.T) {
 		},
 	}, c.ReplaceTags)
 
+	assert.Equal([]config.SpanRule{
+		{Name: "health", Match: `resource =~ "^GET /health"`, Action: "drop"},
+		{Match: `service == "web" && meta["team"]`, Action: "add_tags", Tags: map[string]string{"owner": "web-team"}},
+	}, c.SpanRules)
+
 	assert.EqualValues([]string{"/health", "/500"}, c.Ignore["resource"])
 
 	assert.Equal("0.0.0.0", c.OTLPReceiver.BindHost)
@@ -481,10 +486,29 @@ func TestFullYamlConfig(t *testing.T) {
 	assert.True(o.RemoveStackTraces)
 	assert.True(c.Obfuscation.Redis.Enabled)
 	assert.True(c.Obfuscation.Memcached.Enabled)
+	assert.True(c.Obfuscation.GraphQL.Enabled)
+	assert.True(c.Obfuscation.AWS.Enabled)
 	assert.True(c.Obfuscation.CreditCards.Enabled)
 	assert.True(c.Obfuscation.CreditCards.Luhn)
 }
 
+func TestLoadSpanRules(t *testing.T) {
+	defer cleanConfig()()
+	assert := assert.New(t)
+
+	rules, err := LoadSpanRules("./testdata/full.yaml")
+	assert.NoError(err)
+	assert.Len(rules, 2)
+	assert.Equal("health", rules[0].Name)
+
+	rules, err = LoadSpanRules("./testdata/site_default.yaml")
+	assert.NoError(err)
+	assert.Empty(rules)
+
+	_, err = LoadSpanRules("./testdata/missing.yaml")
+	assert.Error(err)
+}
+
 func TestUndocumentedYamlConfig(t *testing.T) {
 	defer cleanConfig()()
 	origcfg := coreconfig.Datadog
@@ -833,6 +857,20 @@ func TestLoadEnv(t *testing.T) {
 		assert.Contains(cfg.ReplaceTags, rule2)
 	})
 
+	env = "DD_APM_SPAN_RULES"
+	t.Run(env, func(t *testing.T) {
+		defer cleanConfig()()
+		assert := assert.New(t)
+		err := os.Setenv(env, `[{"match":"service == \"web\"","action":"add_tags","tags":{"team":"web"}}]`)
+		assert.NoError(err)
+		defer os.Unsetenv(env)
+		cfg, err := LoadConfigFile("./testdata/full.yaml")
+		assert.NoError(err)
+		assert.Equal([]config.SpanRule{
+			{Match: `service == "web"`, Action: "add_tags", Tags: map[string]string{"team": "web"}},
+		}, cfg.SpanRules)
+	})
+
 	env = "DD_APM_FILTER_TAGS_REQUIRE"
 	t.Run(env, func(t *testing.T) {
 		defer cleanConfig()()
@@ -39,6 +39,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 	"github.com/DataDog/datadog-agent/pkg/util/profiling"
 	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+	remoteworkloadmeta "github.com/DataDog/datadog-agent/pkg/workloadmeta/remote"
 
 	// register all workloadmeta collectors
 	_ "github.com/DataDog/datadog-agent/pkg/workloadmeta/collectors"
@@ -165,6 +166,11 @@ func Run(ctx context.Context) {
 	// starts the local tagger if apm_config says so, or if starting the
 	// remote tagger has failed.
 	if !remoteTagger {
+		if coreconfig.Datadog.GetBool("apm_config.remote_workloadmeta") {
+			if err := workloadmeta.SetGlobalStore(remoteworkloadmeta.NewStore()); err != nil {
+				log.Errorf("failed to use the remote workloadmeta store: %s", err)
+			}
+		}
 		store := workloadmeta.GetGlobalStore()
 		store.Start(ctx)
 
@@ -198,6 +204,9 @@ func Run(ctx context.Context) {
 
 	agnt := agent.NewAgent(ctx, cfg)
 	log.Infof("Trace agent running on host %s", cfg.Hostname)
+	if cfg.ConfigPath != "" {
+		go reloadSpanRulesOnSignal(ctx, agnt, cfg.ConfigPath)
+	}
 	if pcfg := profilingConfig(cfg); pcfg != nil {
 		if err := profiling.Start(*pcfg); err != nil {
 			log.Warn(err)
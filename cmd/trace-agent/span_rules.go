@@ -0,0 +1,41 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	cmdconfig "github.com/DataDog/datadog-agent/cmd/trace-agent/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/agent"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// reloadSpanRulesOnSignal reloads the span rules of agnt from the configuration file at
+// path each time the trace-agent receives a SIGHUP, until ctx is cancelled.
+func reloadSpanRulesOnSignal(ctx context.Context, agnt *agent.Agent, path string) {
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGHUP)
+	defer signal.Stop(sigChan)
+	for {
+		select {
+		case <-sigChan:
+			rules, err := cmdconfig.LoadSpanRules(path)
+			if err == nil {
+				err = agnt.SpanRules.Reload(rules)
+			}
+			if err != nil {
+				log.Errorf("Error reloading span rules, keeping the current ones: %v", err)
+				continue
+			}
+			log.Infof("Reloaded %d span rules from %s", agnt.SpanRules.Len(), path)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -138,11 +138,18 @@ type forwarders struct {
 	orchestrator       *forwarder.DefaultForwarder
 	eventPlatform      epforwarder.EventPlatformForwarder
 	containerLifecycle *forwarder.DefaultForwarder
+	remoteWrite        *forwarder.RemoteWriteForwarder
 }
 
 type dataOutputs struct {
 	forwarders       forwarders
 	sharedSerializer serializer.MetricSerializer
+
+	// remoteWriteSerializer is nil when the Prometheus remote-write output is disabled
+	remoteWriteSerializer *serializer.RemoteWriteSerializer
+	// sendMetricsToDatadog is false when the series and sketches are only sent to the
+	// Prometheus remote-write output
+	sendMetricsToDatadog bool
 }
 
 // trigger be used to trigger something in the TimeSampler or the BufferedAggregator.
@@ -230,10 +237,12 @@ func initAgentDemultiplexer(options DemultiplexerOptions, hostname string) *Agen
 	}
 
 	var sharedForwarder forwarder.Forwarder
+	var remoteWriteForwarder *forwarder.RemoteWriteForwarder
 	if options.UseNoopForwarder {
 		sharedForwarder = forwarder.NoopForwarder{}
 	} else {
 		sharedForwarder = forwarder.NewDefaultForwarder(options.SharedForwarderOptions)
+		remoteWriteForwarder = forwarder.NewRemoteWriteForwarder()
 	}
 
 	// prepare the serializer
@@ -241,6 +250,13 @@ func initAgentDemultiplexer(options DemultiplexerOptions, hostname string) *Agen
 
 	sharedSerializer := serializer.NewSerializer(sharedForwarder, orchestratorForwarder, containerLifecycleForwarder)
 
+	var remoteWriteSerializer *serializer.RemoteWriteSerializer
+	sendMetricsToDatadog := true
+	if remoteWriteForwarder != nil {
+		remoteWriteSerializer = serializer.NewRemoteWriteSerializer(remoteWriteForwarder)
+		sendMetricsToDatadog = config.Datadog.GetBool("prometheus_remote_write.send_to_datadog")
+	}
+
 	// prepare the embedded aggregator
 	// --
 
@@ -286,9 +302,12 @@ func initAgentDemultiplexer(options DemultiplexerOptions, hostname string) *Agen
 				orchestrator:       orchestratorForwarder,
 				eventPlatform:      eventPlatformForwarder,
 				containerLifecycle: containerLifecycleForwarder,
+				remoteWrite:        remoteWriteForwarder,
 			},
 
-			sharedSerializer: sharedSerializer,
+			sharedSerializer:      sharedSerializer,
+			remoteWriteSerializer: remoteWriteSerializer,
+			sendMetricsToDatadog:  sendMetricsToDatadog,
 		},
 
 		senders: newSenders(agg),
@@ -364,6 +383,15 @@ func (d *AgentDemultiplexer) Run() {
 		} else {
 			log.Debug("not starting the shared forwarder")
 		}
+
+		// Prometheus remote-write forwarder
+		if d.forwarders.remoteWrite != nil {
+			if err := d.forwarders.remoteWrite.Start(); err != nil {
+				log.Errorf("error starting Prometheus remote-write forwarder: %v", err)
+			}
+		} else {
+			log.Debug("not starting the Prometheus remote-write forwarder")
+		}
 		log.Debug("Forwarders started")
 	}
 
@@ -461,11 +489,16 @@ func (d *AgentDemultiplexer) Stop(flush bool) {
 			d.dataOutputs.forwarders.shared.Stop()
 			d.dataOutputs.forwarders.shared = nil
 		}
+		if d.dataOutputs.forwarders.remoteWrite != nil {
+			d.dataOutputs.forwarders.remoteWrite.Stop()
+			d.dataOutputs.forwarders.remoteWrite = nil
+		}
 	}
 
 	// misc
 
 	d.dataOutputs.sharedSerializer = nil
+	d.dataOutputs.remoteWriteSerializer = nil
 	d.senders = nil
 	demultiplexerInstance = nil
 }
@@ -509,18 +542,28 @@ func (d *AgentDemultiplexer) flushToSerializer(start time.Time, waitForSerialize
 	flushedSketches := make([]metrics.SketchSeriesList, 0)
 
 	// only used when we're using flush/serialize in parallel feature
-	var seriesSink *metrics.IterableSeries
+	var seriesSink metrics.SerieSink
+	var iterableSeries *metrics.IterableSeries
 	var done chan struct{}
-
-	if d.aggregator.flushAndSerializeInParallel.enabled {
-		seriesSink = metrics.NewIterableSeries(func(se *metrics.Serie) {
+	// series flushed to seriesSink which have to be sent to the remote-write output
+	var remoteWriteSeries metrics.Series
+
+	if d.aggregator.flushAndSerializeInParallel.enabled && !d.sendMetricsToDatadog {
+		// the series are only sent to the remote-write output, there is nothing to stream
+		seriesSink = &remoteWriteSeries
+	} else if d.aggregator.flushAndSerializeInParallel.enabled {
+		iterableSeries = metrics.NewIterableSeries(func(se *metrics.Serie) {
 			if logPayloads {
 				log.Debugf("Flushing serie: %s", se)
 			}
 			tagsetTlm.updateHugeSerieTelemetry(se)
+			if d.remoteWriteSerializer != nil {
+				remoteWriteSeries = append(remoteWriteSeries, se)
+			}
 		}, d.aggregator.flushAndSerializeInParallel.bufferSize, d.aggregator.flushAndSerializeInParallel.channelSize)
+		seriesSink = iterableSeries
 		done = make(chan struct{})
-		go d.sendIterableSeries(start, seriesSink, done)
+		go d.sendIterableSeries(start, iterableSeries, done)
 	}
 
 	// flush DogStatsD pipelines (statsd/time samplers)
@@ -561,8 +604,8 @@ func (d *AgentDemultiplexer) flushToSerializer(start time.Time, waitForSerialize
 		<-t.trigger.blockChan
 	}
 
-	if d.aggregator.flushAndSerializeInParallel.enabled {
-		seriesSink.SenderStopped()
+	if iterableSeries != nil {
+		iterableSeries.SenderStopped()
 		<-done
 	}
 
@@ -598,7 +641,7 @@ func (d *AgentDemultiplexer) flushToSerializer(start time.Time, waitForSerialize
 	// ----------------------------
 
 	addFlushCount("Series", int64(len(series)))
-	if len(series) > 0 {
+	if len(series) > 0 && d.sendMetricsToDatadog {
 		log.Debugf("Flushing %d series to the serializer", len(series))
 		err := d.sharedSerializer.SendSeries(series)
 		updateSerieTelemetry(start, uint64(len(series)), err)
@@ -606,13 +649,17 @@ func (d *AgentDemultiplexer) flushToSerializer(start time.Time, waitForSerialize
 	}
 
 	addFlushCount("Sketches", int64(len(sketches)))
-	if len(sketches) > 0 {
+	if len(sketches) > 0 && d.sendMetricsToDatadog {
 		log.Debugf("Flushing %d sketches to the serializer", len(sketches))
 		err := d.sharedSerializer.SendSketch(sketches)
 		updateSketchTelemetry(start, uint64(len(sketches)), err)
 		tagsetTlm.updateHugeSketchesTelemetry(&sketches)
 	}
 
+	if d.remoteWriteSerializer != nil {
+		d.flushToRemoteWrite(append(remoteWriteSeries, series...), sketches)
+	}
+
 	addFlushTime("MainFlushTime", int64(time.Since(start)))
 	aggregatorNumberOfFlush.Add(1)
 }
@@ -621,6 +668,22 @@ func (d *AgentDemultiplexer) flushToSerializer(start time.Time, waitForSerialize
 // series sink.
 // Mainly meant to be executed in its own routine, sendIterableSeries is closing the `done` channel once it has returned
 // from SendIterableSeries (because the SenderStopped methods has been called on the sink).
+// flushToRemoteWrite sends the series and sketches to the Prometheus remote-write output
+func (d *AgentDemultiplexer) flushToRemoteWrite(series metrics.Series, sketches metrics.SketchSeriesList) {
+	if len(series) > 0 {
+		log.Debugf("Flushing %d series to the Prometheus remote-write serializer", len(series))
+		if err := d.remoteWriteSerializer.SendSeries(series); err != nil {
+			log.Warnf("Error flushing series to the Prometheus remote-write output: %v", err)
+		}
+	}
+	if len(sketches) > 0 {
+		log.Debugf("Flushing %d sketches to the Prometheus remote-write serializer", len(sketches))
+		if err := d.remoteWriteSerializer.SendSketch(sketches); err != nil {
+			log.Warnf("Error flushing sketches to the Prometheus remote-write output: %v", err)
+		}
+	}
+}
+
 func (d *AgentDemultiplexer) sendIterableSeries(start time.Time, series *metrics.IterableSeries, done chan<- struct{}) {
 	log.Debug("Demultiplexer: sendIterableSeries: start sending iterable series to the serializer")
 	err := d.sharedSerializer.SendIterableSeries(series)
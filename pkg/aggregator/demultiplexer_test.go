@@ -6,15 +6,20 @@
 package aggregator
 
 import (
+	"net/http"
 	"testing"
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
 	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/forwarder"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/serializer"
 	"github.com/DataDog/datadog-agent/pkg/util/containers/providers"
 	providerMocks "github.com/DataDog/datadog-agent/pkg/util/containers/providers/mock"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -188,6 +193,45 @@ func TestDemuxFlushAggregatorToSerializer(t *testing.T) {
 	require.Len(sketches, 0)
 }
 
+type remoteWriteForwarderMock struct {
+	payloads forwarder.Payloads
+}
+
+func (f *remoteWriteForwarderMock) SubmitRemoteWrite(payload forwarder.Payloads, extra http.Header) error {
+	f.payloads = append(f.payloads, payload...)
+	return nil
+}
+
+func TestDemuxFlushToRemoteWriteOnly(t *testing.T) {
+	for _, parallel := range []bool{true, false} {
+		s := &serializer.MockSerializer{}
+		f := &remoteWriteForwarderMock{}
+		opts := demuxTestOptions()
+		demux := InitAndStartAgentDemultiplexer(opts, "")
+		demux.sharedSerializer = s
+		demux.aggregator.serializer = s
+		demux.aggregator.flushAndSerializeInParallel.enabled = parallel
+		demux.remoteWriteSerializer = serializer.NewRemoteWriteSerializer(f)
+		demux.sendMetricsToDatadog = false
+
+		start := time.Now()
+		AddRecurrentSeries(&metrics.Serie{
+			Name:   "test.series",
+			Points: []metrics.Point{{Value: 1, Ts: float64(start.Unix())}},
+			MType:  metrics.APIGaugeType,
+		})
+		s.On("SendServiceChecks", mock.Anything).Return(nil)
+
+		demux.ForceFlushToSerializer(start, true)
+		s.AssertNotCalled(t, "SendSeries", mock.Anything)
+		s.AssertNotCalled(t, "SendIterableSeries", mock.Anything)
+		assert.Len(t, f.payloads, 1, "parallel: %v", parallel)
+
+		demux.Stop(false)
+		recurrentSeries = metrics.Series{}
+	}
+}
+
 func TestGetDogStatsDWorkerAndPipelineCount(t *testing.T) {
 	pc := config.Datadog.GetInt("dogstatsd_pipeline_count")
 	aa := config.Datadog.GetInt("dogstatsd_pipeline_autoadjust")
@@ -49,7 +49,7 @@ func (pd *configPoller) contains(c *integration.Config) bool {
 
 // stop stops the provider descriptor if it's polling
 func (pd *configPoller) stop() {
-	if !pd.canPoll || pd.isPolling {
+	if !pd.canPoll || !pd.isPolling {
 		return
 	}
 	pd.stopChan <- struct{}{}
@@ -80,6 +80,9 @@ func (pd *configPoller) poll(ac *AutoConfig) {
 			pd.healthHandle.Deregister() //nolint:errcheck
 			cancel()
 			ticker.Stop()
+			if provider, ok := pd.provider.(providers.StoppableConfigProvider); ok {
+				provider.Stop()
+			}
 			return
 		case <-ticker.C:
 			log.Tracef("Polling %s config provider", pd.provider.String())
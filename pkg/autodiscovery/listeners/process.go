@@ -0,0 +1,176 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build !serverless
+// +build !serverless
+
+package listeners
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+const (
+	processIdentifierPrefix        = "process:"
+	processCmdlineIdentifierPrefix = "process_cmdline:"
+
+	// processLoopbackHost is the address used to reach the host processes
+	// listening on all the interfaces
+	processLoopbackHost = "127.0.0.1"
+)
+
+func init() {
+	Register("process", NewProcessListener)
+}
+
+// ProcessListener listens to host processes listening on a port through a
+// subscription to the workloadmeta store.
+type ProcessListener struct {
+	workloadmetaListener
+	cmdlinePatterns map[string]*regexp.Regexp
+}
+
+// NewProcessListener returns a new ProcessListener.
+func NewProcessListener(Config) (ServiceListener, error) {
+	const name = "ad-processlistener"
+
+	if !config.Datadog.GetBool("workloadmeta.process_collector.enabled") {
+		return nil, errors.New("the process listener requires workloadmeta.process_collector.enabled to be set")
+	}
+
+	patterns, err := compileCmdlinePatterns(config.Datadog.GetStringMapString("process_listener.cmdline_patterns"))
+	if err != nil {
+		return nil, err
+	}
+
+	l := &ProcessListener{cmdlinePatterns: patterns}
+	f := workloadmeta.NewFilter(
+		[]workloadmeta.Kind{workloadmeta.KindProcess},
+		workloadmeta.SourceHost,
+	)
+
+	l.workloadmetaListener, err = newWorkloadmetaListener(name, f, l.createProcessService)
+	if err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+func (l *ProcessListener) createProcessService(entity workloadmeta.Entity) {
+	process := entity.(*workloadmeta.Process)
+
+	// containerized processes are discovered through their container
+	if process.ContainerID != "" {
+		return
+	}
+
+	// only processes serving on a port are worth running a check against
+	if len(process.Ports) == 0 {
+		return
+	}
+
+	ports := make([]ContainerPort, 0, len(process.Ports))
+	hostIPs := make(map[int]string, len(process.Ports))
+	for _, port := range process.Ports {
+		if _, ok := hostIPs[port.Port]; ok {
+			continue
+		}
+
+		hostIPs[port.Port] = port.HostIP
+		ports = append(ports, ContainerPort{
+			Port: port.Port,
+			Name: port.Name,
+		})
+	}
+
+	sort.Slice(ports, func(i, j int) bool {
+		return ports[i].Port < ports[j].Port
+	})
+
+	// %%host%% resolves to the address of the port %%port%% resolves to
+	// by default, the last one
+	host := processHost(hostIPs[ports[len(ports)-1].Port])
+
+	svc := &service{
+		entity:        process,
+		adIdentifiers: ComputeProcessServiceIDs(process, l.cmdlinePatterns),
+		hosts:         map[string]string{"host": host},
+		ports:         ports,
+		pid:           int(process.Pid),
+		ready:         true,
+	}
+
+	svcID := buildSvcID(process.GetID())
+	l.AddService(svcID, svc, "")
+}
+
+// processHost returns the address to reach a process listening on hostIP,
+// the loopback address if it listens on all the interfaces.
+func processHost(hostIP string) string {
+	ip := net.ParseIP(hostIP)
+	if ip == nil || ip.IsUnspecified() {
+		return processLoopbackHost
+	}
+
+	return ip.String()
+}
+
+// ComputeProcessServiceIDs computes the AD identifiers of a host process:
+// process:<name> for the name of the process and of its executable, and
+// process_cmdline:<pattern name> for each configured pattern matching its
+// command line.
+func ComputeProcessServiceIDs(process *workloadmeta.Process, cmdlinePatterns map[string]*regexp.Regexp) []string {
+	var ids []string
+
+	if process.Name != "" {
+		ids = append(ids, processIdentifierPrefix+process.Name)
+	}
+
+	if process.Exe != "" {
+		exe := filepath.Base(process.Exe)
+		if exe != process.Name {
+			ids = append(ids, processIdentifierPrefix+exe)
+		}
+	}
+
+	cmdline := strings.Join(process.Cmdline, " ")
+	names := make([]string, 0, len(cmdlinePatterns))
+	for name, pattern := range cmdlinePatterns {
+		if pattern.MatchString(cmdline) {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+	for _, name := range names {
+		ids = append(ids, processCmdlineIdentifierPrefix+name)
+	}
+
+	return ids
+}
+
+func compileCmdlinePatterns(patterns map[string]string) (map[string]*regexp.Regexp, error) {
+	compiled := make(map[string]*regexp.Regexp, len(patterns))
+	for name, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid command line pattern %q: %w", name, err)
+		}
+
+		compiled[name] = re
+	}
+
+	return compiled, nil
+}
@@ -0,0 +1,169 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build !serverless
+// +build !serverless
+
+package listeners
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+func TestCreateProcessService(t *testing.T) {
+	processEntityID := workloadmeta.EntityID{
+		Kind: workloadmeta.KindProcess,
+		ID:   "1234",
+	}
+
+	redis := &workloadmeta.Process{
+		EntityID: processEntityID,
+		Pid:      1234,
+		Name:     "redis-server",
+		Exe:      "/usr/bin/redis-server",
+		Cmdline:  []string{"/usr/bin/redis-server", "10.0.0.5:6379"},
+		Ports: []workloadmeta.ContainerPort{
+			{Port: 16379, Protocol: "tcp", HostIP: "10.0.0.5"},
+			{Port: 6379, Protocol: "tcp", HostIP: "10.0.0.5"},
+		},
+	}
+
+	kafka := &workloadmeta.Process{
+		EntityID: processEntityID,
+		Pid:      1234,
+		Name:     "java",
+		Exe:      "/usr/lib/jvm/java-11-openjdk/bin/java",
+		Cmdline:  []string{"java", "-Xmx1G", "kafka.Kafka", "/etc/kafka/server.properties"},
+		Ports: []workloadmeta.ContainerPort{
+			{Port: 9092, Protocol: "tcp", HostIP: "::"},
+		},
+	}
+
+	tests := []struct {
+		name             string
+		process          *workloadmeta.Process
+		expectedServices map[string]wlmListenerSvc
+	}{
+		{
+			name:    "process listening on multiple ports collects them in ascending order",
+			process: redis,
+			expectedServices: map[string]wlmListenerSvc{
+				"process://1234": {
+					service: &service{
+						entity:        redis,
+						adIdentifiers: []string{"process:redis-server"},
+						hosts:         map[string]string{"host": "10.0.0.5"},
+						ports: []ContainerPort{
+							{Port: 6379},
+							{Port: 16379},
+						},
+						pid:   1234,
+						ready: true,
+					},
+				},
+			},
+		},
+		{
+			name:    "process matching a command line pattern",
+			process: kafka,
+			expectedServices: map[string]wlmListenerSvc{
+				"process://1234": {
+					service: &service{
+						entity:        kafka,
+						adIdentifiers: []string{"process:java", "process_cmdline:kafka"},
+						hosts:         map[string]string{"host": "127.0.0.1"},
+						ports:         []ContainerPort{{Port: 9092}},
+						pid:           1234,
+						ready:         true,
+					},
+				},
+			},
+		},
+		{
+			name: "process listening on several addresses resolves the host of its last port",
+			process: &workloadmeta.Process{
+				EntityID: processEntityID,
+				Pid:      1234,
+				Name:     "nginx",
+				Ports: []workloadmeta.ContainerPort{
+					{Port: 443, Protocol: "tcp", HostIP: "0.0.0.0"},
+					{Port: 8080, Protocol: "tcp", HostIP: "::1"},
+				},
+			},
+			expectedServices: map[string]wlmListenerSvc{
+				"process://1234": {
+					service: &service{
+						entity: &workloadmeta.Process{
+							EntityID: processEntityID,
+							Pid:      1234,
+							Name:     "nginx",
+							Ports: []workloadmeta.ContainerPort{
+								{Port: 443, Protocol: "tcp", HostIP: "0.0.0.0"},
+								{Port: 8080, Protocol: "tcp", HostIP: "::1"},
+							},
+						},
+						adIdentifiers: []string{"process:nginx"},
+						hosts:         map[string]string{"host": "::1"},
+						ports:         []ContainerPort{{Port: 443}, {Port: 8080}},
+						pid:           1234,
+						ready:         true,
+					},
+				},
+			},
+		},
+		{
+			name: "process not listening on any port does not get collected",
+			process: &workloadmeta.Process{
+				EntityID: processEntityID,
+				Pid:      1234,
+				Name:     "cron",
+			},
+			expectedServices: map[string]wlmListenerSvc{},
+		},
+		{
+			name: "containerized process does not get collected",
+			process: &workloadmeta.Process{
+				EntityID:    processEntityID,
+				Pid:         1234,
+				Name:        "nginx",
+				ContainerID: "foobarquux",
+				Ports:       []workloadmeta.ContainerPort{{Port: 80, Protocol: "tcp"}},
+			},
+			expectedServices: map[string]wlmListenerSvc{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listener, wlm := newProcessListener(t, map[string]string{
+				"kafka":     `kafka\.Kafka`,
+				"zookeeper": `org\.apache\.zookeeper`,
+			})
+
+			listener.createProcessService(tt.process)
+
+			wlm.assertServices(tt.expectedServices)
+		})
+	}
+}
+
+func TestCompileCmdlinePatterns(t *testing.T) {
+	_, err := compileCmdlinePatterns(map[string]string{"broken": "java ("})
+	assert.Error(t, err)
+}
+
+func newProcessListener(t *testing.T, cmdlinePatterns map[string]string) (*ProcessListener, *testWorkloadmetaListener) {
+	wlm := newTestWorkloadmetaListener(t)
+
+	patterns, err := compileCmdlinePatterns(cmdlinePatterns)
+	require.NoError(t, err)
+
+	return &ProcessListener{workloadmetaListener: wlm, cmdlinePatterns: patterns}, wlm
+}
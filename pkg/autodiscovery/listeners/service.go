@@ -42,6 +42,8 @@ func (s *service) GetServiceID() string {
 		return containers.BuildEntityName(string(e.Runtime), e.ID)
 	case *workloadmeta.KubernetesPod:
 		return kubelet.PodUIDToEntityName(e.ID)
+	case *workloadmeta.Process:
+		return fmt.Sprintf("process://%s", e.ID)
 	default:
 		entityID := s.entity.GetID()
 		log.Errorf("cannot build AD entity ID for kind %q, ID %q", entityID.Kind, entityID.ID)
@@ -56,6 +58,8 @@ func (s *service) GetTaggerEntity() string {
 		return containers.BuildTaggerEntityName(e.ID)
 	case *workloadmeta.KubernetesPod:
 		return kubelet.PodUIDToTaggerEntityName(e.ID)
+	case *workloadmeta.Process:
+		return fmt.Sprintf("process://%s", e.ID)
 	default:
 		entityID := s.entity.GetID()
 		log.Errorf("cannot build AD entity ID for kind %q, ID %q", entityID.Kind, entityID.ID)
@@ -0,0 +1,263 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build kubeapiserver
+// +build kubeapiserver
+
+package providers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver"
+)
+
+const (
+	datadogCheckTargetPods     = "pods"
+	datadogCheckTargetServices = "services"
+)
+
+var datadogCheckGVR = schema.GroupVersionResource{
+	Group:    "datadoghq.com",
+	Version:  "v1alpha1",
+	Resource: "datadogchecks",
+}
+
+// newDatadogCheckInformer returns a started informer on the DatadogCheck
+// resources of all namespaces.
+func newDatadogCheckInformer() (informers.GenericInformer, error) {
+	client, err := apiserver.GetKubeDynamicClient(0) // No timeout for the Informers, to allow long watch.
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to apiserver: %w", err)
+	}
+
+	resyncPeriodSeconds := time.Duration(config.Datadog.GetInt64("kubernetes_informers_resync_period"))
+	factory := dynamicinformer.NewDynamicSharedInformerFactory(client, resyncPeriodSeconds*time.Second)
+	informer := factory.ForResource(datadogCheckGVR)
+	factory.Start(wait.NeverStop)
+
+	return informer, nil
+}
+
+// datadogCheck is a DatadogCheck custom resource, declaring an integration to
+// schedule against the pods or the services of its namespace matching its
+// label selector:
+//
+//	apiVersion: datadoghq.com/v1alpha1
+//	kind: DatadogCheck
+//	metadata:
+//	  name: redis
+//	  namespace: default
+//	spec:
+//	  checkName: redisdb
+//	  target: pods # or services, defaults to pods
+//	  selector:
+//	    matchLabels:
+//	      app: redis
+//	  initConfig: {}
+//	  instances:
+//	    - host: "%%host%%"
+//	      port: "6379"
+//	  logs: [] # optional
+//
+// The errors found while parsing it are reported in its status.errors field.
+type datadogCheck struct {
+	namespace  string
+	name       string
+	checkName  string
+	target     string
+	selector   labels.Selector
+	initConfig integration.Data
+	instances  []integration.Data
+	logs       integration.Data
+}
+
+// parseDatadogCheck parses a DatadogCheck from its unstructured representation.
+func parseDatadogCheck(obj *unstructured.Unstructured) (*datadogCheck, error) {
+	spec, found, err := unstructured.NestedMap(obj.Object, "spec")
+	if err != nil {
+		return nil, fmt.Errorf("invalid spec: %w", err)
+	} else if !found {
+		return nil, errors.New("missing spec")
+	}
+
+	dc := &datadogCheck{
+		namespace:  obj.GetNamespace(),
+		name:       obj.GetName(),
+		target:     datadogCheckTargetPods,
+		initConfig: integration.Data("{}"),
+	}
+
+	dc.checkName, _, err = unstructured.NestedString(spec, "checkName")
+	if err != nil {
+		return nil, fmt.Errorf("invalid spec.checkName: %w", err)
+	} else if dc.checkName == "" {
+		return nil, errors.New("missing spec.checkName")
+	}
+
+	target, found, err := unstructured.NestedString(spec, "target")
+	if err != nil {
+		return nil, fmt.Errorf("invalid spec.target: %w", err)
+	}
+	if found {
+		if target != datadogCheckTargetPods && target != datadogCheckTargetServices {
+			return nil, fmt.Errorf("invalid spec.target %q, must be %q or %q", target, datadogCheckTargetPods, datadogCheckTargetServices)
+		}
+		dc.target = target
+	}
+
+	// a missing selector is an error rather than a selector matching
+	// every pod or service of the namespace
+	rawSelector, found, err := unstructured.NestedMap(spec, "selector")
+	if err != nil {
+		return nil, fmt.Errorf("invalid spec.selector: %w", err)
+	} else if !found {
+		return nil, errors.New("missing spec.selector")
+	}
+
+	var selector metav1.LabelSelector
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(rawSelector, &selector); err != nil {
+		return nil, fmt.Errorf("invalid spec.selector: %w", err)
+	}
+
+	dc.selector, err = metav1.LabelSelectorAsSelector(&selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid spec.selector: %w", err)
+	}
+
+	if initConfig, found := spec["initConfig"]; found {
+		dc.initConfig, err = json.Marshal(initConfig)
+		if err != nil {
+			return nil, fmt.Errorf("invalid spec.initConfig: %w", err)
+		}
+	}
+
+	instances, found, err := unstructured.NestedSlice(spec, "instances")
+	if err != nil {
+		return nil, fmt.Errorf("invalid spec.instances: %w", err)
+	} else if !found || len(instances) == 0 {
+		return nil, errors.New("missing spec.instances")
+	}
+
+	for i, instance := range instances {
+		if _, ok := instance.(map[string]interface{}); !ok {
+			return nil, fmt.Errorf("invalid spec.instances[%d]: not an object", i)
+		}
+
+		data, err := json.Marshal(instance)
+		if err != nil {
+			return nil, fmt.Errorf("invalid spec.instances[%d]: %w", i, err)
+		}
+
+		dc.instances = append(dc.instances, data)
+	}
+
+	if logs, found := spec["logs"]; found {
+		dc.logs, err = json.Marshal(logs)
+		if err != nil {
+			return nil, fmt.Errorf("invalid spec.logs: %w", err)
+		}
+	}
+
+	return dc, nil
+}
+
+// matches returns whether the check applies to an object of its target
+// with the given namespace and labels.
+func (dc *datadogCheck) matches(namespace string, objLabels map[string]string) bool {
+	return namespace == dc.namespace && dc.selector.Matches(labels.Set(objLabels))
+}
+
+// template returns the config template of the check for an AD identifier.
+func (dc *datadogCheck) template(adIdentifier, source string) integration.Config {
+	return integration.Config{
+		Name:          dc.checkName,
+		InitConfig:    dc.initConfig,
+		Instances:     dc.instances,
+		LogsConfig:    dc.logs,
+		ADIdentifiers: []string{adIdentifier},
+		Source:        source,
+	}
+}
+
+// parseDatadogChecks parses the given DatadogCheck objects, and returns the
+// checks with the given target and the parsing errors of all the objects,
+// indexed by namespace/name.
+func parseDatadogChecks(objs []runtime.Object, target string) ([]*datadogCheck, map[string]ErrorMsgSet) {
+	checks := make([]*datadogCheck, 0, len(objs))
+	checkErrors := make(map[string]ErrorMsgSet)
+
+	for _, obj := range objs {
+		u, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			continue
+		}
+
+		dc, err := parseDatadogCheck(u)
+		if err != nil {
+			checkErrors[datadogCheckKey(u)] = ErrorMsgSet{err.Error(): {}}
+			continue
+		}
+
+		if dc.target == target {
+			checks = append(checks, dc)
+		}
+	}
+
+	return checks, checkErrors
+}
+
+// withDatadogCheckStatus returns a copy of a DatadogCheck object holding the
+// given errors in its status, or nil if its status already holds them.
+func withDatadogCheckStatus(obj *unstructured.Unstructured, errs ErrorMsgSet) *unstructured.Unstructured {
+	statusErrors := make([]string, 0, len(errs))
+	for err := range errs {
+		statusErrors = append(statusErrors, err)
+	}
+
+	current, _, _ := unstructured.NestedStringSlice(obj.Object, "status", "errors")
+	if len(current) == 0 && len(statusErrors) == 0 {
+		return nil
+	}
+	if len(current) == len(statusErrors) && reflect.DeepEqual(toSet(current), errs) {
+		return nil
+	}
+
+	updated := obj.DeepCopy()
+	if len(statusErrors) == 0 {
+		unstructured.RemoveNestedField(updated.Object, "status", "errors")
+	} else if err := unstructured.SetNestedStringSlice(updated.Object, statusErrors, "status", "errors"); err != nil {
+		return nil
+	}
+
+	return updated
+}
+
+func datadogCheckKey(obj metav1.Object) string {
+	return obj.GetNamespace() + "/" + obj.GetName()
+}
+
+func toSet(values []string) ErrorMsgSet {
+	set := make(ErrorMsgSet, len(values))
+	for _, v := range values {
+		set[v] = struct{}{}
+	}
+	return set
+}
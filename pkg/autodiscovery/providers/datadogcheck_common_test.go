@@ -0,0 +1,226 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build kubeapiserver
+// +build kubeapiserver
+
+package providers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+)
+
+func newDatadogCheckObject(name string, spec map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "datadoghq.com/v1alpha1",
+			"kind":       "DatadogCheck",
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": "ns",
+			},
+		},
+	}
+
+	if spec != nil {
+		obj.Object["spec"] = spec
+	}
+
+	return obj
+}
+
+func TestParseDatadogCheck(t *testing.T) {
+	redisSelector := map[string]interface{}{
+		"matchLabels": map[string]interface{}{"app": "redis"},
+	}
+	redisInstances := []interface{}{
+		map[string]interface{}{"host": "%%host%%", "port": "6379"},
+	}
+
+	for _, tc := range []struct {
+		name          string
+		spec          map[string]interface{}
+		expectedErr   string
+		expectedCheck integration.Config
+		matching      map[string]string
+		notMatching   map[string]string
+	}{
+		{
+			name: "pods target by default",
+			spec: map[string]interface{}{
+				"checkName": "redisdb",
+				"selector":  redisSelector,
+				"instances": redisInstances,
+			},
+			expectedCheck: integration.Config{
+				Name:          "redisdb",
+				ADIdentifiers: []string{"docker://abcd"},
+				InitConfig:    integration.Data("{}"),
+				Instances:     []integration.Data{integration.Data(`{"host":"%%host%%","port":"6379"}`)},
+				Source:        "datadogcheck_pods:docker://abcd",
+			},
+			matching:    map[string]string{"app": "redis", "tier": "cache"},
+			notMatching: map[string]string{"app": "nginx"},
+		},
+		{
+			name: "init config, logs and match expressions",
+			spec: map[string]interface{}{
+				"checkName": "redisdb",
+				"target":    "services",
+				"selector": map[string]interface{}{
+					"matchExpressions": []interface{}{
+						map[string]interface{}{
+							"key":      "app",
+							"operator": "In",
+							"values":   []interface{}{"redis", "keydb"},
+						},
+					},
+				},
+				"initConfig": map[string]interface{}{"service": "cache"},
+				"instances":  redisInstances,
+				"logs":       []interface{}{map[string]interface{}{"source": "redis"}},
+			},
+			expectedCheck: integration.Config{
+				Name:          "redisdb",
+				ADIdentifiers: []string{"docker://abcd"},
+				InitConfig:    integration.Data(`{"service":"cache"}`),
+				Instances:     []integration.Data{integration.Data(`{"host":"%%host%%","port":"6379"}`)},
+				LogsConfig:    integration.Data(`[{"source":"redis"}]`),
+				Source:        "datadogcheck_pods:docker://abcd",
+			},
+			matching:    map[string]string{"app": "keydb"},
+			notMatching: map[string]string{},
+		},
+		{
+			name:        "missing spec",
+			expectedErr: "missing spec",
+		},
+		{
+			name: "missing check name",
+			spec: map[string]interface{}{
+				"selector":  redisSelector,
+				"instances": redisInstances,
+			},
+			expectedErr: "missing spec.checkName",
+		},
+		{
+			name: "invalid target",
+			spec: map[string]interface{}{
+				"checkName": "redisdb",
+				"target":    "nodes",
+				"selector":  redisSelector,
+				"instances": redisInstances,
+			},
+			expectedErr: `invalid spec.target "nodes", must be "pods" or "services"`,
+		},
+		{
+			name: "missing selector",
+			spec: map[string]interface{}{
+				"checkName": "redisdb",
+				"instances": redisInstances,
+			},
+			expectedErr: "missing spec.selector",
+		},
+		{
+			name: "invalid selector operator",
+			spec: map[string]interface{}{
+				"checkName": "redisdb",
+				"selector": map[string]interface{}{
+					"matchExpressions": []interface{}{
+						map[string]interface{}{"key": "app", "operator": "Like"},
+					},
+				},
+				"instances": redisInstances,
+			},
+			expectedErr: `invalid spec.selector: "Like" is not a valid pod selector operator`,
+		},
+		{
+			name: "no instances",
+			spec: map[string]interface{}{
+				"checkName": "redisdb",
+				"selector":  redisSelector,
+				"instances": []interface{}{},
+			},
+			expectedErr: "missing spec.instances",
+		},
+		{
+			name: "instance not an object",
+			spec: map[string]interface{}{
+				"checkName": "redisdb",
+				"selector":  redisSelector,
+				"instances": []interface{}{"localhost:6379"},
+			},
+			expectedErr: "invalid spec.instances[0]: not an object",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dc, err := parseDatadogCheck(newDatadogCheckObject("redis", tc.spec))
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expectedCheck, dc.template("docker://abcd", "datadogcheck_pods:docker://abcd"))
+			assert.True(t, dc.matches("ns", tc.matching))
+			assert.False(t, dc.matches("ns", tc.notMatching))
+			assert.False(t, dc.matches("other-ns", tc.matching))
+		})
+	}
+}
+
+func TestParseDatadogChecks(t *testing.T) {
+	selector := map[string]interface{}{
+		"matchLabels": map[string]interface{}{"app": "redis"},
+	}
+	instances := []interface{}{map[string]interface{}{}}
+
+	objs := []runtime.Object{
+		newDatadogCheckObject("pods", map[string]interface{}{
+			"checkName": "redisdb",
+			"selector":  selector,
+			"instances": instances,
+		}),
+		newDatadogCheckObject("services", map[string]interface{}{
+			"checkName": "redisdb",
+			"target":    "services",
+			"selector":  selector,
+			"instances": instances,
+		}),
+		newDatadogCheckObject("broken", nil),
+	}
+
+	checks, checkErrors := parseDatadogChecks(objs, datadogCheckTargetServices)
+	require.Len(t, checks, 1)
+	assert.Equal(t, "services", checks[0].name)
+	assert.Equal(t, map[string]ErrorMsgSet{"ns/broken": {"missing spec": {}}}, checkErrors)
+}
+
+func TestWithDatadogCheckStatus(t *testing.T) {
+	obj := newDatadogCheckObject("redis", nil)
+
+	// no errors to report nor to clear
+	assert.Nil(t, withDatadogCheckStatus(obj, nil))
+
+	updated := withDatadogCheckStatus(obj, ErrorMsgSet{"missing spec": {}})
+	require.NotNil(t, updated)
+	statusErrors, _, _ := unstructured.NestedStringSlice(updated.Object, "status", "errors")
+	assert.Equal(t, []string{"missing spec"}, statusErrors)
+
+	// the errors are already reported
+	assert.Nil(t, withDatadogCheckStatus(updated, ErrorMsgSet{"missing spec": {}}))
+
+	cleared := withDatadogCheckStatus(updated, nil)
+	require.NotNil(t, cleared)
+	_, found, _ := unstructured.NestedStringSlice(cleared.Object, "status", "errors")
+	assert.False(t, found)
+}
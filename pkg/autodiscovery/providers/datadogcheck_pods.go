@@ -0,0 +1,119 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build kubeapiserver && kubelet
+// +build kubeapiserver,kubelet
+
+package providers
+
+import (
+	"context"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/providers/names"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/telemetry"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/kubelet"
+)
+
+// DatadogCheckPodsConfigProvider implements the ConfigProvider interface for
+// the DatadogCheck resources targeting pods, scheduling their checks against
+// the containers of the matching pods running on the node.
+type DatadogCheckPodsConfigProvider struct {
+	kubelet      kubelet.KubeUtilInterface
+	lister       cache.GenericLister
+	configErrors map[string]ErrorMsgSet
+	sync.RWMutex
+}
+
+// NewDatadogCheckPodsConfigProvider returns a new ConfigProvider watching the
+// DatadogCheck resources. Connectivity to the kubelet is not checked at this
+// stage to allow for retries, Collect will do it.
+func NewDatadogCheckPodsConfigProvider(*config.ConfigurationProviders) (ConfigProvider, error) {
+	informer, err := newDatadogCheckInformer()
+	if err != nil {
+		return nil, err
+	}
+
+	return &DatadogCheckPodsConfigProvider{
+		lister:       informer.Lister(),
+		configErrors: make(map[string]ErrorMsgSet),
+	}, nil
+}
+
+// String returns a string representation of the DatadogCheckPodsConfigProvider
+func (p *DatadogCheckPodsConfigProvider) String() string {
+	return names.DatadogCheckPods
+}
+
+// Collect matches the DatadogCheck resources against the pods running on the
+// node, and returns a config per matching container.
+func (p *DatadogCheckPodsConfigProvider) Collect(ctx context.Context) ([]integration.Config, error) {
+	var err error
+	if p.kubelet == nil {
+		p.kubelet, err = kubelet.GetKubeUtil()
+		if err != nil {
+			return []integration.Config{}, err
+		}
+	}
+
+	pods, err := p.kubelet.GetLocalPodList(ctx)
+	if err != nil {
+		return []integration.Config{}, err
+	}
+
+	objs, err := p.lister.List(labels.Everything())
+	if err != nil {
+		return []integration.Config{}, err
+	}
+
+	checks, checkErrors := parseDatadogChecks(objs, datadogCheckTargetPods)
+
+	p.Lock()
+	p.configErrors = checkErrors
+	p.Unlock()
+	telemetry.Errors.Set(float64(len(checkErrors)), names.DatadogCheckPods)
+
+	return datadogCheckPodConfigs(checks, pods), nil
+}
+
+// IsUpToDate always return false to poll new data from kubelet
+func (p *DatadogCheckPodsConfigProvider) IsUpToDate(ctx context.Context) (bool, error) {
+	return false, nil
+}
+
+// datadogCheckPodConfigs returns the configs of the checks for the
+// containers of the pods they match.
+func datadogCheckPodConfigs(checks []*datadogCheck, pods []*kubelet.Pod) []integration.Config {
+	var configs []integration.Config
+	for _, pod := range pods {
+		for _, check := range checks {
+			if !check.matches(pod.Metadata.Namespace, pod.Metadata.Labels) {
+				continue
+			}
+
+			for _, container := range pod.Status.GetAllContainers() {
+				configs = append(configs, check.template(container.ID, "datadogcheck_pods:"+container.ID))
+			}
+		}
+	}
+	return configs
+}
+
+func init() {
+	RegisterProvider(names.DatadogCheckPodsRegisterName, NewDatadogCheckPodsConfigProvider)
+}
+
+// GetConfigErrors returns a map of configuration errors for each namespace/DatadogCheck
+func (p *DatadogCheckPodsConfigProvider) GetConfigErrors() map[string]ErrorMsgSet {
+	p.RLock()
+	defer p.RUnlock()
+
+	return p.configErrors
+}
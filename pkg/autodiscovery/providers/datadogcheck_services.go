@@ -0,0 +1,243 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build clusterchecks && kubeapiserver
+// +build clusterchecks,kubeapiserver
+
+package providers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/dynamic"
+	listersv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/providers/names"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/telemetry"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// DatadogCheckServicesConfigProvider implements the ConfigProvider interface
+// for the DatadogCheck resources targeting services, scheduling their checks
+// as cluster checks against the matching services. It also reports the
+// errors of all the DatadogCheck resources in their status.
+type DatadogCheckServicesConfigProvider struct {
+	client        dynamic.Interface
+	checkLister   cache.GenericLister
+	serviceLister listersv1.ServiceLister
+	configErrors  map[string]ErrorMsgSet
+	upToDate      bool
+	sync.RWMutex
+}
+
+// NewDatadogCheckServicesConfigProvider returns a new ConfigProvider watching
+// the DatadogCheck resources and the services.
+// Connectivity is not checked at this stage to allow for retries, Collect will do it.
+func NewDatadogCheckServicesConfigProvider(*config.ConfigurationProviders) (ConfigProvider, error) {
+	// Using GetAPIClient() (no retry)
+	ac, err := apiserver.GetAPIClient()
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to apiserver: %s", err)
+	}
+
+	servicesInformer := ac.InformerFactory.Core().V1().Services()
+	if servicesInformer == nil {
+		return nil, fmt.Errorf("cannot get service informer: %s", err)
+	}
+
+	timeout := time.Duration(config.Datadog.GetInt64("kubernetes_apiserver_client_timeout")) * time.Second
+	client, err := apiserver.GetKubeDynamicClient(timeout)
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to apiserver: %s", err)
+	}
+
+	checksInformer, err := newDatadogCheckInformer()
+	if err != nil {
+		return nil, err
+	}
+
+	p := &DatadogCheckServicesConfigProvider{
+		client:        client,
+		checkLister:   checksInformer.Lister(),
+		serviceLister: servicesInformer.Lister(),
+		configErrors:  make(map[string]ErrorMsgSet),
+	}
+
+	servicesInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    p.invalidate,
+		UpdateFunc: p.invalidateIfServiceChanged,
+		DeleteFunc: p.invalidate,
+	})
+
+	checksInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    p.invalidate,
+		UpdateFunc: p.invalidateIfCheckChanged,
+		DeleteFunc: p.invalidate,
+	})
+
+	return p, nil
+}
+
+// String returns a string representation of the DatadogCheckServicesConfigProvider
+func (p *DatadogCheckServicesConfigProvider) String() string {
+	return names.DatadogCheckServices
+}
+
+// Collect matches the DatadogCheck resources against the services, and
+// returns a cluster check config per matching service.
+func (p *DatadogCheckServicesConfigProvider) Collect(ctx context.Context) ([]integration.Config, error) {
+	services, err := p.serviceLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	objs, err := p.checkLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	p.Lock()
+	p.upToDate = true
+	p.Unlock()
+
+	checks, checkErrors := parseDatadogChecks(objs, datadogCheckTargetServices)
+
+	p.Lock()
+	p.configErrors = checkErrors
+	p.Unlock()
+	telemetry.Errors.Set(float64(len(checkErrors)), names.DatadogCheckServices)
+
+	for _, obj := range objs {
+		if u, ok := obj.(*unstructured.Unstructured); ok {
+			p.updateStatus(ctx, u, checkErrors[datadogCheckKey(u)])
+		}
+	}
+
+	return datadogCheckServiceConfigs(checks, services), nil
+}
+
+// IsUpToDate allows to cache configs as long as no changes are detected in the apiserver
+func (p *DatadogCheckServicesConfigProvider) IsUpToDate(ctx context.Context) (bool, error) {
+	p.RLock()
+	defer p.RUnlock()
+	return p.upToDate, nil
+}
+
+// updateStatus reports the errors of a DatadogCheck in its status, if they
+// changed since the last update.
+func (p *DatadogCheckServicesConfigProvider) updateStatus(ctx context.Context, obj *unstructured.Unstructured, errs ErrorMsgSet) {
+	updated := withDatadogCheckStatus(obj, errs)
+	if updated == nil {
+		return
+	}
+
+	_, err := p.client.Resource(datadogCheckGVR).Namespace(obj.GetNamespace()).UpdateStatus(ctx, updated, metav1.UpdateOptions{})
+	if err != nil {
+		log.Warnf("Unable to update the status of DatadogCheck %s: %v", datadogCheckKey(obj), err)
+	}
+}
+
+func (p *DatadogCheckServicesConfigProvider) invalidate(obj interface{}) {
+	if obj != nil {
+		log.Trace("Invalidating configs on new/deleted service or DatadogCheck")
+		p.Lock()
+		p.upToDate = false
+		p.Unlock()
+	}
+}
+
+func (p *DatadogCheckServicesConfigProvider) invalidateIfServiceChanged(old, obj interface{}) {
+	// Cast the updated object, don't invalidate on casting error.
+	// nil pointers are safely handled by the casting logic.
+	castedObj, ok := obj.(*v1.Service)
+	if !ok {
+		log.Errorf("Expected a Service type, got: %v", obj)
+		return
+	}
+	// Cast the old object, invalidate on casting error
+	castedOld, ok := old.(*v1.Service)
+	if !ok {
+		log.Errorf("Expected a Service type, got: %v", old)
+		p.invalidate(obj)
+		return
+	}
+	// Quick exit if resversion did not change
+	if castedObj.ResourceVersion == castedOld.ResourceVersion {
+		return
+	}
+	// Services are matched on their labels
+	if !labels.Equals(castedObj.Labels, castedOld.Labels) {
+		log.Trace("Invalidating configs on service labels change")
+		p.invalidate(obj)
+	}
+}
+
+func (p *DatadogCheckServicesConfigProvider) invalidateIfCheckChanged(old, obj interface{}) {
+	castedObj, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		log.Errorf("Expected an Unstructured type, got: %v", obj)
+		return
+	}
+	castedOld, ok := old.(*unstructured.Unstructured)
+	if !ok {
+		log.Errorf("Expected an Unstructured type, got: %v", old)
+		p.invalidate(obj)
+		return
+	}
+	// The generation only changes with the spec, our own status updates
+	// must not trigger a new collection.
+	if castedObj.GetGeneration() != castedOld.GetGeneration() {
+		log.Trace("Invalidating configs on DatadogCheck change")
+		p.invalidate(obj)
+	}
+}
+
+// datadogCheckServiceConfigs returns the cluster check configs of the checks
+// for the services they match.
+func datadogCheckServiceConfigs(checks []*datadogCheck, services []*v1.Service) []integration.Config {
+	var configs []integration.Config
+	for _, svc := range services {
+		if svc == nil || svc.ObjectMeta.UID == "" {
+			log.Debug("Ignoring a nil service")
+			continue
+		}
+
+		for _, check := range checks {
+			if !check.matches(svc.Namespace, svc.Labels) {
+				continue
+			}
+
+			serviceID := apiserver.EntityForService(svc)
+			c := check.template(serviceID, "datadogcheck_services:"+serviceID)
+			c.ClusterCheck = true
+
+			configs = append(configs, c)
+		}
+	}
+	return configs
+}
+
+func init() {
+	RegisterProvider(names.DatadogCheckServicesRegisterName, NewDatadogCheckServicesConfigProvider)
+}
+
+// GetConfigErrors returns a map of configuration errors for each namespace/DatadogCheck
+func (p *DatadogCheckServicesConfigProvider) GetConfigErrors() map[string]ErrorMsgSet {
+	p.RLock()
+	defer p.RUnlock()
+
+	return p.configErrors
+}
@@ -7,23 +7,26 @@ package names
 
 // User-facing names for the config providers
 const (
-	Consul             = "consul"
-	Container          = "container"
-	CloudFoundryBBS    = "cloudfoundry-bbs"
-	ClusterChecks      = "cluster-checks"
-	ECS                = "ecs"
-	EndpointsChecks    = "endpoints-checks"
-	Etcd               = "etcd"
-	File               = "file"
-	Kubernetes         = "kubernetes"
-	KubeServices       = "kubernetes-services"
-	KubeServicesFile   = "kubernetes-services-file"
-	KubeEndpoints      = "kubernetes-endpoints"
-	KubeEndpointsFile  = "kubernetes-endpoints-file"
-	PrometheusPods     = "prometheus-pods"
-	PrometheusServices = "prometheus-services"
-	SNMP               = "snmp"
-	Zookeeper          = "zookeeper"
+	Consul               = "consul"
+	Container            = "container"
+	CloudFoundryBBS      = "cloudfoundry-bbs"
+	ClusterChecks        = "cluster-checks"
+	DatadogCheckPods     = "datadogcheck-pods"
+	DatadogCheckServices = "datadogcheck-services"
+	ECS                  = "ecs"
+	EndpointsChecks      = "endpoints-checks"
+	Etcd                 = "etcd"
+	File                 = "file"
+	Kubernetes           = "kubernetes"
+	KubeServices         = "kubernetes-services"
+	KubeServicesFile     = "kubernetes-services-file"
+	KubeEndpoints        = "kubernetes-endpoints"
+	KubeEndpointsFile    = "kubernetes-endpoints-file"
+	PrometheusPods       = "prometheus-pods"
+	PrometheusServices   = "prometheus-services"
+	RemoteConfig         = "remote-config"
+	SNMP                 = "snmp"
+	Zookeeper            = "zookeeper"
 )
 
 // Internal Autodiscovery names for the config providers
@@ -31,16 +34,19 @@ const (
 // And they're kept unchanged for backward compatibility
 // as they could be hardcoded in the agent config.
 const (
-	ConsulRegisterName             = "consul"
-	ClusterChecksRegisterName      = "clusterchecks"
-	EndpointsChecksRegisterName    = "endpointschecks"
-	EtcdRegisterName               = "etcd"
-	KubeletRegisterName            = "kubelet"
-	KubeServicesRegisterName       = "kube_services"
-	KubeServicesFileRegisterName   = "kube_services_file"
-	KubeEndpointsRegisterName      = "kube_endpoints"
-	KubeEndpointsFileRegisterName  = "kube_endpoints_file"
-	PrometheusPodsRegisterName     = "prometheus_pods"
-	PrometheusServicesRegisterName = "prometheus_services"
-	ZookeeperRegisterName          = "zookeeper"
+	ConsulRegisterName               = "consul"
+	ClusterChecksRegisterName        = "clusterchecks"
+	DatadogCheckPodsRegisterName     = "datadogcheck_pods"
+	DatadogCheckServicesRegisterName = "datadogcheck_services"
+	EndpointsChecksRegisterName      = "endpointschecks"
+	EtcdRegisterName                 = "etcd"
+	KubeletRegisterName              = "kubelet"
+	KubeServicesRegisterName         = "kube_services"
+	KubeServicesFileRegisterName     = "kube_services_file"
+	KubeEndpointsRegisterName        = "kube_endpoints"
+	KubeEndpointsFileRegisterName    = "kube_endpoints_file"
+	PrometheusPodsRegisterName       = "prometheus_pods"
+	PrometheusServicesRegisterName   = "prometheus_services"
+	RemoteConfigRegisterName         = "remote_config"
+	ZookeeperRegisterName            = "zookeeper"
 )
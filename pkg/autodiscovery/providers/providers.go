@@ -50,3 +50,12 @@ type ConfigProvider interface {
 	// The result is displayed in diagnostic tools such as `agent status`.
 	GetConfigErrors() map[string]ErrorMsgSet
 }
+
+// StoppableConfigProvider is a ConfigProvider holding resources that are
+// released when AutoConfig stops polling it.
+type StoppableConfigProvider interface {
+	ConfigProvider
+
+	// Stop releases the resources of the provider.
+	Stop()
+}
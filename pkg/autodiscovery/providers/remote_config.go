@@ -0,0 +1,146 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build !serverless
+// +build !serverless
+
+package providers
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/providers/names"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/config/remote"
+	"github.com/DataDog/datadog-agent/pkg/config/remote/data"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/version"
+)
+
+// RemoteConfigProvider implements the ConfigProvider interface for the check
+// and log configs delivered through remote configuration.
+type RemoteConfigProvider struct {
+	sync.RWMutex
+	client   *remote.Client
+	configs  map[data.Product][]integration.Config
+	upToDate bool
+}
+
+// NewRemoteConfigProvider returns a new ConfigProvider consuming the
+// integrations and logs remote configuration products.
+// Connectivity is not checked at this stage to allow for retries, Collect will do it.
+func NewRemoteConfigProvider(*config.ConfigurationProviders) (ConfigProvider, error) {
+	if !config.Datadog.GetBool("remote_configuration.enabled") || !config.Datadog.GetBool("remote_configuration.integrations.enabled") {
+		return nil, errors.New("the remote config provider requires remote_configuration.enabled and remote_configuration.integrations.enabled to be set")
+	}
+
+	return &RemoteConfigProvider{
+		configs: make(map[data.Product][]integration.Config),
+	}, nil
+}
+
+// String returns a string representation of the RemoteConfigProvider
+func (p *RemoteConfigProvider) String() string {
+	return names.RemoteConfig
+}
+
+// Collect returns the configs received through remote configuration
+func (p *RemoteConfigProvider) Collect(ctx context.Context) ([]integration.Config, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	if p.client == nil {
+		client, err := remote.NewClient(
+			remote.Facts{ID: "agent-autodiscovery", Name: "agent-autodiscovery", Version: version.AgentVersion},
+			[]data.Product{data.ProductIntegrations, data.ProductLogs},
+		)
+		if err != nil {
+			return []integration.Config{}, err
+		}
+
+		p.client = client
+		go p.listen(client.IntegrationsUpdates())
+	}
+
+	p.upToDate = true
+
+	var configs []integration.Config
+	for _, product := range []data.Product{data.ProductIntegrations, data.ProductLogs} {
+		configs = append(configs, p.configs[product]...)
+	}
+
+	return configs, nil
+}
+
+// Stop closes the remote configuration client
+func (p *RemoteConfigProvider) Stop() {
+	p.Lock()
+	defer p.Unlock()
+
+	if p.client != nil {
+		p.client.Close()
+		p.client = nil
+	}
+}
+
+// IsUpToDate returns whether no update was received since the last Collect
+func (p *RemoteConfigProvider) IsUpToDate(ctx context.Context) (bool, error) {
+	p.RLock()
+	defer p.RUnlock()
+	return p.upToDate, nil
+}
+
+func (p *RemoteConfigProvider) listen(updates <-chan remote.IntegrationsUpdate) {
+	for update := range updates {
+		configs := make([]integration.Config, 0, len(update.Configs))
+		for _, c := range update.Configs {
+			configs = append(configs, remoteIntegrationConfig(c))
+		}
+
+		log.Debugf("Received %d %s configs through remote configuration", len(configs), update.Product)
+
+		p.Lock()
+		p.configs[update.Product] = configs
+		p.upToDate = false
+		p.Unlock()
+	}
+}
+
+// remoteIntegrationConfig converts a config received through remote
+// configuration to an integration config.
+func remoteIntegrationConfig(c remote.IntegrationConfig) integration.Config {
+	config := integration.Config{
+		Name:          c.Name,
+		ADIdentifiers: c.ADIdentifiers,
+		InitConfig:    integration.Data("{}"),
+		Source:        "remote_config:" + c.ID,
+	}
+
+	if len(c.InitConfig) > 0 {
+		config.InitConfig = integration.Data(c.InitConfig)
+	}
+
+	for _, instance := range c.Instances {
+		config.Instances = append(config.Instances, integration.Data(instance))
+	}
+
+	if len(c.Logs) > 0 {
+		config.LogsConfig = integration.Data(c.Logs)
+	}
+
+	return config
+}
+
+func init() {
+	RegisterProvider(names.RemoteConfigRegisterName, NewRemoteConfigProvider)
+}
+
+// GetConfigErrors is not implemented for the RemoteConfigProvider
+func (p *RemoteConfigProvider) GetConfigErrors() map[string]ErrorMsgSet {
+	return make(map[string]ErrorMsgSet)
+}
@@ -0,0 +1,92 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build !serverless
+// +build !serverless
+
+package providers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/config/remote"
+	"github.com/DataDog/datadog-agent/pkg/config/remote/data"
+)
+
+func TestNewRemoteConfigProvider(t *testing.T) {
+	defer config.Datadog.Set("remote_configuration.enabled", false)
+	defer config.Datadog.Set("remote_configuration.integrations.enabled", false)
+
+	config.Datadog.Set("remote_configuration.enabled", true)
+	_, err := NewRemoteConfigProvider(nil)
+	assert.Error(t, err)
+
+	config.Datadog.Set("remote_configuration.integrations.enabled", true)
+	p, err := NewRemoteConfigProvider(nil)
+	assert.NoError(t, err)
+	assert.Implements(t, (*StoppableConfigProvider)(nil), p)
+}
+
+func TestRemoteConfigProviderListen(t *testing.T) {
+	p := &RemoteConfigProvider{
+		configs:  make(map[data.Product][]integration.Config),
+		upToDate: true,
+	}
+
+	redis := remote.IntegrationConfig{
+		Config:        remote.Config{ID: "redis", Version: 1},
+		Name:          "redisdb",
+		ADIdentifiers: []string{"redis"},
+		Instances:     []json.RawMessage{json.RawMessage(`{"host":"%%host%%"}`)},
+	}
+	nginxLogs := remote.IntegrationConfig{
+		Config: remote.Config{ID: "nginx", Version: 1},
+		Name:   "nginx",
+		Logs:   json.RawMessage(`[{"type":"file","path":"/var/log/nginx/access.log"}]`),
+	}
+
+	updates := make(chan remote.IntegrationsUpdate, 3)
+	updates <- remote.IntegrationsUpdate{Product: data.ProductIntegrations, Configs: []remote.IntegrationConfig{redis}}
+	updates <- remote.IntegrationsUpdate{Product: data.ProductLogs, Configs: []remote.IntegrationConfig{nginxLogs}}
+	close(updates)
+
+	p.listen(updates)
+
+	assert.False(t, p.upToDate)
+	assert.Equal(t, map[data.Product][]integration.Config{
+		data.ProductIntegrations: {
+			{
+				Name:          "redisdb",
+				ADIdentifiers: []string{"redis"},
+				InitConfig:    integration.Data("{}"),
+				Instances:     []integration.Data{integration.Data(`{"host":"%%host%%"}`)},
+				Source:        "remote_config:redis",
+			},
+		},
+		data.ProductLogs: {
+			{
+				Name:       "nginx",
+				InitConfig: integration.Data("{}"),
+				LogsConfig: integration.Data(`[{"type":"file","path":"/var/log/nginx/access.log"}]`),
+				Source:     "remote_config:nginx",
+			},
+		},
+	}, p.configs)
+
+	// removals are updates without the removed configs
+	updates = make(chan remote.IntegrationsUpdate, 1)
+	updates <- remote.IntegrationsUpdate{Product: data.ProductIntegrations, Configs: []remote.IntegrationConfig{}}
+	close(updates)
+
+	p.listen(updates)
+
+	assert.Empty(t, p.configs[data.ProductIntegrations])
+	assert.Len(t, p.configs[data.ProductLogs], 1)
+}
@@ -0,0 +1,446 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package openmetrics
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	// CheckName is the name of the check. It is the name of the checks generated by the
+	// Prometheus autodiscovery providers, so that they are run by this check when the Python
+	// check is not available.
+	CheckName = "openmetrics"
+
+	defaultTimeout = 10 // seconds
+
+	acceptHeader = "application/openmetrics-text;version=1.0.0,text/plain;version=0.0.4;q=0.5,*/*;q=0.1"
+)
+
+// Check scrapes an endpoint exposing metrics in the Prometheus or OpenMetrics text format.
+type Check struct {
+	core.CheckBase
+	instance *instanceConfig
+	client   *http.Client
+	filters  []metricFilter // the metrics to collect
+	excluded []metricFilter // the metrics not to collect
+}
+
+type instanceConfig struct {
+	// PrometheusURL is the endpoint of the V1 configurations.
+	PrometheusURL string `yaml:"prometheus_url"`
+	// OpenMetricsEndpoint is the endpoint of the V2 configurations, it supersedes PrometheusURL.
+	OpenMetricsEndpoint string `yaml:"openmetrics_endpoint"`
+
+	Namespace      string            `yaml:"namespace"`
+	Metrics        []interface{}     `yaml:"metrics"`
+	PromPrefix     string            `yaml:"prometheus_metrics_prefix"`
+	RawPrefix      string            `yaml:"raw_metric_prefix"`
+	IgnoreMetrics  []string          `yaml:"ignore_metrics"`
+	ExcludeMetrics []string          `yaml:"exclude_metrics"`
+	ExcludeLabels  []string          `yaml:"exclude_labels"`
+	LabelsMapper   map[string]string `yaml:"labels_mapper"`
+	RenameLabels   map[string]string `yaml:"rename_labels"`
+	HealthCheck    *bool             `yaml:"health_service_check"`
+	EnableHealth   *bool             `yaml:"enable_health_service_check"`
+	Headers        map[string]string `yaml:"headers"`
+	ExtraHeaders   map[string]string `yaml:"extra_headers"`
+	TLSVerify      *bool             `yaml:"tls_verify"`
+	Timeout        int               `yaml:"timeout"`
+}
+
+// v2 reports whether the instance is an OpenMetrics V2 configuration, whose metrics are
+// regular expressions and whose counters are suffixed with ".count".
+func (c *instanceConfig) v2() bool { return c.OpenMetricsEndpoint != "" }
+
+func (c *instanceConfig) endpoint() string {
+	if c.v2() {
+		return c.OpenMetricsEndpoint
+	}
+	return c.PrometheusURL
+}
+
+func (c *instanceConfig) prefix() string {
+	if c.v2() {
+		return c.RawPrefix
+	}
+	return c.PromPrefix
+}
+
+func (c *instanceConfig) healthCheck() bool {
+	enabled := c.HealthCheck
+	if c.v2() {
+		enabled = c.EnableHealth
+	}
+	return enabled == nil || *enabled
+}
+
+// metricFilter selects the metrics to collect and their names.
+type metricFilter struct {
+	name    string         // the name of the metric, if not a pattern
+	pattern *regexp.Regexp // the pattern of the names of the metrics, if any
+	rename  string         // the name to report the metric with, if any
+}
+
+func (f *metricFilter) match(name string) bool {
+	if f.pattern != nil {
+		return f.pattern.MatchString(name)
+	}
+	return f.name == name
+}
+
+// newMetricFilter returns the filter of the metrics named name, which is a wildcard pattern
+// in V1 configurations and a regular expression in V2 configurations.
+func newMetricFilter(name, rename string, v2 bool) (metricFilter, error) {
+	f := metricFilter{name: name, rename: rename}
+	switch {
+	case v2:
+		re, err := regexp.Compile("^(?:" + name + ")$")
+		if err != nil {
+			return f, fmt.Errorf("invalid metric pattern %q: %v", name, err)
+		}
+		f.pattern = re
+	case strings.Contains(name, "*"):
+		f.pattern = regexp.MustCompile("^" + strings.ReplaceAll(regexp.QuoteMeta(name), `\*`, ".*") + "$")
+	}
+	return f, nil
+}
+
+// parseMetrics parses the metrics option, a list of metric names or of maps of metric names
+// to the names they are reported with.
+func parseMetrics(items []interface{}, v2 bool) ([]metricFilter, error) {
+	var filters []metricFilter
+	add := func(name, rename string) error {
+		f, err := newMetricFilter(name, rename, v2)
+		if err != nil {
+			return err
+		}
+		filters = append(filters, f)
+		return nil
+	}
+	for _, item := range items {
+		switch v := item.(type) {
+		case string:
+			if err := add(v, ""); err != nil {
+				return nil, err
+			}
+		case map[interface{}]interface{}:
+			for name, rename := range v {
+				n, ok1 := name.(string)
+				r, ok2 := rename.(string)
+				if !ok1 || !ok2 {
+					return nil, fmt.Errorf("invalid metric mapping %v: %v", name, rename)
+				}
+				if err := add(n, r); err != nil {
+					return nil, err
+				}
+			}
+		default:
+			return nil, fmt.Errorf("invalid metric %v", item)
+		}
+	}
+	return filters, nil
+}
+
+func (c *Check) parse(data []byte) error {
+	instance := &instanceConfig{Timeout: defaultTimeout}
+	if err := yaml.Unmarshal(data, instance); err != nil {
+		return err
+	}
+	if instance.endpoint() == "" {
+		return errors.New("missing prometheus_url or openmetrics_endpoint")
+	}
+	if len(instance.Metrics) == 0 {
+		return errors.New("missing metrics")
+	}
+	filters, err := parseMetrics(instance.Metrics, instance.v2())
+	if err != nil {
+		return err
+	}
+	excluded := instance.IgnoreMetrics
+	if instance.v2() {
+		excluded = instance.ExcludeMetrics
+	}
+	c.excluded = nil
+	for _, name := range excluded {
+		f, err := newMetricFilter(name, "", instance.v2())
+		if err != nil {
+			return err
+		}
+		c.excluded = append(c.excluded, f)
+	}
+	c.instance = instance
+	c.filters = filters
+	return nil
+}
+
+// Configure parses the check configuration and initializes the check
+func (c *Check) Configure(data integration.Data, initConfig integration.Data, source string) error {
+	if err := c.parse(data); err != nil {
+		log.Errorf("Error parsing configuration file: %s", err)
+		return err
+	}
+	c.BuildID(data, initConfig)
+
+	c.client = &http.Client{
+		Timeout: time.Duration(c.instance.Timeout) * time.Second,
+		Transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: c.instance.TLSVerify != nil && !*c.instance.TLSVerify,
+			},
+		},
+	}
+
+	return c.CommonConfigure(data, source)
+}
+
+// Run scrapes the endpoint and submits its metrics
+func (c *Check) Run() error {
+	sender, err := c.GetSender()
+	if err != nil {
+		return err
+	}
+	defer sender.Commit()
+
+	families, err := c.scrape()
+	if c.instance.healthCheck() {
+		status, message := metrics.ServiceCheckOK, ""
+		if err != nil {
+			status, message = metrics.ServiceCheckCritical, err.Error()
+		}
+		healthName := "prometheus.health"
+		if c.instance.v2() {
+			healthName = "openmetrics.health"
+		}
+		sender.ServiceCheck(c.metricName(healthName), status, "", []string{"endpoint:" + c.instance.endpoint()}, message)
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, f := range families {
+		c.submitFamily(sender, f)
+	}
+	return nil
+}
+
+// scrape returns the metric families exposed by the endpoint.
+func (c *Check) scrape() ([]*family, error) {
+	req, err := http.NewRequest(http.MethodGet, c.instance.endpoint(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", acceptHeader)
+	for k, v := range c.instance.Headers {
+		req.Header.Set(k, v)
+	}
+	for k, v := range c.instance.ExtraHeaders {
+		req.Header.Set(k, v)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d scraping %s", resp.StatusCode, c.instance.endpoint())
+	}
+	return parseFamilies(resp.Body)
+}
+
+// metricName returns the name of the metric name reported with the namespace.
+func (c *Check) metricName(name string) string {
+	if c.instance.Namespace == "" {
+		return name
+	}
+	return c.instance.Namespace + "." + name
+}
+
+// reportedName returns the name the metric family named name should be reported with, or
+// false if the family must not be collected.
+func (c *Check) reportedName(name string) (string, bool) {
+	name = strings.TrimPrefix(name, c.instance.prefix())
+	for _, f := range c.excluded {
+		if f.match(name) {
+			return "", false
+		}
+	}
+	for _, f := range c.filters {
+		if !f.match(name) {
+			continue
+		}
+		if f.rename != "" {
+			return c.metricName(f.rename), true
+		}
+		return c.metricName(name), true
+	}
+	return "", false
+}
+
+// tags returns the tags of the labels of the sample, except for the label named skip.
+func (c *Check) tags(s *sample, skip string) []string {
+	renamed := c.instance.LabelsMapper
+	if c.instance.v2() {
+		renamed = c.instance.RenameLabels
+	}
+	tags := make([]string, 0, len(s.labels))
+	for _, l := range s.labels {
+		if l.name == skip || l.value == "" || contains(c.instance.ExcludeLabels, l.name) {
+			continue
+		}
+		name := l.name
+		if r, ok := renamed[name]; ok {
+			name = r
+		}
+		tags = append(tags, name+":"+l.value)
+	}
+	return tags
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// submitFamily submits the samples of the metric family f:
+//   - counters are submitted as monotonic counts, with their "_total" suffix replaced with
+//     ".count" in V2 configurations,
+//   - gauges and untyped metrics are submitted as gauges,
+//   - histogram buckets are submitted as distributions suffixed with ".bucket",
+//   - summary quantiles are submitted as gauges suffixed with ".quantile",
+//   - histogram and summary sums and counts are submitted as monotonic counts suffixed with
+//     ".sum" and ".count".
+func (c *Check) submitFamily(sender aggregator.Sender, f *family) {
+	familyName := f.name
+	if f.typ == typeCounter && c.instance.v2() {
+		// V2 configurations match and report counters without their suffix
+		familyName = strings.TrimSuffix(familyName, "_total")
+	}
+	name, ok := c.reportedName(familyName)
+	if !ok {
+		return
+	}
+	switch f.typ {
+	case typeCounter:
+		if c.instance.v2() {
+			name += ".count"
+		}
+		for i := range f.samples {
+			if s := &f.samples[i]; !strings.HasSuffix(s.name, "_created") {
+				sender.MonotonicCount(name, s.value, "", c.tags(s, ""))
+			}
+		}
+	case typeHistogram:
+		c.submitBuckets(sender, name+".bucket", f)
+		c.submitSumAndCount(sender, name, f)
+	case typeSummary:
+		for i := range f.samples {
+			s := &f.samples[i]
+			if q, ok := s.labelValue("quantile"); ok && s.name == f.name {
+				sender.Gauge(name+".quantile", s.value, "", append(c.tags(s, "quantile"), "quantile:"+q))
+			}
+		}
+		c.submitSumAndCount(sender, name, f)
+	default:
+		for i := range f.samples {
+			s := &f.samples[i]
+			sender.Gauge(name, s.value, "", c.tags(s, ""))
+		}
+	}
+}
+
+func (c *Check) submitSumAndCount(sender aggregator.Sender, name string, f *family) {
+	for i := range f.samples {
+		switch s := &f.samples[i]; s.name {
+		case f.name + "_sum":
+			sender.MonotonicCount(name+".sum", s.value, "", c.tags(s, ""))
+		case f.name + "_count":
+			sender.MonotonicCount(name+".count", s.value, "", c.tags(s, ""))
+		}
+	}
+}
+
+type bucket struct {
+	upperBound float64
+	count      float64
+}
+
+// submitBuckets submits the buckets of the histogram f as a distribution. The cumulative
+// buckets of each series of the histogram are converted to the count of each bucket.
+func (c *Check) submitBuckets(sender aggregator.Sender, name string, f *family) {
+	var (
+		series  []string // the tags keys of the series, in order
+		buckets = make(map[string][]bucket)
+		tags    = make(map[string][]string)
+	)
+	for i := range f.samples {
+		s := &f.samples[i]
+		if s.name != f.name+"_bucket" {
+			continue
+		}
+		le, ok := s.labelValue("le")
+		if !ok {
+			continue
+		}
+		upperBound, err := strconv.ParseFloat(le, 64)
+		if err != nil {
+			log.Debugf("Invalid bucket upper bound %q of metric %s", le, s.name)
+			continue
+		}
+		t := c.tags(s, "le")
+		key := strings.Join(t, ",")
+		if _, ok := buckets[key]; !ok {
+			series = append(series, key)
+			tags[key] = t
+		}
+		buckets[key] = append(buckets[key], bucket{upperBound: upperBound, count: s.value})
+	}
+	for _, key := range series {
+		b := buckets[key]
+		sort.Slice(b, func(i, j int) bool { return b[i].upperBound < b[j].upperBound })
+		lowerBound, previous := 0.0, 0.0
+		if len(b) > 0 && b[0].upperBound <= 0 {
+			lowerBound = math.Inf(-1)
+		}
+		for _, bk := range b {
+			sender.HistogramBucket(name, int64(bk.count-previous), lowerBound, bk.upperBound, true, "", tags[key], false)
+			lowerBound, previous = bk.upperBound, bk.count
+		}
+	}
+}
+
+func openmetricsFactory() check.Check {
+	return &Check{
+		CheckBase: core.NewCheckBase(CheckName),
+	}
+}
+
+func init() {
+	core.RegisterCheck(CheckName, openmetricsFactory)
+}
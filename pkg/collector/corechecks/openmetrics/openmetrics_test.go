@@ -0,0 +1,135 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package openmetrics
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+const testPayload = `# TYPE http_requests_total counter
+http_requests_total{method="post",code="200"} 1027
+# TYPE go_goroutines gauge
+go_goroutines 42
+# TYPE rpc_duration_seconds summary
+rpc_duration_seconds{quantile="0.5"} 4773
+rpc_duration_seconds_sum 17560473
+rpc_duration_seconds_count 2693
+# TYPE request_duration_seconds histogram
+request_duration_seconds_bucket{le="0.05"} 24
+request_duration_seconds_bucket{le="0.5"} 30
+request_duration_seconds_bucket{le="+Inf"} 31
+request_duration_seconds_sum 5
+request_duration_seconds_count 31
+`
+
+func newTestCheck(t *testing.T, config string) (*Check, *mocksender.MockSender) {
+	c := openmetricsFactory().(*Check)
+	require.NoError(t, c.Configure([]byte(config), nil, "test"))
+	sender := mocksender.NewMockSender(c.ID())
+	sender.SetupAcceptAll()
+	return c, sender
+}
+
+func newTestServer(payload string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/metrics" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(payload))
+	}))
+}
+
+func TestRunV1(t *testing.T) {
+	srv := newTestServer(testPayload)
+	defer srv.Close()
+
+	c, sender := newTestCheck(t, fmt.Sprintf(`
+prometheus_url: %s/metrics
+namespace: app
+metrics:
+  - http_*
+  - go_goroutines: goroutines
+  - rpc_duration_seconds
+  - request_duration_seconds
+labels_mapper:
+  code: status_code
+exclude_labels:
+  - method
+`, srv.URL))
+	require.NoError(t, c.Run())
+
+	sender.AssertServiceCheck(t, "app.prometheus.health", metrics.ServiceCheckOK, "", []string{"endpoint:" + srv.URL + "/metrics"}, "")
+	sender.AssertMetric(t, "MonotonicCount", "app.http_requests_total", 1027, "", []string{"status_code:200"})
+	sender.AssertMetric(t, "Gauge", "app.goroutines", 42, "", []string{})
+	sender.AssertMetric(t, "Gauge", "app.rpc_duration_seconds.quantile", 4773, "", []string{"quantile:0.5"})
+	sender.AssertMetric(t, "MonotonicCount", "app.rpc_duration_seconds.sum", 17560473, "", []string{})
+	sender.AssertMetric(t, "MonotonicCount", "app.rpc_duration_seconds.count", 2693, "", []string{})
+	sender.AssertHistogramBucket(t, "HistogramBucket", "app.request_duration_seconds.bucket", 24, 0, 0.05, true, "", []string{}, false)
+	sender.AssertHistogramBucket(t, "HistogramBucket", "app.request_duration_seconds.bucket", 6, 0.05, 0.5, true, "", []string{}, false)
+	sender.AssertHistogramBucket(t, "HistogramBucket", "app.request_duration_seconds.bucket", 1, 0.5, math.Inf(1), true, "", []string{}, false)
+	sender.AssertMetric(t, "MonotonicCount", "app.request_duration_seconds.count", 31, "", []string{})
+	sender.AssertNumberOfCalls(t, "Commit", 1)
+}
+
+func TestRunV2(t *testing.T) {
+	srv := newTestServer(testPayload)
+	defer srv.Close()
+
+	c, sender := newTestCheck(t, fmt.Sprintf(`
+openmetrics_endpoint: %s/metrics
+metrics:
+  - .*
+exclude_metrics:
+  - rpc_.*
+  - request_.*
+`, srv.URL))
+	require.NoError(t, c.Run())
+
+	sender.AssertServiceCheck(t, "openmetrics.health", metrics.ServiceCheckOK, "", []string{"endpoint:" + srv.URL + "/metrics"}, "")
+	sender.AssertMetric(t, "MonotonicCount", "http_requests.count", 1027, "", []string{"method:post", "code:200"})
+	sender.AssertMetric(t, "Gauge", "go_goroutines", 42, "", []string{})
+	sender.AssertNumberOfCalls(t, "MonotonicCount", 1)
+	sender.AssertNumberOfCalls(t, "Gauge", 1)
+	sender.AssertNotCalled(t, "HistogramBucket")
+}
+
+func TestRunError(t *testing.T) {
+	srv := newTestServer(testPayload)
+	defer srv.Close()
+
+	c, sender := newTestCheck(t, fmt.Sprintf(`
+prometheus_url: %s/other
+namespace: app
+metrics: ["*"]
+`, srv.URL))
+	require.Error(t, c.Run())
+
+	sender.AssertServiceCheck(t, "app.prometheus.health", metrics.ServiceCheckCritical, "", []string{"endpoint:" + srv.URL + "/other"}, fmt.Sprintf("unexpected status code 404 scraping %s/other", srv.URL))
+	sender.AssertNotCalled(t, "Gauge")
+}
+
+func TestConfigure(t *testing.T) {
+	for _, config := range []string{
+		`metrics: ["*"]`,
+		`prometheus_url: http://localhost/metrics`,
+		`{openmetrics_endpoint: "http://localhost/metrics", metrics: ["(invalid"]}`,
+		`{prometheus_url: "http://localhost/metrics", metrics: [1]}`,
+	} {
+		c := openmetricsFactory()
+		assert.Error(t, c.Configure([]byte(config), nil, "test"), config)
+	}
+}
@@ -0,0 +1,223 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package openmetrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type metricType int
+
+const (
+	typeUntyped metricType = iota
+	typeCounter
+	typeGauge
+	typeHistogram
+	typeSummary
+)
+
+// metricTypes maps the types of the "# TYPE" lines to the metric types. The types
+// of the OpenMetrics format which are not listed are handled as untyped.
+var metricTypes = map[string]metricType{
+	"counter":   typeCounter,
+	"gauge":     typeGauge,
+	"histogram": typeHistogram,
+	"summary":   typeSummary,
+}
+
+// typeSuffixes lists the suffixes of the samples of each metric type.
+var typeSuffixes = map[metricType][]string{
+	typeCounter:   {"_total", "_created"},
+	typeHistogram: {"_bucket", "_sum", "_count", "_created"},
+	typeSummary:   {"_sum", "_count", "_created"},
+}
+
+type label struct {
+	name, value string
+}
+
+// sample is a sample of a metric family, such as http_requests_total{code="200"} 1027.
+type sample struct {
+	name   string
+	labels []label
+	value  float64
+}
+
+// labelValue returns the value of the label name of the sample.
+func (s *sample) labelValue(name string) (string, bool) {
+	for _, l := range s.labels {
+		if l.name == name {
+			return l.value, true
+		}
+	}
+	return "", false
+}
+
+// family is a metric family, that is all the samples of a metric.
+type family struct {
+	name    string
+	typ     metricType
+	samples []sample
+}
+
+// parseFamilies parses the metric families exposed in the Prometheus text format or in the
+// OpenMetrics text format by r. The families are returned in the order they were exposed.
+// Timestamps and exemplars are ignored.
+func parseFamilies(r io.Reader) ([]*family, error) {
+	var (
+		families []*family
+		byName   = make(map[string]*family)
+	)
+	lookup := func(name string) *family {
+		if f, ok := byName[name]; ok {
+			return f
+		}
+		f := &family{name: name}
+		byName[name] = f
+		families = append(families, f)
+		return f
+	}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+			continue
+		case line == "# EOF":
+			return families, nil
+		case strings.HasPrefix(line, "#"):
+			fields := strings.Fields(line)
+			if len(fields) >= 4 && fields[1] == "TYPE" {
+				f := lookup(fields[2])
+				f.typ = metricTypes[fields[3]]
+			}
+			// HELP, UNIT and comments are ignored
+			continue
+		}
+		s, err := parseSample(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", n, err)
+		}
+		f := familyOf(byName, s.name)
+		if f == nil {
+			f = lookup(s.name)
+		}
+		f.samples = append(f.samples, s)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return families, nil
+}
+
+// familyOf returns the family declared in byName to which the sample named name belongs,
+// or nil if there is none.
+func familyOf(byName map[string]*family, name string) *family {
+	if f, ok := byName[name]; ok {
+		return f
+	}
+	for typ, suffixes := range typeSuffixes {
+		for _, suffix := range suffixes {
+			if !strings.HasSuffix(name, suffix) {
+				continue
+			}
+			if f, ok := byName[strings.TrimSuffix(name, suffix)]; ok && f.typ == typ {
+				return f
+			}
+		}
+	}
+	return nil
+}
+
+// parseSample parses the sample line, such as `name{label="value"} 1 1395066363000`.
+func parseSample(line string) (sample, error) {
+	var s sample
+	i := strings.IndexAny(line, "{ \t")
+	if i <= 0 {
+		return s, fmt.Errorf("invalid sample %q", line)
+	}
+	s.name = line[:i]
+	rest := line[i:]
+	if rest[0] == '{' {
+		labels, n, err := parseLabels(rest)
+		if err != nil {
+			return s, err
+		}
+		s.labels = labels
+		rest = rest[n:]
+	}
+	if i := strings.Index(rest, " # "); i >= 0 {
+		// OpenMetrics exemplar
+		rest = rest[:i]
+	}
+	fields := strings.Fields(rest)
+	if len(fields) == 0 || len(fields) > 2 {
+		return s, fmt.Errorf("invalid value of sample %q", s.name)
+	}
+	v, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return s, fmt.Errorf("invalid value of sample %q: %v", s.name, err)
+	}
+	s.value = v
+	return s, nil
+}
+
+// parseLabels parses the label set starting at the opening brace of s. It returns the labels
+// and the length of the label set.
+func parseLabels(s string) ([]label, int, error) {
+	var labels []label
+	i := 1
+	for {
+		for i < len(s) && (s[i] == ' ' || s[i] == ',') {
+			i++
+		}
+		if i >= len(s) {
+			return nil, 0, fmt.Errorf("unterminated label set")
+		}
+		if s[i] == '}' {
+			return labels, i + 1, nil
+		}
+		eq := strings.IndexByte(s[i:], '=')
+		if eq < 0 {
+			return nil, 0, fmt.Errorf("invalid label set %q", s)
+		}
+		name := strings.TrimSpace(s[i : i+eq])
+		i += eq + 1
+		for i < len(s) && s[i] == ' ' {
+			i++
+		}
+		if i >= len(s) || s[i] != '"' {
+			return nil, 0, fmt.Errorf("invalid value of label %q", name)
+		}
+		var value strings.Builder
+		for i++; ; i++ {
+			if i >= len(s) {
+				return nil, 0, fmt.Errorf("unterminated value of label %q", name)
+			}
+			c := s[i]
+			if c == '"' {
+				break
+			}
+			if c == '\\' && i+1 < len(s) {
+				i++
+				switch s[i] {
+				case 'n':
+					c = '\n'
+				default:
+					c = s[i]
+				}
+			}
+			value.WriteByte(c)
+		}
+		i++
+		labels = append(labels, label{name: name, value: value.String()})
+	}
+}
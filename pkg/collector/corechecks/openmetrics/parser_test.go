@@ -0,0 +1,101 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package openmetrics
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFamiliesPrometheus(t *testing.T) {
+	payload := `# HELP http_requests_total The total number of HTTP requests.
+# TYPE http_requests_total counter
+http_requests_total{method="post",code="200"} 1027 1395066363000
+http_requests_total{method="post",code="400"}    3 1395066363000
+
+# A comment
+msdos_file_access_time_seconds{path="C:\\DIR\\FILE.TXT",error="Cannot find file:\n\"FILE.TXT\""} 1.458255915e9
+metric_without_timestamp_and_labels 12.47
+
+# TYPE rpc_duration_seconds summary
+rpc_duration_seconds{quantile="0.5"} 4773
+rpc_duration_seconds_sum 1.7560473e+07
+rpc_duration_seconds_count 2693
+# TYPE http_request_duration_seconds histogram
+http_request_duration_seconds_bucket{le="0.05"} 24054
+http_request_duration_seconds_bucket{le="+Inf"} 144320
+http_request_duration_seconds_sum 53423
+http_request_duration_seconds_count 144320
+`
+	families, err := parseFamilies(strings.NewReader(payload))
+	require.NoError(t, err)
+	require.Len(t, families, 5)
+
+	assert.Equal(t, "http_requests_total", families[0].name)
+	assert.Equal(t, typeCounter, families[0].typ)
+	assert.Equal(t, []sample{
+		{name: "http_requests_total", labels: []label{{"method", "post"}, {"code", "200"}}, value: 1027},
+		{name: "http_requests_total", labels: []label{{"method", "post"}, {"code", "400"}}, value: 3},
+	}, families[0].samples)
+
+	assert.Equal(t, typeUntyped, families[1].typ)
+	assert.Equal(t, []label{{"path", `C:\DIR\FILE.TXT`}, {"error", "Cannot find file:\n\"FILE.TXT\""}}, families[1].samples[0].labels)
+	assert.Equal(t, "metric_without_timestamp_and_labels", families[2].name)
+	assert.Equal(t, 12.47, families[2].samples[0].value)
+
+	assert.Equal(t, typeSummary, families[3].typ)
+	assert.Len(t, families[3].samples, 3)
+	assert.Equal(t, typeHistogram, families[4].typ)
+	assert.Len(t, families[4].samples, 4)
+	le, ok := families[4].samples[1].labelValue("le")
+	assert.True(t, ok)
+	assert.Equal(t, "+Inf", le)
+}
+
+func TestParseFamiliesOpenMetrics(t *testing.T) {
+	payload := `# TYPE acme_http_router_request_seconds summary
+# UNIT acme_http_router_request_seconds seconds
+# HELP acme_http_router_request_seconds Latency though all of ACME's HTTP request router.
+acme_http_router_request_seconds_sum{path="/api/v1",method="GET"} 9036.32
+acme_http_router_request_seconds_count{path="/api/v1",method="GET"} 807283.0
+acme_http_router_request_seconds_created{path="/api/v1",method="GET"} 1605281325.0
+# TYPE foo counter
+foo_total 17.0 1520879607.789 # {trace_id="KOO5S4vxi0o"} 0.67
+foo_created 1520872607.123
+# TYPE bar gauge
+bar NaN
+# EOF
+ignored 1
+`
+	families, err := parseFamilies(strings.NewReader(payload))
+	require.NoError(t, err)
+	require.Len(t, families, 3)
+
+	assert.Equal(t, typeSummary, families[0].typ)
+	assert.Len(t, families[0].samples, 3)
+	assert.Equal(t, "foo", families[1].name)
+	assert.Equal(t, typeCounter, families[1].typ)
+	assert.Equal(t, []sample{{name: "foo_total", value: 17}, {name: "foo_created", value: 1520872607.123}}, families[1].samples)
+	assert.True(t, math.IsNaN(families[2].samples[0].value))
+}
+
+func TestParseFamiliesErrors(t *testing.T) {
+	for _, payload := range []string{
+		"metric",
+		"metric{",
+		`metric{label="value} 1`,
+		`metric{label=value} 1`,
+		"metric abc",
+		"metric 1 2 3",
+	} {
+		_, err := parseFamilies(strings.NewReader(payload))
+		assert.Error(t, err, payload)
+	}
+}
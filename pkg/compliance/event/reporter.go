@@ -41,7 +41,7 @@ func NewLogReporter(stopper restart.Stopper, sourceName, sourceType, runPath str
 	auditor.Start()
 
 	// setup the pipeline provider that provides pairs of processor and sender
-	pipelineProvider := pipeline.NewProvider(config.NumberOfPipelines, auditor, &diagnostic.NoopMessageReceiver{}, nil, endpoints, context)
+	pipelineProvider := pipeline.NewProvider(config.NumberOfPipelines, auditor, &diagnostic.NoopMessageReceiver{}, nil, endpoints, context, nil)
 	pipelineProvider.Start()
 
 	stopper.Add(pipelineProvider)
@@ -34,16 +34,25 @@ func setupAPM(config Config) {
 	config.SetKnown("apm_config.obfuscation.remove_stack_traces")
 	config.SetKnown("apm_config.obfuscation.redis.enabled")
 	config.SetKnown("apm_config.obfuscation.memcached.enabled")
+	config.SetKnown("apm_config.obfuscation.graphql.enabled")
+	config.SetKnown("apm_config.obfuscation.aws.enabled")
 	config.SetKnown("apm_config.filter_tags.require")
 	config.SetKnown("apm_config.filter_tags.reject")
 	config.SetKnown("apm_config.extra_sample_rate")
+	config.SetKnown("apm_config.tail_sampling.decision_wait_seconds")
+	config.SetKnown("apm_config.tail_sampling.max_spans")
+	config.SetKnown("apm_config.tail_sampling.policies")
 	config.SetKnown("apm_config.dd_agent_bin")
 	config.SetKnown("apm_config.trace_writer.connection_limit")
 	config.SetKnown("apm_config.trace_writer.queue_size")
+	config.SetKnown("apm_config.trace_writer.disk_buffer_path")
+	config.SetKnown("apm_config.trace_writer.disk_buffer_max_size")
 	config.SetKnown("apm_config.service_writer.connection_limit")
 	config.SetKnown("apm_config.service_writer.queue_size")
 	config.SetKnown("apm_config.stats_writer.connection_limit")
 	config.SetKnown("apm_config.stats_writer.queue_size")
+	config.SetKnown("apm_config.stats_writer.disk_buffer_path")
+	config.SetKnown("apm_config.stats_writer.disk_buffer_max_size")
 	config.SetKnown("apm_config.analyzed_rate_by_service.*")
 	config.SetKnown("apm_config.log_throttling")
 	config.SetKnown("apm_config.bucket_size_seconds")
@@ -62,6 +71,7 @@ func setupAPM(config Config) {
 	config.BindEnvAndSetDefault("apm_config.windows_pipe_buffer_size", 1_000_000, "DD_APM_WINDOWS_PIPE_BUFFER_SIZE")                          //nolint:errcheck
 	config.BindEnvAndSetDefault("apm_config.windows_pipe_security_descriptor", "D:AI(A;;GA;;;WD)", "DD_APM_WINDOWS_PIPE_SECURITY_DESCRIPTOR") //nolint:errcheck
 	config.BindEnvAndSetDefault("apm_config.remote_tagger", true, "DD_APM_REMOTE_TAGGER")                                                     //nolint:errcheck
+	config.BindEnvAndSetDefault("apm_config.remote_workloadmeta", false, "DD_APM_REMOTE_WORKLOADMETA")                                        //nolint:errcheck
 
 	config.BindEnv("apm_config.max_catalog_services", "DD_APM_MAX_CATALOG_SERVICES")
 	config.BindEnv("apm_config.receiver_timeout", "DD_APM_RECEIVER_TIMEOUT")
@@ -72,6 +82,9 @@ func setupAPM(config Config) {
 	config.BindEnv("apm_config.errors_per_second", "DD_APM_ERROR_TPS")
 	config.BindEnv("apm_config.disable_rare_sampler", "DD_APM_DISABLE_RARE_SAMPLER")
 	config.BindEnv("apm_config.max_remote_traces_per_second", "DD_APM_MAX_REMOTE_TPS")
+	config.BindEnv("apm_config.tail_sampling.enabled", "DD_APM_TAIL_SAMPLING_ENABLED")
+	config.BindEnv("apm_config.stats_extra_tags", "DD_APM_STATS_EXTRA_TAGS")
+	config.BindEnv("apm_config.stats_extra_tags_max_values", "DD_APM_STATS_EXTRA_TAGS_MAX_VALUES")
 
 	config.BindEnv("apm_config.max_memory", "DD_APM_MAX_MEMORY")
 	config.BindEnv("apm_config.max_cpu_percent", "DD_APM_MAX_CPU_PERCENT")
@@ -84,6 +97,7 @@ func setupAPM(config Config) {
 	config.BindEnv("apm_config.profiling_additional_endpoints", "DD_APM_PROFILING_ADDITIONAL_ENDPOINTS")
 	config.BindEnv("apm_config.additional_endpoints", "DD_APM_ADDITIONAL_ENDPOINTS")
 	config.BindEnv("apm_config.replace_tags", "DD_APM_REPLACE_TAGS")
+	config.BindEnv("apm_config.span_rules", "DD_APM_SPAN_RULES")
 	config.BindEnv("apm_config.analyzed_spans", "DD_APM_ANALYZED_SPANS")
 	config.BindEnv("apm_config.ignore_resources", "DD_APM_IGNORE_RESOURCES", "DD_IGNORE_RESOURCE")
 	config.BindEnv("apm_config.receiver_socket", "DD_APM_RECEIVER_SOCKET")
@@ -125,6 +139,14 @@ func setupAPM(config Config) {
 		return out
 	})
 
+	config.SetEnvKeyTransformer("apm_config.span_rules", func(in string) interface{} {
+		var out []map[string]interface{}
+		if err := json.Unmarshal([]byte(in), &out); err != nil {
+			log.Warnf(`"apm_config.span_rules" can not be parsed: %v`, err)
+		}
+		return out
+	})
+
 	config.SetEnvKeyTransformer("apm_config.analyzed_spans", func(in string) interface{} {
 		out, err := parseAnalyzedSpans(in)
 		if err != nil {
@@ -174,10 +174,14 @@ type MappingProfile struct {
 
 // MetricMapping represent one mapping rule
 type MetricMapping struct {
-	Match     string            `mapstructure:"match" json:"match"`
-	MatchType string            `mapstructure:"match_type" json:"match_type"`
-	Name      string            `mapstructure:"name" json:"name"`
-	Tags      map[string]string `mapstructure:"tags" json:"tags"`
+	Match      string            `mapstructure:"match" json:"match"`
+	MatchType  string            `mapstructure:"match_type" json:"match_type"`
+	MatchTags  map[string]string `mapstructure:"match_tags" json:"match_tags"`
+	Action     string            `mapstructure:"action" json:"action"`
+	Name       string            `mapstructure:"name" json:"name"`
+	Tags       map[string]string `mapstructure:"tags" json:"tags"`
+	RemoveTags []string          `mapstructure:"remove_tags" json:"remove_tags"`
+	RenameTag  map[string]string `mapstructure:"rename_tag" json:"rename_tag"`
 }
 
 // Endpoint represent a datadog endpoint
@@ -290,6 +294,7 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("remote_configuration.refresh_interval", 1*time.Minute)
 	config.BindEnvAndSetDefault("remote_configuration.max_backoff_interval", 5*time.Minute)
 	config.BindEnvAndSetDefault("remote_configuration.clients.ttl_seconds", 30*time.Second)
+	config.BindEnvAndSetDefault("remote_configuration.integrations.enabled", false)
 	// Remote config unstable features
 	config.BindEnvAndSetDefault("remote_configuration.unstable.self_signed", false)
 	config.BindEnvAndSetDefault("remote_configuration.unstable.self_signed_root", "")
@@ -439,6 +444,11 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("serializer_max_payload_size", 2*megaByte+megaByte/2)
 	config.BindEnvAndSetDefault("serializer_max_uncompressed_payload_size", 4*megaByte)
 	config.BindEnvAndSetDefault("serializer_max_series_points_per_payload", 10000)
+	// Compression of the payloads: an empty kind selects the compression the agent was built with
+	// and the level 0 the default level of the compression.
+	config.BindEnvAndSetDefault("serializer_compression_kind", "")
+	config.BindEnvAndSetDefault("serializer_compression_level", 0)
+	config.SetKnown("serializer_compression_per_payload")
 
 	config.BindEnvAndSetDefault("use_v2_api.series", false)
 	// Serializer: allow user to blacklist any kind of payload to be sent
@@ -448,6 +458,16 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("enable_payloads.sketches", true)
 	config.BindEnvAndSetDefault("enable_payloads.json_to_v1_intake", true)
 
+	// Prometheus remote-write output of the series and sketches
+	config.BindEnvAndSetDefault("prometheus_remote_write.enabled", false)
+	config.BindEnvAndSetDefault("prometheus_remote_write.url", "")
+	config.BindEnvAndSetDefault("prometheus_remote_write.send_to_datadog", true)
+	config.BindEnvAndSetDefault("prometheus_remote_write.username", "")
+	config.BindEnvAndSetDefault("prometheus_remote_write.password", "")
+	config.BindEnvAndSetDefault("prometheus_remote_write.bearer_token", "")
+	config.BindEnvAndSetDefault("prometheus_remote_write.max_series_per_payload", 2000)
+	config.BindEnvAndSetDefault("prometheus_remote_write.sketch_quantiles", []string{"0.5", "0.75", "0.9", "0.95", "0.99"})
+
 	// Forwarder
 	config.BindEnvAndSetDefault("additional_endpoints", map[string][]string{})
 	config.BindEnvAndSetDefault("forwarder_timeout", 20)
@@ -456,6 +476,7 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("forwarder_connection_reset_interval", 0)                                // in seconds, 0 means disabled
 	config.BindEnvAndSetDefault("forwarder_apikey_validation_interval", DefaultAPIKeyValidationInterval) // in minutes
 	config.BindEnvAndSetDefault("forwarder_num_workers", 1)
+	config.SetKnown("forwarder_compression_per_domain")
 	config.BindEnvAndSetDefault("forwarder_stop_timeout", 2)
 	// Forwarder retry settings
 	config.BindEnvAndSetDefault("forwarder_backoff_factor", 2)
@@ -515,6 +536,11 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("dogstatsd_metrics_stats_enable", false)
 	config.BindEnvAndSetDefault("dogstatsd_tags", []string{})
 	config.BindEnvAndSetDefault("dogstatsd_mapper_cache_size", 1000)
+	// Maximum number of contexts per metric name and per origin, 0 disables the limit
+	config.BindEnvAndSetDefault("dogstatsd_context_limit_per_metric", 0)
+	config.BindEnvAndSetDefault("dogstatsd_context_limit_per_origin", 0)
+	// What to do with the samples of the contexts over the limits: `drop` or `collapse`
+	config.BindEnvAndSetDefault("dogstatsd_context_limit_action", "drop")
 	config.BindEnvAndSetDefault("dogstatsd_string_interner_size", 4096)
 	// Enable check for Entity-ID presence when enriching Dogstatsd metrics with tags
 	config.BindEnvAndSetDefault("dogstatsd_entity_id_precedence", false)
@@ -565,6 +591,10 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("autoconfig_exclude_features", []string{})
 	config.BindEnvAndSetDefault("autoconfig_include_features", []string{})
 
+	// Workloadmeta
+	config.BindEnvAndSetDefault("workloadmeta.process_collector.enabled", false)
+	config.BindEnvAndSetDefault("process_listener.cmdline_patterns", map[string]string{})
+
 	// Docker
 	config.BindEnvAndSetDefault("docker_query_timeout", int64(5))
 	config.BindEnvAndSetDefault("docker_labels_as_tags", map[string]string{})
@@ -822,6 +852,8 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("logs_config.aggregation_timeout", 1000)
 	// Time in seconds
 	config.BindEnvAndSetDefault("logs_config.file_scan_period", 10.0)
+	// Time in seconds during which identical logs are collapsed into a single one, 0 disables it
+	config.BindEnvAndSetDefault("logs_config.dedup_window", 0)
 
 	// The cardinality of tags to send for checks and dogstatsd respectively.
 	// Choices are: low, orchestrator, high.
@@ -952,6 +984,7 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("security_agent.expvar_port", 5011)
 	config.BindEnvAndSetDefault("security_agent.log_file", defaultSecurityAgentLogFile)
 	config.BindEnvAndSetDefault("security_agent.remote_tagger", true)
+	config.BindEnvAndSetDefault("security_agent.remote_workloadmeta", false)
 
 	// Datadog security agent (compliance)
 	config.BindEnvAndSetDefault("compliance_config.enabled", false)
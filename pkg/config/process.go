@@ -140,6 +140,7 @@ func setupProcesses(config Config) {
 	procBindEnvAndSetDefault(config, "process_config.internal_profiling.enabled", false)
 	procBindEnvAndSetDefault(config, "process_config.grpc_connection_timeout_secs", DefaultGRPCConnectionTimeoutSecs)
 	procBindEnvAndSetDefault(config, "process_config.remote_tagger", false)
+	procBindEnvAndSetDefault(config, "process_config.remote_workloadmeta", false)
 	procBindEnvAndSetDefault(config, "process_config.disable_realtime_checks", false)
 
 	// Process Discovery Check
@@ -38,6 +38,10 @@ func TestProcessDefaultConfig(t *testing.T) {
 			key:          "process_config.remote_tagger",
 			defaultValue: false,
 		},
+		{
+			key:          "process_config.remote_workloadmeta",
+			defaultValue: false,
+		},
 		{
 			key:          "process_config.process_discovery.enabled",
 			defaultValue: true,
@@ -189,6 +193,12 @@ func TestEnvVarOverride(t *testing.T) {
 			value:    "true",
 			expected: true,
 		},
+		{
+			key:      "process_config.remote_workloadmeta",
+			env:      "DD_PROCESS_CONFIG_REMOTE_WORKLOADMETA",
+			value:    "true",
+			expected: true,
+		},
 		{
 			key:      "process_config.process_discovery.enabled",
 			env:      "DD_PROCESS_CONFIG_PROCESS_DISCOVERY_ENABLED",
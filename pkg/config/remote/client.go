@@ -32,7 +32,8 @@ type Client struct {
 
 	lastPollErr error
 
-	apmSamplingUpdates chan APMSamplingUpdate
+	apmSamplingUpdates  chan APMSamplingUpdate
+	integrationsUpdates chan IntegrationsUpdate
 }
 
 // Facts are facts used to identify the client
@@ -77,22 +78,26 @@ func newClient(facts Facts, products []data.Product, dialOpts ...grpc.DialOption
 		enabledProducts[product] = struct{}{}
 	}
 	return &Client{
-		ctx:                ctx,
-		facts:              facts,
-		enabledProducts:    enabledProducts,
-		grpc:               grpcClient,
-		close:              close,
-		pollInterval:       1 * time.Second,
-		partialClient:      partialClient,
-		apmSamplingUpdates: make(chan APMSamplingUpdate, 8),
-		configs:            newConfigs(),
+		ctx:                 ctx,
+		facts:               facts,
+		enabledProducts:     enabledProducts,
+		grpc:                grpcClient,
+		close:               close,
+		pollInterval:        1 * time.Second,
+		partialClient:       partialClient,
+		apmSamplingUpdates:  make(chan APMSamplingUpdate, 8),
+		integrationsUpdates: make(chan IntegrationsUpdate, len(integrationsProducts)),
+		configs:             newConfigs(),
 	}, nil
 }
 
 // Close closes the client
 func (c *Client) Close() {
+	c.m.Lock()
+	defer c.m.Unlock()
 	c.close()
 	close(c.apmSamplingUpdates)
+	close(c.integrationsUpdates)
 }
 
 func (c *Client) pollLoop() {
@@ -120,6 +125,10 @@ func (c *Client) products() []data.Product {
 func (c *Client) poll() error {
 	c.m.Lock()
 	defer c.m.Unlock()
+	// the update channels are closed once the client is closed
+	if c.ctx.Err() != nil {
+		return nil
+	}
 	state := c.partialClient.State()
 	lastPollErr := ""
 	if c.lastPollErr != nil {
@@ -194,9 +203,46 @@ func (c *Client) publishUpdates(update update) {
 			log.Warnf("apm sampling update queue is full, dropping configuration")
 		}
 	}
+	if update.integrationsUpdate != nil || update.logsUpdate != nil {
+		c.publishIntegrationsUpdates(update.integrationsUpdate, update.logsUpdate)
+	}
+}
+
+// publishIntegrationsUpdates queues the updates of the integrations and logs
+// products. An update holds all the configs of its product, so it replaces the
+// update of the same product still queued instead of being dropped when the
+// consumer lags behind: the queue holds at most the latest update of each product.
+func (c *Client) publishIntegrationsUpdates(updates ...*IntegrationsUpdate) {
+	pending := make(map[data.Product]IntegrationsUpdate, len(integrationsProducts))
+	for drained := false; !drained; {
+		select {
+		case queued := <-c.integrationsUpdates:
+			pending[queued.Product] = queued
+		default:
+			drained = true
+		}
+	}
+	for _, update := range updates {
+		if update != nil {
+			pending[update.Product] = *update
+		}
+	}
+	// poll holds the lock, the queue only has room for one update per
+	// product and nothing else publishes to it, so this cannot block
+	for _, product := range integrationsProducts {
+		if update, ok := pending[product]; ok {
+			c.integrationsUpdates <- update
+		}
+	}
 }
 
 // APMSamplingUpdates returns a chan to consume apm sampling updates
 func (c *Client) APMSamplingUpdates() <-chan APMSamplingUpdate {
 	return c.apmSamplingUpdates
 }
+
+// IntegrationsUpdates returns a chan to consume the updates of the integrations
+// and logs products
+func (c *Client) IntegrationsUpdates() <-chan IntegrationsUpdate {
+	return c.integrationsUpdates
+}
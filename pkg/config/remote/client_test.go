@@ -161,6 +161,112 @@ func TestClientValidResponse(t *testing.T) {
 	}, apmUpdate)
 }
 
+func TestClientIntegrationsResponse(t *testing.T) {
+	testServer := getTestServer(t)
+
+	targetsKey := generateKey()
+	embeddedRoot := generateRoot(generateKey(), 1, targetsKey)
+	rawIntegrationConfig := []byte(`{"name":"redisdb","instances":[{"host":"localhost"}]}`)
+	target1 := generateTarget(rawIntegrationConfig, 3)
+	target2content, target2 := generateRandomTarget(2)
+	targets := generateTargets(targetsKey, 1, data.TargetFiles{"datadog/3/INTEGRATIONS/redis/config": target1, "datadog/3/TESTING1/id/2": target2})
+	config.Datadog.Set("remote_configuration.director_root", embeddedRoot)
+
+	testFacts := Facts{ID: "test-agent", Name: "test-agent-name", Version: "v6.1.1"}
+	client, err := newClient(testFacts, []rdata.Product{rdata.ProductIntegrations})
+	assert.NoError(t, err)
+
+	testServer.On("ClientGetConfigs", mock.Anything, &pbgo.ClientGetConfigsRequest{Client: &pbgo.Client{
+		State: &pbgo.ClientState{
+			RootVersion:    meta.RootsDirector().LastVersion(),
+			TargetsVersion: 0,
+			Error:          "",
+		},
+		Id:       testFacts.ID,
+		Name:     testFacts.Name,
+		Version:  testFacts.Version,
+		Products: []string{string(rdata.ProductIntegrations)},
+	}}).Return(&pbgo.ClientGetConfigsResponse{
+		Roots: []*pbgo.TopMeta{},
+		Targets: &pbgo.TopMeta{
+			Version: 1,
+			Raw:     targets,
+		},
+		TargetFiles: []*pbgo.File{
+			{Path: "datadog/3/INTEGRATIONS/redis/config", Raw: rawIntegrationConfig},
+			{Path: "datadog/3/TESTING1/id/2", Raw: target2content},
+		},
+	}, nil)
+
+	err = client.poll()
+	assert.NoError(t, err)
+	integrationsUpdates := client.IntegrationsUpdates()
+	require.Len(t, integrationsUpdates, 1)
+	integrationsUpdate := <-integrationsUpdates
+	assert.Equal(t, IntegrationsUpdate{
+		Product: rdata.ProductIntegrations,
+		Configs: []IntegrationConfig{
+			{
+				Config: Config{
+					ID:      "redis",
+					Version: 3,
+				},
+				Name:      "redisdb",
+				Instances: []json.RawMessage{json.RawMessage(`{"host":"localhost"}`)},
+			},
+		},
+	}, integrationsUpdate)
+}
+
+func TestClientIntegrationsInvalidTarget(t *testing.T) {
+	testServer := getTestServer(t)
+
+	targetsKey := generateKey()
+	embeddedRoot := generateRoot(generateKey(), 1, targetsKey)
+	rawIntegrationConfig := []byte(`{"name":"redisdb","instances":[{"host":"localhost"}]}`)
+	target1 := generateTarget(rawIntegrationConfig, 3)
+	targets := generateTargets(targetsKey, 1, data.TargetFiles{"datadog/3/INTEGRATIONS/redis/config": target1})
+	config.Datadog.Set("remote_configuration.director_root", embeddedRoot)
+
+	testFacts := Facts{ID: "test-agent", Name: "test-agent-name", Version: "v6.1.1"}
+	client, err := newClient(testFacts, []rdata.Product{rdata.ProductIntegrations})
+	assert.NoError(t, err)
+
+	// the served file doesn't match the signed target
+	testServer.On("ClientGetConfigs", mock.Anything, mock.Anything).Return(&pbgo.ClientGetConfigsResponse{
+		Roots: []*pbgo.TopMeta{},
+		Targets: &pbgo.TopMeta{
+			Version: 1,
+			Raw:     targets,
+		},
+		TargetFiles: []*pbgo.File{
+			{Path: "datadog/3/INTEGRATIONS/redis/config", Raw: []byte(`{"name":"redisdb","instances":[{"host":"evil"}]}`)},
+		},
+	}, nil)
+
+	err = client.poll()
+	assert.Error(t, err)
+	assert.Len(t, client.IntegrationsUpdates(), 0)
+}
+
+func TestClientPublishIntegrationsUpdates(t *testing.T) {
+	client := &Client{integrationsUpdates: make(chan IntegrationsUpdate, len(integrationsProducts))}
+
+	redis := func(version uint64) IntegrationConfig {
+		return IntegrationConfig{Config: Config{ID: "redis", Version: version}, Name: "redisdb"}
+	}
+	nginx := IntegrationConfig{Config: Config{ID: "nginx", Version: 1}, Name: "nginx"}
+
+	client.publishUpdates(update{integrationsUpdate: &IntegrationsUpdate{Product: rdata.ProductIntegrations, Configs: []IntegrationConfig{redis(1)}}})
+	client.publishUpdates(update{logsUpdate: &IntegrationsUpdate{Product: rdata.ProductLogs, Configs: []IntegrationConfig{nginx}}})
+	// the queue is full, the latest state of the product replaces the queued one
+	client.publishUpdates(update{integrationsUpdate: &IntegrationsUpdate{Product: rdata.ProductIntegrations, Configs: []IntegrationConfig{redis(2)}}})
+
+	require.Len(t, client.integrationsUpdates, 2)
+	assert.Equal(t, IntegrationsUpdate{Product: rdata.ProductIntegrations, Configs: []IntegrationConfig{redis(2)}}, <-client.integrationsUpdates)
+	assert.Equal(t, IntegrationsUpdate{Product: rdata.ProductLogs, Configs: []IntegrationConfig{nginx}}, <-client.integrationsUpdates)
+}
+
 func generateKey() keys.Signer {
 	key, _ := keys.GenerateEd25519Key()
 	return key
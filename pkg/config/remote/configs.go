@@ -31,17 +31,23 @@ type configFile struct {
 }
 
 type configs struct {
-	apmSampling *apmSamplingConfigs
+	apmSampling  *apmSamplingConfigs
+	integrations *integrationsConfigs
+	logs         *integrationsConfigs
 }
 
 func newConfigs() *configs {
 	return &configs{
-		apmSampling: newApmSamplingConfigs(),
+		apmSampling:  newApmSamplingConfigs(),
+		integrations: newIntegrationsConfigs(data.ProductIntegrations),
+		logs:         newIntegrationsConfigs(data.ProductLogs),
 	}
 }
 
 type update struct {
-	apmSamplingUpdate *APMSamplingUpdate
+	apmSamplingUpdate  *APMSamplingUpdate
+	integrationsUpdate *IntegrationsUpdate
+	logsUpdate         *IntegrationsUpdate
 }
 
 func (c *configs) update(products []data.Product, files configFiles) update {
@@ -62,6 +68,10 @@ func (c *configs) update(products []data.Product, files configFiles) update {
 				continue
 			}
 			update.apmSamplingUpdate = apmSamplingUpdate
+		case data.ProductIntegrations:
+			update.integrationsUpdate = c.integrations.update(productConfigIDFiles[product])
+		case data.ProductLogs:
+			update.logsUpdate = c.logs.update(productConfigIDFiles[product])
 		default:
 			log.Warnf("received %d files for unknown product %v", len(productConfigIDFiles[product]), product)
 		}
@@ -77,5 +87,7 @@ func (c *configs) state() []*pbgo.Config {
 			Version: c.apmSampling.config.Version,
 		})
 	}
+	configs = append(configs, c.integrations.state()...)
+	configs = append(configs, c.logs.state()...)
 	return configs
 }
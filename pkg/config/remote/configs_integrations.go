@@ -0,0 +1,136 @@
+package remote
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/DataDog/datadog-agent/pkg/config/remote/data"
+	"github.com/DataDog/datadog-agent/pkg/proto/pbgo"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// IntegrationConfig is an integration config, holding check instances for the
+// integrations product or log sources for the logs product
+type IntegrationConfig struct {
+	Config
+	Name          string            `json:"name"`
+	ADIdentifiers []string          `json:"ad_identifiers,omitempty"`
+	InitConfig    json.RawMessage   `json:"init_config,omitempty"`
+	Instances     []json.RawMessage `json:"instances,omitempty"`
+	Logs          json.RawMessage   `json:"logs,omitempty"`
+}
+
+// IntegrationsUpdate is an update of the integration configs of a product.
+// It holds all the configs of the product, the ones it does not hold anymore
+// have been removed.
+type IntegrationsUpdate struct {
+	Product data.Product
+	Configs []IntegrationConfig
+}
+
+// integrationsProducts are the products whose updates are IntegrationsUpdates
+var integrationsProducts = []data.Product{data.ProductIntegrations, data.ProductLogs}
+
+type integrationsConfigs struct {
+	product data.Product
+	configs map[string]IntegrationConfig
+}
+
+func newIntegrationsConfigs(product data.Product) *integrationsConfigs {
+	return &integrationsConfigs{
+		product: product,
+		configs: make(map[string]IntegrationConfig),
+	}
+}
+
+func (c *integrationsConfigs) update(configFiles map[string]configFiles) *IntegrationsUpdate {
+	configs := make(map[string]IntegrationConfig, len(configFiles))
+	for configID, files := range configFiles {
+		current, exists := c.configs[configID]
+		if exists && current.Version >= files.version() {
+			configs[configID] = current
+			continue
+		}
+		config, err := c.parseConfig(configID, files)
+		if err != nil {
+			// keep running the previous version of a config rather than
+			// removing it
+			log.Errorf("could not refresh %s configuration %s: %v", c.product, configID, err)
+			if exists {
+				configs[configID] = current
+			}
+			continue
+		}
+		configs[configID] = config
+	}
+	changed := len(configs) != len(c.configs)
+	for configID, config := range configs {
+		if current, exists := c.configs[configID]; !exists || current.Version != config.Version {
+			changed = true
+		}
+	}
+	c.configs = configs
+	if !changed {
+		return nil
+	}
+	return &IntegrationsUpdate{
+		Product: c.product,
+		Configs: c.list(),
+	}
+}
+
+// list returns the configs sorted by ID
+func (c *integrationsConfigs) list() []IntegrationConfig {
+	configs := make([]IntegrationConfig, 0, len(c.configs))
+	for _, config := range c.configs {
+		configs = append(configs, config)
+	}
+	sort.Slice(configs, func(i, j int) bool {
+		return configs[i].ID < configs[j].ID
+	})
+	return configs
+}
+
+func (c *integrationsConfigs) parseConfig(configID string, files configFiles) (IntegrationConfig, error) {
+	if len(files) != 1 {
+		return IntegrationConfig{}, fmt.Errorf("expects one file per config. %d received", len(files))
+	}
+	var config IntegrationConfig
+	err := json.Unmarshal(files[0].raw, &config)
+	if err != nil {
+		return IntegrationConfig{}, fmt.Errorf("could not parse config: %v", err)
+	}
+	if config.Name == "" {
+		return IntegrationConfig{}, fmt.Errorf("config has no name")
+	}
+	switch c.product {
+	case data.ProductIntegrations:
+		if len(config.Instances) == 0 {
+			return IntegrationConfig{}, fmt.Errorf("integration config has no instances")
+		}
+	case data.ProductLogs:
+		if len(config.Logs) == 0 {
+			return IntegrationConfig{}, fmt.Errorf("logs config has no logs")
+		}
+		if len(config.Instances) != 0 {
+			return IntegrationConfig{}, fmt.Errorf("logs config cannot have instances")
+		}
+	}
+	config.Config = Config{
+		ID:      configID,
+		Version: files.version(),
+	}
+	return config, nil
+}
+
+func (c *integrationsConfigs) state() []*pbgo.Config {
+	var configs []*pbgo.Config
+	for _, config := range c.list() {
+		configs = append(configs, &pbgo.Config{
+			Id:      config.ID,
+			Version: config.Version,
+		})
+	}
+	return configs
+}
@@ -1,9 +1,11 @@
 package remote
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/DataDog/datadog-agent/pkg/config/remote/data"
+	"github.com/DataDog/datadog-agent/pkg/proto/pbgo"
 	"github.com/DataDog/datadog-agent/pkg/trace/pb"
 	"github.com/stretchr/testify/assert"
 )
@@ -83,3 +85,90 @@ func TestConfigsAPMSamplingUpdates(t *testing.T) {
 	}
 	assert.Equal(t, update{apmSamplingUpdate: &APMSamplingUpdate{Config: expectedConfig2}}, update2)
 }
+
+func TestConfigsIntegrationsUpdates(t *testing.T) {
+	configs := newConfigs()
+	products := []data.Product{data.ProductIntegrations, data.ProductLogs}
+	redisFile := configFile{
+		pathMeta: data.PathMeta{
+			Product:  data.ProductIntegrations,
+			ConfigID: "redis",
+			Name:     "config",
+		},
+		version: 1,
+		raw:     []byte(`{"name":"redisdb","ad_identifiers":["redis"],"instances":[{"host":"%%host%%"}]}`),
+	}
+	nginxLogsFile := configFile{
+		pathMeta: data.PathMeta{
+			Product:  data.ProductLogs,
+			ConfigID: "nginx",
+			Name:     "config",
+		},
+		version: 2,
+		raw:     []byte(`{"name":"nginx","logs":[{"type":"file","path":"/var/log/nginx/access.log","log_processing_rules":[{"type":"exclude_at_match","name":"healthchecks","pattern":"GET /health"}]}]}`),
+	}
+	expectedRedis := IntegrationConfig{
+		Config: Config{
+			ID:      "redis",
+			Version: 1,
+		},
+		Name:          "redisdb",
+		ADIdentifiers: []string{"redis"},
+		Instances:     []json.RawMessage{json.RawMessage(`{"host":"%%host%%"}`)},
+	}
+	expectedNginxLogs := IntegrationConfig{
+		Config: Config{
+			ID:      "nginx",
+			Version: 2,
+		},
+		Name: "nginx",
+		Logs: json.RawMessage(`[{"type":"file","path":"/var/log/nginx/access.log","log_processing_rules":[{"type":"exclude_at_match","name":"healthchecks","pattern":"GET /health"}]}]`),
+	}
+
+	update1 := configs.update(products, configFiles{redisFile, nginxLogsFile})
+	assert.Equal(t, update{
+		integrationsUpdate: &IntegrationsUpdate{Product: data.ProductIntegrations, Configs: []IntegrationConfig{expectedRedis}},
+		logsUpdate:         &IntegrationsUpdate{Product: data.ProductLogs, Configs: []IntegrationConfig{expectedNginxLogs}},
+	}, update1)
+
+	// unchanged configs don't trigger updates
+	update2 := configs.update(products, configFiles{redisFile, nginxLogsFile})
+	assert.Equal(t, update{}, update2)
+
+	// an invalid new version keeps the previous one running
+	invalidRedisFile := redisFile
+	invalidRedisFile.version = 2
+	invalidRedisFile.raw = []byte(`{"name":"redisdb","instances":[]}`)
+	update3 := configs.update(products, configFiles{invalidRedisFile, nginxLogsFile})
+	assert.Equal(t, update{}, update3)
+
+	// removed configs trigger an update without them
+	update4 := configs.update(products, configFiles{nginxLogsFile})
+	assert.Equal(t, update{
+		integrationsUpdate: &IntegrationsUpdate{Product: data.ProductIntegrations, Configs: []IntegrationConfig{}},
+	}, update4)
+	assert.Equal(t, []*pbgo.Config{{Id: "nginx", Version: 2}}, configs.state())
+}
+
+func TestIntegrationsConfigsInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		product data.Product
+		raw     string
+	}{
+		{name: "invalid json", product: data.ProductIntegrations, raw: `{"name":`},
+		{name: "no name", product: data.ProductIntegrations, raw: `{"instances":[{}]}`},
+		{name: "check without instances", product: data.ProductIntegrations, raw: `{"name":"redisdb"}`},
+		{name: "logs without logs", product: data.ProductLogs, raw: `{"name":"nginx"}`},
+		{name: "logs with instances", product: data.ProductLogs, raw: `{"name":"nginx","logs":[{}],"instances":[{}]}`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			configs := newIntegrationsConfigs(tc.product)
+			update := configs.update(map[string]configFiles{
+				"id": {{pathMeta: data.PathMeta{Product: tc.product, ConfigID: "id"}, version: 1, raw: []byte(tc.raw)}},
+			})
+			assert.Nil(t, update)
+			assert.Empty(t, configs.state())
+		})
+	}
+}
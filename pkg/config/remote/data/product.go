@@ -6,6 +6,10 @@ type Product string
 const (
 	// ProductAPMSampling is the apm sampling product
 	ProductAPMSampling Product = "APM_SAMPLING"
+	// ProductIntegrations is the agent integrations product, holding check configs
+	ProductIntegrations Product = "INTEGRATIONS"
+	// ProductLogs is the agent logs product, holding log source configs
+	ProductLogs Product = "LOGS"
 	// ProductTesting1 is a testing product
 	ProductTesting1 Product = "TESTING1"
 )
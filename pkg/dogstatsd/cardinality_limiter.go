@@ -0,0 +1,432 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package dogstatsd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/ckey"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/tagset"
+)
+
+const (
+	// contextLimitActionDrop drops the samples of the contexts over the limit
+	contextLimitActionDrop = "drop"
+	// contextLimitActionCollapse replaces the value of the tag having the most
+	// distinct values by overflowTagValue in the samples of the contexts over the limit
+	contextLimitActionCollapse = "collapse"
+
+	overflowTagValue = "overflow"
+
+	// contextLimitExpireInterval is the interval between two expirations of the tracked contexts
+	contextLimitExpireInterval = 10 * time.Second
+
+	// topOffendersCount is the number of offenders reported by the limiter stats
+	topOffendersCount = 10
+)
+
+// cardinalityLimiter caps the number of contexts per metric name and per origin.
+//
+// Contexts are tracked until they haven't been seen for the expiry duration,
+// the same way the aggregator expires its contexts. Once a metric name or an
+// origin reaches its limit, the samples of new contexts are either dropped or
+// collapsed: the value of their tag having the most distinct values for this
+// metric name is replaced by `overflow`. At most limit collapsed contexts are
+// kept on top of the regular ones, further samples are dropped.
+//
+// Each DogStatsD worker owns a limiter so that the workers don't contend on it,
+// the limits apply to the contexts seen by each worker.
+type cardinalityLimiter struct {
+	sync.Mutex
+	cardinalityLimits
+	lastExpire time.Time
+
+	keyGen     *ckey.KeyGenerator
+	tagsBuffer *tagset.HashingTagsAccumulator
+
+	contexts map[limiterKey]*limiterContext
+	metrics  map[string]*limiterEntry
+	origins  map[string]*limiterEntry
+}
+
+// cardinalityLimits holds the configuration of the cardinality limiters.
+type cardinalityLimits struct {
+	metricLimit int
+	originLimit int
+	collapse    bool
+	expiry      time.Duration
+}
+
+type limiterKey struct {
+	key    ckey.ContextKey
+	origin string
+}
+
+type limiterContext struct {
+	name     string
+	origin   string
+	tags     []string
+	overflow bool
+	lastSeen time.Time
+}
+
+// limiterEntry holds the contexts count of a metric name or an origin.
+type limiterEntry struct {
+	contexts         int
+	overflowContexts int
+	limited          uint64
+	// tagValues counts the contexts per tag value, per tag key, it is only
+	// maintained for metric names.
+	tagValues map[string]map[string]int
+}
+
+// cardinalityOffender is a metric name or an origin whose contexts have been limited.
+type cardinalityOffender struct {
+	Name     string `json:"name"`
+	Contexts int    `json:"contexts"`
+	Limited  uint64 `json:"limited"`
+	// TopTag is the tag key having the most distinct values for a metric name
+	TopTag string `json:"top_tag,omitempty"`
+}
+
+// cardinalityStats holds the top offenders of the cardinality limiter.
+type cardinalityStats struct {
+	Metrics []cardinalityOffender `json:"metrics"`
+	Origins []cardinalityOffender `json:"origins"`
+}
+
+func newCardinalityLimits(metricLimit, originLimit int, action string, expiry time.Duration) (*cardinalityLimits, error) {
+	if action != contextLimitActionDrop && action != contextLimitActionCollapse {
+		return nil, fmt.Errorf("invalid context limit action %q, must be %q or %q", action, contextLimitActionDrop, contextLimitActionCollapse)
+	}
+	return &cardinalityLimits{
+		metricLimit: metricLimit,
+		originLimit: originLimit,
+		collapse:    action == contextLimitActionCollapse,
+		expiry:      expiry,
+	}, nil
+}
+
+func newCardinalityLimiter(limits cardinalityLimits) *cardinalityLimiter {
+	return &cardinalityLimiter{
+		cardinalityLimits: limits,
+		keyGen:            ckey.NewKeyGenerator(),
+		tagsBuffer:        tagset.NewHashingTagsAccumulator(),
+		contexts:          make(map[limiterKey]*limiterContext),
+		metrics:           make(map[string]*limiterEntry),
+		origins:           make(map[string]*limiterEntry),
+	}
+}
+
+// apply tracks the context of sample and returns false if sample must be
+// dropped. The tags of collapsed samples are replaced.
+func (l *cardinalityLimiter) apply(sample *metrics.MetricSample, now time.Time) bool {
+	l.Lock()
+	defer l.Unlock()
+
+	if now.Sub(l.lastExpire) > contextLimitExpireInterval {
+		l.expireContexts(now)
+		l.lastExpire = now
+	}
+
+	origin := sampleOrigin(sample)
+	key := l.key(sample.Name, sample.Host, sample.Tags, origin)
+	if ctx, found := l.contexts[key]; found {
+		ctx.lastSeen = now
+		return true
+	}
+
+	metricEntry := l.metrics[sample.Name]
+	originEntry := l.origins[origin]
+	if !reached(metricEntry, l.metricLimit) && !reached(originEntry, l.originLimit) {
+		l.track(key, sample.Name, origin, sample.Tags, false, now)
+		return true
+	}
+
+	if metricEntry != nil {
+		metricEntry.limited++
+	}
+	if originEntry != nil {
+		originEntry.limited++
+	}
+	if !l.collapse || metricEntry == nil {
+		return false
+	}
+
+	tags, collapsed := collapseTags(sample.Tags, topTag(metricEntry, sample.Tags))
+	if !collapsed {
+		return false
+	}
+	key = l.key(sample.Name, sample.Host, tags, origin)
+	if ctx, found := l.contexts[key]; found {
+		ctx.lastSeen = now
+		sample.Tags = tags
+		return true
+	}
+	if overflowReached(metricEntry, l.metricLimit) || overflowReached(originEntry, l.originLimit) {
+		return false
+	}
+	l.track(key, sample.Name, origin, tags, true, now)
+	sample.Tags = tags
+	return true
+}
+
+func (l *cardinalityLimiter) key(name, host string, tags []string, origin string) limiterKey {
+	l.tagsBuffer.Append(tags...)
+	key := l.keyGen.Generate(name, host, l.tagsBuffer)
+	l.tagsBuffer.Reset()
+	return limiterKey{key: key, origin: origin}
+}
+
+func (l *cardinalityLimiter) track(key limiterKey, name, origin string, tags []string, overflow bool, now time.Time) {
+	l.contexts[key] = &limiterContext{name: name, origin: origin, tags: tags, overflow: overflow, lastSeen: now}
+
+	metricEntry := l.metrics[name]
+	if metricEntry == nil {
+		metricEntry = &limiterEntry{tagValues: make(map[string]map[string]int)}
+		l.metrics[name] = metricEntry
+	}
+	metricEntry.add(overflow)
+	for _, tag := range tags {
+		k, v := splitTag(tag)
+		values := metricEntry.tagValues[k]
+		if values == nil {
+			values = make(map[string]int)
+			metricEntry.tagValues[k] = values
+		}
+		values[v]++
+	}
+
+	// the samples without origin are only limited per metric name
+	if origin == "" {
+		return
+	}
+	originEntry := l.origins[origin]
+	if originEntry == nil {
+		originEntry = &limiterEntry{}
+		l.origins[origin] = originEntry
+	}
+	originEntry.add(overflow)
+}
+
+// expireContexts stops tracking the contexts that haven't been seen for the expiry duration.
+func (l *cardinalityLimiter) expireContexts(now time.Time) {
+	for key, ctx := range l.contexts {
+		if now.Sub(ctx.lastSeen) <= l.expiry {
+			continue
+		}
+		delete(l.contexts, key)
+
+		if metricEntry := l.metrics[ctx.name]; metricEntry != nil {
+			for _, tag := range ctx.tags {
+				k, v := splitTag(tag)
+				values := metricEntry.tagValues[k]
+				if values[v]--; values[v] <= 0 {
+					delete(values, v)
+				}
+				if len(values) == 0 {
+					delete(metricEntry.tagValues, k)
+				}
+			}
+			if metricEntry.remove(ctx.overflow) {
+				delete(l.metrics, ctx.name)
+			}
+		}
+		if originEntry := l.origins[ctx.origin]; originEntry != nil && originEntry.remove(ctx.overflow) {
+			delete(l.origins, ctx.origin)
+		}
+	}
+}
+
+// cardinalityStatsOf returns the top offenders of the limiters of all the workers.
+// A context can be seen by several workers, so the contexts count of an offender
+// is the highest one among the workers while the limited samples are summed.
+func cardinalityStatsOf(limiters []*cardinalityLimiter) cardinalityStats {
+	metricOffenders := make(map[string]*cardinalityOffender)
+	originOffenders := make(map[string]*cardinalityOffender)
+	topTagValues := make(map[string]int)
+	for _, l := range limiters {
+		l.Lock()
+		mergeOffenders(metricOffenders, l.metrics, topTagValues)
+		mergeOffenders(originOffenders, l.origins, nil)
+		l.Unlock()
+	}
+	return cardinalityStats{
+		Metrics: topOffenders(metricOffenders),
+		Origins: topOffenders(originOffenders),
+	}
+}
+
+func (e *limiterEntry) add(overflow bool) {
+	if overflow {
+		e.overflowContexts++
+	} else {
+		e.contexts++
+	}
+}
+
+// remove returns true when the entry doesn't hold any context anymore.
+func (e *limiterEntry) remove(overflow bool) bool {
+	if overflow {
+		e.overflowContexts--
+	} else {
+		e.contexts--
+	}
+	return e.contexts <= 0 && e.overflowContexts <= 0
+}
+
+func reached(entry *limiterEntry, limit int) bool {
+	return limit > 0 && entry != nil && entry.contexts >= limit
+}
+
+func overflowReached(entry *limiterEntry, limit int) bool {
+	return limit > 0 && entry != nil && entry.overflowContexts >= limit
+}
+
+// topTag returns the key of the tag of tags having the most distinct values for a metric name.
+func topTag(entry *limiterEntry, tags []string) string {
+	top, topCount := "", 0
+	for _, tag := range tags {
+		k, _ := splitTag(tag)
+		if count := len(entry.tagValues[k]); count > topCount || (count == topCount && k < top) {
+			top, topCount = k, count
+		}
+	}
+	return top
+}
+
+// collapseTags returns a copy of tags where the value of the tags whose key is
+// tagKey is replaced by overflowTagValue.
+func collapseTags(tags []string, tagKey string) ([]string, bool) {
+	if tagKey == "" {
+		return nil, false
+	}
+	collapsed := make([]string, len(tags))
+	for i, tag := range tags {
+		if k, _ := splitTag(tag); k == tagKey {
+			tag = tagKey + ":" + overflowTagValue
+		}
+		collapsed[i] = tag
+	}
+	return collapsed, true
+}
+
+// mergeOffenders adds the limited entries to offenders. The top tag of the
+// offenders is only set when topTagValues, holding the values count of their
+// top tag, is not nil.
+func mergeOffenders(offenders map[string]*cardinalityOffender, entries map[string]*limiterEntry, topTagValues map[string]int) {
+	for name, entry := range entries {
+		if entry.limited == 0 {
+			continue
+		}
+		offender := offenders[name]
+		if offender == nil {
+			offender = &cardinalityOffender{Name: name}
+			offenders[name] = offender
+		}
+		if contexts := entry.contexts + entry.overflowContexts; contexts > offender.Contexts {
+			offender.Contexts = contexts
+		}
+		offender.Limited += entry.limited
+		if topTagValues != nil {
+			k, count := entry.mostDistinctTag()
+			if count > topTagValues[name] || (count == topTagValues[name] && k < offender.TopTag) {
+				offender.TopTag, topTagValues[name] = k, count
+			}
+		}
+	}
+}
+
+// mostDistinctTag returns the tag key having the most distinct values and its
+// count of values, the smallest key wins ties.
+func (e *limiterEntry) mostDistinctTag() (string, int) {
+	keys := make([]string, 0, len(e.tagValues))
+	for k := range e.tagValues {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	top, topCount := "", 0
+	for _, k := range keys {
+		if count := len(e.tagValues[k]); count > topCount {
+			top, topCount = k, count
+		}
+	}
+	return top, topCount
+}
+
+func topOffenders(offenders map[string]*cardinalityOffender) []cardinalityOffender {
+	var top []cardinalityOffender
+	for _, offender := range offenders {
+		top = append(top, *offender)
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Limited == top[j].Limited {
+			return top[i].Name < top[j].Name
+		}
+		return top[i].Limited > top[j].Limited
+	})
+	if len(top) > topOffendersCount {
+		top = top[:topOffendersCount]
+	}
+	return top
+}
+
+// sampleOrigin returns the origin of a sample, the origin sent by the client
+// takes precedence over the one detected on the socket.
+func sampleOrigin(sample *metrics.MetricSample) string {
+	if sample.OriginFromClient != "" {
+		return sample.OriginFromClient
+	}
+	return sample.OriginFromUDS
+}
+
+// splitTag splits a `key:value` tag, the value of tags without `:` is empty.
+func splitTag(tag string) (string, string) {
+	if i := strings.IndexByte(tag, ':'); i >= 0 {
+		return tag[:i], tag[i+1:]
+	}
+	return tag, ""
+}
+
+// FormatCardinalityStats returns a printable version of the cardinality limiter stats.
+func FormatCardinalityStats(stats []byte) (string, error) {
+	var cardinality cardinalityStats
+	if err := json.Unmarshal(stats, &cardinality); err != nil {
+		return "", err
+	}
+
+	buf := bytes.NewBuffer(nil)
+
+	header := fmt.Sprintf("%-40s | %-20s | %-10s | %-10s\n", "Metric", "Top Tag", "Contexts", "Limited")
+	buf.Write([]byte(header))
+	buf.Write([]byte(strings.Repeat("-", len(header)) + "\n"))
+	for _, offender := range cardinality.Metrics {
+		buf.Write([]byte(fmt.Sprintf("%-40s | %-20s | %-10d | %-10d\n", offender.Name, offender.TopTag, offender.Contexts, offender.Limited)))
+	}
+	if len(cardinality.Metrics) == 0 {
+		buf.Write([]byte("No metric limited yet.\n"))
+	}
+
+	buf.Write([]byte("\n"))
+	header = fmt.Sprintf("%-63s | %-10s | %-10s\n", "Origin", "Contexts", "Limited")
+	buf.Write([]byte(header))
+	buf.Write([]byte(strings.Repeat("-", len(header)) + "\n"))
+	for _, offender := range cardinality.Origins {
+		buf.Write([]byte(fmt.Sprintf("%-63s | %-10d | %-10d\n", offender.Name, offender.Contexts, offender.Limited)))
+	}
+	if len(cardinality.Origins) == 0 {
+		buf.Write([]byte("No origin limited yet."))
+	}
+
+	return buf.String(), nil
+}
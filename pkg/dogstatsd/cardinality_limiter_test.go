@@ -0,0 +1,132 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package dogstatsd
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+func newLimitedSample(name, origin string, tags ...string) *metrics.MetricSample {
+	return &metrics.MetricSample{Name: name, Tags: tags, OriginFromUDS: origin, Mtype: metrics.CountType, Value: 1}
+}
+
+func newTestCardinalityLimiter(t *testing.T, metricLimit, originLimit int, action string) *cardinalityLimiter {
+	limits, err := newCardinalityLimits(metricLimit, originLimit, action, time.Minute)
+	require.NoError(t, err)
+	return newCardinalityLimiter(*limits)
+}
+
+func TestCardinalityLimiterDrop(t *testing.T) {
+	limiter := newTestCardinalityLimiter(t, 2, 0, contextLimitActionDrop)
+	now := time.Now()
+
+	assert.True(t, limiter.apply(newLimitedSample("requests", "", "user:1"), now))
+	assert.True(t, limiter.apply(newLimitedSample("requests", "", "user:2"), now))
+	assert.False(t, limiter.apply(newLimitedSample("requests", "", "user:3"), now))
+	// known contexts and other metric names are still accepted
+	assert.True(t, limiter.apply(newLimitedSample("requests", "", "user:1"), now))
+	assert.True(t, limiter.apply(newLimitedSample("errors", "", "user:3"), now))
+
+	stats := cardinalityStatsOf([]*cardinalityLimiter{limiter})
+	require.Len(t, stats.Metrics, 1)
+	assert.Equal(t, cardinalityOffender{Name: "requests", Contexts: 2, Limited: 1, TopTag: "user"}, stats.Metrics[0])
+
+	// the contexts expire
+	later := now.Add(2 * time.Minute)
+	assert.True(t, limiter.apply(newLimitedSample("requests", "", "user:3"), later))
+	assert.Len(t, limiter.contexts, 1)
+	assert.Empty(t, cardinalityStatsOf([]*cardinalityLimiter{limiter}).Metrics)
+}
+
+func TestCardinalityLimiterCollapse(t *testing.T) {
+	limiter := newTestCardinalityLimiter(t, 2, 0, contextLimitActionCollapse)
+	now := time.Now()
+
+	assert.True(t, limiter.apply(newLimitedSample("requests", "", "env:prod", "user:1"), now))
+	assert.True(t, limiter.apply(newLimitedSample("requests", "", "env:prod", "user:2"), now))
+
+	sample := newLimitedSample("requests", "", "env:prod", "user:3")
+	assert.True(t, limiter.apply(sample, now))
+	assert.Equal(t, []string{"env:prod", "user:overflow"}, sample.Tags)
+
+	sample = newLimitedSample("requests", "", "env:prod", "user:4")
+	assert.True(t, limiter.apply(sample, now))
+	assert.Equal(t, []string{"env:prod", "user:overflow"}, sample.Tags)
+
+	// at most limit collapsed contexts are kept
+	assert.True(t, limiter.apply(newLimitedSample("requests", "", "env:staging", "user:5"), now))
+	assert.False(t, limiter.apply(newLimitedSample("requests", "", "env:dev", "user:6"), now))
+
+	// samples without tags can't be collapsed
+	assert.False(t, limiter.apply(newLimitedSample("requests", ""), now))
+}
+
+func TestCardinalityLimiterOrigin(t *testing.T) {
+	limiter := newTestCardinalityLimiter(t, 0, 3, contextLimitActionDrop)
+	now := time.Now()
+
+	for i := 0; i < 3; i++ {
+		assert.True(t, limiter.apply(newLimitedSample(fmt.Sprintf("metric.%d", i), "container_id://abc"), now))
+	}
+	assert.False(t, limiter.apply(newLimitedSample("metric.3", "container_id://abc"), now))
+	assert.True(t, limiter.apply(newLimitedSample("metric.3", "container_id://def"), now))
+	// samples without origin are not limited per origin
+	for i := 0; i < 5; i++ {
+		assert.True(t, limiter.apply(newLimitedSample(fmt.Sprintf("metric.%d", i), ""), now))
+	}
+
+	stats := cardinalityStatsOf([]*cardinalityLimiter{limiter})
+	require.Len(t, stats.Origins, 1)
+	assert.Equal(t, cardinalityOffender{Name: "container_id://abc", Contexts: 3, Limited: 1}, stats.Origins[0])
+}
+
+func TestCardinalityStatsOfWorkers(t *testing.T) {
+	first := newTestCardinalityLimiter(t, 2, 0, contextLimitActionDrop)
+	second := newTestCardinalityLimiter(t, 2, 0, contextLimitActionDrop)
+	now := time.Now()
+
+	// both tag keys have as many values, the top tag is picked by name
+	for _, limiter := range []*cardinalityLimiter{first, second} {
+		limiter.apply(newLimitedSample("requests", "", "user:1", "host:a"), now)
+		limiter.apply(newLimitedSample("requests", "", "user:2", "host:b"), now)
+		limiter.apply(newLimitedSample("requests", "", "user:3", "host:c"), now)
+	}
+	second.apply(newLimitedSample("requests", "", "user:4", "host:d"), now)
+
+	stats := cardinalityStatsOf([]*cardinalityLimiter{first, second})
+	require.Len(t, stats.Metrics, 1)
+	assert.Equal(t, "requests", stats.Metrics[0].Name)
+	assert.Equal(t, 2, stats.Metrics[0].Contexts)
+	assert.Equal(t, uint64(3), stats.Metrics[0].Limited)
+	assert.Equal(t, "host", stats.Metrics[0].TopTag)
+}
+
+func TestCardinalityLimiterInvalidAction(t *testing.T) {
+	_, err := newCardinalityLimits(1, 0, "invalid", time.Minute)
+	assert.Error(t, err)
+}
+
+func TestFormatCardinalityStats(t *testing.T) {
+	limiter := newTestCardinalityLimiter(t, 1, 0, contextLimitActionDrop)
+	now := time.Now()
+	limiter.apply(newLimitedSample("requests", "", "user:1"), now)
+	limiter.apply(newLimitedSample("requests", "", "user:2"), now)
+
+	s := &Server{workers: []*worker{{limiter: limiter}, {}}}
+	stats, err := s.GetJSONCardinalityStats()
+	require.NoError(t, err)
+	formatted, err := FormatCardinalityStats(stats)
+	require.NoError(t, err)
+	assert.Contains(t, formatted, "requests")
+	assert.Contains(t, formatted, "No origin limited yet.")
+}
@@ -23,6 +23,15 @@ const (
 	matchTypeRegex    = "regex"
 )
 
+// Mapping actions
+const (
+	actionMap        = "map"
+	actionDrop       = "drop"
+	actionKeep       = "keep"
+	actionRemoveTags = "remove_tags"
+	actionRenameTag  = "rename_tag"
+)
+
 // MetricMapper contains mappings and cache instance
 type MetricMapper struct {
 	Profiles []MappingProfile
@@ -34,20 +43,31 @@ type MappingProfile struct {
 	Name     string
 	Prefix   string
 	Mappings []*MetricMapping
+	// matchTags is true when one of the mappings matches on tags, the results
+	// of such profiles depend on the tags of the metrics and can't be cached.
+	matchTags bool
 }
 
 // MetricMapping represent one mapping rule
 type MetricMapping struct {
-	name  string
-	tags  map[string]string
-	regex *regexp.Regexp
+	name       string
+	tags       map[string]string
+	regex      *regexp.Regexp
+	action     string
+	matchTags  map[string]*regexp.Regexp
+	removeTags []string
+	renameTag  map[string]string
 }
 
 // MapResult represent the outcome of the mapping
 type MapResult struct {
-	Name    string
-	Tags    []string
-	matched bool
+	Name string
+	Tags []string
+	// Drop is true when the metric must be dropped
+	Drop       bool
+	removeTags []string
+	renameTag  map[string]string
+	matched    bool
 }
 
 // NewMetricMapper creates, validates, prepares a new MetricMapper
@@ -69,8 +89,12 @@ func NewMetricMapper(configProfiles []config.MappingProfile, cacheSize int) (*Me
 			if matchType != matchTypeWildcard && matchType != matchTypeRegex {
 				return nil, fmt.Errorf("profile: %s, mapping num %d: invalid match type, must be `wildcard` or `regex`", profile.Name, i)
 			}
-			if currentMapping.Name == "" {
-				return nil, fmt.Errorf("profile: %s, mapping num %d: name is required", profile.Name, i)
+			action := currentMapping.Action
+			if action == "" {
+				action = actionMap
+			}
+			if err := validateAction(currentMapping, action); err != nil {
+				return nil, fmt.Errorf("profile: %s, mapping num %d: %v", profile.Name, i, err)
 			}
 			if currentMapping.Match == "" {
 				return nil, fmt.Errorf("profile: %s, mapping num %d: match is required", profile.Name, i)
@@ -79,7 +103,27 @@ func NewMetricMapper(configProfiles []config.MappingProfile, cacheSize int) (*Me
 			if err != nil {
 				return nil, err
 			}
-			profile.Mappings = append(profile.Mappings, &MetricMapping{name: currentMapping.Name, tags: currentMapping.Tags, regex: regex})
+			matchTags := make(map[string]*regexp.Regexp, len(currentMapping.MatchTags))
+			for tagKey, tagMatch := range currentMapping.MatchTags {
+				if tagKey == "" {
+					return nil, fmt.Errorf("profile: %s, mapping num %d: match_tags keys must not be empty", profile.Name, i)
+				}
+				tagRegex, err := buildTagRegex(tagMatch, matchType)
+				if err != nil {
+					return nil, err
+				}
+				matchTags[tagKey] = tagRegex
+			}
+			profile.matchTags = profile.matchTags || len(matchTags) > 0
+			profile.Mappings = append(profile.Mappings, &MetricMapping{
+				name:       currentMapping.Name,
+				tags:       currentMapping.Tags,
+				regex:      regex,
+				action:     action,
+				matchTags:  matchTags,
+				removeTags: currentMapping.RemoveTags,
+				renameTag:  currentMapping.RenameTag,
+			})
 		}
 		profiles = append(profiles, profile)
 	}
@@ -90,6 +134,41 @@ func NewMetricMapper(configProfiles []config.MappingProfile, cacheSize int) (*Me
 	return &MetricMapper{Profiles: profiles, cache: cache}, nil
 }
 
+func validateAction(mapping config.MetricMapping, action string) error {
+	switch action {
+	case actionMap:
+		if mapping.Name == "" {
+			return fmt.Errorf("name is required")
+		}
+	case actionDrop, actionKeep:
+		if mapping.Name != "" || len(mapping.Tags) > 0 || len(mapping.RemoveTags) > 0 || len(mapping.RenameTag) > 0 {
+			return fmt.Errorf("name, tags, remove_tags and rename_tag can't be used with the `%s` action", action)
+		}
+	case actionRemoveTags:
+		if len(mapping.RemoveTags) == 0 {
+			return fmt.Errorf("remove_tags is required by the `%s` action", action)
+		}
+	case actionRenameTag:
+		if len(mapping.RenameTag) == 0 {
+			return fmt.Errorf("rename_tag is required by the `%s` action", action)
+		}
+		for from, to := range mapping.RenameTag {
+			if from == "" || to == "" {
+				return fmt.Errorf("rename_tag keys and values must not be empty")
+			}
+		}
+	default:
+		return fmt.Errorf("invalid action, must be `%s`, `%s`, `%s`, `%s` or `%s`", actionMap, actionDrop, actionKeep, actionRemoveTags, actionRenameTag)
+	}
+	if action != actionRemoveTags && len(mapping.RemoveTags) > 0 {
+		return fmt.Errorf("remove_tags can only be used with the `%s` action", actionRemoveTags)
+	}
+	if action != actionRenameTag && len(mapping.RenameTag) > 0 {
+		return fmt.Errorf("rename_tag can only be used with the `%s` action", actionRenameTag)
+	}
+	return nil
+}
+
 func buildRegex(matchRe string, matchType string) (*regexp.Regexp, error) {
 	if matchType == matchTypeWildcard {
 		if !allowedWildcardMatchPattern.MatchString(matchRe) {
@@ -108,45 +187,137 @@ func buildRegex(matchRe string, matchType string) (*regexp.Regexp, error) {
 	return regex, nil
 }
 
-// Map returns a MapResult
-func (m *MetricMapper) Map(metricName string) *MapResult {
+// buildTagRegex builds the regex matching tag values, unlike metric names
+// wildcard patterns of tag values can contain any character.
+func buildTagRegex(matchRe string, matchType string) (*regexp.Regexp, error) {
+	if matchType == matchTypeWildcard {
+		if strings.Contains(matchRe, "**") {
+			return nil, fmt.Errorf("invalid wildcard match pattern `%s`, it should not contain consecutive `*`", matchRe)
+		}
+		matchRe = strings.Replace(regexp.QuoteMeta(matchRe), "\\*", "(.*)", -1)
+	}
+	regex, err := regexp.Compile("^" + matchRe + "$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid tag match `%s`. cannot compile regex: %v", matchRe, err)
+	}
+	return regex, nil
+}
+
+// Map returns a MapResult, tags are the tags of the metric, they are only
+// used by the mappings matching on tags.
+func (m *MetricMapper) Map(metricName string, tags []string) *MapResult {
 	for _, profile := range m.Profiles {
 		if !strings.HasPrefix(metricName, profile.Prefix) && profile.Prefix != "*" {
 			continue
 		}
-		result, cached := m.cache.get(metricName)
-		if cached {
-			if result.matched {
-				return result
+		if !profile.matchTags {
+			result, cached := m.cache.get(metricName)
+			if cached {
+				if result.matched {
+					return result
+				}
+				return nil
 			}
-			return nil
 		}
 		for _, mapping := range profile.Mappings {
 			matches := mapping.regex.FindStringSubmatchIndex(metricName)
-			if len(matches) == 0 {
+			if len(matches) == 0 || !mapping.matchesTags(tags) {
 				continue
 			}
-
-			name := string(mapping.regex.ExpandString(
-				[]byte{},
-				mapping.name,
-				metricName,
-				matches,
-			))
-
-			var tags []string
-			for tagKey, tagValueExpr := range mapping.tags {
-				tagValue := string(mapping.regex.ExpandString([]byte{}, tagValueExpr, metricName, matches))
-				tags = append(tags, tagKey+":"+tagValue)
+			mapResult := mapping.result(metricName, matches)
+			if !profile.matchTags {
+				m.cache.add(metricName, mapResult)
 			}
-
-			mapResult := &MapResult{Name: name, matched: true, Tags: tags}
-			m.cache.add(metricName, mapResult)
 			return mapResult
 		}
-		mapResult := &MapResult{matched: false}
-		m.cache.add(metricName, mapResult)
+		if !profile.matchTags {
+			m.cache.add(metricName, &MapResult{matched: false})
+		}
 		return nil
 	}
 	return nil
 }
+
+// matchesTags returns true if, for each tag to match, one of tags has the
+// expected key and a matching value.
+func (mapping *MetricMapping) matchesTags(tags []string) bool {
+	for tagKey, regex := range mapping.matchTags {
+		found := false
+		for _, tag := range tags {
+			key, value := splitTag(tag)
+			if key == tagKey && regex.MatchString(value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// result returns the MapResult of a metric matched by mapping.
+func (mapping *MetricMapping) result(metricName string, matches []int) *MapResult {
+	switch mapping.action {
+	case actionDrop:
+		return &MapResult{Drop: true, matched: true}
+	case actionKeep:
+		return &MapResult{Name: metricName, matched: true}
+	}
+
+	name := metricName
+	if mapping.name != "" {
+		name = string(mapping.regex.ExpandString(
+			[]byte{},
+			mapping.name,
+			metricName,
+			matches,
+		))
+	}
+
+	var tags []string
+	for tagKey, tagValueExpr := range mapping.tags {
+		tagValue := string(mapping.regex.ExpandString([]byte{}, tagValueExpr, metricName, matches))
+		tags = append(tags, tagKey+":"+tagValue)
+	}
+
+	return &MapResult{Name: name, matched: true, Tags: tags, removeTags: mapping.removeTags, renameTag: mapping.renameTag}
+}
+
+// ApplyTags removes and renames the tags of a mapped metric and appends the
+// tags added by the mapping. tags is modified in place.
+func (r *MapResult) ApplyTags(tags []string) []string {
+	if len(r.removeTags) == 0 && len(r.renameTag) == 0 {
+		return append(tags, r.Tags...)
+	}
+	kept := tags[:0]
+	for _, tag := range tags {
+		key, _ := splitTag(tag)
+		if r.isRemoved(key) {
+			continue
+		}
+		if newKey, ok := r.renameTag[key]; ok {
+			tag = newKey + tag[len(key):]
+		}
+		kept = append(kept, tag)
+	}
+	return append(kept, r.Tags...)
+}
+
+func (r *MapResult) isRemoved(key string) bool {
+	for _, removed := range r.removeTags {
+		if removed == key {
+			return true
+		}
+	}
+	return false
+}
+
+// splitTag splits a `key:value` tag, the value of tags without `:` is empty.
+func splitTag(tag string) (string, string) {
+	if i := strings.IndexByte(tag, ':'); i >= 0 {
+		return tag[:i], tag[i+1:]
+	}
+	return tag, ""
+}
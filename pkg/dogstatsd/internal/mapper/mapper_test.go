@@ -333,7 +333,7 @@ dogstatsd_mapper_profiles:
 
 			var actualResults []MapResult
 			for _, packet := range scenario.packets {
-				mapResult := mapper.Map(packet)
+				mapResult := mapper.Map(packet, nil)
 				if mapResult != nil {
 					actualResults = append(actualResults, *mapResult)
 				}
@@ -499,6 +499,72 @@ dogstatsd_mapper_profiles:
 			},
 			expectedError: "missing prefix for profile",
 		},
+		{
+			name: "Invalid action",
+			config: `
+dogstatsd_mapper_profiles:
+  - name: test
+    prefix: 'test.'
+    mappings:
+      - match: "test.job.duration"
+        action: invalid
+`,
+			expectedError: "invalid action",
+		},
+		{
+			name: "Drop with name",
+			config: `
+dogstatsd_mapper_profiles:
+  - name: test
+    prefix: 'test.'
+    mappings:
+      - match: "test.job.duration"
+        action: drop
+        name: "test.job"
+`,
+			expectedError: "can't be used with the `drop` action",
+		},
+		{
+			name: "Missing remove_tags",
+			config: `
+dogstatsd_mapper_profiles:
+  - name: test
+    prefix: 'test.'
+    mappings:
+      - match: "test.job.duration"
+        action: remove_tags
+`,
+			expectedError: "remove_tags is required",
+		},
+		{
+			name: "rename_tag with another action",
+			config: `
+dogstatsd_mapper_profiles:
+  - name: test
+    prefix: 'test.'
+    mappings:
+      - match: "test.job.duration"
+        name: "test.job"
+        rename_tag:
+          foo: bar
+`,
+			expectedError: "rename_tag can only be used with the `rename_tag` action",
+		},
+		{
+			name: "Invalid tag regex",
+			config: `
+dogstatsd_mapper_profiles:
+  - name: test
+    prefix: 'test.'
+    mappings:
+      - match: 'test\.job\.duration'
+        match_type: regex
+        match_tags:
+          env: "(prod"
+        name: "test.job"
+`,
+			expectedError: "invalid tag match",
+		},
 	}
 
 	for _, scenario := range scenarios {
@@ -510,6 +576,112 @@ dogstatsd_mapper_profiles:
 	}
 }
 
+func TestMappingActions(t *testing.T) {
+	mapper, err := getMapper(`
+dogstatsd_mapper_profiles:
+  - name: test
+    prefix: 'test.'
+    mappings:
+      - match: "test.internal.*"
+        action: drop
+      - match: "test.*.requests"
+        match_tags:
+          env: "prod-*"
+        action: keep
+      - match: "test.*.requests"
+        action: drop
+      - match: "test.legacy.*"
+        match_type: wildcard
+        name: "test.$1"
+        action: remove_tags
+        remove_tags:
+          - request_id
+          - user
+        tags:
+          legacy: "true"
+      - match: 'test\.(\w+)\.latency'
+        match_type: regex
+        match_tags:
+          host: 'web-\d+'
+        action: rename_tag
+        rename_tag:
+          host: web_host
+`)
+	require.NoError(t, err)
+
+	scenarios := []struct {
+		name         string
+		metricName   string
+		tags         []string
+		expectedName string
+		expectedTags []string
+		expectedDrop bool
+		expectedNil  bool
+	}{
+		{
+			name:         "drop",
+			metricName:   "test.internal.queue",
+			expectedDrop: true,
+		},
+		{
+			name:         "keep on tag value",
+			metricName:   "test.api.requests",
+			tags:         []string{"env:prod-us", "code:200"},
+			expectedName: "test.api.requests",
+			expectedTags: []string{"env:prod-us", "code:200"},
+		},
+		{
+			name:         "drop when the tag value does not match",
+			metricName:   "test.api.requests",
+			tags:         []string{"env:staging"},
+			expectedDrop: true,
+		},
+		{
+			name:         "remove tags",
+			metricName:   "test.legacy.hits",
+			tags:         []string{"request_id:1234", "user", "path:/"},
+			expectedName: "test.hits",
+			expectedTags: []string{"path:/", "legacy:true"},
+		},
+		{
+			name:         "rename tag",
+			metricName:   "test.db.latency",
+			tags:         []string{"host:web-1", "hostname:web-1"},
+			expectedName: "test.db.latency",
+			expectedTags: []string{"web_host:web-1", "hostname:web-1"},
+		},
+		{
+			name:        "tag name without value",
+			metricName:  "test.db.latency",
+			tags:        []string{"host"},
+			expectedNil: true,
+		},
+		{
+			name:        "no match",
+			metricName:  "test.db.size",
+			tags:        []string{"host:web-1"},
+			expectedNil: true,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			result := mapper.Map(scenario.metricName, scenario.tags)
+			if scenario.expectedNil {
+				assert.Nil(t, result)
+				return
+			}
+			require.NotNil(t, result)
+			assert.Equal(t, scenario.expectedDrop, result.Drop)
+			if scenario.expectedDrop {
+				return
+			}
+			assert.Equal(t, scenario.expectedName, result.Name)
+			assert.Equal(t, scenario.expectedTags, result.ApplyTags(scenario.tags))
+		})
+	}
+}
+
 func getMapper(configString string) (*MetricMapper, error) {
 	var profiles []config.MappingProfile
 	config.Datadog.SetConfigType("yaml")
@@ -43,6 +43,8 @@ var (
 	dogstatsdMetricPackets            = expvar.Int{}
 	dogstatsdPacketsLastSec           = expvar.Int{}
 	dogstatsdUnterminatedMetricErrors = expvar.Int{}
+	dogstatsdMetricMapperDrops        = expvar.Int{}
+	dogstatsdMetricContextsLimited    = expvar.Int{}
 
 	tlmProcessed = telemetry.NewCounter("dogstatsd", "processed",
 		[]string{"message_type", "state", "origin"}, "Count of service checks/events/metrics processed by dogstatsd")
@@ -68,6 +70,8 @@ func init() {
 	dogstatsdExpvars.Set("MetricParseErrors", &dogstatsdMetricParseErrors)
 	dogstatsdExpvars.Set("MetricPackets", &dogstatsdMetricPackets)
 	dogstatsdExpvars.Set("UnterminatedMetricErrors", &dogstatsdUnterminatedMetricErrors)
+	dogstatsdExpvars.Set("MetricMapperDrops", &dogstatsdMetricMapperDrops)
+	dogstatsdExpvars.Set("MetricContextsLimited", &dogstatsdMetricContextsLimited)
 }
 
 // used in debug mode to add the origin on the processed metric as a tag
@@ -147,6 +151,7 @@ type Server struct {
 	debugTagsAccumulator      *tagset.HashingTagsAccumulator
 	TCapture                  *replay.TrafficCapture
 	mapper                    *mapper.MetricMapper
+	cardinalityLimits         *cardinalityLimits
 	eolTerminationUDP         bool
 	eolTerminationUDS         bool
 	eolTerminationNamedPipe   bool
@@ -393,6 +398,21 @@ func NewServer(demultiplexer aggregator.Demultiplexer) (*Server, error) {
 			s.mapper = mapperInstance
 		}
 	}
+
+	// limit the contexts cardinality
+	// ----------------------
+
+	metricContextLimit := config.Datadog.GetInt("dogstatsd_context_limit_per_metric")
+	originContextLimit := config.Datadog.GetInt("dogstatsd_context_limit_per_origin")
+	if metricContextLimit > 0 || originContextLimit > 0 {
+		expiry := time.Duration(config.Datadog.GetInt64("dogstatsd_context_expiry_seconds")) * time.Second
+		limits, err := newCardinalityLimits(metricContextLimit, originContextLimit, config.Datadog.GetString("dogstatsd_context_limit_action"), expiry)
+		if err != nil {
+			log.Warnf("Could not create the contexts cardinality limiter: %v", err)
+		} else {
+			s.cardinalityLimits = limits
+		}
+	}
 	return s, nil
 }
 
@@ -519,7 +539,7 @@ func (s *Server) eolEnabled(sourceType packets.SourceType) bool {
 }
 
 // workers are running this function in their goroutine
-func (s *Server) parsePackets(batcher *batcher, parser *parser, packets []*packets.Packet, samples []metrics.MetricSample) []metrics.MetricSample {
+func (s *Server) parsePackets(batcher *batcher, parser *parser, limiter *cardinalityLimiter, packets []*packets.Packet, samples []metrics.MetricSample) []metrics.MetricSample {
 	for _, packet := range packets {
 		log.Tracef("Dogstatsd receive: %q", packet.Contents)
 		for {
@@ -563,6 +583,10 @@ func (s *Server) parsePackets(batcher *batcher, parser *parser, packets []*packe
 				}
 
 				for idx := range samples {
+					if limiter != nil && !limiter.apply(&samples[idx], time.Now()) {
+						dogstatsdMetricContextsLimited.Add(1)
+						continue
+					}
 					if debugEnabled {
 						s.storeMetricStats(samples[idx])
 					}
@@ -642,11 +666,19 @@ func (s *Server) parseMetricMessage(metricSamples []metrics.MetricSample, parser
 	}
 
 	if s.mapper != nil {
-		mapResult := s.mapper.Map(sample.name)
+		mapResult := s.mapper.Map(sample.name, sample.tags)
+		if mapResult != nil && mapResult.Drop {
+			log.Tracef("Dogstatsd mapper: metric %q dropped", sample.name)
+			dogstatsdMetricMapperDrops.Add(1)
+			if len(sample.values) > 0 {
+				s.sharedFloat64List.put(sample.values)
+			}
+			return metricSamples, nil
+		}
 		if mapResult != nil {
 			log.Tracef("Dogstatsd mapper: metric mapped from %q to %q with tags %v", sample.name, mapResult.Name, mapResult.Tags)
 			sample.name = mapResult.Name
-			sample.tags = append(sample.tags, mapResult.Tags...)
+			sample.tags = mapResult.ApplyTags(sample.tags)
 		}
 	}
 	metricSamples = enrichMetricSample(metricSamples, sample, s.metricPrefix, s.metricPrefixBlacklist, s.metricBlocklist, s.defaultHostname, origin, s.entityIDPrecedenceEnabled, s.ServerlessMode)
@@ -827,6 +859,17 @@ func (s *Server) GetJSONDebugStats() ([]byte, error) {
 	return json.Marshal(s.Debug.Stats)
 }
 
+// GetJSONCardinalityStats returns jsonified top offenders of the contexts cardinality limiter.
+func (s *Server) GetJSONCardinalityStats() ([]byte, error) {
+	var limiters []*cardinalityLimiter
+	for _, worker := range s.workers {
+		if worker.limiter != nil {
+			limiters = append(limiters, worker.limiter)
+		}
+	}
+	return json.Marshal(cardinalityStatsOf(limiters))
+}
+
 // FormatDebugStats returns a printable version of debug stats.
 func FormatDebugStats(stats []byte) (string, error) {
 	var dogStats map[uint64]metricStat
@@ -74,7 +74,7 @@ func benchParsePackets(b *testing.B, rawPacket []byte) {
 		samples := make([]metrics.MetricSample, 0, 512)
 		for pb.Next() {
 			packet.Contents = rawPacket
-			samples = s.parsePackets(batcher, parser, packets, samples)
+			samples = s.parsePackets(batcher, parser, nil, packets, samples)
 		}
 	})
 }
@@ -175,7 +175,7 @@ func BenchmarkMapperControl(b *testing.B) {
 			Origin:   packets.NoOrigin,
 		}
 		packets := packets.Packets{&packet}
-		samples = s.parsePackets(batcher, parser, packets, samples)
+		samples = s.parsePackets(batcher, parser, nil, packets, samples)
 	}
 
 	b.ReportAllocs()
@@ -765,6 +765,41 @@ dogstatsd_mapper_profiles:
 			},
 			expectedCacheSize: 1000,
 		},
+		{
+			name: "Actions",
+			config: `
+dogstatsd_mapper_profiles:
+  - name: legacy
+    prefix: 'legacy.'
+    mappings:
+      - match: "legacy.debug.*"
+        action: drop
+      - match: "legacy.*"
+        match_tags:
+          env: "staging*"
+        action: drop
+      - match: "legacy.requests"
+        action: remove_tags
+        remove_tags:
+          - request_id
+      - match: "legacy.*"
+        name: "app.$1"
+        action: rename_tag
+        rename_tag:
+          hostname: source_host
+`,
+			packets: []string{
+				"legacy.debug.queue:1|g",
+				"legacy.requests:2|c|#env:staging-eu",
+				"legacy.requests:3|c|#env:prod,request_id:42",
+				"legacy.latency:4|g|#hostname:web-1,env:prod",
+			},
+			expectedSamples: []MetricSample{
+				{Name: "legacy.requests", Tags: []string{"env:prod"}, Mtype: metrics.CounterType, Value: 3.0},
+				{Name: "app.latency", Tags: []string{"source_host:web-1", "env:prod"}, Mtype: metrics.GaugeType, Value: 4.0},
+			},
+			expectedCacheSize: 1000,
+		},
 		{
 			name: "Cache size",
 			config: `
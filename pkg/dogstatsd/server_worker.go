@@ -23,6 +23,9 @@ type worker struct {
 	// the flushing logic to the aggregator is actually in the batcher.
 	batcher *batcher
 	parser  *parser
+	// limiter caps the contexts cardinality of the samples parsed by this
+	// worker, it is nil when no limit is configured.
+	limiter *cardinalityLimiter
 
 	// we allocate it once per worker instead of once per packet. This will
 	// be used to store the samples out a of packets. Allocating it every
@@ -31,12 +34,16 @@ type worker struct {
 }
 
 func newWorker(s *Server) *worker {
-	return &worker{
+	w := &worker{
 		server:  s,
 		batcher: newBatcher(s.demultiplexer),
 		parser:  newParser(s.sharedFloat64List),
 		samples: make([]metrics.MetricSample, 0, defaultSampleSize),
 	}
+	if s.cardinalityLimits != nil {
+		w.limiter = newCardinalityLimiter(*s.cardinalityLimits)
+	}
+	return w
 }
 
 func (w *worker) run() {
@@ -51,7 +58,7 @@ func (w *worker) run() {
 			w.samples = w.samples[0:0]
 			// we return the samples in case the slice was extended
 			// when parsing the packets
-			w.samples = w.server.parsePackets(w.batcher, w.parser, packets, w.samples)
+			w.samples = w.server.parsePackets(w.batcher, w.parser, w.limiter, packets, w.samples)
 		}
 
 	}
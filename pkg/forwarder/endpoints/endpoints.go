@@ -52,4 +52,8 @@ var (
 	OrchestratorEndpoint = transaction.Endpoint{Route: "/api/v2/orch", Name: "orchestrator"}
 	// ContainerLifecycleEndpoint is an event platform endpoint used to send container lifecycle events
 	ContainerLifecycleEndpoint = transaction.Endpoint{Route: "/api/v2/contlcycle", Name: "contlcycle"}
+
+	// PrometheusRemoteWriteEndpoint is the endpoint used to send series to a Prometheus remote-write backend,
+	// its route is the path of the configured remote-write URL
+	PrometheusRemoteWriteEndpoint = transaction.Endpoint{Route: "/api/v1/write", Name: "prometheus_remote_write"}
 )
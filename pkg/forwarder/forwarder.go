@@ -20,6 +20,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/forwarder/endpoints"
 	"github.com/DataDog/datadog-agent/pkg/forwarder/internal/retry"
 	"github.com/DataDog/datadog-agent/pkg/forwarder/transaction"
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
 	"github.com/DataDog/datadog-agent/pkg/util/filesystem"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 	"github.com/DataDog/datadog-agent/pkg/version"
@@ -104,6 +105,11 @@ type Options struct {
 	DomainResolvers                map[string]resolver.DomainResolver
 	ConnectionResetInterval        time.Duration
 	CompletionHandler              transaction.HTTPCompletionHandler
+	// DomainCompressors are the compressions to use for the domains which do not accept
+	// the compression of the serializer, indexed by domain
+	DomainCompressors map[string]compression.Compressor
+	// APIKeyHTTPHeader is the header carrying the API keys of the domains, "DD-Api-Key" when empty
+	APIKeyHTTPHeader string
 }
 
 // SetFeature sets forwarder features in a feature set
@@ -163,6 +169,7 @@ func NewOptionsWithResolvers(domainResolvers map[string]resolver.DomainResolver)
 		APIKeyValidationInterval:       time.Duration(validationInterval) * time.Minute,
 		DomainResolvers:                domainResolvers,
 		ConnectionResetInterval:        time.Duration(config.Datadog.GetInt("forwarder_connection_reset_interval")) * time.Second,
+		DomainCompressors:              domainCompressorsFromConfig(),
 	}
 
 	if config.Datadog.IsSet(forwarderRetryQueueMaxSizeKey) {
@@ -183,6 +190,25 @@ func NewOptionsWithResolvers(domainResolvers map[string]resolver.DomainResolver)
 	return option
 }
 
+// domainCompressorsFromConfig returns the compressors configured by `forwarder_compression_per_domain`
+func domainCompressorsFromConfig() map[string]compression.Compressor {
+	settings := map[string]compression.Settings{}
+	if err := config.Datadog.UnmarshalKey("forwarder_compression_per_domain", &settings); err != nil {
+		log.Errorf("Could not parse forwarder_compression_per_domain: %v", err)
+		return nil
+	}
+	compressors := make(map[string]compression.Compressor, len(settings))
+	for domain, s := range settings {
+		c, err := compression.NewCompressor(s.Kind, s.Level)
+		if err != nil {
+			log.Errorf("Invalid compression for domain '%s', payloads will be sent as serialized: %v", domain, err)
+			continue
+		}
+		compressors[domain] = c
+	}
+	return compressors
+}
+
 // setRetryQueuePayloadsTotalMaxSizeFromQueueMax set `RetryQueuePayloadsTotalMaxSize` from the value
 // of the deprecated settings `forwarder_retry_queue_max_size`
 func (o *Options) setRetryQueuePayloadsTotalMaxSizeFromQueueMax(v int) {
@@ -195,13 +221,15 @@ type DefaultForwarder struct {
 	// NumberOfWorkers Number of concurrent HTTP request made by the DefaultForwarder (default 4).
 	NumberOfWorkers int
 
-	domainForwarders map[string]*domainForwarder
-	domainResolvers  map[string]resolver.DomainResolver
-	healthChecker    *forwarderHealth
-	internalState    uint32     // atomic
-	m                sync.Mutex // To control Start/Stop races
+	domainForwarders  map[string]*domainForwarder
+	domainResolvers   map[string]resolver.DomainResolver
+	domainCompressors map[string]compression.Compressor
+	healthChecker     *forwarderHealth
+	internalState     uint32     // atomic
+	m                 sync.Mutex // To control Start/Stop races
 
 	completionHandler transaction.HTTPCompletionHandler
+	apiKeyHTTPHeader  string
 
 	agentName                       string
 	queueDurationCapacity           *retry.QueueDurationCapacity
@@ -212,18 +240,23 @@ type DefaultForwarder struct {
 func NewDefaultForwarder(options *Options) *DefaultForwarder {
 	agentName := getAgentName(options)
 	f := &DefaultForwarder{
-		NumberOfWorkers:  options.NumberOfWorkers,
-		domainForwarders: map[string]*domainForwarder{},
-		domainResolvers:  map[string]resolver.DomainResolver{},
-		internalState:    Stopped,
+		NumberOfWorkers:   options.NumberOfWorkers,
+		domainForwarders:  map[string]*domainForwarder{},
+		domainResolvers:   map[string]resolver.DomainResolver{},
+		domainCompressors: map[string]compression.Compressor{},
+		internalState:     Stopped,
 		healthChecker: &forwarderHealth{
 			domainResolvers:       options.DomainResolvers,
 			disableAPIKeyChecking: options.DisableAPIKeyChecking,
 			validationInterval:    options.APIKeyValidationInterval,
 		},
 		completionHandler: options.CompletionHandler,
+		apiKeyHTTPHeader:  options.APIKeyHTTPHeader,
 		agentName:         agentName,
 	}
+	if f.apiKeyHTTPHeader == "" {
+		f.apiKeyHTTPHeader = apiHTTPHeaderKey
+	}
 	var optionalRemovalPolicy *retry.FileRemovalPolicy
 	storageMaxSize := config.Datadog.GetInt64("forwarder_storage_max_size_in_bytes")
 	var diskUsageLimit *retry.DiskUsageLimit
@@ -264,6 +297,7 @@ func NewDefaultForwarder(options *Options) *DefaultForwarder {
 	var queueDiskSpaceUsedList []retry.QueueDiskSpaceUsed
 
 	for domain, resolver := range options.DomainResolvers {
+		compressor, hasCompressor := options.DomainCompressors[domain]
 		domain, _ := config.AddAgentVersionToDomain(domain, "app")
 		resolver.SetBaseDomain(domain)
 		if resolver.GetAPIKeys() == nil || len(resolver.GetAPIKeys()) == 0 {
@@ -286,6 +320,9 @@ func NewDefaultForwarder(options *Options) *DefaultForwarder {
 				transactionContainerSort,
 				resolver)
 			f.domainResolvers[domain] = resolver
+			if hasCompressor {
+				f.domainCompressors[domain] = compressor
+			}
 			queueDiskSpaceUsedList = append(queueDiskSpaceUsedList, transactionContainer)
 			fwd := newDomainForwarder(
 				domain,
@@ -424,8 +461,9 @@ func (f *DefaultForwarder) createAdvancedHTTPTransactions(endpoint transaction.E
 	transactions := make([]*transaction.HTTPTransaction, 0, len(payloads)*len(f.domainForwarders))
 	allowArbitraryTags := config.Datadog.GetBool("allow_arbitrary_tags")
 
-	for _, payload := range payloads {
+	for _, serializedPayload := range payloads {
 		for domain, dr := range f.domainResolvers {
+			payload, contentEncoding := f.compressForDomain(domain, serializedPayload, extra.Get("Content-Encoding"))
 			for _, apiKey := range dr.GetAPIKeys() {
 				t := transaction.NewHTTPTransaction()
 				t.Domain, _ = dr.Resolve(endpoint)
@@ -436,7 +474,9 @@ func (f *DefaultForwarder) createAdvancedHTTPTransactions(endpoint transaction.E
 				t.Payload = payload
 				t.Priority = priority
 				t.StorableOnDisk = storableOnDisk
-				t.Headers.Set(apiHTTPHeaderKey, apiKey)
+				if apiKey != "" {
+					t.Headers.Set(f.apiKeyHTTPHeader, apiKey)
+				}
 				t.Headers.Set(versionHTTPHeaderKey, version.AgentVersion)
 				t.Headers.Set(useragentHTTPHeaderKey, fmt.Sprintf("datadog-agent/%s", version.AgentVersion))
 				if allowArbitraryTags {
@@ -455,6 +495,11 @@ func (f *DefaultForwarder) createAdvancedHTTPTransactions(endpoint transaction.E
 				for key := range extra {
 					t.Headers.Set(key, extra.Get(key))
 				}
+				if contentEncoding != "" {
+					t.Headers.Set("Content-Encoding", contentEncoding)
+				} else {
+					t.Headers.Del("Content-Encoding")
+				}
 				transactions = append(transactions, t)
 			}
 		}
@@ -462,6 +507,32 @@ func (f *DefaultForwarder) createAdvancedHTTPTransactions(endpoint transaction.E
 	return transactions
 }
 
+// compressForDomain returns the payload compressed with the compressor of the domain along with
+// its content encoding. Payloads which are not compressed, or which cannot be decompressed, are
+// returned as is.
+func (f *DefaultForwarder) compressForDomain(domain string, payload *[]byte, contentEncoding string) (*[]byte, string) {
+	compressor, ok := f.domainCompressors[domain]
+	if !ok || contentEncoding == "" || contentEncoding == compressor.ContentEncoding() {
+		return payload, contentEncoding
+	}
+	decompressor, ok := compression.ForContentEncoding(contentEncoding)
+	if !ok {
+		log.Warnf("Cannot recompress payload for domain '%s': unknown content encoding '%s'", domain, contentEncoding)
+		return payload, contentEncoding
+	}
+	raw, err := decompressor.Decompress(*payload)
+	if err != nil {
+		log.Errorf("Cannot decompress payload for domain '%s': %v", domain, err)
+		return payload, contentEncoding
+	}
+	compressed, err := compressor.Compress(raw)
+	if err != nil {
+		log.Errorf("Cannot compress payload for domain '%s': %v", domain, err)
+		return payload, contentEncoding
+	}
+	return &compressed, compressor.ContentEncoding()
+}
+
 func (f *DefaultForwarder) sendHTTPTransactions(transactions []*transaction.HTTPTransaction) error {
 	if atomic.LoadUint32(&f.internalState) == Stopped {
 		return fmt.Errorf("the forwarder is not started")
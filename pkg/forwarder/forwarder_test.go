@@ -23,6 +23,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/config/resolver"
 	"github.com/DataDog/datadog-agent/pkg/forwarder/endpoints"
 	"github.com/DataDog/datadog-agent/pkg/forwarder/transaction"
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
 	"github.com/DataDog/datadog-agent/pkg/version"
 )
 
@@ -288,6 +289,45 @@ func TestArbitraryTagsHTTPHeader(t *testing.T) {
 	assert.Equal(t, "true", transactions[0].Headers.Get(arbitraryTagHTTPHeaderKey))
 }
 
+func TestCreateHTTPTransactionsWithDomainCompressors(t *testing.T) {
+	zlibCompressor, err := compression.NewCompressor(compression.ZlibKind, 0)
+	require.NoError(t, err)
+	gzipCompressor, err := compression.NewCompressor(compression.GzipKind, 0)
+	require.NoError(t, err)
+
+	options := NewOptionsWithResolvers(resolver.NewSingleDomainResolvers(keysWithMultipleDomains))
+	options.DomainCompressors = map[string]compression.Compressor{"datadog.bar": gzipCompressor}
+	forwarder := NewDefaultForwarder(options)
+	endpoint := transaction.Endpoint{Route: "/api/foo", Name: "foo"}
+	payload, err := zlibCompressor.Compress([]byte("A payload"))
+	require.NoError(t, err)
+	headers := make(http.Header)
+	headers.Set("Content-Encoding", "deflate")
+
+	transactions := forwarder.createHTTPTransactions(endpoint, Payloads{&payload}, false, headers)
+	require.Len(t, transactions, 3)
+	for _, tr := range transactions {
+		if tr.Domain != "datadog.bar" {
+			assert.Equal(t, "deflate", tr.Headers.Get("Content-Encoding"))
+			assert.Equal(t, payload, *tr.Payload)
+			continue
+		}
+		assert.Equal(t, "gzip", tr.Headers.Get("Content-Encoding"))
+		decompressed, err := gzipCompressor.Decompress(*tr.Payload)
+		require.NoError(t, err)
+		assert.Equal(t, "A payload", string(decompressed))
+	}
+
+	// uncompressed payloads are left untouched
+	raw := []byte("A payload")
+	transactions = forwarder.createHTTPTransactions(endpoint, Payloads{&raw}, false, make(http.Header))
+	require.Len(t, transactions, 3)
+	for _, tr := range transactions {
+		assert.Empty(t, tr.Headers.Get("Content-Encoding"))
+		assert.Equal(t, raw, *tr.Payload)
+	}
+}
+
 func TestSendHTTPTransactions(t *testing.T) {
 	forwarder := NewDefaultForwarder(NewOptionsWithResolvers(resolver.NewSingleDomainResolvers(keysPerDomains)))
 	endpoint := transaction.Endpoint{Route: "/api/foo", Name: "foo"}
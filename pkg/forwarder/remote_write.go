@@ -0,0 +1,95 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package forwarder
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/config/resolver"
+	"github.com/DataDog/datadog-agent/pkg/forwarder/endpoints"
+	"github.com/DataDog/datadog-agent/pkg/forwarder/transaction"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// RemoteWriteForwarder sends Prometheus remote-write payloads to a remote-write compatible
+// backend (Cortex, Mimir, Thanos receive...). The transactions go through the retry queue of
+// the embedded DefaultForwarder.
+type RemoteWriteForwarder struct {
+	*DefaultForwarder
+	endpoint transaction.Endpoint
+}
+
+// NewRemoteWriteForwarder returns a RemoteWriteForwarder sending payloads to the backend configured
+// by `prometheus_remote_write`, or nil if the remote-write output is disabled or misconfigured.
+func NewRemoteWriteForwarder() *RemoteWriteForwarder {
+	if !config.Datadog.GetBool("prometheus_remote_write.enabled") {
+		return nil
+	}
+
+	f, err := newRemoteWriteForwarder(
+		config.Datadog.GetString("prometheus_remote_write.url"),
+		remoteWriteAuthorization(
+			config.Datadog.GetString("prometheus_remote_write.username"),
+			config.Datadog.GetString("prometheus_remote_write.password"),
+			config.Datadog.GetString("prometheus_remote_write.bearer_token"),
+		),
+	)
+	if err != nil {
+		log.Errorf("Prometheus remote-write output disabled: %v", err)
+		return nil
+	}
+	return f
+}
+
+func newRemoteWriteForwarder(rawURL, authorization string) (*RemoteWriteForwarder, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote-write URL: %v", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return nil, fmt.Errorf("invalid remote-write URL %q: an absolute http(s) URL is expected", rawURL)
+	}
+
+	endpoint := endpoints.PrometheusRemoteWriteEndpoint
+	if route := u.RequestURI(); route != "/" {
+		endpoint.Route = route
+	}
+	domain := u.Scheme + "://" + u.Host
+
+	// The authorization is handled as the API key of the domain, so that it is scrubbed
+	// like API keys are.
+	options := NewOptionsWithResolvers(resolver.NewSingleDomainResolvers(map[string][]string{domain: {authorization}}))
+	options.DisableAPIKeyChecking = true
+	options.APIKeyHTTPHeader = "Authorization"
+
+	return &RemoteWriteForwarder{
+		DefaultForwarder: NewDefaultForwarder(options),
+		endpoint:         endpoint,
+	}, nil
+}
+
+// remoteWriteAuthorization returns the value of the Authorization header of the remote-write
+// requests, empty if no authentication is configured.
+func remoteWriteAuthorization(username, password, bearerToken string) string {
+	switch {
+	case bearerToken != "":
+		return "Bearer " + bearerToken
+	case username != "":
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+	}
+	return ""
+}
+
+// SubmitRemoteWrite sends remote-write payloads to the backend
+func (f *RemoteWriteForwarder) SubmitRemoteWrite(payload Payloads, extra http.Header) error {
+	// The payloads are not stored on disk as the requests carry the credentials of the backend
+	transactions := f.createAdvancedHTTPTransactions(f.endpoint, payload, false, extra, transaction.TransactionPriorityNormal, false)
+	return f.sendHTTPTransactions(transactions)
+}
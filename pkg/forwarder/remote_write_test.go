@@ -0,0 +1,73 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package forwarder
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRemoteWriteForwarderInvalidURL(t *testing.T) {
+	for _, u := range []string{"", "mimir:9009/api/v1/push", "ftp://mimir/push", "http://"} {
+		_, err := newRemoteWriteForwarder(u, "")
+		assert.Error(t, err, u)
+	}
+}
+
+func TestRemoteWriteAuthorization(t *testing.T) {
+	assert.Equal(t, "", remoteWriteAuthorization("", "", ""))
+	assert.Equal(t, "Basic dXNlcjpwYXNz", remoteWriteAuthorization("user", "pass", ""))
+	assert.Equal(t, "Bearer token", remoteWriteAuthorization("user", "pass", "token"))
+}
+
+func TestRemoteWriteForwarderEndToEnd(t *testing.T) {
+	type request struct {
+		path, authorization, apiKey, encoding string
+		body                                  []byte
+	}
+	requests := make(chan request, 10)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests <- request{
+			path:          r.URL.RequestURI(),
+			authorization: r.Header.Get("Authorization"),
+			apiKey:        r.Header.Get(apiHTTPHeaderKey),
+			encoding:      r.Header.Get("Content-Encoding"),
+			body:          body,
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	for _, authorization := range []string{"Bearer token", ""} {
+		f, err := newRemoteWriteForwarder(ts.URL+"/api/v1/push?tenant=a", authorization)
+		require.NoError(t, err)
+		require.NoError(t, f.Start())
+
+		payload := []byte("payload")
+		headers := http.Header{}
+		headers.Set("Content-Encoding", "snappy")
+		require.NoError(t, f.SubmitRemoteWrite(Payloads{&payload}, headers))
+
+		select {
+		case r := <-requests:
+			assert.Equal(t, "/api/v1/push?tenant=a", r.path)
+			assert.Equal(t, authorization, r.authorization)
+			assert.Empty(t, r.apiKey)
+			assert.Equal(t, "snappy", r.encoding)
+			assert.Equal(t, payload, r.body)
+		case <-time.After(5 * time.Second):
+			require.Fail(t, "no remote-write request received")
+		}
+		f.Stop()
+	}
+}
@@ -81,7 +81,7 @@ func createAgent(endpoints *config.Endpoints) (*Agent, *config.LogSources, *serv
 	services := service.NewServices()
 
 	// setup and start the agent
-	agent = NewAgent(sources, services, nil, endpoints)
+	agent = NewAgent(sources, services, nil, endpoints, nil)
 	return agent, sources, services
 }
 
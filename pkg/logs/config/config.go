@@ -317,3 +317,8 @@ func TaggerWarmupDuration() time.Duration {
 func AggregationTimeout() time.Duration {
 	return defaultLogsConfigKeys().aggregationTimeout()
 }
+
+// DedupWindow is the window during which identical messages are collapsed, 0 disables the deduplication
+func DedupWindow() time.Duration {
+	return defaultLogsConfigKeys().dedupWindow()
+}
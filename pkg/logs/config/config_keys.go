@@ -247,6 +247,10 @@ func (l *LogsConfigKeys) aggregationTimeout() time.Duration {
 	return l.getConfig().GetDuration(l.getConfigKey("aggregation_timeout")) * time.Millisecond
 }
 
+func (l *LogsConfigKeys) dedupWindow() time.Duration {
+	return l.getConfig().GetDuration(l.getConfigKey("dedup_window")) * time.Second
+}
+
 func (l *LogsConfigKeys) useV2API() bool {
 	return l.getConfig().GetBool(l.getConfigKey("use_v2_api"))
 }
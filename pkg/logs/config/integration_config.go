@@ -22,6 +22,7 @@ const (
 	WindowsEventType  = "windows_event"
 	SnmpTrapsType     = "snmp_traps"
 	StringChannelType = "string_channel"
+	OTLPType          = "otlp"
 
 	// UTF16BE for UTF-16 Big endian encoding
 	UTF16BE string = "utf-16-be"
@@ -29,6 +30,9 @@ const (
 	UTF16LE string = "utf-16-le"
 	// SHIFTJIS for Shift JIS (Japanese) encoding
 	SHIFTJIS string = "shift-jis"
+
+	// SyslogFormat for syslog messages, following RFC 5424 or RFC 3164
+	SyslogFormat string = "syslog"
 )
 
 // LogsConfig represents a log source config, which can be for instance
@@ -38,6 +42,8 @@ type LogsConfig struct {
 
 	Port        int    // Network
 	IdleTimeout string `mapstructure:"idle_timeout" json:"idle_timeout"` // Network
+	GRPCPort    int    `mapstructure:"grpc_port" json:"grpc_port"`       // OTLP
+	Format      string // Network
 	Path        string // File, Journald
 
 	Encoding     string   `mapstructure:"encoding" json:"encoding"`             // File
@@ -68,6 +74,9 @@ type LogsConfig struct {
 	Tags            []string
 	ProcessingRules []*ProcessingRule `mapstructure:"log_processing_rules" json:"log_processing_rules"`
 
+	RateLimit *RateLimit `mapstructure:"rate_limit" json:"rate_limit"`
+	Sampling  *Sampling
+
 	AutoMultiLine               *bool   `mapstructure:"auto_multi_line_detection" json:"auto_multi_line_detection"`
 	AutoMultiLineSampleSize     int     `mapstructure:"auto_multi_line_sample_size" json:"auto_multi_line_sample_size"`
 	AutoMultiLineMatchThreshold float64 `mapstructure:"auto_multi_line_match_threshold" json:"auto_multi_line_match_threshold"`
@@ -139,6 +148,17 @@ func (c *LogsConfig) Validate() error {
 		return fmt.Errorf("tcp source must have a port")
 	case c.Type == UDPType && c.Port == 0:
 		return fmt.Errorf("udp source must have a port")
+	case c.Type == OTLPType && c.Port == 0 && c.GRPCPort == 0:
+		return fmt.Errorf("otlp source must have a port or a grpc_port")
+	}
+	if err := c.validateFormat(); err != nil {
+		return err
+	}
+	if err := validateRateLimit(c.RateLimit); err != nil {
+		return err
+	}
+	if err := validateSampling(c.Sampling); err != nil {
+		return err
 	}
 	err := ValidateProcessingRules(c.ProcessingRules)
 	if err != nil {
@@ -147,6 +167,18 @@ func (c *LogsConfig) Validate() error {
 	return CompileProcessingRules(c.ProcessingRules)
 }
 
+func (c *LogsConfig) validateFormat() error {
+	switch {
+	case c.Format == "":
+		return nil
+	case c.Format != SyslogFormat:
+		return fmt.Errorf("invalid format '%v', supported formats are: %v", c.Format, SyslogFormat)
+	case c.Type != TCPType && c.Type != UDPType:
+		return fmt.Errorf("format '%v' is only supported by tcp and udp sources", c.Format)
+	}
+	return nil
+}
+
 func (c *LogsConfig) validateTailingMode() error {
 	mode, found := TailingModeFromString(c.TailingMode)
 	if !found && c.TailingMode != "" {
@@ -21,6 +21,8 @@ func TestValidateShouldSucceedWithValidConfigs(t *testing.T) {
 		{Type: FileType, Path: "/var/log/foo.log"},
 		{Type: TCPType, Port: 1234},
 		{Type: UDPType, Port: 5678},
+		{Type: TCPType, Port: 1234, Format: SyslogFormat},
+		{Type: UDPType, Port: 5678, Format: SyslogFormat},
 		{Type: DockerType},
 		{Type: JournaldType, ProcessingRules: []*ProcessingRule{{Name: "foo", Type: ExcludeAtMatch, Pattern: ".*"}}},
 		{Type: SnmpTrapsType},
@@ -38,6 +40,8 @@ func TestValidateShouldFailWithInvalidConfigs(t *testing.T) {
 		{Type: FileType},
 		{Type: TCPType},
 		{Type: UDPType},
+		{Type: TCPType, Port: 1234, Format: "json"},
+		{Type: FileType, Path: "/var/log/foo.log", Format: SyslogFormat},
 		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Name: "foo"}}},
 		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Name: "foo", Type: "bar"}}},
 		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Name: "foo", Type: ExcludeAtMatch}}},
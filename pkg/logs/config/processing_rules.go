@@ -8,6 +8,7 @@ package config
 import (
 	"fmt"
 	"regexp"
+	"strings"
 )
 
 // Processing rule types
@@ -16,6 +17,33 @@ const (
 	IncludeAtMatch = "include_at_match"
 	MaskSequences  = "mask_sequences"
 	MultiLine      = "multi_line"
+	ExtractMetric  = "extract_metric"
+)
+
+// Aggregation modes of multi_line rules, their pattern is matched at the beginning of the lines
+const (
+	// MultiLineStart starts a new message at every line matching the pattern
+	MultiLineStart = "start"
+	// MultiLineContinue appends the lines matching the pattern to the current message
+	// and starts a new message at every other line
+	MultiLineContinue = "continue"
+	// MultiLineEnd ends the current message at every line matching the pattern
+	MultiLineEnd = "end"
+)
+
+// Types of the metrics emitted by extract_metric processing rules
+const (
+	MetricTypeCount        = "count"
+	MetricTypeDistribution = "distribution"
+)
+
+// Structured processing rule types, applied on a single field of JSON formatted logs
+const (
+	RemoveField = "remove_field"
+	MaskField   = "mask_field"
+	HashField   = "hash_field"
+	RenameField = "rename_field"
+	AddField    = "add_field"
 )
 
 // ProcessingRule defines an exclusion or a masking rule to
@@ -25,9 +53,54 @@ type ProcessingRule struct {
 	Name               string
 	ReplacePlaceholder string `mapstructure:"replace_placeholder" json:"replace_placeholder"`
 	Pattern            string
+	// Path is the dot separated path of the JSON field targeted by a structured rule
+	Path string
+	// Target is the destination path of a rename_field rule
+	Target string
+	// Value is the value set by an add_field rule
+	Value string
+	// Metric is the metric emitted by an extract_metric rule
+	Metric *ExtractedMetric
+	// Mode is the aggregation mode of a multi_line rule, defaults to start
+	Mode string
+	// AggregationTimeout is the number of milliseconds after which a multi_line rule
+	// flushes an incomplete message, defaults to logs_config.aggregation_timeout
+	AggregationTimeout int `mapstructure:"aggregation_timeout" json:"aggregation_timeout"`
+	// MaxLines is the maximum number of lines aggregated by a multi_line rule,
+	// longer messages are truncated, unlimited when not set
+	MaxLines int `mapstructure:"max_lines" json:"max_lines"`
 	// TODO: should be moved out
 	Regex       *regexp.Regexp
 	Placeholder []byte
+	FieldPath   []string
+	TargetPath  []string
+	// ValueGroupIndex is the index of the Metric.ValueGroup capture group in Regex
+	ValueGroupIndex int
+}
+
+// ExtractedMetric defines the metric sample emitted for every log matching
+// an extract_metric processing rule.
+type ExtractedMetric struct {
+	Name string
+	// Type is either count or distribution, defaults to count
+	Type string
+	// ValueGroup is the name of the capture group holding the value of the sample,
+	// when not set every matching log increments the metric by 1
+	ValueGroup string `mapstructure:"value_group" json:"value_group"`
+	// Tags can reference capture groups, e.g. `status:${status}`
+	Tags []string
+	// DropLog drops the log once the metric has been extracted
+	DropLog bool `mapstructure:"drop_log" json:"drop_log"`
+}
+
+// IsStructuredRule returns true if the rule type applies on a JSON field
+// instead of the raw content of the log.
+func IsStructuredRule(ruleType string) bool {
+	switch ruleType {
+	case RemoveField, MaskField, HashField, RenameField, AddField:
+		return true
+	}
+	return false
 }
 
 // ValidateProcessingRules validates the rules and raises an error if one is misconfigured.
@@ -35,14 +108,22 @@ type ProcessingRule struct {
 // - a valid name
 // - a valid type
 // - a valid pattern that compiles
+// Structured rules must have a valid path instead of a pattern.
 func ValidateProcessingRules(rules []*ProcessingRule) error {
 	for _, rule := range rules {
 		if rule.Name == "" {
 			return fmt.Errorf("all processing rules must have a name")
 		}
 
+		if IsStructuredRule(rule.Type) {
+			if err := validateStructuredProcessingRule(rule); err != nil {
+				return err
+			}
+			continue
+		}
+
 		switch rule.Type {
-		case ExcludeAtMatch, IncludeAtMatch, MaskSequences, MultiLine:
+		case ExcludeAtMatch, IncludeAtMatch, MaskSequences, MultiLine, ExtractMetric:
 			break
 		case "":
 			return fmt.Errorf("type must be set for processing rule `%s`", rule.Name)
@@ -53,14 +134,102 @@ func ValidateProcessingRules(rules []*ProcessingRule) error {
 		if rule.Pattern == "" {
 			return fmt.Errorf("no pattern provided for processing rule: %s", rule.Name)
 		}
-		_, err := regexp.Compile(rule.Pattern)
+		re, err := regexp.Compile(rule.Pattern)
 		if err != nil {
 			return fmt.Errorf("invalid pattern %s for processing rule: %s", rule.Pattern, rule.Name)
 		}
+
+		switch rule.Type {
+		case ExtractMetric:
+			if err := validateExtractedMetric(rule, re); err != nil {
+				return err
+			}
+		case MultiLine:
+			if err := validateMultiLineRule(rule); err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
 
+// validateMultiLineRule validates the aggregation settings of a multi_line rule.
+func validateMultiLineRule(rule *ProcessingRule) error {
+	switch rule.Mode {
+	case "", MultiLineStart, MultiLineContinue, MultiLineEnd:
+		break
+	default:
+		return fmt.Errorf("mode %s is not supported for processing rule: %s", rule.Mode, rule.Name)
+	}
+	if rule.AggregationTimeout < 0 {
+		return fmt.Errorf("aggregation_timeout must not be negative for processing rule: %s", rule.Name)
+	}
+	if rule.MaxLines < 0 {
+		return fmt.Errorf("max_lines must not be negative for processing rule: %s", rule.Name)
+	}
+	return nil
+}
+
+// validateExtractedMetric validates the metric definition of an extract_metric rule.
+func validateExtractedMetric(rule *ProcessingRule, re *regexp.Regexp) error {
+	if rule.Metric == nil || rule.Metric.Name == "" {
+		return fmt.Errorf("no metric name provided for processing rule: %s", rule.Name)
+	}
+	switch rule.Metric.Type {
+	case "", MetricTypeCount:
+		break
+	case MetricTypeDistribution:
+		if rule.Metric.ValueGroup == "" {
+			return fmt.Errorf("a value_group must be set for distribution metrics in processing rule: %s", rule.Name)
+		}
+	default:
+		return fmt.Errorf("metric type %s is not supported for processing rule: %s", rule.Metric.Type, rule.Name)
+	}
+	if rule.Metric.ValueGroup != "" && re.SubexpIndex(rule.Metric.ValueGroup) < 0 {
+		return fmt.Errorf("no capture group named %s in the pattern of processing rule: %s", rule.Metric.ValueGroup, rule.Name)
+	}
+	return nil
+}
+
+// validateStructuredProcessingRule validates a rule applied on a JSON field.
+func validateStructuredProcessingRule(rule *ProcessingRule) error {
+	if !isValidFieldPath(rule.Path) {
+		return fmt.Errorf("invalid path %q for processing rule: %s", rule.Path, rule.Name)
+	}
+	switch rule.Type {
+	case RenameField:
+		if !isValidFieldPath(rule.Target) {
+			return fmt.Errorf("invalid target %q for processing rule: %s", rule.Target, rule.Name)
+		}
+		if rule.Target == rule.Path || strings.HasPrefix(rule.Target, rule.Path+".") {
+			return fmt.Errorf("target %q can't be located under path %q for processing rule: %s", rule.Target, rule.Path, rule.Name)
+		}
+	case AddField:
+		if rule.Value == "" {
+			return fmt.Errorf("no value provided for processing rule: %s", rule.Name)
+		}
+	case MaskField:
+		// the pattern is optional, the whole value is masked when it is not set
+		if _, err := regexp.Compile(rule.Pattern); err != nil {
+			return fmt.Errorf("invalid pattern %s for processing rule: %s", rule.Pattern, rule.Name)
+		}
+	}
+	return nil
+}
+
+// isValidFieldPath returns true if path is a dot separated list of non-empty keys.
+func isValidFieldPath(path string) bool {
+	if path == "" {
+		return false
+	}
+	for _, key := range strings.Split(path, ".") {
+		if key == "" {
+			return false
+		}
+	}
+	return true
+}
+
 // CompileProcessingRules compiles all processing rule regular expressions.
 func CompileProcessingRules(rules []*ProcessingRule) error {
 	for _, rule := range rules {
@@ -71,6 +240,11 @@ func CompileProcessingRules(rules []*ProcessingRule) error {
 		switch rule.Type {
 		case ExcludeAtMatch, IncludeAtMatch:
 			rule.Regex = re
+		case ExtractMetric:
+			rule.Regex = re
+			if rule.Metric != nil && rule.Metric.ValueGroup != "" {
+				rule.ValueGroupIndex = re.SubexpIndex(rule.Metric.ValueGroup)
+			}
 		case MaskSequences:
 			rule.Regex = re
 			rule.Placeholder = []byte(rule.ReplacePlaceholder)
@@ -79,6 +253,17 @@ func CompileProcessingRules(rules []*ProcessingRule) error {
 			if err != nil {
 				return err
 			}
+		case RemoveField, HashField, AddField:
+			rule.FieldPath = strings.Split(rule.Path, ".")
+		case MaskField:
+			rule.FieldPath = strings.Split(rule.Path, ".")
+			if rule.Pattern != "" {
+				rule.Regex = re
+			}
+			rule.Placeholder = []byte(rule.ReplacePlaceholder)
+		case RenameField:
+			rule.FieldPath = strings.Split(rule.Path, ".")
+			rule.TargetPath = strings.Split(rule.Target, ".")
 		}
 	}
 	return nil
@@ -34,3 +34,72 @@ func TestCompileShouldFailWithInvalidRules(t *testing.T) {
 		assert.Nil(t, rule.Regex)
 	}
 }
+
+func TestValidateStructuredRules(t *testing.T) {
+	validRules := []*ProcessingRule{
+		{Name: "remove", Type: RemoveField, Path: "http.request.headers.authorization"},
+		{Name: "mask", Type: MaskField, Path: "password", ReplacePlaceholder: "[masked]"},
+		{Name: "mask_pattern", Type: MaskField, Path: "card", Pattern: "\\d{12}"},
+		{Name: "hash", Type: HashField, Path: "user.email"},
+		{Name: "rename", Type: RenameField, Path: "usr", Target: "user"},
+		{Name: "add", Type: AddField, Path: "env", Value: "prod"},
+	}
+	assert.Nil(t, ValidateProcessingRules(validRules))
+	assert.Nil(t, CompileProcessingRules(validRules))
+	assert.Equal(t, []string{"http", "request", "headers", "authorization"}, validRules[0].FieldPath)
+	assert.Nil(t, validRules[1].Regex)
+	assert.NotNil(t, validRules[2].Regex)
+	assert.Equal(t, []string{"user"}, validRules[4].TargetPath)
+
+	invalidRules := []*ProcessingRule{
+		{Name: "no_path", Type: RemoveField},
+		{Name: "empty_key", Type: HashField, Path: "user..email"},
+		{Name: "no_target", Type: RenameField, Path: "usr"},
+		{Name: "target_under_path", Type: RenameField, Path: "usr", Target: "usr.id"},
+		{Name: "no_value", Type: AddField, Path: "env"},
+		{Name: "bad_pattern", Type: MaskField, Path: "card", Pattern: "(?=abf)"},
+	}
+	for _, rule := range invalidRules {
+		assert.NotNil(t, ValidateProcessingRules([]*ProcessingRule{rule}), rule.Name)
+	}
+}
+
+func TestValidateExtractMetricRules(t *testing.T) {
+	validRules := []*ProcessingRule{
+		{Name: "count", Type: ExtractMetric, Pattern: "level=error", Metric: &ExtractedMetric{Name: "app.errors"}},
+		{Name: "distribution", Type: ExtractMetric, Pattern: "took (?P<duration>\\d+)ms", Metric: &ExtractedMetric{Name: "app.duration", Type: MetricTypeDistribution, ValueGroup: "duration"}},
+	}
+	assert.Nil(t, ValidateProcessingRules(validRules))
+	assert.Nil(t, CompileProcessingRules(validRules))
+	assert.NotNil(t, validRules[0].Regex)
+	assert.Equal(t, 1, validRules[1].ValueGroupIndex)
+
+	invalidRules := []*ProcessingRule{
+		{Name: "no_metric", Type: ExtractMetric, Pattern: "error"},
+		{Name: "no_name", Type: ExtractMetric, Pattern: "error", Metric: &ExtractedMetric{}},
+		{Name: "bad_type", Type: ExtractMetric, Pattern: "error", Metric: &ExtractedMetric{Name: "app.errors", Type: "gauge"}},
+		{Name: "no_value_group", Type: ExtractMetric, Pattern: "took (\\d+)ms", Metric: &ExtractedMetric{Name: "app.duration", Type: MetricTypeDistribution}},
+		{Name: "unknown_group", Type: ExtractMetric, Pattern: "took (\\d+)ms", Metric: &ExtractedMetric{Name: "app.duration", ValueGroup: "duration"}},
+	}
+	for _, rule := range invalidRules {
+		assert.NotNil(t, ValidateProcessingRules([]*ProcessingRule{rule}), rule.Name)
+	}
+}
+
+func TestValidateMultiLineRules(t *testing.T) {
+	validRules := []*ProcessingRule{
+		{Name: "start", Type: MultiLine, Pattern: "\\d{4}-\\d{2}-\\d{2}"},
+		{Name: "continue", Type: MultiLine, Pattern: "\\s", Mode: MultiLineContinue, AggregationTimeout: 500},
+		{Name: "end", Type: MultiLine, Pattern: "exit status", Mode: MultiLineEnd, MaxLines: 100},
+	}
+	assert.Nil(t, ValidateProcessingRules(validRules))
+
+	invalidRules := []*ProcessingRule{
+		{Name: "bad_mode", Type: MultiLine, Pattern: "\\s", Mode: "while"},
+		{Name: "negative_timeout", Type: MultiLine, Pattern: "\\s", AggregationTimeout: -1},
+		{Name: "negative_max_lines", Type: MultiLine, Pattern: "\\s", MaxLines: -1},
+	}
+	for _, rule := range invalidRules {
+		assert.NotNil(t, ValidateProcessingRules([]*ProcessingRule{rule}), rule.Name)
+	}
+}
@@ -0,0 +1,63 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package config
+
+import (
+	"fmt"
+)
+
+// Rate limit scopes
+const (
+	// RateLimitScopeSource limits the logs of each source independently
+	RateLimitScopeSource = "source"
+	// RateLimitScopeService limits the logs of all the sources sharing the same service
+	RateLimitScopeService = "service"
+)
+
+// RateLimit defines a token bucket limiting the number of logs sent per second.
+type RateLimit struct {
+	MessagesPerSecond float64 `mapstructure:"messages_per_second" json:"messages_per_second"`
+	// Burst is the number of logs that can be sent at once, defaults to MessagesPerSecond
+	Burst int
+	// Scope is either source or service, defaults to source
+	Scope string
+}
+
+// Sampling defines how the logs of a source are sampled.
+type Sampling struct {
+	// Rate is the ratio of logs kept, between 0 and 1, all logs are kept when not set
+	Rate float64
+}
+
+// validateRateLimit returns an error if the rate limit is misconfigured.
+func validateRateLimit(r *RateLimit) error {
+	if r == nil {
+		return nil
+	}
+	if r.MessagesPerSecond <= 0 {
+		return fmt.Errorf("rate_limit messages_per_second must be greater than 0")
+	}
+	if r.Burst < 0 {
+		return fmt.Errorf("rate_limit burst must not be negative")
+	}
+	switch r.Scope {
+	case "", RateLimitScopeSource, RateLimitScopeService:
+		return nil
+	default:
+		return fmt.Errorf("rate_limit scope %s is not supported", r.Scope)
+	}
+}
+
+// validateSampling returns an error if the sampling is misconfigured.
+func validateSampling(s *Sampling) error {
+	if s == nil {
+		return nil
+	}
+	if s.Rate < 0 || s.Rate > 1 {
+		return fmt.Errorf("sampling rate must be between 0 and 1")
+	}
+	return nil
+}
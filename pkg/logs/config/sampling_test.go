@@ -0,0 +1,33 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateSampling(t *testing.T) {
+	valid := []*LogsConfig{
+		{Type: TCPType, Port: 1234, RateLimit: &RateLimit{MessagesPerSecond: 10}},
+		{Type: TCPType, Port: 1234, RateLimit: &RateLimit{MessagesPerSecond: 10, Burst: 100, Scope: RateLimitScopeService}},
+		{Type: TCPType, Port: 1234, Sampling: &Sampling{Rate: 0.1}},
+	}
+	for _, config := range valid {
+		assert.Nil(t, config.Validate())
+	}
+
+	invalid := []*LogsConfig{
+		{Type: TCPType, Port: 1234, RateLimit: &RateLimit{}},
+		{Type: TCPType, Port: 1234, RateLimit: &RateLimit{MessagesPerSecond: 10, Burst: -1}},
+		{Type: TCPType, Port: 1234, RateLimit: &RateLimit{MessagesPerSecond: 10, Scope: "host"}},
+		{Type: TCPType, Port: 1234, Sampling: &Sampling{Rate: 2}},
+	}
+	for _, config := range invalid {
+		assert.NotNil(t, config.Validate())
+	}
+}
@@ -31,6 +31,9 @@ type LogSource struct {
 	// Put expvar Int first because it's modified with sync/atomic, so it needs to
 	// be 64-bit aligned on 32-bit systems. See https://golang.org/pkg/sync/atomic/#pkg-note-BUG
 	BytesRead expvar.Int
+	// RateLimited and SampledOut count the logs dropped by the sampler of the source
+	RateLimited expvar.Int
+	SampledOut  expvar.Int
 
 	Name     string
 	Config   *LogsConfig
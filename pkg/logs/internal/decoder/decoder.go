@@ -38,6 +38,7 @@ type Message struct {
 	Status             string
 	RawDataLen         int
 	Timestamp          string
+	Hostname           string
 	IngestionTimestamp int64
 }
 
@@ -97,7 +98,7 @@ func NewDecoderWithFraming(source *config.LogSource, parser parsers.Parser, fram
 	var lineHandler LineHandler
 	for _, rule := range source.Config.ProcessingRules {
 		if rule.Type == config.MultiLine {
-			lh := NewMultiLineHandler(outputFn, rule.Regex, config.AggregationTimeout(), lineLimit)
+			lh := NewMultiLineHandlerWithRule(outputFn, rule, lineLimit)
 
 			// Since a single source can have multiple file tailers - each with their own decoder instance,
 			// Make sure we keep track of the multiline match count info from all of the decoders so the
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/internal/metrics"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -173,6 +174,137 @@ func TestMultiLineHandler(t *testing.T) {
 	assert.Equal(t, len(shortLineTracingSpaces)+1, output.RawDataLen)
 }
 
+func TestMultiLineHandlerContinueMode(t *testing.T) {
+	rule := &config.ProcessingRule{Type: config.MultiLine, Mode: config.MultiLineContinue, Regex: regexp.MustCompile(`^(\s|Traceback)`)}
+	outputFn, outputChan := lineHandlerChans()
+	h := NewMultiLineHandlerWithRule(outputFn, rule, 100)
+
+	var output *Message
+
+	h.process(getDummyMessageWithLF("Traceback (most recent call last):"))
+	h.process(getDummyMessageWithLF("  File \"app.py\", line 1"))
+	h.process(getDummyMessageWithLF("ValueError: boom"))
+	h.process(getDummyMessageWithLF("next message"))
+
+	output = <-outputChan
+	assert.Equal(t, "Traceback (most recent call last):\\n  File \"app.py\", line 1", string(output.Content))
+
+	output = <-outputChan
+	assert.Equal(t, "ValueError: boom", string(output.Content))
+
+	assertNothingInChannel(t, outputChan)
+	h.flush()
+
+	output = <-outputChan
+	assert.Equal(t, "next message", string(output.Content))
+	assert.Equal(t, len("next message")+1, output.RawDataLen)
+}
+
+func TestMultiLineHandlerEndMode(t *testing.T) {
+	rule := &config.ProcessingRule{Type: config.MultiLine, Mode: config.MultiLineEnd, Regex: regexp.MustCompile(`^exit status`)}
+	outputFn, outputChan := lineHandlerChans()
+	h := NewMultiLineHandlerWithRule(outputFn, rule, 100)
+
+	var output *Message
+
+	h.process(getDummyMessageWithLF("panic: boom"))
+	h.process(getDummyMessageWithLF("goroutine 1 [running]:"))
+	h.process(getDummyMessageWithLF("exit status 2"))
+
+	output = <-outputChan
+	assert.Equal(t, "panic: boom\\ngoroutine 1 [running]:\\nexit status 2", string(output.Content))
+	assert.Equal(t, len("panic: boom")+len("goroutine 1 [running]:")+len("exit status 2")+3, output.RawDataLen)
+
+	forceFlushed := metrics.LogsMultiLineForceFlushed.Value()
+	h.process(getDummyMessageWithLF("incomplete"))
+	assertNothingInChannel(t, outputChan)
+	h.flush()
+
+	output = <-outputChan
+	assert.Equal(t, "incomplete", string(output.Content))
+	// the end line was not received before the timeout
+	assert.Equal(t, forceFlushed+1, metrics.LogsMultiLineForceFlushed.Value())
+}
+
+func TestMultiLineHandlerEndModeTruncatedEndLine(t *testing.T) {
+	rule := &config.ProcessingRule{Type: config.MultiLine, Mode: config.MultiLineEnd, Regex: regexp.MustCompile(`^exit status`)}
+	outputFn, outputChan := lineHandlerChans()
+	h := NewMultiLineHandlerWithRule(outputFn, rule, 20)
+
+	var output *Message
+
+	h.process(getDummyMessageWithLF("panic: boom"))
+	h.process(getDummyMessageWithLF("exit status 2"))
+
+	output = <-outputChan
+	assert.Equal(t, "panic: boom\\nexit status 2...TRUNCATED...", string(output.Content))
+
+	// the end line completed the message, the next line is not a remainder
+	h.process(getDummyMessageWithLF("panic: again"))
+	h.flush()
+
+	output = <-outputChan
+	assert.Equal(t, "panic: again", string(output.Content))
+}
+
+func TestMultiLineHandlerMaxLines(t *testing.T) {
+	rule := &config.ProcessingRule{Type: config.MultiLine, Regex: regexp.MustCompile(`^\d+\.`), MaxLines: 2}
+	outputFn, outputChan := lineHandlerChans()
+	h := NewMultiLineHandlerWithRule(outputFn, rule, 100)
+
+	var output *Message
+
+	truncated := metrics.LogsMultiLineTruncated.Value()
+	forceFlushed := metrics.LogsMultiLineForceFlushed.Value()
+	h.process(getDummyMessageWithLF("1. first"))
+	h.process(getDummyMessageWithLF("second"))
+	h.process(getDummyMessageWithLF("third"))
+	h.process(getDummyMessageWithLF("2. next"))
+
+	output = <-outputChan
+	assert.Equal(t, "1. first\\nsecond...TRUNCATED...", string(output.Content))
+	assert.Equal(t, len("1. first")+len("second")+2, output.RawDataLen)
+
+	output = <-outputChan
+	assert.Equal(t, "...TRUNCATED...third", string(output.Content))
+	assert.Equal(t, truncated+1, metrics.LogsMultiLineTruncated.Value())
+	assert.Equal(t, forceFlushed+1, metrics.LogsMultiLineForceFlushed.Value())
+
+	// a message holding exactly the maximum number of lines is not truncated
+	h.process(getDummyMessageWithLF("continued"))
+	h.process(getDummyMessageWithLF("3. last"))
+
+	output = <-outputChan
+	assert.Equal(t, "2. next\\ncontinued", string(output.Content))
+	assert.Equal(t, truncated+1, metrics.LogsMultiLineTruncated.Value())
+	assert.Equal(t, forceFlushed+1, metrics.LogsMultiLineForceFlushed.Value())
+}
+
+func TestMultiLineHandlerAggregationTimeout(t *testing.T) {
+	rule := &config.ProcessingRule{Type: config.MultiLine, Regex: regexp.MustCompile(`^\d+\.`), AggregationTimeout: 42}
+	outputFn, outputChan := lineHandlerChans()
+	h := NewMultiLineHandlerWithRule(outputFn, rule, 100)
+	assert.Equal(t, 42*time.Millisecond, h.flushTimeout)
+
+	rule.AggregationTimeout = 0
+	h = NewMultiLineHandlerWithRule(outputFn, rule, 100)
+	assert.Equal(t, config.AggregationTimeout(), h.flushTimeout)
+
+	// the timeout is how the last message completes in start mode, it is not cut short
+	forceFlushed := metrics.LogsMultiLineForceFlushed.Value()
+	h.process(getDummyMessageWithLF("1. first"))
+	h.flush()
+	<-outputChan
+	assert.Equal(t, forceFlushed, metrics.LogsMultiLineForceFlushed.Value())
+
+	// flushing an empty buffer is not counted
+	rule.Mode = config.MultiLineEnd
+	h = NewMultiLineHandlerWithRule(outputFn, rule, 100)
+	h.flush()
+	assertNothingInChannel(t, outputChan)
+	assert.Equal(t, forceFlushed, metrics.LogsMultiLineForceFlushed.Value())
+}
+
 func TestTrimMultiLine(t *testing.T) {
 	re := regexp.MustCompile("[0-9]+\\.")
 	outputFn, outputChan := lineHandlerChans()
@@ -221,6 +353,23 @@ func TestMultiLineHandlerDropsEmptyMessages(t *testing.T) {
 	assert.Equal(t, "1.third line\\nfourth line", string(output.Content))
 }
 
+func TestMultiLineHandlerKeepsHostname(t *testing.T) {
+	re := regexp.MustCompile("[0-9]+\\.")
+	outputFn, outputChan := lineHandlerChans()
+	h := NewMultiLineHandler(outputFn, re, 10*time.Millisecond, 100)
+
+	for _, content := range []string{"1.first line", "second line"} {
+		msg := getDummyMessage(content)
+		msg.Hostname = "myhost"
+		h.process(msg)
+	}
+	h.flush()
+
+	output := <-outputChan
+	assert.Equal(t, "1.first line\\nsecond line", string(output.Content))
+	assert.Equal(t, "myhost", output.Hostname)
+}
+
 func TestSingleLineHandlerSendsRawInvalidMessages(t *testing.T) {
 	outputFn, outputChan := lineHandlerChans()
 	h := NewSingleLineHandler(outputFn, 100)
@@ -58,7 +58,9 @@ func (p *SingleLineParser) process(content []byte, rawDataLen int) {
 	if err != nil {
 		log.Debug(err)
 	}
-	p.outputFn(NewMessage(msg.Content, msg.Status, rawDataLen, msg.Timestamp))
+	output := NewMessage(msg.Content, msg.Status, rawDataLen, msg.Timestamp)
+	output.Hostname = msg.Hostname
+	p.outputFn(output)
 }
 
 // MultiLineParser makes sure that chunked lines are properly put together.
@@ -72,6 +74,7 @@ type MultiLineParser struct {
 	lineLimit    int
 	status       string
 	timestamp    string
+	hostname     string
 }
 
 // NewMultiLineParser returns a new MultiLineParser.
@@ -118,6 +121,7 @@ func (p *MultiLineParser) process(content []byte, rawDataLen int) {
 	// from the right place at restart
 	p.rawDataLen += rawDataLen
 	p.timestamp = msg.Timestamp
+	p.hostname = msg.Hostname
 	p.status = msg.Status
 	p.buffer.Write(msg.Content)
 
@@ -145,6 +149,8 @@ func (p *MultiLineParser) sendLine() {
 	content := make([]byte, p.buffer.Len())
 	copy(content, p.buffer.Bytes())
 	if len(content) > 0 || p.rawDataLen > 0 {
-		p.outputFn(NewMessage(content, p.status, p.rawDataLen, p.timestamp))
+		output := NewMessage(content, p.status, p.rawDataLen, p.timestamp)
+		output.Hostname = p.hostname
+		p.outputFn(output)
 	}
 }
@@ -11,29 +11,36 @@ import (
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/internal/metrics"
 )
 
 // MultiLineHandler makes sure that multiple lines from a same content
 // are properly put together.
 type MultiLineHandler struct {
 	outputFn       func(*Message)
-	newContentRe   *regexp.Regexp
+	re             *regexp.Regexp
+	mode           string
 	buffer         *bytes.Buffer
 	flushTimeout   time.Duration
 	flushTimer     *time.Timer
 	lineLimit      int
+	maxLines       int
 	shouldTruncate bool
 	linesLen       int
+	linesCount     int
 	status         string
 	timestamp      string
+	hostname       string
 	countInfo      *config.CountInfo
 }
 
-// NewMultiLineHandler returns a new MultiLineHandler.
+// NewMultiLineHandler returns a new MultiLineHandler starting a new message
+// at every line matching newContentRe.
 func NewMultiLineHandler(outputFn func(*Message), newContentRe *regexp.Regexp, flushTimeout time.Duration, lineLimit int) *MultiLineHandler {
 	return &MultiLineHandler{
 		outputFn:     outputFn,
-		newContentRe: newContentRe,
+		re:           newContentRe,
+		mode:         config.MultiLineStart,
 		buffer:       bytes.NewBuffer(nil),
 		flushTimeout: flushTimeout,
 		lineLimit:    lineLimit,
@@ -41,6 +48,21 @@ func NewMultiLineHandler(outputFn func(*Message), newContentRe *regexp.Regexp, f
 	}
 }
 
+// NewMultiLineHandlerWithRule returns a new MultiLineHandler aggregating lines following
+// the mode, the aggregation timeout and the maximum number of lines of a multi_line rule.
+func NewMultiLineHandlerWithRule(outputFn func(*Message), rule *config.ProcessingRule, lineLimit int) *MultiLineHandler {
+	flushTimeout := config.AggregationTimeout()
+	if rule.AggregationTimeout > 0 {
+		flushTimeout = time.Duration(rule.AggregationTimeout) * time.Millisecond
+	}
+	h := NewMultiLineHandler(outputFn, rule.Regex, flushTimeout, lineLimit)
+	if rule.Mode != "" {
+		h.mode = rule.Mode
+	}
+	h.maxLines = rule.MaxLines
+	return h
+}
+
 func (h *MultiLineHandler) flushChan() <-chan time.Time {
 	if h.flushTimer != nil && h.buffer.Len() > 0 {
 		return h.flushTimer.C
@@ -49,12 +71,23 @@ func (h *MultiLineHandler) flushChan() <-chan time.Time {
 }
 
 func (h *MultiLineHandler) flush() {
+	if h.mode == config.MultiLineEnd && h.buffer.Len() > 0 {
+		// the message is sent before its end line was received,
+		// in the other modes the timeout is how the last message completes
+		h.countForceFlush()
+	}
 	h.sendBuffer()
 }
 
+func (h *MultiLineHandler) countForceFlush() {
+	metrics.LogsMultiLineForceFlushed.Add(1)
+	metrics.TlmLogsMultiLineForceFlushed.Inc()
+}
+
 // process aggregates multiple lines to form a full multiline message,
-// it stops when a line matches with the new content regular expression.
-// It also makes sure that the content will never exceed the limit
+// depending on the mode it stops when a line matches with the regular
+// expression, when a line does not match with it, or after a line matching with it.
+// It also makes sure that the content will never exceed the limits
 // and that the length of the lines is properly tracked
 // so that the agent restarts tailing from the right place.
 func (h *MultiLineHandler) process(message *Message) {
@@ -65,11 +98,19 @@ func (h *MultiLineHandler) process(message *Message) {
 		}
 	}
 
-	if h.newContentRe.Match(message.Content) {
+	matched := h.re.Match(message.Content)
+	if matched {
 		h.countInfo.Add(1)
+	}
+	switch {
+	case h.mode == config.MultiLineStart && matched, h.mode == config.MultiLineContinue && !matched:
 		// the current line is part of a new message,
 		// send the buffer
 		h.sendBuffer()
+	case h.maxLines > 0 && h.linesCount >= h.maxLines:
+		// the message already holds the maximum number of lines,
+		// it needs to be cut off and the current line is a remainder
+		h.truncate()
 	}
 
 	isTruncated := h.shouldTruncate
@@ -79,6 +120,7 @@ func (h *MultiLineHandler) process(message *Message) {
 	// from the right place at restart
 	h.linesLen += message.RawDataLen
 	h.timestamp = message.Timestamp
+	h.hostname = message.Hostname
 	h.status = message.Status
 
 	if h.buffer.Len() > 0 {
@@ -95,13 +137,18 @@ func (h *MultiLineHandler) process(message *Message) {
 	}
 
 	h.buffer.Write(message.Content)
+	h.linesCount++
 
 	if h.buffer.Len() >= h.lineLimit {
-		// the multiline message is too long, it needs to be cut off and send,
-		// adding the truncated flag the end of the content
-		h.buffer.Write(truncatedFlag)
+		// the multiline message is too long, it needs to be cut off and send
+		h.truncate()
+		if h.mode == config.MultiLineEnd && matched {
+			// the end line completed the message, the next line starts a new one
+			h.shouldTruncate = false
+		}
+	} else if h.mode == config.MultiLineEnd && matched {
+		// the current line is the last line of the message
 		h.sendBuffer()
-		h.shouldTruncate = true
 	}
 
 	if h.buffer.Len() > 0 {
@@ -114,12 +161,24 @@ func (h *MultiLineHandler) process(message *Message) {
 	}
 }
 
+// truncate sends the buffer, adding the truncated flag at the end of the content,
+// and flags the next line as the remainder of a truncated message.
+func (h *MultiLineHandler) truncate() {
+	h.buffer.Write(truncatedFlag)
+	h.sendBuffer()
+	h.shouldTruncate = true
+	metrics.LogsMultiLineTruncated.Add(1)
+	metrics.TlmLogsMultiLineTruncated.Inc()
+	h.countForceFlush()
+}
+
 // sendBuffer forwards the content stored in the buffer
 // to the output function.
 func (h *MultiLineHandler) sendBuffer() {
 	defer func() {
 		h.buffer.Reset()
 		h.linesLen = 0
+		h.linesCount = 0
 		h.shouldTruncate = false
 	}()
 
@@ -128,6 +187,8 @@ func (h *MultiLineHandler) sendBuffer() {
 	copy(content, data)
 
 	if len(content) > 0 || h.linesLen > 0 {
-		h.outputFn(NewMessage(content, h.status, h.linesLen, h.timestamp))
+		output := NewMessage(content, h.status, h.linesLen, h.timestamp)
+		output.Hostname = h.hostname
+		h.outputFn(output)
 	}
 }
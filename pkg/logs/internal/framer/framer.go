@@ -37,6 +37,14 @@ const (
 	// headers are included in the log frame.  The size in those headers is not
 	// consulted.  The result does not include the trailing newlines.
 	DockerStream
+
+	// Syslog messages framed with octet counting, as defined in RFC 6587.  Each
+	// message is prefixed by its length in bytes and a space.  Messages which
+	// do not start with a length are newline-terminated, so that both framing
+	// methods can be mixed on the same stream.  Messages longer than the
+	// content limit are truncated, the rest of their length is output as
+	// empty frames.
+	SyslogOctetCounting
 )
 
 // Framer gets chunks of bytes (via Process(..)) and uses an
@@ -95,6 +103,8 @@ func NewFramer(
 		matcher = &oneByteNewLineMatcher{contentLenLimit}
 	case DockerStream:
 		matcher = &dockerStreamMatcher{contentLenLimit}
+	case SyslogOctetCounting:
+		matcher = &octetCountingMatcher{newline: oneByteNewLineMatcher{contentLenLimit}, contentLenLimit: contentLenLimit}
 	default:
 		panic(fmt.Sprintf("unknown framing %d", framing))
 	}
@@ -113,6 +113,49 @@ func TestLineBreaking(t *testing.T) {
 		t.Run("one-byte chunks", test(framing, chunk(utf16, 1), lines, lens))
 	})
 
+	t.Run("SyslogOctetCounting", func(t *testing.T) {
+		input := []byte("5 line17 line2\nAnot counted\n\n9 last line")
+		lines := []string{"line1", "line2\nA", "not counted", "", "last line"}
+		lens := []int{7, 9, 12, 1, 11}
+		framing := SyslogOctetCounting
+		t.Run("one chunk", test(framing, chunk(input, len(input)), lines, lens))
+		for size := 0; size < 20; size++ {
+			t.Run(fmt.Sprintf("%d-byte chunks", size), test(framing, chunk(input, size), lines, lens))
+		}
+	})
+
+	t.Run("SyslogOctetCountingTooLong", func(t *testing.T) {
+		// the declared length exceeds contentLenLimit, the message is truncated
+		// and the rest of it is discarded, whether it was fully buffered or not
+		long := strings.Repeat("a", contentLenLimit+50)
+		input := []byte(fmt.Sprintf("%d %s5 line2", len(long), long))
+		for _, size := range []int{len(input), 100000, 4096} {
+			t.Run(fmt.Sprintf("%d-byte chunks", size), func(t *testing.T) {
+				gotContent := []string{}
+				rawDataLen := 0
+				outputFn := func(content []byte, rawLen int) {
+					if len(content) > 0 {
+						gotContent = append(gotContent, string(content))
+					}
+					rawDataLen += rawLen
+				}
+				framer := NewFramer(outputFn, SyslogOctetCounting, contentLenLimit)
+				for start := 0; start < len(input); start += size {
+					end := start + size
+					if end > len(input) {
+						end = len(input)
+					}
+					framer.Process(input[start:end])
+				}
+				require.Len(t, gotContent, 2)
+				assert.Equal(t, long[:len(gotContent[0])], gotContent[0])
+				assert.LessOrEqual(t, len(gotContent[0]), contentLenLimit)
+				assert.Equal(t, "line2", gotContent[1])
+				assert.Equal(t, len(input), rawDataLen)
+			})
+		}
+	})
+
 	dockerChunk := func(stream byte, data []byte) []byte {
 		header := [8]byte{stream}
 		binary.BigEndian.PutUint32(header[4:8], uint32(len(data)))
@@ -0,0 +1,78 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package framer
+
+// maxMsgLenDigits is the maximum number of digits of an octet-counted message
+// length; longer prefixes are not considered as lengths.
+const maxMsgLenDigits = 10
+
+// octetCountingMatcher matches syslog messages framed following the octet-counting
+// method of RFC 6587, i.e. `MSG-LEN SP SYSLOG-MSG`.
+//
+// Frames which do not start with a digit are matched with the non-transparent-framing
+// method, i.e. they are terminated by a newline.
+//
+// Messages longer than contentLenLimit are truncated, and the rest of their declared
+// length is discarded with empty frames, so that the following messages are framed
+// from the right place.
+type octetCountingMatcher struct {
+	newline         oneByteNewLineMatcher
+	contentLenLimit int
+	// discard is the number of bytes of a truncated message which are yet to be discarded
+	discard int
+}
+
+// FindFrame implements EndLineMatcher#FindFrame.
+func (o *octetCountingMatcher) FindFrame(buf []byte, seen int) ([]byte, int) {
+	if o.discard > 0 {
+		discarded := o.discard
+		if discarded > len(buf) {
+			discarded = len(buf)
+		}
+		o.discard -= discarded
+		return []byte{}, discarded
+	}
+
+	if len(buf) == 0 || buf[0] < '1' || buf[0] > '9' {
+		return o.newline.FindFrame(buf, seen)
+	}
+
+	msgLen := 0
+	for i := 0; i < len(buf); i++ {
+		c := buf[i]
+		switch {
+		case c >= '0' && c <= '9' && i < maxMsgLenDigits:
+			msgLen = msgLen*10 + int(c-'0')
+		case c == ' ':
+			start := i + 1
+			if len(buf)-start >= msgLen {
+				return buf[start : start+o.truncated(msgLen)], start + msgLen
+			}
+			if len(buf) < o.contentLenLimit {
+				// wait for the rest of the message
+				return nil, 0
+			}
+			// the message does not fit in the buffer of the framer,
+			// send its beginning and discard the rest
+			end := start + o.truncated(len(buf)-start)
+			o.discard = msgLen - (end - start)
+			return buf[start:end], end
+		default:
+			// not a length prefix
+			return o.newline.FindFrame(buf, seen)
+		}
+	}
+	// wait for the end of the length prefix
+	return nil, 0
+}
+
+// truncated returns the length of the content of a message of msgLen bytes.
+func (o *octetCountingMatcher) truncated(msgLen int) int {
+	if msgLen > o.contentLenLimit {
+		return o.contentLenLimit
+	}
+	return msgLen
+}
@@ -6,6 +6,7 @@
 package file
 
 import (
+	"io"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -40,13 +41,16 @@ const DefaultSleepDuration = 1 * time.Second
 // Launcher checks all files provided by fileProvider and create new tailers
 // or update the old ones if needed
 type Launcher struct {
-	pipelineProvider    pipeline.Provider
-	addedSources        chan *config.LogSource
-	removedSources      chan *config.LogSource
-	activeSources       []*config.LogSource
-	tailingLimit        int
-	fileProvider        *fileProvider
-	tailers             map[string]*tailer.Tailer
+	pipelineProvider pipeline.Provider
+	addedSources     chan *config.LogSource
+	removedSources   chan *config.LogSource
+	activeSources    []*config.LogSource
+	tailingLimit     int
+	fileProvider     *fileProvider
+	tailers          map[string]*tailer.Tailer
+	// readArchives holds the identifiers of the compressed archives which have
+	// been read or skipped, they are not tailed again as they don't grow.
+	readArchives        map[string]bool
 	registry            auditor.Registry
 	tailerSleepDuration time.Duration
 	stop                chan struct{}
@@ -67,6 +71,7 @@ func NewLauncher(sources *config.LogSources, tailingLimit int, pipelineProvider
 		removedSources:         sources.GetRemovedForType(config.FileType),
 		fileProvider:           newFileProvider(tailingLimit),
 		tailers:                make(map[string]*tailer.Tailer),
+		readArchives:           make(map[string]bool),
 		registry:               registry,
 		tailerSleepDuration:    tailerSleepDuration,
 		stop:                   make(chan struct{}),
@@ -126,6 +131,16 @@ func (s *Launcher) scan() {
 	filesTailed := make(map[string]bool)
 	tailersLen := len(s.tailers)
 
+	// the archives being tailed, which may have been renamed by a rotation since
+	archivesTailed := make(map[string]bool)
+	for _, tailer := range s.tailers {
+		if tailer.IsFinished() && tailer.HasReadArchive() {
+			s.readArchives[tailer.Identifier()] = true
+		}
+		archivesTailed[tailer.Identifier()] = true
+	}
+	archivesSeen := make(map[string]bool)
+
 	for _, file := range files {
 		// We're using generated key here: in case this file has been found while
 		// scanning files for container, the key will use the format:
@@ -142,14 +157,37 @@ func (s *Launcher) scan() {
 			// skip this tailer as it must be stopped
 			continue
 		}
+		if file.IsCompressed() {
+			if isTailed {
+				// compressed archives don't grow and their tailers are never restarted after
+				// a rotation, the tailer keeps reading the archive it opened until its end
+				filesTailed[tailerKey] = true
+				continue
+			}
+			identifier, err := file.ArchiveIdentifier()
+			if err != nil {
+				log.Debugf("Could not identify the compressed file %s: %v", file.Path, err)
+				continue
+			}
+			archivesSeen[identifier] = true
+			if archivesTailed[identifier] || s.readArchives[identifier] {
+				continue
+			}
+		}
 		if !isTailed && tailersLen >= s.tailingLimit {
 			// can't create new tailer because tailingLimit is reached
 			continue
 		}
 
 		if !isTailed && tailersLen < s.tailingLimit {
-			// create a new tailer tailing from the beginning of the file if no offset has been recorded
-			succeeded := s.startNewTailer(file, config.Beginning)
+			// create a new tailer tailing from the beginning of the file if no offset has been recorded,
+			// compressed archives appearing while the agent is running are rotated files whose content
+			// has already been tailed, so they are skipped unless the agent missed the rotation.
+			var mode config.TailingMode = config.Beginning
+			if file.IsCompressed() {
+				mode = config.End
+			}
+			succeeded := s.startNewTailer(file, mode)
 			if !succeeded {
 				// the setup failed, let's try to tail this file in the next scan
 				continue
@@ -182,6 +220,13 @@ func (s *Launcher) scan() {
 			s.stopTailer(scanKey, tailer)
 		}
 	}
+
+	for identifier := range s.readArchives {
+		// forget the archives which are not there anymore
+		if !archivesSeen[identifier] {
+			delete(s.readArchives, identifier)
+		}
+	}
 }
 
 // addSource keeps track of the new source and launch new tailers for this source.
@@ -216,6 +261,11 @@ func (s *Launcher) launchTailers(source *config.LogSource) {
 		if _, isTailed := s.tailers[file.GetScanKey()]; isTailed {
 			continue
 		}
+		if file.IsCompressed() {
+			if identifier, err := file.ArchiveIdentifier(); err == nil && s.readArchives[identifier] {
+				continue
+			}
+		}
 
 		mode, _ := config.TailingModeFromString(source.Config.TailingMode)
 
@@ -255,17 +305,37 @@ func (s *Launcher) startNewTailer(file *tailer.File, m config.TailingMode) bool
 		return false
 	}
 
+	if file.IsCompressed() {
+		// the registry identifier of an archive depends on its content
+		if _, err := file.ArchiveIdentifier(); err != nil {
+			log.Warnf("Could not identify the compressed file %s: %v", file.Path, err)
+			return false
+		}
+	}
+
 	tailer := s.createTailer(file, s.pipelineProvider.NextPipelineChan())
 
 	var offset int64
 	var whence int
+	var err error
 	mode := s.handleTailingModeChange(tailer.Identifier(), m)
 
-	offset, whence, err := Position(s.registry, tailer.Identifier(), mode)
+	if file.IsCompressed() {
+		offset, whence, err = ArchivePosition(s.registry, tailer.Identifier(), file.RotatedFrom(), mode)
+	} else {
+		offset, whence, err = Position(s.registry, tailer.Identifier(), mode)
+	}
 	if err != nil {
 		log.Warnf("Could not recover offset for file with path %v: %v", file.Path, err)
 	}
 
+	if file.IsCompressed() && whence == io.SeekEnd {
+		// compressed archives don't grow, there is nothing to tail from their end
+		log.Debugf("Skipping the content of the compressed file %s", file.Path)
+		s.readArchives[tailer.Identifier()] = true
+		return true
+	}
+
 	log.Infof("Starting a new tailer for: %s (offset: %d, whence: %d) for tailer key %s", file.Path, offset, whence, file.GetScanKey())
 	err = tailer.Start(offset, whence)
 	if err != nil {
@@ -9,6 +9,8 @@
 package file
 
 import (
+	"bytes"
+	"compress/gzip"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -394,6 +396,42 @@ func TestLauncherScanWithTooManyFiles(t *testing.T) {
 	assert.Equal(t, 2, len(launcher.tailers))
 }
 
+func TestLauncherReadsArchivesOnce(t *testing.T) {
+	testDir := t.TempDir()
+
+	launcher := NewLauncher(config.NewLogSources(), 2, mock.NewMockProvider(), auditor.NewRegistry(), 20*time.Millisecond, false, 10*time.Second)
+	outputChan := launcher.pipelineProvider.NextPipelineChan()
+	source := config.NewLogSource("", &config.LogsConfig{Type: config.FileType, Path: fmt.Sprintf("%s/app.log*", testDir), TailingMode: "beginning"})
+	status.Clear()
+	status.InitStatus(config.CreateSources([]*config.LogSource{source}))
+	defer status.Clear()
+
+	archive := &bytes.Buffer{}
+	w := gzip.NewWriter(archive)
+	_, err := w.Write([]byte("hello\n"))
+	assert.Nil(t, err)
+	assert.Nil(t, w.Close())
+	path := fmt.Sprintf("%s/app.log.1.gz", testDir)
+	assert.Nil(t, ioutil.WriteFile(path, archive.Bytes(), 0644))
+
+	launcher.addSource(source)
+	assert.Equal(t, 1, len(launcher.tailers))
+	msg := <-outputChan
+	assert.Equal(t, "hello", string(msg.Content))
+
+	// the tailer of the archive is released once it is read
+	tailer := launcher.tailers[getScanKey(path, source)]
+	assert.Eventually(t, tailer.IsFinished, time.Second, 10*time.Millisecond)
+	launcher.scan()
+	assert.Equal(t, 0, len(launcher.tailers))
+
+	// the archive is not read again when it is renamed by the next rotation
+	assert.Nil(t, os.Rename(path, fmt.Sprintf("%s/app.log.2.gz", testDir)))
+	launcher.scan()
+	assert.Equal(t, 0, len(launcher.tailers))
+	assert.Equal(t, 0, len(outputChan))
+}
+
 func TestContainerIDInContainerLogFile(t *testing.T) {
 	assert := assert.New(t)
 	//func (s *Launcher) shouldIgnore(file *File) bool {
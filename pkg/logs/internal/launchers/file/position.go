@@ -6,7 +6,9 @@
 package file
 
 import (
+	"fmt"
 	"io"
+	"os"
 	"strconv"
 
 	"github.com/DataDog/datadog-agent/pkg/logs/auditor"
@@ -47,3 +49,26 @@ func Position(registry auditor.Registry, identifier string, mode config.TailingM
 	}
 	return offset, whence, err
 }
+
+// ArchivePosition returns the position from where logs should be collected in a
+// compressed archive, offsets being offsets in its decompressed content.
+//
+// When no offset was registered for the archive, the offset registered for the file
+// it was rotated from is used if it is beyond the end of the file currently at that
+// path, which means the file was rotated and compressed while the agent was not tailing
+// it, so that the agent catches up with the logs it missed.
+func ArchivePosition(registry auditor.Registry, identifier string, rotatedFrom string, mode config.TailingMode) (int64, int, error) {
+	if mode == config.ForceBeginning || mode == config.ForceEnd || rotatedFrom == "" || registry.GetOffset(identifier) != "" {
+		return Position(registry, identifier, mode)
+	}
+	// see tailer.Identifier
+	offset, err := strconv.ParseInt(registry.GetOffset(fmt.Sprintf("file:%s", rotatedFrom)), 10, 64)
+	if err != nil {
+		return Position(registry, identifier, mode)
+	}
+	if fi, err := os.Stat(rotatedFrom); err == nil && fi.Size() >= offset {
+		// the offset belongs to the file currently at that path
+		return Position(registry, identifier, mode)
+	}
+	return offset, io.SeekStart, nil
+}
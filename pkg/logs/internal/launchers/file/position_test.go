@@ -7,6 +7,9 @@ package file
 
 import (
 	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -68,3 +71,62 @@ func TestPosition(t *testing.T) {
 	assert.Equal(t, int64(0), offset)
 	assert.Equal(t, io.SeekEnd, whence)
 }
+
+// identifierRegistry is a registry holding an offset per identifier.
+type identifierRegistry map[string]string
+
+func (r identifierRegistry) GetOffset(identifier string) string {
+	return r[identifier]
+}
+
+func (r identifierRegistry) GetTailingMode(identifier string) string {
+	return ""
+}
+
+func TestArchivePosition(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log-position-test-")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	plainPath := filepath.Join(dir, "app.log")
+	archiveID := "file:" + filepath.Join(dir, "app.log.1.gz")
+	registry := identifierRegistry{"file:" + plainPath: "10"}
+
+	var offset int64
+	var whence int
+
+	// the plain file has been rotated and not recreated
+	offset, whence, err = ArchivePosition(registry, archiveID, plainPath, config.End)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(10), offset)
+	assert.Equal(t, io.SeekStart, whence)
+
+	// the plain file has been rotated and recreated
+	assert.Nil(t, ioutil.WriteFile(plainPath, []byte("foo\n"), 0644))
+	offset, whence, err = ArchivePosition(registry, archiveID, plainPath, config.End)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(10), offset)
+	assert.Equal(t, io.SeekStart, whence)
+
+	// the offset belongs to the plain file currently at that path
+	assert.Nil(t, ioutil.WriteFile(plainPath, []byte("foo\nbar\nbaz\n"), 0644))
+	offset, whence, err = ArchivePosition(registry, archiveID, plainPath, config.End)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), offset)
+	assert.Equal(t, io.SeekEnd, whence)
+
+	// the offset of the archive has precedence
+	registry[archiveID] = "3"
+	offset, whence, err = ArchivePosition(registry, archiveID, plainPath, config.End)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), offset)
+	assert.Equal(t, io.SeekStart, whence)
+
+	// forced tailing modes ignore the registry
+	delete(registry, archiveID)
+	assert.Nil(t, os.Remove(plainPath))
+	offset, whence, err = ArchivePosition(registry, archiveID, plainPath, config.ForceBeginning)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), offset)
+	assert.Equal(t, io.SeekStart, whence)
+}
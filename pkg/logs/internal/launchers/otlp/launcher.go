@@ -0,0 +1,86 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package otlp
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	tailer "github.com/DataDog/datadog-agent/pkg/logs/internal/tailers/otlp"
+	"github.com/DataDog/datadog-agent/pkg/logs/pipeline"
+	"github.com/DataDog/datadog-agent/pkg/logs/restart"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// Launcher starts an OTLP receiver for every otlp source,
+// and stops it when the source is removed.
+type Launcher struct {
+	pipelineProvider pipeline.Provider
+	addedSources     chan *config.LogSource
+	removedSources   chan *config.LogSource
+	tailers          map[*config.LogSource]*tailer.Tailer
+	stop             chan struct{}
+}
+
+// NewLauncher returns an initialized Launcher
+func NewLauncher(sources *config.LogSources, pipelineProvider pipeline.Provider) *Launcher {
+	return &Launcher{
+		pipelineProvider: pipelineProvider,
+		addedSources:     sources.GetAddedForType(config.OTLPType),
+		removedSources:   sources.GetRemovedForType(config.OTLPType),
+		tailers:          make(map[*config.LogSource]*tailer.Tailer),
+		stop:             make(chan struct{}),
+	}
+}
+
+// Start starts the launcher.
+func (l *Launcher) Start() {
+	go l.run()
+}
+
+// run starts a new tailer for every new source, and stops the tailers of the removed sources.
+func (l *Launcher) run() {
+	for {
+		select {
+		case source := <-l.addedSources:
+			l.startTailer(source)
+		case source := <-l.removedSources:
+			l.stopTailer(source)
+		case <-l.stop:
+			return
+		}
+	}
+}
+
+// startTailer starts a new tailer for source.
+func (l *Launcher) startTailer(source *config.LogSource) {
+	t := tailer.NewTailer(source, l.pipelineProvider.NextPipelineChan())
+	if err := t.Start(); err != nil {
+		log.Errorf("Can't start the OTLP logs receiver: %v", err)
+		source.Status.Error(err)
+		return
+	}
+	source.Status.Success()
+	l.tailers[source] = t
+}
+
+// stopTailer stops the tailer of source, if any.
+func (l *Launcher) stopTailer(source *config.LogSource) {
+	t, exists := l.tailers[source]
+	if !exists {
+		return
+	}
+	t.Stop()
+	delete(l.tailers, source)
+}
+
+// Stop stops all the tailers.
+func (l *Launcher) Stop() {
+	l.stop <- struct{}{}
+	stopper := restart.NewParallelStopper()
+	for _, t := range l.tailers {
+		stopper.Add(t)
+	}
+	stopper.Stop()
+}
@@ -0,0 +1,42 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package otlp
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/pipeline/mock"
+)
+
+func TestLauncherRemovesSources(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+
+	sources := config.NewLogSources()
+	launcher := NewLauncher(sources, mock.NewMockProvider())
+	launcher.Start()
+
+	source := config.NewLogSource("otlp", &config.LogsConfig{Type: config.OTLPType, Port: port})
+	sources.AddSource(source)
+	sources.RemoveSource(source)
+
+	// the launcher handles the removal before stopping
+	launcher.Stop()
+	assert.True(t, source.Status.IsSuccess())
+	assert.Empty(t, launcher.tailers)
+
+	// the port of the removed source is released
+	ln, err = net.Listen("tcp", ln.Addr().String())
+	require.NoError(t, err)
+	ln.Close()
+}
@@ -25,6 +25,36 @@ var (
 	TlmLogsProcessed = telemetry.NewCounter("logs", "processed",
 		nil, "Total number of processed logs")
 
+	// LogsRateLimited is the total number of logs dropped by the rate limit of their source.
+	LogsRateLimited = expvar.Int{}
+	// TlmLogsRateLimited is the total number of logs dropped by the rate limit of their source.
+	TlmLogsRateLimited = telemetry.NewCounter("logs", "rate_limited",
+		nil, "Total number of logs dropped by the rate limit of their source")
+	// LogsSampledOut is the total number of logs dropped by the sampling of their source.
+	LogsSampledOut = expvar.Int{}
+	// TlmLogsSampledOut is the total number of logs dropped by the sampling of their source.
+	TlmLogsSampledOut = telemetry.NewCounter("logs", "sampled_out",
+		nil, "Total number of logs dropped by the sampling of their source")
+
+	// LogsMultiLineTruncated is the total number of multi-line messages truncated because they were too long.
+	LogsMultiLineTruncated = expvar.Int{}
+	// TlmLogsMultiLineTruncated is the total number of multi-line messages truncated because they were too long.
+	TlmLogsMultiLineTruncated = telemetry.NewCounter("logs", "multi_line_truncated",
+		nil, "Total number of multi-line messages truncated because they were too long")
+	// LogsMultiLineForceFlushed is the total number of multi-line messages cut short, because they reached
+	// the maximum number of lines or bytes, or because their end line was not received in time.
+	LogsMultiLineForceFlushed = expvar.Int{}
+	// TlmLogsMultiLineForceFlushed is the total number of multi-line messages cut short, because they reached
+	// the maximum number of lines or bytes, or because their end line was not received in time.
+	TlmLogsMultiLineForceFlushed = telemetry.NewCounter("logs", "multi_line_force_flushed",
+		nil, "Total number of multi-line messages cut short before being complete")
+
+	// LogsMetricsExtracted is the total number of metric samples extracted from logs.
+	LogsMetricsExtracted = expvar.Int{}
+	// TlmLogsMetricsExtracted is the total number of metric samples extracted from logs.
+	TlmLogsMetricsExtracted = telemetry.NewCounter("logs", "metrics_extracted",
+		nil, "Total number of metric samples extracted from logs")
+
 	// LogsSent is the total number of sent logs.
 	LogsSent = expvar.Int{}
 	// TlmLogsSent is the total number of sent logs.
@@ -66,6 +96,11 @@ func init() {
 	LogsExpvars = expvar.NewMap("logs-agent")
 	LogsExpvars.Set("LogsDecoded", &LogsDecoded)
 	LogsExpvars.Set("LogsProcessed", &LogsProcessed)
+	LogsExpvars.Set("LogsRateLimited", &LogsRateLimited)
+	LogsExpvars.Set("LogsSampledOut", &LogsSampledOut)
+	LogsExpvars.Set("LogsMultiLineTruncated", &LogsMultiLineTruncated)
+	LogsExpvars.Set("LogsMultiLineForceFlushed", &LogsMultiLineForceFlushed)
+	LogsExpvars.Set("LogsMetricsExtracted", &LogsMetricsExtracted)
 	LogsExpvars.Set("LogsSent", &LogsSent)
 	LogsExpvars.Set("DestinationErrors", &DestinationErrors)
 	LogsExpvars.Set("DestinationLogsDropped", &DestinationLogsDropped)
@@ -24,6 +24,10 @@ type Message struct {
 	// which do not contain a timestamp (such as files) leave this set to "".
 	Timestamp string
 
+	// Hostname is the name of the host which emitted the message, if the
+	// message carries it (such as syslog messages).  It is "" otherwise.
+	Hostname string
+
 	// IsPartial indicates that this is a partial message.  If the parser
 	// supports partial lines, then this is true only for the message returned
 	// from the last parsed line in a multi-line message.
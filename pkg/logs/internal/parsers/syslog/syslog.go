@@ -0,0 +1,319 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package syslog implements a parser for syslog messages formatted following
+// RFC 5424 or RFC 3164.
+package syslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/internal/parsers"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+const nilValue = "-"
+
+// severityToStatus maps the syslog severities to the log statuses.
+var severityToStatus = []string{
+	message.StatusEmergency,
+	message.StatusAlert,
+	message.StatusCritical,
+	message.StatusError,
+	message.StatusWarning,
+	message.StatusNotice,
+	message.StatusInfo,
+	message.StatusDebug,
+}
+
+// rfc3164TimestampLayouts are the layouts of the timestamps of RFC 3164 messages.
+var rfc3164TimestampLayouts = []string{time.Stamp, "Jan 2 15:04:05"}
+
+// New returns a parser for syslog messages. The content of the parsed messages
+// is a JSON object holding the MSG part of the syslog message in its message
+// attribute, the header fields in its syslog attribute and an attribute per
+// structured data element.
+// Lines that are not syslog messages are returned unchanged.
+func New() parsers.Parser {
+	return &parser{now: time.Now}
+}
+
+type parser struct {
+	now func() time.Time
+}
+
+// header holds the fields of a syslog message.
+type header struct {
+	facility  int
+	severity  int
+	timestamp time.Time
+	hostname  string
+	appname   string
+	procid    string
+	msgid     string
+	data      map[string]map[string]string
+	msg       []byte
+}
+
+// Parse implements Parser#Parse
+func (p *parser) Parse(line []byte) (parsers.Message, error) {
+	pri, rest, err := parsePriority(line)
+	if err != nil {
+		return parsers.Message{Content: line}, err
+	}
+	h := &header{facility: pri / 8, severity: pri % 8}
+	if len(rest) > 1 && rest[0] == '1' && rest[1] == ' ' {
+		err = parseRFC5424(h, rest[2:])
+	} else {
+		err = parseRFC3164(h, rest, p.now())
+	}
+	if err != nil {
+		return parsers.Message{Content: line}, err
+	}
+
+	content, err := encode(h)
+	if err != nil {
+		return parsers.Message{Content: line}, err
+	}
+	msg := parsers.Message{
+		Content:  content,
+		Status:   severityToStatus[h.severity],
+		Hostname: h.hostname,
+	}
+	if !h.timestamp.IsZero() {
+		msg.Timestamp = h.timestamp.UTC().Format(config.DateFormat)
+	}
+	return msg, nil
+}
+
+// SupportsPartialLine implements Parser#SupportsPartialLine
+func (p *parser) SupportsPartialLine() bool {
+	return false
+}
+
+// parsePriority parses the <PRI> part of a message and returns the remaining bytes.
+func parsePriority(line []byte) (int, []byte, error) {
+	if len(line) < 3 || line[0] != '<' {
+		return 0, nil, fmt.Errorf("not a syslog message: missing priority")
+	}
+	end := bytes.IndexByte(line[:min(len(line), 5)], '>')
+	if end < 2 {
+		return 0, nil, fmt.Errorf("not a syslog message: invalid priority")
+	}
+	pri, err := strconv.Atoi(string(line[1:end]))
+	if err != nil || pri > 191 {
+		return 0, nil, fmt.Errorf("not a syslog message: invalid priority %q", line[1:end])
+	}
+	return pri, line[end+1:], nil
+}
+
+// parseRFC5424 parses TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA [MSG].
+func parseRFC5424(h *header, rest []byte) error {
+	fields := make([]string, 5)
+	for i := range fields {
+		var field []byte
+		field, rest = nextField(rest)
+		if field == nil {
+			return fmt.Errorf("invalid RFC 5424 message: missing header field")
+		}
+		fields[i] = string(field)
+	}
+	if fields[0] != nilValue {
+		ts, err := time.Parse(time.RFC3339Nano, fields[0])
+		if err != nil {
+			return fmt.Errorf("invalid RFC 5424 timestamp %q: %v", fields[0], err)
+		}
+		h.timestamp = ts
+	}
+	h.hostname = nilToEmpty(fields[1])
+	h.appname = nilToEmpty(fields[2])
+	h.procid = nilToEmpty(fields[3])
+	h.msgid = nilToEmpty(fields[4])
+
+	data, rest, err := parseStructuredData(rest)
+	if err != nil {
+		return err
+	}
+	h.data = data
+	if len(rest) > 0 && rest[0] == ' ' {
+		rest = rest[1:]
+	}
+	// strip the UTF-8 byte order mark
+	h.msg = bytes.TrimPrefix(rest, []byte("\xef\xbb\xbf"))
+	return nil
+}
+
+// parseRFC3164 parses TIMESTAMP HOSTNAME TAG[PID]: MSG, messages without a valid
+// header are kept whole in the MSG part.
+func parseRFC3164(h *header, rest []byte, now time.Time) error {
+	h.msg = rest
+	if len(rest) < len(time.Stamp) {
+		return nil
+	}
+	var ts time.Time
+	var err error
+	for _, layout := range rfc3164TimestampLayouts {
+		if ts, err = time.ParseInLocation(layout, string(rest[:len(time.Stamp)]), time.Local); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return nil
+	}
+	// the year is not part of RFC 3164 timestamps
+	ts = ts.AddDate(now.Year(), 0, 0)
+	if ts.After(now.AddDate(0, 1, 0)) {
+		ts = ts.AddDate(-1, 0, 0)
+	}
+	h.timestamp = ts
+	rest = bytes.TrimLeft(rest[len(time.Stamp):], " ")
+
+	hostname, remaining := nextField(rest)
+	if hostname == nil {
+		h.msg = rest
+		return nil
+	}
+	h.hostname = string(hostname)
+	rest = remaining
+
+	// the tag is made of alphanumeric characters and ends at the first other character
+	tagEnd := 0
+	for tagEnd < len(rest) && tagEnd < 48 && isTagChar(rest[tagEnd]) {
+		tagEnd++
+	}
+	if tagEnd > 0 && tagEnd < len(rest) && (rest[tagEnd] == ':' || rest[tagEnd] == '[') {
+		h.appname = string(rest[:tagEnd])
+		rest = rest[tagEnd:]
+		if rest[0] == '[' {
+			if end := bytes.IndexByte(rest, ']'); end > 0 {
+				h.procid = string(rest[1:end])
+				rest = rest[end+1:]
+			}
+		}
+		rest = bytes.TrimPrefix(rest, []byte(":"))
+		rest = bytes.TrimPrefix(rest, []byte(" "))
+	}
+	h.msg = rest
+	return nil
+}
+
+// parseStructuredData parses the STRUCTURED-DATA part of a RFC 5424 message.
+func parseStructuredData(rest []byte) (map[string]map[string]string, []byte, error) {
+	if len(rest) > 0 && rest[0] == '-' {
+		return nil, rest[1:], nil
+	}
+	data := make(map[string]map[string]string)
+	for len(rest) > 0 && rest[0] == '[' {
+		end := bytes.IndexAny(rest, " ]")
+		if end < 0 {
+			return nil, nil, fmt.Errorf("invalid RFC 5424 structured data")
+		}
+		params := make(map[string]string)
+		data[string(rest[1:end])] = params
+		rest = rest[end:]
+		for len(rest) > 0 && rest[0] == ' ' {
+			rest = rest[1:]
+			eq := bytes.IndexByte(rest, '=')
+			if eq < 1 || len(rest) < eq+2 || rest[eq+1] != '"' {
+				return nil, nil, fmt.Errorf("invalid RFC 5424 structured data parameter")
+			}
+			name := string(rest[:eq])
+			value, remaining, err := parseParamValue(rest[eq+2:])
+			if err != nil {
+				return nil, nil, err
+			}
+			params[name] = value
+			rest = remaining
+		}
+		if len(rest) == 0 || rest[0] != ']' {
+			return nil, nil, fmt.Errorf("invalid RFC 5424 structured data: unterminated element")
+		}
+		rest = rest[1:]
+	}
+	if len(data) == 0 {
+		return nil, nil, fmt.Errorf("invalid RFC 5424 structured data")
+	}
+	return data, rest, nil
+}
+
+// parseParamValue parses a quoted parameter value whose opening quote has been consumed,
+// the '"', '\' and ']' characters can be escaped with a '\'.
+func parseParamValue(rest []byte) (string, []byte, error) {
+	var value []byte
+	for i := 0; i < len(rest); i++ {
+		switch rest[i] {
+		case '\\':
+			if i+1 < len(rest) && (rest[i+1] == '"' || rest[i+1] == '\\' || rest[i+1] == ']') {
+				i++
+			}
+			value = append(value, rest[i])
+		case '"':
+			return string(value), rest[i+1:], nil
+		default:
+			value = append(value, rest[i])
+		}
+	}
+	return "", nil, fmt.Errorf("invalid RFC 5424 structured data: unterminated parameter value")
+}
+
+// encode returns the JSON content of a parsed message.
+func encode(h *header) ([]byte, error) {
+	content := make(map[string]interface{}, len(h.data)+2)
+	for id, params := range h.data {
+		content[id] = params
+	}
+	syslog := map[string]interface{}{
+		"facility": h.facility,
+		"severity": h.severity,
+	}
+	if h.hostname != "" {
+		syslog["hostname"] = h.hostname
+	}
+	if h.appname != "" {
+		syslog["appname"] = h.appname
+	}
+	if h.procid != "" {
+		syslog["procid"] = h.procid
+	}
+	if h.msgid != "" {
+		syslog["msgid"] = h.msgid
+	}
+	content["syslog"] = syslog
+	content["message"] = string(h.msg)
+	return json.Marshal(content)
+}
+
+// nextField returns the content preceding the next space and the bytes following it,
+// or nil if there is no such field.
+func nextField(rest []byte) ([]byte, []byte) {
+	end := bytes.IndexByte(rest, ' ')
+	if end <= 0 {
+		return nil, rest
+	}
+	return rest[:end], rest[end+1:]
+}
+
+func nilToEmpty(field string) string {
+	if field == nilValue {
+		return ""
+	}
+	return field
+}
+
+func isTagChar(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c == '-' || c == '_' || c == '.' || c == '/'
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,90 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package syslog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+func TestSyslogParserShouldParseRFC5424Messages(t *testing.T) {
+	line := `<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog 1234 ID47 [exampleSDID@32473 iut="3" eventSource="Application" eventID="1011"][examplePriority@32473 class="high"] An application event`
+	msg, err := New().Parse([]byte(line))
+	assert.Nil(t, err)
+	assert.False(t, msg.IsPartial)
+	assert.Equal(t, message.StatusNotice, msg.Status)
+	assert.Equal(t, "2003-10-11T22:14:15.003000000Z", msg.Timestamp)
+	assert.Equal(t, "mymachine.example.com", msg.Hostname)
+	assert.JSONEq(t, `{
+		"message": "An application event",
+		"syslog": {"facility": 20, "severity": 5, "hostname": "mymachine.example.com", "appname": "evntslog", "procid": "1234", "msgid": "ID47"},
+		"exampleSDID@32473": {"iut": "3", "eventSource": "Application", "eventID": "1011"},
+		"examplePriority@32473": {"class": "high"}
+	}`, string(msg.Content))
+}
+
+func TestSyslogParserShouldHandleRFC5424NilValues(t *testing.T) {
+	msg, err := New().Parse([]byte("<34>1 - - - - - -"))
+	assert.Nil(t, err)
+	assert.Equal(t, message.StatusCritical, msg.Status)
+	assert.Equal(t, "", msg.Timestamp)
+	assert.Equal(t, "", msg.Hostname)
+	assert.JSONEq(t, `{"message": "", "syslog": {"facility": 4, "severity": 2}}`, string(msg.Content))
+}
+
+func TestSyslogParserShouldUnescapeStructuredDataValues(t *testing.T) {
+	msg, err := New().Parse([]byte(`<14>1 2021-01-01T00:00:00+01:00 host app - - [meta q="a \"quoted\" \] value"] msg`))
+	assert.Nil(t, err)
+	assert.Equal(t, "2020-12-31T23:00:00.000000000Z", msg.Timestamp)
+	assert.JSONEq(t, `{
+		"message": "msg",
+		"syslog": {"facility": 1, "severity": 6, "hostname": "host", "appname": "app"},
+		"meta": {"q": "a \"quoted\" ] value"}
+	}`, string(msg.Content))
+}
+
+func TestSyslogParserShouldParseRFC3164Messages(t *testing.T) {
+	p := &parser{now: func() time.Time { return time.Date(2021, time.March, 1, 0, 0, 0, 0, time.Local) }}
+	msg, err := p.Parse([]byte("<34>Oct 11 22:14:15 mymachine su[123]: 'su root' failed for lonvick on /dev/pts/8"))
+	assert.Nil(t, err)
+	assert.Equal(t, message.StatusCritical, msg.Status)
+	// the timestamp can't be in the future, so it is from the previous year
+	assert.Equal(t, time.Date(2020, time.October, 11, 22, 14, 15, 0, time.Local).UTC().Format("2006-01-02T15:04:05.000000000Z"), msg.Timestamp)
+	assert.Equal(t, "mymachine", msg.Hostname)
+	assert.JSONEq(t, `{
+		"message": "'su root' failed for lonvick on /dev/pts/8",
+		"syslog": {"facility": 4, "severity": 2, "hostname": "mymachine", "appname": "su", "procid": "123"}
+	}`, string(msg.Content))
+}
+
+func TestSyslogParserShouldKeepRFC3164MessagesWithoutHeader(t *testing.T) {
+	msg, err := New().Parse([]byte("<13>hello world"))
+	assert.Nil(t, err)
+	assert.Equal(t, message.StatusNotice, msg.Status)
+	assert.Equal(t, "", msg.Timestamp)
+	assert.JSONEq(t, `{"message": "hello world", "syslog": {"facility": 1, "severity": 5}}`, string(msg.Content))
+}
+
+func TestSyslogParserShouldFailWithInvalidInput(t *testing.T) {
+	for _, line := range []string{
+		"",
+		"hello world",
+		"<>1 - - - - - -",
+		"<192>1 - - - - - -",
+		"<14>1 not-a-date host app - - - msg",
+		"<14>1 - host app - - [unterminated a=\"b\"",
+		"<14>1 - host app",
+	} {
+		msg, err := New().Parse([]byte(line))
+		assert.NotNil(t, err, line)
+		assert.Equal(t, line, string(msg.Content))
+		assert.Equal(t, "", msg.Status)
+	}
+}
@@ -0,0 +1,106 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package processor
+
+import (
+	"bytes"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+// dedupKey identifies the input a message was read from.
+type dedupKey struct {
+	source     *config.LogSource
+	identifier string
+}
+
+// pendingMessage is a message held by the deduper until it is flushed.
+type pendingMessage struct {
+	msg         *message.Message
+	redactedMsg []byte
+	received    time.Time
+}
+
+// deduper collapses the consecutive identical messages of an input received within
+// a window into a single message holding a repeat counter.
+// A single message is held per input and it is flushed before any different message
+// of the same input, so that the offsets of an input reach the auditor in order.
+type deduper struct {
+	window  time.Duration
+	mu      sync.Mutex
+	pending map[dedupKey]*pendingMessage
+}
+
+func newDeduper(window time.Duration) *deduper {
+	return &deduper{
+		window:  window,
+		pending: make(map[dedupKey]*pendingMessage),
+	}
+}
+
+// add holds msg if it can be collapsed with the following messages of its input,
+// and returns the messages that must be sent right away.
+func (d *deduper) add(msg *message.Message, redactedMsg []byte, now time.Time) []*pendingMessage {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	key := dedupKey{source: msg.Origin.LogSource, identifier: msg.Origin.Identifier}
+	ts := messageTime(msg, now)
+
+	var flushed []*pendingMessage
+	if current, found := d.pending[key]; found {
+		if now.Sub(current.received) < d.window && bytes.Equal(current.redactedMsg, redactedMsg) {
+			if current.msg.Repeat == nil {
+				current.msg.Repeat = &message.Repeat{Count: 1, First: messageTime(current.msg, current.received)}
+			}
+			current.msg.Repeat.Count++
+			current.msg.Repeat.Last = ts
+			// the collapsed message must commit the offset of the last message it holds
+			current.msg.Origin = msg.Origin
+			return nil
+		}
+		flushed = append(flushed, current)
+	}
+	d.pending[key] = &pendingMessage{msg: msg, redactedMsg: redactedMsg, received: now}
+	return flushed
+}
+
+// flushExpired returns the messages held for longer than the window.
+func (d *deduper) flushExpired(now time.Time) []*pendingMessage {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var flushed []*pendingMessage
+	for key, current := range d.pending {
+		if now.Sub(current.received) >= d.window {
+			flushed = append(flushed, current)
+			delete(d.pending, key)
+		}
+	}
+	return flushed
+}
+
+// flushAll returns all the messages held.
+func (d *deduper) flushAll() []*pendingMessage {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	flushed := make([]*pendingMessage, 0, len(d.pending))
+	for key, current := range d.pending {
+		flushed = append(flushed, current)
+		delete(d.pending, key)
+	}
+	return flushed
+}
+
+// messageTime returns the timestamp of msg, or now if it has none.
+func messageTime(msg *message.Message, now time.Time) time.Time {
+	if !msg.Timestamp.IsZero() {
+		return msg.Timestamp
+	}
+	return now
+}
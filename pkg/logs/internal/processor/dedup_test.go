@@ -0,0 +1,119 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package processor
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	coreConfig "github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/diagnostic"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+func newFileMessage(content string, source *config.LogSource, offset string) *message.Message {
+	msg := newMessage([]byte(content), source, "")
+	msg.Origin.Identifier = "file:/var/log/app.log"
+	msg.Origin.Offset = offset
+	return msg
+}
+
+func TestDeduperCollapsesIdenticalMessages(t *testing.T) {
+	d := newDeduper(10 * time.Second)
+	source := config.NewLogSource("", &config.LogsConfig{})
+	now := time.Now()
+
+	assert.Empty(t, d.add(newFileMessage("retry", source, "10"), []byte("retry"), now))
+	assert.Empty(t, d.add(newFileMessage("retry", source, "20"), []byte("retry"), now.Add(time.Second)))
+	assert.Empty(t, d.add(newFileMessage("retry", source, "30"), []byte("retry"), now.Add(2*time.Second)))
+
+	// a different message flushes the collapsed one first
+	flushed := d.add(newFileMessage("done", source, "40"), []byte("done"), now.Add(3*time.Second))
+	require.Len(t, flushed, 1)
+	msg := flushed[0].msg
+	require.NotNil(t, msg.Repeat)
+	assert.Equal(t, 3, msg.Repeat.Count)
+	assert.Equal(t, now, msg.Repeat.First)
+	assert.Equal(t, now.Add(2*time.Second), msg.Repeat.Last)
+	// the auditor commits the offset of the last collapsed message
+	assert.Equal(t, "30", msg.Origin.Offset)
+
+	flushed = d.flushAll()
+	require.Len(t, flushed, 1)
+	assert.Nil(t, flushed[0].msg.Repeat)
+	assert.Equal(t, "40", flushed[0].msg.Origin.Offset)
+}
+
+func TestDeduperWindow(t *testing.T) {
+	d := newDeduper(10 * time.Second)
+	source := config.NewLogSource("", &config.LogsConfig{})
+	now := time.Now()
+
+	assert.Empty(t, d.add(newFileMessage("retry", source, "10"), []byte("retry"), now))
+	assert.Empty(t, d.flushExpired(now.Add(5*time.Second)))
+	assert.Len(t, d.flushExpired(now.Add(10*time.Second)), 1)
+
+	// identical messages received after the window are not collapsed
+	assert.Empty(t, d.add(newFileMessage("retry", source, "20"), []byte("retry"), now))
+	assert.Len(t, d.add(newFileMessage("retry", source, "30"), []byte("retry"), now.Add(11*time.Second)), 1)
+}
+
+func TestDeduperSeparatesInputs(t *testing.T) {
+	d := newDeduper(10 * time.Second)
+	source := config.NewLogSource("", &config.LogsConfig{})
+	other := config.NewLogSource("", &config.LogsConfig{})
+	now := time.Now()
+
+	assert.Empty(t, d.add(newFileMessage("retry", source, "10"), []byte("retry"), now))
+	assert.Empty(t, d.add(newFileMessage("retry", other, "10"), []byte("retry"), now))
+	for _, pending := range d.flushAll() {
+		assert.Nil(t, pending.msg.Repeat)
+	}
+}
+
+func TestProcessorDedup(t *testing.T) {
+	outputChan := make(chan *message.Message, 10)
+	p := New(make(chan *message.Message), outputChan, nil, JSONEncoder, &diagnostic.NoopMessageReceiver{}, nil, nil)
+	p.deduper = newDeduper(time.Minute)
+	source := config.NewLogSource("", &config.LogsConfig{})
+
+	p.processMessage(newFileMessage("retry", source, "10"))
+	p.processMessage(newFileMessage("retry", source, "20"))
+	assert.Len(t, outputChan, 0)
+
+	p.flushPending(true)
+	require.Len(t, outputChan, 1)
+	msg := <-outputChan
+	assert.Equal(t, "20", msg.Origin.Offset)
+
+	var payload jsonPayload
+	require.NoError(t, json.Unmarshal(msg.Content, &payload))
+	assert.Equal(t, "retry", payload.Message)
+	assert.Equal(t, 2, payload.RepeatCount)
+	assert.NotZero(t, payload.FirstTimestamp)
+	assert.NotZero(t, payload.LastTimestamp)
+}
+
+func TestProcessorDedupRequiresJSONEncoder(t *testing.T) {
+	mockConfig := coreConfig.Mock()
+	mockConfig.Set("logs_config.dedup_window", 10)
+	defer mockConfig.Set("logs_config.dedup_window", 0)
+
+	p := New(make(chan *message.Message), make(chan *message.Message), nil, JSONEncoder, &diagnostic.NoopMessageReceiver{}, nil, nil)
+	require.NotNil(t, p.deduper)
+	assert.Equal(t, 10*time.Second, p.deduper.window)
+
+	// the proto and raw encoders cannot send the number of collapsed messages
+	for _, encoder := range []Encoder{ProtoEncoder, RawEncoder, JSONServerlessEncoder} {
+		p = New(make(chan *message.Message), make(chan *message.Message), nil, encoder, &diagnostic.NoopMessageReceiver{}, nil, nil)
+		assert.Nil(t, p.deduper)
+	}
+}
@@ -29,6 +29,10 @@ type jsonPayload struct {
 	Service   string `json:"service"`
 	Source    string `json:"ddsource"`
 	Tags      string `json:"ddtags"`
+	// set when identical messages have been collapsed into this one
+	RepeatCount    int   `json:"repeat_count,omitempty"`
+	FirstTimestamp int64 `json:"first_timestamp,omitempty"`
+	LastTimestamp  int64 `json:"last_timestamp,omitempty"`
 }
 
 // Encode encodes a message into a JSON byte array.
@@ -37,7 +41,7 @@ func (j *jsonEncoder) Encode(msg *message.Message, redactedMsg []byte) ([]byte,
 	if !msg.Timestamp.IsZero() {
 		ts = msg.Timestamp
 	}
-	return json.Marshal(jsonPayload{
+	payload := jsonPayload{
 		Message:   toValidUtf8(redactedMsg),
 		Status:    msg.GetStatus(),
 		Timestamp: ts.UnixNano() / nanoToMillis,
@@ -45,5 +49,11 @@ func (j *jsonEncoder) Encode(msg *message.Message, redactedMsg []byte) ([]byte,
 		Service:   msg.Origin.Service(),
 		Source:    msg.Origin.Source(),
 		Tags:      msg.Origin.TagsToString(),
-	})
+	}
+	if msg.Repeat != nil {
+		payload.RepeatCount = msg.Repeat.Count
+		payload.FirstTimestamp = msg.Repeat.First.UnixNano() / nanoToMillis
+		payload.LastTimestamp = msg.Repeat.Last.UnixNano() / nanoToMillis
+	}
+	return json.Marshal(payload)
 }
@@ -0,0 +1,74 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package processor
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/internal/metrics"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	coreMetrics "github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// extractMetric sends a metric sample to the demultiplexer if content matches the rule,
+// it returns true if a sample has been sent.
+func (p *Processor) extractMetric(msg *message.Message, content []byte, rule *config.ProcessingRule) bool {
+	if p.demux == nil {
+		return false
+	}
+	match := rule.Regex.FindSubmatchIndex(content)
+	if match == nil {
+		return false
+	}
+
+	value := 1.0
+	if rule.Metric.ValueGroup != "" {
+		index := rule.ValueGroupIndex
+		if match[2*index] < 0 {
+			return false
+		}
+		raw := string(content[match[2*index]:match[2*index+1]])
+		parsed, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			log.Debugf("Unable to parse value %q of metric %s for processing rule %s: %v", raw, rule.Metric.Name, rule.Name, err)
+			return false
+		}
+		value = parsed
+	}
+
+	tags := make([]string, 0, len(rule.Metric.Tags)+1)
+	for _, tag := range rule.Metric.Tags {
+		tags = append(tags, string(rule.Regex.Expand(nil, []byte(tag), content, match)))
+	}
+	if service := msg.Origin.Service(); service != "" {
+		tags = append(tags, "service:"+service)
+	}
+
+	mtype := coreMetrics.CountType
+	if rule.Metric.Type == config.MetricTypeDistribution {
+		mtype = coreMetrics.DistributionType
+	}
+
+	ts := time.Now()
+	if !msg.Timestamp.IsZero() {
+		ts = msg.Timestamp
+	}
+
+	p.demux.AddTimeSample(coreMetrics.MetricSample{
+		Name:       rule.Metric.Name,
+		Value:      value,
+		Mtype:      mtype,
+		Tags:       tags,
+		SampleRate: 1,
+		Timestamp:  float64(ts.UnixNano()) / float64(time.Second),
+	})
+	metrics.LogsMetricsExtracted.Add(1)
+	metrics.TlmLogsMetricsExtracted.Inc()
+	return true
+}
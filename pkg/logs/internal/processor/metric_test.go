@@ -0,0 +1,97 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package processor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+func TestExtractCountMetric(t *testing.T) {
+	demux := aggregator.InitTestAgentDemultiplexer()
+	defer demux.Stop(false)
+
+	rule := newCompiledProcessingRule(t, config.ProcessingRule{
+		Type:    config.ExtractMetric,
+		Pattern: `level=error code=(?P<code>\d+)`,
+		Metric: &config.ExtractedMetric{
+			Name: "app.errors",
+			Type: config.MetricTypeCount,
+			Tags: []string{"code:${code}", "team:logs"},
+		},
+	})
+	p := &Processor{processingRules: []*config.ProcessingRule{rule}, demux: demux}
+	source := config.NewLogSource("", &config.LogsConfig{Service: "web"})
+
+	shouldProcess, _ := p.applyRedactingRules(newMessage([]byte("level=info all good"), source, ""))
+	assert.True(t, shouldProcess)
+	assert.Len(t, demux.WaitForSamples(100*time.Millisecond), 0)
+
+	shouldProcess, redactedMessage := p.applyRedactingRules(newMessage([]byte("level=error code=503 upstream timeout"), source, ""))
+	assert.True(t, shouldProcess)
+	assert.Equal(t, []byte("level=error code=503 upstream timeout"), redactedMessage)
+
+	samples := demux.WaitForSamples(time.Second)
+	require.Len(t, samples, 1)
+	assert.Equal(t, "app.errors", samples[0].Name)
+	assert.Equal(t, metrics.CountType, samples[0].Mtype)
+	assert.Equal(t, 1.0, samples[0].Value)
+	assert.ElementsMatch(t, []string{"code:503", "team:logs", "service:web"}, samples[0].Tags)
+}
+
+func TestExtractDistributionMetricAndDrop(t *testing.T) {
+	demux := aggregator.InitTestAgentDemultiplexer()
+	defer demux.Stop(false)
+
+	rule := newCompiledProcessingRule(t, config.ProcessingRule{
+		Type:    config.ExtractMetric,
+		Pattern: `latency=(?P<latency>[0-9.]+)ms`,
+		Metric: &config.ExtractedMetric{
+			Name:       "app.latency",
+			Type:       config.MetricTypeDistribution,
+			ValueGroup: "latency",
+			DropLog:    true,
+		},
+	})
+	p := &Processor{processingRules: []*config.ProcessingRule{rule}, demux: demux}
+	source := config.NewLogSource("", &config.LogsConfig{})
+
+	shouldProcess, _ := p.applyRedactingRules(newMessage([]byte("GET /home latency=12.5ms"), source, ""))
+	assert.False(t, shouldProcess)
+
+	samples := demux.WaitForSamples(time.Second)
+	require.Len(t, samples, 1)
+	assert.Equal(t, "app.latency", samples[0].Name)
+	assert.Equal(t, metrics.DistributionType, samples[0].Mtype)
+	assert.Equal(t, 12.5, samples[0].Value)
+	assert.Empty(t, samples[0].Tags)
+
+	// logs are kept when no metric could be extracted
+	demux.Reset()
+	shouldProcess, _ = p.applyRedactingRules(newMessage([]byte("GET /home latency=1.2.3ms"), source, ""))
+	assert.True(t, shouldProcess)
+	assert.Len(t, demux.WaitForSamples(100*time.Millisecond), 0)
+}
+
+func TestExtractMetricWithoutDemultiplexer(t *testing.T) {
+	rule := newCompiledProcessingRule(t, config.ProcessingRule{
+		Type:    config.ExtractMetric,
+		Pattern: "error",
+		Metric:  &config.ExtractedMetric{Name: "app.errors", DropLog: true},
+	})
+	p := &Processor{processingRules: []*config.ProcessingRule{rule}}
+	source := config.NewLogSource("", &config.LogsConfig{})
+
+	shouldProcess, _ := p.applyRedactingRules(newMessage([]byte("error"), source, ""))
+	assert.True(t, shouldProcess)
+}
@@ -8,15 +8,20 @@ package processor
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
 	"github.com/DataDog/datadog-agent/pkg/logs/config"
 	"github.com/DataDog/datadog-agent/pkg/logs/diagnostic"
 	"github.com/DataDog/datadog-agent/pkg/logs/internal/metrics"
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
 )
 
+// dedupUnsupported makes sure the unsupported deduplication is only reported once for all the processors.
+var dedupUnsupported sync.Once
+
 // A Processor updates messages from an inputChan and pushes
 // in an outputChan.
 type Processor struct {
@@ -24,18 +29,38 @@ type Processor struct {
 	outputChan                chan *message.Message
 	processingRules           []*config.ProcessingRule
 	encoder                   Encoder
+	demux                     aggregator.Demultiplexer
+	deduper                   *deduper
+	samplers                  *Samplers
 	done                      chan struct{}
 	diagnosticMessageReceiver diagnostic.MessageReceiver
 	mu                        sync.Mutex
 }
 
 // New returns an initialized Processor.
-func New(inputChan, outputChan chan *message.Message, processingRules []*config.ProcessingRule, encoder Encoder, diagnosticMessageReceiver diagnostic.MessageReceiver) *Processor {
+// The metrics extracted from logs are sent to demux, they are not extracted if demux is nil.
+// Identical messages are collapsed when logs_config.dedup_window is set and the
+// encoder can send the number of collapsed messages, which only JSONEncoder does.
+// The rate limits and the sampling of the sources are applied with samplers, shared by all the processors.
+func New(inputChan, outputChan chan *message.Message, processingRules []*config.ProcessingRule, encoder Encoder, diagnosticMessageReceiver diagnostic.MessageReceiver, demux aggregator.Demultiplexer, samplers *Samplers) *Processor {
+	var deduper *deduper
+	if window := config.DedupWindow(); window > 0 {
+		if encoder == JSONEncoder {
+			deduper = newDeduper(window)
+		} else {
+			dedupUnsupported.Do(func() {
+				log.Warn("logs_config.dedup_window is ignored as identical logs can only be collapsed when they are sent with HTTPS")
+			})
+		}
+	}
 	return &Processor{
 		inputChan:                 inputChan,
 		outputChan:                outputChan,
 		processingRules:           processingRules,
 		encoder:                   encoder,
+		demux:                     demux,
+		deduper:                   deduper,
+		samplers:                  samplers,
 		done:                      make(chan struct{}),
 		diagnosticMessageReceiver: diagnosticMessageReceiver,
 	}
@@ -63,6 +88,7 @@ func (p *Processor) Flush(ctx context.Context) {
 			return
 		default:
 			if len(p.inputChan) == 0 {
+				p.flushPending(true)
 				return
 			}
 			msg := <-p.inputChan
@@ -76,8 +102,23 @@ func (p *Processor) run() {
 	defer func() {
 		p.done <- struct{}{}
 	}()
-	for msg := range p.inputChan {
-		p.processMessage(msg)
+	var flushTicker <-chan time.Time
+	if p.deduper != nil {
+		ticker := time.NewTicker(p.deduper.window / 2)
+		defer ticker.Stop()
+		flushTicker = ticker.C
+	}
+	for {
+		select {
+		case msg, ok := <-p.inputChan:
+			if !ok {
+				p.flushPending(true)
+				return
+			}
+			p.processMessage(msg)
+		case <-flushTicker:
+			p.flushPending(false)
+		}
 		p.mu.Lock() // block here if we're trying to flush synchronously
 		p.mu.Unlock()
 	}
@@ -86,28 +127,90 @@ func (p *Processor) run() {
 func (p *Processor) processMessage(msg *message.Message) {
 	metrics.LogsDecoded.Add(1)
 	metrics.TlmLogsDecoded.Inc()
+	if !p.sample(msg) {
+		return
+	}
 	if shouldProcess, redactedMsg := p.applyRedactingRules(msg); shouldProcess {
 		metrics.LogsProcessed.Add(1)
 		metrics.TlmLogsProcessed.Inc()
 
-		p.diagnosticMessageReceiver.HandleMessage(*msg, redactedMsg)
-
-		// Encode the message to its final format
-		content, err := p.encoder.Encode(msg, redactedMsg)
-		if err != nil {
-			log.Error("unable to encode msg ", err)
+		if p.deduper == nil {
+			p.sendMessage(msg, redactedMsg)
 			return
 		}
-		msg.Content = content
-		p.outputChan <- msg
+		for _, pending := range p.deduper.add(msg, redactedMsg, time.Now()) {
+			p.sendMessage(pending.msg, pending.redactedMsg)
+		}
 	}
 }
 
+// flushPending sends the messages held by the deduper for longer than its window,
+// or all of them if all is true.
+func (p *Processor) flushPending(all bool) {
+	if p.deduper == nil {
+		return
+	}
+	var flushed []*pendingMessage
+	if all {
+		flushed = p.deduper.flushAll()
+	} else {
+		flushed = p.deduper.flushExpired(time.Now())
+	}
+	for _, pending := range flushed {
+		p.sendMessage(pending.msg, pending.redactedMsg)
+	}
+}
+
+// sendMessage encodes msg to its final format and sends it to the output channel.
+func (p *Processor) sendMessage(msg *message.Message, redactedMsg []byte) {
+	p.diagnosticMessageReceiver.HandleMessage(*msg, redactedMsg)
+
+	// Encode the message to its final format
+	content, err := p.encoder.Encode(msg, redactedMsg)
+	if err != nil {
+		log.Error("unable to encode msg ", err)
+		return
+	}
+	msg.Content = content
+	p.outputChan <- msg
+}
+
+// sample returns true if msg should be processed according to the rate limit
+// and the sampling of its source, dropped messages are counted on the source.
+func (p *Processor) sample(msg *message.Message) bool {
+	if p.samplers == nil {
+		return true
+	}
+	source := msg.Origin.LogSource
+	now := time.Now()
+	sampler := p.samplers.get(source, now)
+	if sampler == nil {
+		return true
+	}
+	switch sampler.sample(now) {
+	case samplingRateLimited:
+		source.RateLimited.Add(1)
+		metrics.LogsRateLimited.Add(1)
+		metrics.TlmLogsRateLimited.Inc()
+		return false
+	case samplingSampledOut:
+		source.SampledOut.Add(1)
+		metrics.LogsSampledOut.Add(1)
+		metrics.TlmLogsSampledOut.Inc()
+		return false
+	}
+	return true
+}
+
 // applyRedactingRules returns given a message if we should process it or not,
-// and a copy of the message with some fields redacted, depending on config
+// and a copy of the message with some fields redacted, depending on config.
+// Structured rules are applied once all the other rules have been applied,
+// whatever their configured order, so that the content is decoded only once per message.
+// The other rules thus always see the content before any field is modified.
 func (p *Processor) applyRedactingRules(msg *message.Message) (bool, []byte) {
 	content := msg.Content
 	rules := append(p.processingRules, msg.Origin.LogSource.Config.ProcessingRules...)
+	hasStructuredRules := false
 	for _, rule := range rules {
 		switch rule.Type {
 		case config.ExcludeAtMatch:
@@ -120,7 +223,16 @@ func (p *Processor) applyRedactingRules(msg *message.Message) (bool, []byte) {
 			}
 		case config.MaskSequences:
 			content = rule.Regex.ReplaceAll(content, rule.Placeholder)
+		case config.ExtractMetric:
+			if p.extractMetric(msg, content, rule) && rule.Metric.DropLog {
+				return false, nil
+			}
+		default:
+			hasStructuredRules = hasStructuredRules || config.IsStructuredRule(rule.Type)
 		}
 	}
+	if hasStructuredRules {
+		content = applyStructuredRules(content, rules)
+	}
 	return true, content
 }
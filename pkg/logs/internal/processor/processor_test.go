@@ -12,6 +12,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/logs/config"
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestExclusion(t *testing.T) {
@@ -149,6 +150,15 @@ func newProcessingRule(ruleType, replacePlaceholder, pattern string) *config.Pro
 	}
 }
 
+// newCompiledProcessingRule validates and compiles rule as the logs agent does on startup.
+func newCompiledProcessingRule(t *testing.T, rule config.ProcessingRule) *config.ProcessingRule {
+	rule.Name = "test"
+	rules := []*config.ProcessingRule{&rule}
+	require.NoError(t, config.ValidateProcessingRules(rules))
+	require.NoError(t, config.CompileProcessingRules(rules))
+	return &rule
+}
+
 func newSource(ruleType, replacePlaceholder, pattern string) config.LogSource {
 	return config.LogSource{Config: &config.LogsConfig{ProcessingRules: []*config.ProcessingRule{newProcessingRule(ruleType, replacePlaceholder, pattern)}}}
 }
@@ -156,3 +166,22 @@ func newSource(ruleType, replacePlaceholder, pattern string) config.LogSource {
 func newMessage(content []byte, source *config.LogSource, status string) *message.Message {
 	return message.NewMessageWithSource(content, status, source, 0)
 }
+
+func TestSampling(t *testing.T) {
+	p := &Processor{samplers: NewSamplers()}
+	random := 0.0
+	p.samplers.random = func() float64 { return random }
+	source := config.NewLogSource("", &config.LogsConfig{
+		RateLimit: &config.RateLimit{MessagesPerSecond: 1},
+		Sampling:  &config.Sampling{Rate: 0.5},
+	})
+
+	assert.True(t, p.sample(newMessage([]byte("hello"), source, "")))
+	assert.False(t, p.sample(newMessage([]byte("hello"), source, "")))
+	random = 0.7
+	assert.False(t, p.sample(newMessage([]byte("world"), source, "")))
+	assert.Equal(t, int64(1), source.SampledOut.Value())
+	assert.Equal(t, int64(1), source.RateLimited.Value())
+
+	assert.True(t, p.sample(newMessage([]byte("hello"), config.NewLogSource("", &config.LogsConfig{}), "")))
+}
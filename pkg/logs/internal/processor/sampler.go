@@ -0,0 +1,229 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package processor
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// samplerTTL is the duration after which the sampler of a source that did not
+// receive any log is released
+const samplerTTL = time.Hour
+
+// samplingDecision is the decision taken by a sampler for a log.
+type samplingDecision int
+
+const (
+	// samplingKept means the log should be sent
+	samplingKept samplingDecision = iota
+	// samplingRateLimited means the log exceeded the rate limit
+	samplingRateLimited
+	// samplingSampledOut means the log was dropped by the sampling
+	samplingSampledOut
+)
+
+// tokenBucket is a thread safe token bucket refilled continuously.
+type tokenBucket struct {
+	mu       sync.Mutex
+	rate     float64
+	capacity float64
+	tokens   float64
+	last     time.Time
+}
+
+func newTokenBucket(r *config.RateLimit) *tokenBucket {
+	rate, capacity := bucketLimits(r)
+	return &tokenBucket{
+		rate:     rate,
+		capacity: capacity,
+		tokens:   capacity,
+	}
+}
+
+// bucketLimits returns the refill rate and the capacity of the token bucket of r.
+func bucketLimits(r *config.RateLimit) (float64, float64) {
+	capacity := float64(r.Burst)
+	if capacity == 0 {
+		capacity = r.MessagesPerSecond
+	}
+	if capacity < 1 {
+		capacity = 1
+	}
+	return r.MessagesPerSecond, capacity
+}
+
+// tighten lowers the limits of the bucket to the ones of r when they are stricter,
+// and returns true if the limits of r differ from the ones of the bucket.
+func (b *tokenBucket) tighten(r *config.RateLimit) bool {
+	rate, capacity := bucketLimits(r)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	conflict := rate != b.rate || capacity != b.capacity
+	if rate < b.rate {
+		b.rate = rate
+	}
+	if capacity < b.capacity {
+		b.capacity = capacity
+		if b.tokens > capacity {
+			b.tokens = capacity
+		}
+	}
+	return conflict
+}
+
+// allow consumes a token and returns true if one was available at now.
+func (b *tokenBucket) allow(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.last.IsZero() {
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.capacity {
+			b.tokens = b.capacity
+		}
+	}
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// sampler decides whether the logs of a source should be sent,
+// according to the rate limit and the sampling of its configuration.
+type sampler struct {
+	bucket  *tokenBucket
+	service string
+	rate    float64
+	random  func() float64
+
+	mu       sync.Mutex
+	lastUsed time.Time
+}
+
+// sample returns samplingKept if the log should be sent, or the reason why it should be dropped otherwise.
+// Sampling is applied before rate limiting so that dropped logs don't consume tokens.
+func (s *sampler) sample(now time.Time) samplingDecision {
+	s.mu.Lock()
+	s.lastUsed = now
+	s.mu.Unlock()
+	if s.rate > 0 && s.rate < 1 && s.random() >= s.rate {
+		return samplingSampledOut
+	}
+	if s.bucket != nil && !s.bucket.allow(now) {
+		return samplingRateLimited
+	}
+	return samplingKept
+}
+
+func (s *sampler) idleSince(now time.Time) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return now.Sub(s.lastUsed)
+}
+
+// Samplers holds the samplers of the log sources and the token buckets shared
+// by the sources with a service scoped rate limit. It is shared by the processors
+// of all the pipelines, as the logs of a source can be spread over them.
+type Samplers struct {
+	mu        sync.RWMutex
+	sources   map[*config.LogSource]*sampler
+	services  map[string]*tokenBucket
+	lastSweep time.Time
+	random    func() float64
+}
+
+// NewSamplers returns an empty Samplers.
+func NewSamplers() *Samplers {
+	return &Samplers{
+		sources:  make(map[*config.LogSource]*sampler),
+		services: make(map[string]*tokenBucket),
+		random:   rand.Float64,
+	}
+}
+
+// get returns the sampler of source, or nil if no rate limit nor sampling is configured.
+func (s *Samplers) get(source *config.LogSource, now time.Time) *sampler {
+	cfg := source.Config
+	if cfg == nil || (cfg.RateLimit == nil && cfg.Sampling == nil) {
+		return nil
+	}
+
+	s.mu.RLock()
+	sp, exists := s.sources[source]
+	sweepDue := now.Sub(s.lastSweep) >= samplerTTL
+	s.mu.RUnlock()
+	if exists && !sweepDue {
+		return sp
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastSweep) >= samplerTTL {
+		s.sweep(now)
+	}
+
+	if sp, exists := s.sources[source]; exists {
+		return sp
+	}
+
+	sp = &sampler{random: s.random, lastUsed: now}
+	if r := cfg.RateLimit; r != nil {
+		if r.Scope == config.RateLimitScopeService && cfg.Service != "" {
+			sp.service = cfg.Service
+			sp.bucket = s.serviceBucket(cfg.Service, r)
+		} else {
+			sp.bucket = newTokenBucket(r)
+		}
+	}
+	if cfg.Sampling != nil {
+		sp.rate = cfg.Sampling.Rate
+	}
+	s.sources[source] = sp
+	return sp
+}
+
+// serviceBucket returns the token bucket shared by all the sources of service.
+// When the sources of a service are configured with different limits, the strictest ones apply.
+func (s *Samplers) serviceBucket(service string, r *config.RateLimit) *tokenBucket {
+	bucket, exists := s.services[service]
+	if !exists {
+		bucket = newTokenBucket(r)
+		s.services[service] = bucket
+		return bucket
+	}
+	if bucket.tighten(r) {
+		log.Warnf("Conflicting rate limits configured for the logs of service %s, applying the strictest ones", service)
+	}
+	return bucket
+}
+
+// sweep releases the samplers of the sources which did not receive any log
+// for samplerTTL, and the service buckets they do not use anymore.
+func (s *Samplers) sweep(now time.Time) {
+	s.lastSweep = now
+	services := make(map[string]struct{})
+	for source, sp := range s.sources {
+		if sp.idleSince(now) >= samplerTTL {
+			delete(s.sources, source)
+			continue
+		}
+		if sp.service != "" {
+			services[sp.service] = struct{}{}
+		}
+	}
+	for service := range s.services {
+		if _, used := services[service]; !used {
+			delete(s.services, service)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package processor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+)
+
+func TestSamplersNotConfigured(t *testing.T) {
+	samplers := NewSamplers()
+	now := time.Now()
+
+	assert.Nil(t, samplers.get(config.NewLogSource("", nil), now))
+	assert.Nil(t, samplers.get(config.NewLogSource("", &config.LogsConfig{}), now))
+}
+
+func TestSamplerRateLimit(t *testing.T) {
+	samplers := NewSamplers()
+	source := config.NewLogSource("", &config.LogsConfig{RateLimit: &config.RateLimit{MessagesPerSecond: 2, Burst: 3}})
+	now := time.Now()
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, samplingKept, samplers.get(source, now).sample(now))
+	}
+	assert.Equal(t, samplingRateLimited, samplers.get(source, now).sample(now))
+
+	// 2 tokens are refilled every second
+	now = now.Add(time.Second)
+	assert.Equal(t, samplingKept, samplers.get(source, now).sample(now))
+	assert.Equal(t, samplingKept, samplers.get(source, now).sample(now))
+	assert.Equal(t, samplingRateLimited, samplers.get(source, now).sample(now))
+}
+
+func TestSamplerServiceRateLimit(t *testing.T) {
+	samplers := NewSamplers()
+	rateLimit := &config.RateLimit{MessagesPerSecond: 1, Scope: config.RateLimitScopeService}
+	first := config.NewLogSource("", &config.LogsConfig{Service: "foo", RateLimit: rateLimit})
+	second := config.NewLogSource("", &config.LogsConfig{Service: "foo", RateLimit: rateLimit})
+	other := config.NewLogSource("", &config.LogsConfig{Service: "bar", RateLimit: rateLimit})
+	now := time.Now()
+
+	assert.Equal(t, samplingKept, samplers.get(first, now).sample(now))
+	assert.Equal(t, samplingRateLimited, samplers.get(second, now).sample(now))
+	assert.Equal(t, samplingKept, samplers.get(other, now).sample(now))
+
+	// the samplers are not shared between Samplers
+	assert.Equal(t, samplingKept, NewSamplers().get(second, now).sample(now))
+}
+
+func TestSamplerServiceRateLimitConflict(t *testing.T) {
+	samplers := NewSamplers()
+	loose := config.NewLogSource("", &config.LogsConfig{
+		Service:   "foo",
+		RateLimit: &config.RateLimit{MessagesPerSecond: 10, Scope: config.RateLimitScopeService},
+	})
+	strict := config.NewLogSource("", &config.LogsConfig{
+		Service:   "foo",
+		RateLimit: &config.RateLimit{MessagesPerSecond: 1, Scope: config.RateLimitScopeService},
+	})
+	now := time.Now()
+
+	samplers.get(loose, now)
+	samplers.get(strict, now)
+
+	// the strictest limits apply to all the sources of the service
+	assert.Equal(t, samplingKept, samplers.get(loose, now).sample(now))
+	assert.Equal(t, samplingRateLimited, samplers.get(loose, now).sample(now))
+	assert.Equal(t, samplingRateLimited, samplers.get(strict, now).sample(now))
+}
+
+func TestSamplerRate(t *testing.T) {
+	samplers := NewSamplers()
+	random := 0.0
+	samplers.random = func() float64 { return random }
+	source := config.NewLogSource("", &config.LogsConfig{Sampling: &config.Sampling{Rate: 0.5}})
+	now := time.Now()
+
+	assert.Equal(t, samplingKept, samplers.get(source, now).sample(now))
+	random = 0.7
+	assert.Equal(t, samplingSampledOut, samplers.get(source, now).sample(now))
+}
+
+func TestSamplersSweep(t *testing.T) {
+	samplers := NewSamplers()
+	rateLimit := &config.RateLimit{MessagesPerSecond: 1, Scope: config.RateLimitScopeService}
+	idle := config.NewLogSource("", &config.LogsConfig{Service: "foo", RateLimit: rateLimit})
+	active := config.NewLogSource("", &config.LogsConfig{RateLimit: rateLimit})
+	now := time.Now()
+
+	samplers.get(idle, now).sample(now)
+	samplers.get(active, now).sample(now)
+
+	now = now.Add(samplerTTL / 2)
+	samplers.get(active, now).sample(now)
+
+	now = now.Add(samplerTTL / 2)
+	samplers.get(active, now)
+
+	assert.Len(t, samplers.sources, 1)
+	assert.Contains(t, samplers.sources, active)
+	assert.Empty(t, samplers.services)
+}
@@ -0,0 +1,198 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package processor
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+)
+
+// applyStructuredRules applies the field-level processing rules on content.
+// The content is decoded once, all the rules are applied on the decoded object
+// and it is encoded back only if at least one field was modified.
+// Rules that are not structured are ignored, content that is not a JSON object
+// is returned as is.
+func applyStructuredRules(content []byte, rules []*config.ProcessingRule) []byte {
+	fields, ok := decodeJSONObject(content)
+	if !ok {
+		return content
+	}
+	updated := false
+	for _, rule := range rules {
+		if applyStructuredRule(fields, rule) {
+			updated = true
+		}
+	}
+	if !updated {
+		return content
+	}
+	encoded, err := encodeJSONObject(fields)
+	if err != nil {
+		return content
+	}
+	return encoded
+}
+
+// applyStructuredRule applies a single rule on fields and returns true if fields were modified.
+func applyStructuredRule(fields map[string]interface{}, rule *config.ProcessingRule) bool {
+	switch rule.Type {
+	case config.RemoveField:
+		_, found := removeField(fields, rule.FieldPath)
+		return found
+	case config.MaskField:
+		value, found := getField(fields, rule.FieldPath)
+		if !found {
+			return false
+		}
+		if rule.Regex == nil {
+			return setField(fields, rule.FieldPath, string(rule.Placeholder))
+		}
+		str := stringifyField(value)
+		masked := rule.Regex.ReplaceAllString(str, string(rule.Placeholder))
+		if masked == str {
+			// keep the original value, and its type, when nothing was masked
+			return false
+		}
+		return setField(fields, rule.FieldPath, masked)
+	case config.HashField:
+		value, found := getField(fields, rule.FieldPath)
+		if !found {
+			return false
+		}
+		sum := sha256.Sum256([]byte(stringifyField(value)))
+		return setField(fields, rule.FieldPath, hex.EncodeToString(sum[:]))
+	case config.RenameField:
+		value, found := getField(fields, rule.FieldPath)
+		if !found {
+			return false
+		}
+		// the source is only removed once the value has been moved to the target,
+		// the log is left unchanged if the target can't be set
+		if !setField(fields, rule.TargetPath, value) {
+			return false
+		}
+		removeField(fields, rule.FieldPath)
+		return true
+	case config.AddField:
+		return setField(fields, rule.FieldPath, rule.Value)
+	}
+	return false
+}
+
+// decodeJSONObject decodes content into a map if it holds a JSON object.
+func decodeJSONObject(content []byte) (map[string]interface{}, bool) {
+	trimmed := bytes.TrimSpace(content)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return nil, false
+	}
+	decoder := json.NewDecoder(bytes.NewReader(trimmed))
+	// keep numbers as they were written in the original log
+	decoder.UseNumber()
+	var fields map[string]interface{}
+	if err := decoder.Decode(&fields); err != nil || decoder.More() {
+		return nil, false
+	}
+	return fields, true
+}
+
+// encodeJSONObject encodes fields without escaping HTML characters to stay as close
+// as possible to the original content.
+func encodeJSONObject(fields map[string]interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(fields); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// stringifyField returns the string representation of a JSON value.
+func stringifyField(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(encoded)
+}
+
+// getField returns the value located at path.
+func getField(fields map[string]interface{}, path []string) (interface{}, bool) {
+	parent, found := lookupParent(fields, path, false)
+	if !found {
+		return nil, false
+	}
+	value, found := parent[path[len(path)-1]]
+	return value, found
+}
+
+// removeField deletes the value located at path and returns it.
+// The intermediate objects are left in place, even when empty.
+func removeField(fields map[string]interface{}, path []string) (interface{}, bool) {
+	parent, found := lookupParent(fields, path, false)
+	if !found {
+		return nil, false
+	}
+	key := path[len(path)-1]
+	value, found := parent[key]
+	if found {
+		delete(parent, key)
+	}
+	return value, found
+}
+
+// setField sets value at path, creating the intermediate objects when needed.
+// It returns false if an intermediate field exists and is not an object.
+func setField(fields map[string]interface{}, path []string, value interface{}) bool {
+	parent, found := lookupParent(fields, path, true)
+	if !found {
+		return false
+	}
+	parent[path[len(path)-1]] = value
+	return true
+}
+
+// lookupParent returns the object holding the last key of path.
+func lookupParent(fields map[string]interface{}, path []string, create bool) (map[string]interface{}, bool) {
+	if len(path) == 0 {
+		return nil, false
+	}
+	current := fields
+	for _, key := range path[:len(path)-1] {
+		next, found := current[key]
+		if !found {
+			if !create {
+				return nil, false
+			}
+			child := make(map[string]interface{})
+			current[key] = child
+			current = child
+			continue
+		}
+		child, ok := next.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		current = child
+	}
+	return current, true
+}
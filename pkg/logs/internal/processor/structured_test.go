@@ -0,0 +1,135 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package processor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+)
+
+func TestStructuredRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     config.ProcessingRule
+		content  string
+		expected string
+	}{
+		{
+			name:     "remove nested field",
+			rule:     config.ProcessingRule{Type: config.RemoveField, Path: "http.request.headers.authorization"},
+			content:  `{"http":{"request":{"headers":{"authorization":"Bearer abc","host":"foo"}}},"msg":"hello"}`,
+			expected: `{"http":{"request":{"headers":{"host":"foo"}}},"msg":"hello"}`,
+		},
+		{
+			name:     "remove last nested field keeps its parent",
+			rule:     config.ProcessingRule{Type: config.RemoveField, Path: "http.request.headers.authorization"},
+			content:  `{"http":{"request":{"headers":{"authorization":"Bearer abc"}}}}`,
+			expected: `{"http":{"request":{"headers":{}}}}`,
+		},
+		{
+			name:     "remove missing field",
+			rule:     config.ProcessingRule{Type: config.RemoveField, Path: "http.status"},
+			content:  `{"msg":"hello", "count":12}`,
+			expected: `{"msg":"hello", "count":12}`,
+		},
+		{
+			name:     "mask whole field",
+			rule:     config.ProcessingRule{Type: config.MaskField, Path: "user.password", ReplacePlaceholder: "[masked]"},
+			content:  `{"user":{"name":"bob","password":"secret"}}`,
+			expected: `{"user":{"name":"bob","password":"[masked]"}}`,
+		},
+		{
+			name:     "mask field with pattern",
+			rule:     config.ProcessingRule{Type: config.MaskField, Path: "card", Pattern: `\d{12}(\d{4})`, ReplacePlaceholder: "XXXX${1}"},
+			content:  `{"card":"4323124312341234"}`,
+			expected: `{"card":"XXXX1234"}`,
+		},
+		{
+			name:     "mask non string field without match",
+			rule:     config.ProcessingRule{Type: config.MaskField, Path: "count", Pattern: `\d{12}`, ReplacePlaceholder: "[masked]"},
+			content:  `{"count":12, "msg":"hello"}`,
+			expected: `{"count":12, "msg":"hello"}`,
+		},
+		{
+			name:     "hash field",
+			rule:     config.ProcessingRule{Type: config.HashField, Path: "email"},
+			content:  `{"email":"bob@datadoghq.com"}`,
+			expected: `{"email":"45f757695f14b552bdb758915f1e64bd3231332ff9c4c686b28849d0179f3435"}`,
+		},
+		{
+			name:     "rename field",
+			rule:     config.ProcessingRule{Type: config.RenameField, Path: "usr.id", Target: "user.id"},
+			content:  `{"usr":{"id":1234567890123456789}}`,
+			expected: `{"user":{"id":1234567890123456789},"usr":{}}`,
+		},
+		{
+			name:     "rename field to invalid target",
+			rule:     config.ProcessingRule{Type: config.RenameField, Path: "usr.id", Target: "user.id"},
+			content:  `{"usr":{"id":12}, "user":"bob"}`,
+			expected: `{"usr":{"id":12}, "user":"bob"}`,
+		},
+		{
+			name:     "add field",
+			rule:     config.ProcessingRule{Type: config.AddField, Path: "env.name", Value: "prod"},
+			content:  `{"msg":"<hello> & bye"}`,
+			expected: `{"env":{"name":"prod"},"msg":"<hello> & bye"}`,
+		},
+		{
+			name:     "non json content",
+			rule:     config.ProcessingRule{Type: config.AddField, Path: "env", Value: "prod"},
+			content:  `hello world`,
+			expected: `hello world`,
+		},
+		{
+			name:     "json array",
+			rule:     config.ProcessingRule{Type: config.AddField, Path: "env", Value: "prod"},
+			content:  `[{"msg":"hello"}]`,
+			expected: `[{"msg":"hello"}]`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rule := newCompiledProcessingRule(t, test.rule)
+			assert.Equal(t, test.expected, string(applyStructuredRules([]byte(test.content), []*config.ProcessingRule{rule})))
+		})
+	}
+}
+
+func TestStructuredRulesWithRegexRules(t *testing.T) {
+	p := &Processor{processingRules: []*config.ProcessingRule{
+		newProcessingRule(config.ExcludeAtMatch, "", "healthcheck"),
+		newCompiledProcessingRule(t, config.ProcessingRule{Type: config.RemoveField, Path: "token"}),
+	}}
+	source := config.NewLogSource("", &config.LogsConfig{})
+
+	shouldProcess, _ := p.applyRedactingRules(newMessage([]byte(`{"msg":"healthcheck","token":"abc"}`), source, ""))
+	assert.False(t, shouldProcess)
+
+	shouldProcess, redactedMessage := p.applyRedactingRules(newMessage([]byte(`{"msg":"hello","token":"abc"}`), source, ""))
+	assert.True(t, shouldProcess)
+	assert.Equal(t, []byte(`{"msg":"hello"}`), redactedMessage)
+}
+
+func TestStructuredRulesAppliedAfterRegexRules(t *testing.T) {
+	// structured rules are applied last whatever their configured order,
+	// the regex rules configured after them see the original content
+	p := &Processor{processingRules: []*config.ProcessingRule{
+		newCompiledProcessingRule(t, config.ProcessingRule{Type: config.MaskField, Path: "token", ReplacePlaceholder: "[masked]"}),
+		newProcessingRule(config.ExcludeAtMatch, "", "secret"),
+	}}
+	source := config.NewLogSource("", &config.LogsConfig{})
+
+	shouldProcess, _ := p.applyRedactingRules(newMessage([]byte(`{"msg":"hello","token":"secret"}`), source, ""))
+	assert.False(t, shouldProcess)
+
+	shouldProcess, redactedMessage := p.applyRedactingRules(newMessage([]byte(`{"msg":"hello","token":"abc"}`), source, ""))
+	assert.True(t, shouldProcess)
+	assert.Equal(t, []byte(`{"msg":"hello","token":"[masked]"}`), redactedMessage)
+}
@@ -0,0 +1,93 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package file
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sync/atomic"
+
+	"github.com/DataDog/zstd"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/internal/decoder"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// setupCompressed sets up the tailer of a compressed file.
+//
+// The offsets of a compressed file are offsets in its decompressed content, so the
+// tailer decompresses and discards the content preceding the offset it starts from.
+// Compressed files don't grow, so starting from their end means skipping all their
+// content.
+func (t *Tailer) setupCompressed(offset int64, whence int) error {
+	log.Info("Opening compressed file", t.file.Path, "for tailer key", t.file.GetScanKey())
+	f, err := openFile(t.fullpath)
+	if err != nil {
+		return err
+	}
+	archive, err := newDecompressor(f, t.file.compression())
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	var skipped int64
+	switch whence {
+	case io.SeekStart:
+		skipped, err = io.CopyN(ioutil.Discard, archive, offset)
+	case io.SeekEnd:
+		skipped, err = io.Copy(ioutil.Discard, archive)
+	}
+	if err != nil && err != io.EOF {
+		archive.Close()
+		f.Close()
+		return fmt.Errorf("can't decompress %s: %v", t.file.Path, err)
+	}
+
+	t.osFile = f
+	t.archive = archive
+	t.lastReadOffset = skipped
+	t.decodedOffset = skipped
+	return nil
+}
+
+// readCompressed reads the decompressed content of a compressed file.
+// Compressed files don't grow, so it returns io.EOF once all their content
+// is read, which stops the tailer and releases the file.
+func (t *Tailer) readCompressed() (int, error) {
+	inBuf := make([]byte, 4096)
+	n, err := t.archive.Read(inBuf)
+	if err != nil && err != io.EOF {
+		// the archive is corrupted or still being written, stop the tailer,
+		// a new one will resume from the last committed offset
+		t.file.Source.Status.Error(err)
+		return 0, log.Warn("Unexpected error occurred while decompressing file: ", err)
+	}
+	if n == 0 {
+		if err == io.EOF {
+			atomic.StoreInt32(&t.didReadArchive, 1)
+			return 0, io.EOF
+		}
+		return 0, nil
+	}
+	t.decoder.InputChan <- decoder.NewInput(inBuf[:n])
+	t.incrementLastReadOffset(n)
+	return n, nil
+}
+
+// newDecompressor returns a reader decompressing r.
+func newDecompressor(r io.Reader, compression string) (io.ReadCloser, error) {
+	switch compression {
+	case gzipCompression:
+		return gzip.NewReader(r)
+	case zstdCompression:
+		return zstd.NewReader(r), nil
+	default:
+		return nil, fmt.Errorf("unsupported compression %q", compression)
+	}
+}
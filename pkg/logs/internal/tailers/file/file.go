@@ -6,11 +6,39 @@
 package file
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
 
 	"github.com/DataDog/datadog-agent/pkg/logs/config"
 )
 
+// Compression formats of the compressed files, which are decompressed while tailed.
+const (
+	noCompression   = ""
+	gzipCompression = "gzip"
+	zstdCompression = "zstd"
+)
+
+// compressionExtensions maps the extensions of the compressed files to their compression format.
+var compressionExtensions = map[string]string{
+	".gz":  gzipCompression,
+	".zst": zstdCompression,
+}
+
+// fingerprintSize is the number of bytes at the beginning of a compressed archive
+// used to identify it.
+const fingerprintSize = 4096
+
+// rxRotationSuffix matches the suffixes added to the names of the rotated files,
+// e.g. `.1` or `-20211016`.
+var rxRotationSuffix = regexp.MustCompile(`[.-][0-9]+$`)
+
 // File represents a file to tail
 type File struct {
 	// Path contains the path to the file which should be tailed.
@@ -22,6 +50,10 @@ type File struct {
 
 	// Source is the LogSource that led to this File.
 	Source *config.LogSource
+
+	// archiveIdentifier is the identifier of a compressed archive in the registry,
+	// see ArchiveIdentifier.
+	archiveIdentifier string
 }
 
 // NewFile returns a new File
@@ -33,6 +65,72 @@ func NewFile(path string, source *config.LogSource, isWildcardPath bool) *File {
 	}
 }
 
+// IsCompressed returns true if the file is a compressed archive, such as the
+// ones created by logrotate.
+func (t *File) IsCompressed() bool {
+	return t.compression() != noCompression
+}
+
+// compression returns the compression format of the file, based on its extension.
+func (t *File) compression() string {
+	return compressionExtensions[strings.ToLower(filepath.Ext(t.Path))]
+}
+
+// RotatedFrom returns the path of the file a compressed archive was rotated from,
+// e.g. `/var/log/app.log` for `/var/log/app.log.1.gz`, or "" if the file is not
+// a compressed archive or if it is not the latest archive rotated from that file,
+// as the offsets of the file only relate to the last archive rotated from it.
+func (t *File) RotatedFrom() string {
+	if !t.IsCompressed() {
+		return ""
+	}
+	rotatedFrom := t.rotatedFrom()
+	fi, err := os.Stat(t.Path)
+	if err != nil {
+		return ""
+	}
+	archives, err := filepath.Glob(rotatedFrom + "*")
+	if err != nil {
+		return ""
+	}
+	for _, archive := range archives {
+		other := NewFile(archive, t.Source, t.IsWildcardPath)
+		if archive == t.Path || !other.IsCompressed() || other.rotatedFrom() != rotatedFrom {
+			continue
+		}
+		if ofi, err := os.Stat(archive); err == nil && ofi.ModTime().After(fi.ModTime()) {
+			return ""
+		}
+	}
+	return rotatedFrom
+}
+
+// rotatedFrom returns the path of the file a compressed archive was rotated from.
+func (t *File) rotatedFrom() string {
+	return rxRotationSuffix.ReplaceAllString(strings.TrimSuffix(t.Path, filepath.Ext(t.Path)), "")
+}
+
+// ArchiveIdentifier returns the identifier of a compressed archive in the registry.
+// It is based on the beginning of its content rather than on its path, so that it
+// does not change when the archive is renamed by the following rotations,
+// e.g. from `app.log.1.gz` to `app.log.2.gz`.
+func (t *File) ArchiveIdentifier() (string, error) {
+	if t.archiveIdentifier != "" {
+		return t.archiveIdentifier, nil
+	}
+	f, err := openFile(t.Path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	hash := sha256.New()
+	if _, err := io.CopyN(hash, f, fingerprintSize); err != nil && err != io.EOF {
+		return "", err
+	}
+	t.archiveIdentifier = fmt.Sprintf("archive:%s", hex.EncodeToString(hash.Sum(nil)))
+	return t.archiveIdentifier, nil
+}
+
 // GetScanKey returns a key used by the scanner to index the scanned file.  The
 // string uniquely identifies this File, even if sources for multiple
 // containers use the same Path.
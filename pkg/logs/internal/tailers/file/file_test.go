@@ -0,0 +1,80 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package file
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotatedFrom(t *testing.T) {
+	dir := t.TempDir()
+	for path, rotatedFrom := range map[string]string{
+		"app.log":               "",
+		"app.log.1":             "",
+		"app.log.1.gz":          "app.log",
+		"other.log.12.zst":      "other.log",
+		"dated.log-20211016.gz": "dated.log",
+		"upper.log.GZ":          "upper.log",
+		"plain.log.gz":          "plain.log",
+		"app-2.log.1.gz":        "app-2.log",
+		"app.tar.bz2":           "",
+	} {
+		path = filepath.Join(dir, path)
+		require.NoError(t, ioutil.WriteFile(path, nil, 0644))
+		if rotatedFrom != "" {
+			rotatedFrom = filepath.Join(dir, rotatedFrom)
+		}
+		file := NewFile(path, nil, false)
+		assert.Equal(t, rotatedFrom != "", file.IsCompressed(), path)
+		assert.Equal(t, rotatedFrom, file.RotatedFrom(), path)
+	}
+}
+
+func TestRotatedFromLatestArchive(t *testing.T) {
+	dir := t.TempDir()
+	latest := filepath.Join(dir, "app.log.1.gz")
+	older := filepath.Join(dir, "app.log.2.gz")
+	require.NoError(t, ioutil.WriteFile(latest, nil, 0644))
+	require.NoError(t, ioutil.WriteFile(older, nil, 0644))
+	now := time.Now()
+	require.NoError(t, os.Chtimes(older, now.Add(-time.Hour), now.Add(-time.Hour)))
+
+	// the offsets of the file only relate to the last archive rotated from it
+	assert.Equal(t, filepath.Join(dir, "app.log"), NewFile(latest, nil, false).RotatedFrom())
+	assert.Equal(t, "", NewFile(older, nil, false).RotatedFrom())
+}
+
+func TestArchiveIdentifier(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "app.log.1.gz")
+	require.NoError(t, ioutil.WriteFile(first, []byte("foo"), 0644))
+
+	identifier, err := NewFile(first, nil, false).ArchiveIdentifier()
+	require.NoError(t, err)
+
+	// the identifier does not change when the archive is renamed
+	second := filepath.Join(dir, "app.log.2.gz")
+	require.NoError(t, os.Rename(first, second))
+	renamed, err := NewFile(second, nil, false).ArchiveIdentifier()
+	require.NoError(t, err)
+	assert.Equal(t, identifier, renamed)
+
+	// it changes with the content of the archive
+	require.NoError(t, ioutil.WriteFile(first, []byte("bar"), 0644))
+	other, err := NewFile(first, nil, false).ArchiveIdentifier()
+	require.NoError(t, err)
+	assert.NotEqual(t, identifier, other)
+
+	_, err = NewFile(filepath.Join(dir, "missing.gz"), nil, false).ArchiveIdentifier()
+	assert.Error(t, err)
+}
@@ -36,7 +36,8 @@ func (t *Tailer) DidRotate() (bool, error) {
 	}
 
 	recreated := !os.SameFile(fi1, fi2)
-	truncated := fi1.Size() < t.getLastReadOffset()
+	// the offsets of compressed files are offsets in their decompressed content
+	truncated := !t.file.IsCompressed() && fi1.Size() < t.getLastReadOffset()
 
 	return recreated || truncated, nil
 }
@@ -35,7 +35,8 @@ func (t *Tailer) DidRotate() (bool, error) {
 	sz := st.Size()
 	offset := t.getLastReadOffset()
 
-	if sz < offset {
+	// the offsets of compressed files are offsets in their decompressed content
+	if sz < offset && !t.file.IsCompressed() {
 		return true, nil
 	}
 
@@ -62,6 +62,9 @@ type Tailer struct {
 	// is platform-specific.
 	osFile *os.File
 
+	// archive decompresses the content of osFile when the file is compressed.
+	archive io.ReadCloser
+
 	// tags are the tags to be attached to each log message, excluding tags provided
 	// by the tag provider.
 	tags []string
@@ -91,6 +94,10 @@ type Tailer struct {
 	// didFileRotate is an atomic value, used to determine hasFileRotated.
 	didFileRotate int32
 
+	// didReadArchive is an atomic value, set to 1 when the tailer has read
+	// all the content of a compressed archive.
+	didReadArchive int32
+
 	// stop is monitored by the readForever component, and causes it to stop reading
 	// and close the channel to the decoder.
 	stop chan struct{}
@@ -160,6 +167,9 @@ func (t *Tailer) Identifier() string {
 	//
 	// This is the identifier used in the registry, so changing it will invalidate existing
 	// registry entries on upgrade.
+	if t.file.archiveIdentifier != "" {
+		return t.file.archiveIdentifier
+	}
 	return fmt.Sprintf("file:%s", t.file.Path)
 }
 
@@ -211,6 +221,9 @@ func (t *Tailer) StopAfterFileRotation() {
 // until it is closed or the tailer is stopped.
 func (t *Tailer) readForever() {
 	defer func() {
+		if t.archive != nil {
+			t.archive.Close()
+		}
 		t.osFile.Close()
 		t.decoder.Stop()
 		log.Info("Closed", t.file.Path, "for tailer key", t.file.GetScanKey(), "read", t.bytesRead, "bytes and", t.decoder.GetLineCount(), "lines")
@@ -289,6 +302,12 @@ func (t *Tailer) forwardMessages() {
 	}
 }
 
+// HasReadArchive returns true if the tailer has read all the content of
+// a compressed archive, after which it is finished.
+func (t *Tailer) HasReadArchive() bool {
+	return atomic.LoadInt32(&t.didReadArchive) != 0
+}
+
 // incrementLastReadOffset increments the lastReadOffset field, atomically.
 func (t *Tailer) incrementLastReadOffset(n int) {
 	atomic.AddInt64(&t.lastReadOffset, int64(n))
@@ -27,6 +27,10 @@ func (t *Tailer) setup(offset int64, whence int) error {
 	// adds metadata to enable users to filter logs by filename
 	t.tags = t.buildTailerTags()
 
+	if t.file.IsCompressed() {
+		return t.setupCompressed(offset, whence)
+	}
+
 	log.Info("Opening", t.file.Path, "for tailer key", t.file.GetScanKey())
 	f, err := openFile(fullpath)
 	if err != nil {
@@ -44,6 +48,9 @@ func (t *Tailer) setup(offset int64, whence int) error {
 // read lets the tailer tail the content of a file
 // until it is closed or the tailer is stopped.
 func (t *Tailer) read() (int, error) {
+	if t.archive != nil {
+		return t.readCompressed()
+	}
 	// keep reading data from file
 	inBuf := make([]byte, 4096)
 	n, err := t.osFile.Read(inBuf)
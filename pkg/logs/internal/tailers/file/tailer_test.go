@@ -9,6 +9,8 @@
 package file
 
 import (
+	"bytes"
+	"compress/gzip"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -18,6 +20,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/DataDog/zstd"
 	"github.com/stretchr/testify/suite"
 
 	"path/filepath"
@@ -327,6 +330,56 @@ func (suite *TailerTestSuite) TestMutliLineAutoDetect() {
 	suite.Equal(suite.tailer.GetDetectedPattern(), expectedRegex)
 }
 
+func (suite *TailerTestSuite) TestTailCompressedFiles() {
+	lines := []string{"hello world\n", "hello again\n", "good bye\n"}
+	content := []byte(lines[0] + lines[1] + lines[2])
+
+	gzipped := &bytes.Buffer{}
+	w := gzip.NewWriter(gzipped)
+	_, err := w.Write(content)
+	suite.Nil(err)
+	suite.Nil(w.Close())
+	zstded, err := zstd.Compress(nil, content)
+	suite.Nil(err)
+
+	for name, compressed := range map[string][]byte{"tailer.log.1.gz": gzipped.Bytes(), "tailer.log.1.zst": zstded} {
+		path := filepath.Join(suite.testDir, name)
+		suite.Nil(ioutil.WriteFile(path, compressed, 0644))
+		file := NewFile(path, suite.source, true)
+		suite.True(file.IsCompressed())
+		identifier, err := file.ArchiveIdentifier()
+		suite.Nil(err)
+
+		// offsets are offsets in the decompressed content
+		tailer := NewTailer(suite.outputChan, file, 10*time.Millisecond, decoder.NewDecoderFromSource(suite.source))
+		suite.Nil(tailer.Start(int64(len(lines[0])), io.SeekStart))
+
+		msg := <-suite.outputChan
+		suite.Equal("hello again", string(msg.Content))
+		suite.Equal(len(lines[0])+len(lines[1]), toInt(msg.Origin.Offset))
+		suite.Equal(identifier, msg.Origin.Identifier)
+
+		msg = <-suite.outputChan
+		suite.Equal("good bye", string(msg.Content))
+		suite.Equal(len(content), toInt(msg.Origin.Offset))
+
+		// the tailer releases the archive once it is read
+		suite.Eventually(tailer.IsFinished, time.Second, 10*time.Millisecond)
+		suite.True(tailer.HasReadArchive())
+		tailer.Stop()
+
+		// tailing a compressed file from its end skips all its content
+		tailer = NewTailer(suite.outputChan, file, 10*time.Millisecond, decoder.NewDecoderFromSource(suite.source))
+		suite.Nil(tailer.Start(0, io.SeekEnd))
+		suite.Equal(int64(len(content)), tailer.getLastReadOffset())
+		tailer.Stop()
+		suite.Equal(0, len(suite.outputChan))
+	}
+
+	// to satisfy the suite level tailer
+	suite.tailer.StartFromBeginning()
+}
+
 func toInt(str string) int {
 	if value, err := strconv.ParseInt(str, 10, 64); err == nil {
 		return int(value)
@@ -29,6 +29,10 @@ func (t *Tailer) setup(offset int64, whence int) error {
 	// adds metadata to enable users to filter logs by filename
 	t.tags = t.buildTailerTags()
 
+	if t.file.IsCompressed() {
+		return t.setupCompressed(offset, whence)
+	}
+
 	log.Info("Opening ", t.fullpath)
 	f, err := openFile(t.fullpath)
 	if err != nil {
@@ -88,6 +92,10 @@ func (t *Tailer) readAvailable() (int, error) {
 // windows version open and close the file between each call to 'read'. This is
 // needed in order not to block the file and prevent the user from renaming it.
 func (t *Tailer) read() (int, error) {
+	// compressed files don't grow, they are read through the handle opened in setup
+	if t.archive != nil {
+		return t.readCompressed()
+	}
 	n, err := t.readAvailable()
 	if err == io.EOF || os.IsNotExist(err) {
 		return n, nil
@@ -0,0 +1,160 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package otlp
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/collector/model/pdata"
+	semconv "go.opentelemetry.io/collector/model/semconv/v1.6.1"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+// Attributes added to the content of the converted log records
+const (
+	messageKey        = "message"
+	traceIDKey        = "dd.trace_id"
+	spanIDKey         = "dd.span_id"
+	otelTraceIDKey    = "otel.trace_id"
+	otelSpanIDKey     = "otel.span_id"
+	severityTextKey   = "otel.severity_text"
+	severityNumberKey = "otel.severity_number"
+)
+
+// resourceTagNames maps the resource attributes added as tags to the names of the tags,
+// the other resource attributes are added to the content of the converted log records.
+var resourceTagNames = map[string]string{
+	semconv.AttributeServiceName:           "service",
+	semconv.AttributeDeploymentEnvironment: "env",
+	semconv.AttributeServiceVersion:        "version",
+	semconv.AttributeHostName:              "host",
+	semconv.AttributeContainerID:           "container_id",
+	semconv.AttributeK8SPodName:            "pod_name",
+	semconv.AttributeK8SNamespaceName:      "kube_namespace",
+	semconv.AttributeK8SContainerName:      "kube_container_name",
+}
+
+// convertLogs converts all the log records of logs to messages.
+func convertLogs(source *config.LogSource, logs pdata.Logs, now time.Time) []*message.Message {
+	var messages []*message.Message
+	rls := logs.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		rl := rls.At(i)
+		resource := rl.Resource().Attributes()
+		service := ""
+		if v, ok := resource.Get(semconv.AttributeServiceName); ok {
+			service = v.AsString()
+		}
+		tags, attributes := resourceTags(resource)
+
+		ills := rl.InstrumentationLibraryLogs()
+		for j := 0; j < ills.Len(); j++ {
+			records := ills.At(j).LogRecords()
+			for k := 0; k < records.Len(); k++ {
+				origin := message.NewOrigin(source)
+				origin.SetService(service)
+				origin.SetTags(tags)
+				messages = append(messages, convertLogRecord(origin, attributes, records.At(k), now))
+			}
+		}
+	}
+	return messages
+}
+
+// convertLogRecord converts a log record into a message whose content is a JSON object
+// holding the body of the record in its message attribute, and the resource attributes
+// which are not tags. The attributes of the record override the resource ones.
+func convertLogRecord(origin *message.Origin, attributes map[string]interface{}, record pdata.LogRecord, now time.Time) *message.Message {
+	content := make(map[string]interface{}, len(attributes)+record.Attributes().Len()+5)
+	for k, v := range attributes {
+		content[k] = v
+	}
+	record.Attributes().Range(func(k string, v pdata.AttributeValue) bool {
+		content[k] = attributeValue(v)
+		return true
+	})
+	content[messageKey] = record.Body().AsString()
+	if traceID := record.TraceID(); !traceID.IsEmpty() {
+		bytes := traceID.Bytes()
+		content[traceIDKey] = strconv.FormatUint(binary.BigEndian.Uint64(bytes[8:]), 10)
+		content[otelTraceIDKey] = traceID.HexString()
+	}
+	if spanID := record.SpanID(); !spanID.IsEmpty() {
+		bytes := spanID.Bytes()
+		content[spanIDKey] = strconv.FormatUint(binary.BigEndian.Uint64(bytes[:]), 10)
+		content[otelSpanIDKey] = spanID.HexString()
+	}
+	if text := record.SeverityText(); text != "" {
+		content[severityTextKey] = text
+	}
+	if number := record.SeverityNumber(); number != pdata.SeverityNumberUNDEFINED {
+		content[severityNumberKey] = int32(number)
+	}
+
+	encoded, err := json.Marshal(content)
+	if err != nil {
+		encoded = []byte(record.Body().AsString())
+	}
+
+	msg := message.NewMessage(encoded, origin, severityToStatus(record.SeverityNumber()), now.UnixNano())
+	if record.Timestamp() != 0 {
+		msg.Timestamp = record.Timestamp().AsTime().UTC()
+	}
+	return msg
+}
+
+// resourceTags converts the resource attributes of resourceTagNames to tags,
+// and returns the other ones as attributes.
+func resourceTags(resource pdata.AttributeMap) ([]string, map[string]interface{}) {
+	var tags []string
+	attributes := make(map[string]interface{})
+	resource.Range(func(k string, v pdata.AttributeValue) bool {
+		if name, ok := resourceTagNames[k]; ok {
+			tags = append(tags, name+":"+v.AsString())
+		} else {
+			attributes[k] = attributeValue(v)
+		}
+		return true
+	})
+	return tags, attributes
+}
+
+// attributeValue returns the value of an attribute with its JSON type.
+func attributeValue(v pdata.AttributeValue) interface{} {
+	switch v.Type() {
+	case pdata.AttributeValueTypeString:
+		return v.StringVal()
+	case pdata.AttributeValueTypeInt:
+		return v.IntVal()
+	case pdata.AttributeValueTypeDouble:
+		return v.DoubleVal()
+	case pdata.AttributeValueTypeBool:
+		return v.BoolVal()
+	}
+	return v.AsString()
+}
+
+// severityToStatus maps the OpenTelemetry severity numbers to the log statuses.
+func severityToStatus(severity pdata.SeverityNumber) string {
+	switch {
+	case severity == pdata.SeverityNumberUNDEFINED:
+		return message.StatusInfo
+	case severity < pdata.SeverityNumberINFO:
+		return message.StatusDebug
+	case severity < pdata.SeverityNumberWARN:
+		return message.StatusInfo
+	case severity < pdata.SeverityNumberERROR:
+		return message.StatusWarning
+	case severity < pdata.SeverityNumberFATAL:
+		return message.StatusError
+	}
+	return message.StatusCritical
+}
@@ -0,0 +1,70 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package otlp
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/model/pdata"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+func TestConvertLogs(t *testing.T) {
+	logs := pdata.NewLogs()
+	rl := logs.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().InsertString("service.name", "checkout")
+	rl.Resource().Attributes().InsertString("deployment.environment", "prod")
+	rl.Resource().Attributes().InsertString("telemetry.sdk.language", "go")
+	rl.Resource().Attributes().InsertString("http.method", "GET")
+	record := rl.InstrumentationLibraryLogs().AppendEmpty().LogRecords().AppendEmpty()
+	record.Body().SetStringVal("payment failed")
+	record.SetSeverityNumber(pdata.SeverityNumberERROR)
+	record.SetSeverityText("ERROR")
+	ts := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	record.SetTimestamp(pdata.NewTimestampFromTime(ts))
+	record.SetTraceID(pdata.NewTraceID([16]byte{0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 2}))
+	record.SetSpanID(pdata.NewSpanID([8]byte{0, 0, 0, 0, 0, 0, 0, 3}))
+	record.Attributes().InsertString("http.method", "POST")
+	record.Attributes().InsertInt("http.status_code", 500)
+
+	source := config.NewLogSource("otlp", &config.LogsConfig{Type: config.OTLPType, Port: 4318})
+	messages := convertLogs(source, logs, time.Now())
+	require.Len(t, messages, 1)
+	msg := messages[0]
+
+	assert.Equal(t, message.StatusError, msg.GetStatus())
+	assert.Equal(t, ts, msg.Timestamp)
+	assert.Equal(t, "checkout", msg.Origin.Service())
+	assert.ElementsMatch(t, []string{"service:checkout", "env:prod"}, msg.Origin.Tags())
+
+	var content map[string]interface{}
+	require.NoError(t, json.Unmarshal(msg.Content, &content))
+	assert.Equal(t, "payment failed", content["message"])
+	assert.Equal(t, "2", content["dd.trace_id"])
+	assert.Equal(t, "3", content["dd.span_id"])
+	assert.Equal(t, "00000000000000010000000000000002", content["otel.trace_id"])
+	assert.Equal(t, "ERROR", content["otel.severity_text"])
+	assert.Equal(t, "go", content["telemetry.sdk.language"])
+	// the attributes of the record override the resource ones
+	assert.Equal(t, "POST", content["http.method"])
+	assert.Equal(t, float64(500), content["http.status_code"])
+}
+
+func TestSeverityToStatus(t *testing.T) {
+	assert.Equal(t, message.StatusInfo, severityToStatus(pdata.SeverityNumberUNDEFINED))
+	assert.Equal(t, message.StatusDebug, severityToStatus(pdata.SeverityNumberTRACE))
+	assert.Equal(t, message.StatusDebug, severityToStatus(pdata.SeverityNumberDEBUG4))
+	assert.Equal(t, message.StatusInfo, severityToStatus(pdata.SeverityNumberINFO2))
+	assert.Equal(t, message.StatusWarning, severityToStatus(pdata.SeverityNumberWARN))
+	assert.Equal(t, message.StatusError, severityToStatus(pdata.SeverityNumberERROR3))
+	assert.Equal(t, message.StatusCritical, severityToStatus(pdata.SeverityNumberFATAL))
+}
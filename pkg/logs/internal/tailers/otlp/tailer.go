@@ -0,0 +1,179 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package otlp
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/model/otlpgrpc"
+	"google.golang.org/grpc"
+
+	coreConfig "github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	// logsPath is the path of the OTLP/HTTP logs endpoint
+	logsPath = "/v1/logs"
+	// maxRequestSize bounds the size of the OTLP/HTTP requests
+	maxRequestSize = 10 * 1024 * 1024
+)
+
+// Tailer receives logs sent with the OpenTelemetry protocol over gRPC and HTTP,
+// and sends them to a stream of log messages.
+type Tailer struct {
+	source     *config.LogSource
+	outputChan chan *message.Message
+
+	httpServer *http.Server
+	grpcServer *grpc.Server
+	wg         sync.WaitGroup
+}
+
+// NewTailer returns a new Tailer
+func NewTailer(source *config.LogSource, outputChan chan *message.Message) *Tailer {
+	return &Tailer{
+		source:     source,
+		outputChan: outputChan,
+	}
+}
+
+// Start starts the servers configured on the source, it returns an error
+// if one of them can't listen on its port.
+// The servers listen on bind_host, which is localhost by default.
+func (t *Tailer) Start() error {
+	if t.source.Config.Port != 0 {
+		ln, err := net.Listen("tcp", listenAddress(t.source.Config.Port))
+		if err != nil {
+			return fmt.Errorf("can't listen for OTLP/HTTP logs on port %d: %v", t.source.Config.Port, err)
+		}
+		mux := http.NewServeMux()
+		mux.Handle(logsPath, t)
+		t.httpServer = &http.Server{Handler: mux}
+		t.wg.Add(1)
+		go func() {
+			defer t.wg.Done()
+			if err := t.httpServer.Serve(ln); err != nil && err != http.ErrServerClosed {
+				log.Errorf("Error serving OTLP/HTTP logs on port %d: %v", t.source.Config.Port, err)
+			}
+		}()
+		log.Infof("Listening for OTLP/HTTP logs on port %d", t.source.Config.Port)
+	}
+	if t.source.Config.GRPCPort != 0 {
+		ln, err := net.Listen("tcp", listenAddress(t.source.Config.GRPCPort))
+		if err != nil {
+			t.Stop()
+			return fmt.Errorf("can't listen for OTLP/gRPC logs on port %d: %v", t.source.Config.GRPCPort, err)
+		}
+		t.grpcServer = grpc.NewServer()
+		otlpgrpc.RegisterLogsServer(t.grpcServer, t)
+		t.wg.Add(1)
+		go func() {
+			defer t.wg.Done()
+			if err := t.grpcServer.Serve(ln); err != nil {
+				log.Errorf("Error serving OTLP/gRPC logs on port %d: %v", t.source.Config.GRPCPort, err)
+			}
+		}()
+		log.Infof("Listening for OTLP/gRPC logs on port %d", t.source.Config.GRPCPort)
+	}
+	return nil
+}
+
+// listenAddress returns the address the servers listen on for port.
+func listenAddress(port int) string {
+	return net.JoinHostPort(coreConfig.GetBindHost(), strconv.Itoa(port))
+}
+
+// Stop stops the servers and waits for the pending requests to be handled.
+func (t *Tailer) Stop() {
+	if t.httpServer != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		if err := t.httpServer.Shutdown(ctx); err != nil {
+			log.Errorf("Error shutting down OTLP/HTTP logs server: %v", err)
+		}
+	}
+	if t.grpcServer != nil {
+		t.grpcServer.GracefulStop()
+	}
+	t.wg.Wait()
+}
+
+// Export implements otlpgrpc.LogsServer
+func (t *Tailer) Export(ctx context.Context, req otlpgrpc.LogsRequest) (otlpgrpc.LogsResponse, error) {
+	t.forward(req)
+	return otlpgrpc.NewLogsResponse(), nil
+}
+
+// ServeHTTP implements http.Handler
+func (t *Tailer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var r io.Reader = http.MaxBytesReader(w, req.Body, maxRequestSize)
+	if req.Header.Get("Content-Encoding") == "gzip" {
+		gzipr, err := gzip.NewReader(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer gzipr.Close()
+		r = gzipr
+	}
+	body, err := ioutil.ReadAll(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var logsReq otlpgrpc.LogsRequest
+	var encoded []byte
+	resp := otlpgrpc.NewLogsResponse()
+	switch req.Header.Get("Content-Type") {
+	case "application/json":
+		if logsReq, err = otlpgrpc.UnmarshalJSONLogsRequest(body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		encoded, err = resp.MarshalJSON()
+	default:
+		if logsReq, err = otlpgrpc.UnmarshalLogsRequest(body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		encoded, err = resp.Marshal()
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	t.forward(logsReq)
+	w.Header().Set("Content-Type", req.Header.Get("Content-Type"))
+	w.WriteHeader(http.StatusOK)
+	w.Write(encoded) //nolint:errcheck
+}
+
+// forward converts the log records of req to messages and sends them to the pipeline.
+func (t *Tailer) forward(req otlpgrpc.LogsRequest) {
+	for _, msg := range convertLogs(t.source, req.Logs(), time.Now()) {
+		t.source.BytesRead.Add(int64(len(msg.Content)))
+		t.outputChan <- msg
+	}
+}
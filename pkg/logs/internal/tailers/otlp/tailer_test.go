@@ -0,0 +1,169 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package otlp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/model/otlpgrpc"
+	"go.opentelemetry.io/collector/model/pdata"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+func newLogsRequest(bodies ...string) otlpgrpc.LogsRequest {
+	logs := pdata.NewLogs()
+	records := logs.ResourceLogs().AppendEmpty().InstrumentationLibraryLogs().AppendEmpty().LogRecords()
+	for _, body := range bodies {
+		records.AppendEmpty().Body().SetStringVal(body)
+	}
+	req := otlpgrpc.NewLogsRequest()
+	req.SetLogs(logs)
+	return req
+}
+
+func newTestTailer(cfg *config.LogsConfig) (*Tailer, chan *message.Message) {
+	outputChan := make(chan *message.Message, 10)
+	return NewTailer(config.NewLogSource("otlp", cfg), outputChan), outputChan
+}
+
+func TestTailerServeHTTP(t *testing.T) {
+	tailer, outputChan := newTestTailer(&config.LogsConfig{Type: config.OTLPType})
+
+	protoBody, err := newLogsRequest("foo", "bar").Marshal()
+	require.NoError(t, err)
+	jsonBody, err := newLogsRequest("baz").MarshalJSON()
+	require.NoError(t, err)
+	var gzipBody bytes.Buffer
+	gzipw := gzip.NewWriter(&gzipBody)
+	gzipw.Write(protoBody) //nolint:errcheck
+	require.NoError(t, gzipw.Close())
+
+	for _, tc := range []struct {
+		name            string
+		method          string
+		contentType     string
+		contentEncoding string
+		body            []byte
+		expectedStatus  int
+		expectedLogs    []string
+	}{
+		{
+			name:           "protobuf",
+			method:         http.MethodPost,
+			contentType:    "application/x-protobuf",
+			body:           protoBody,
+			expectedStatus: http.StatusOK,
+			expectedLogs:   []string{"foo", "bar"},
+		},
+		{
+			name:           "json",
+			method:         http.MethodPost,
+			contentType:    "application/json",
+			body:           jsonBody,
+			expectedStatus: http.StatusOK,
+			expectedLogs:   []string{"baz"},
+		},
+		{
+			name:            "gzip",
+			method:          http.MethodPost,
+			contentType:     "application/x-protobuf",
+			contentEncoding: "gzip",
+			body:            gzipBody.Bytes(),
+			expectedStatus:  http.StatusOK,
+			expectedLogs:    []string{"foo", "bar"},
+		},
+		{
+			name:           "invalid body",
+			method:         http.MethodPost,
+			contentType:    "application/json",
+			body:           []byte("{"),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid method",
+			method:         http.MethodGet,
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, logsPath, bytes.NewReader(tc.body))
+			req.Header.Set("Content-Type", tc.contentType)
+			if tc.contentEncoding != "" {
+				req.Header.Set("Content-Encoding", tc.contentEncoding)
+			}
+			w := httptest.NewRecorder()
+
+			tailer.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			require.Len(t, outputChan, len(tc.expectedLogs))
+			for _, expected := range tc.expectedLogs {
+				msg := <-outputChan
+				assert.Contains(t, string(msg.Content), fmt.Sprintf(`"message":%q`, expected))
+			}
+		})
+	}
+}
+
+func TestTailerExport(t *testing.T) {
+	tailer, outputChan := newTestTailer(&config.LogsConfig{Type: config.OTLPType})
+
+	_, err := tailer.Export(context.Background(), newLogsRequest("foo"))
+	require.NoError(t, err)
+
+	require.Len(t, outputChan, 1)
+	msg := <-outputChan
+	assert.Contains(t, string(msg.Content), `"message":"foo"`)
+	assert.Equal(t, int64(len(msg.Content)), tailer.source.BytesRead.Value())
+}
+
+func TestTailerStartStop(t *testing.T) {
+	httpPort, grpcPort := freePort(t), freePort(t)
+	tailer, outputChan := newTestTailer(&config.LogsConfig{Type: config.OTLPType, Port: httpPort, GRPCPort: grpcPort})
+	require.NoError(t, tailer.Start())
+
+	body, err := newLogsRequest("foo").Marshal()
+	require.NoError(t, err)
+	resp, err := http.Post(fmt.Sprintf("http://127.0.0.1:%d%s", httpPort, logsPath), "application/x-protobuf", bytes.NewReader(body))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, outputChan, 1)
+
+	// another tailer can't listen on the same ports
+	other, _ := newTestTailer(&config.LogsConfig{Type: config.OTLPType, Port: httpPort})
+	assert.Error(t, other.Start())
+
+	tailer.Stop()
+
+	// the ports are released
+	other, _ = newTestTailer(&config.LogsConfig{Type: config.OTLPType, Port: httpPort, GRPCPort: grpcPort})
+	require.NoError(t, other.Start())
+	other.Stop()
+}
+
+func TestListenAddress(t *testing.T) {
+	assert.Equal(t, "localhost:4318", listenAddress(4318))
+}
+
+// freePort returns a port on which nothing listens.
+func freePort(t *testing.T) int {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	return ln.Addr().(*net.TCPAddr).Port
+}
@@ -8,12 +8,15 @@ package socket
 import (
 	"io"
 	"net"
+	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
 	"github.com/DataDog/datadog-agent/pkg/logs/config"
 	"github.com/DataDog/datadog-agent/pkg/logs/internal/decoder"
+	"github.com/DataDog/datadog-agent/pkg/logs/internal/framer"
 	"github.com/DataDog/datadog-agent/pkg/logs/internal/parsers/noop"
+	"github.com/DataDog/datadog-agent/pkg/logs/internal/parsers/syslog"
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
 )
 
@@ -36,12 +39,26 @@ func NewTailer(source *config.LogSource, conn net.Conn, outputChan chan *message
 		Conn:       conn,
 		outputChan: outputChan,
 		read:       read,
-		decoder:    decoder.InitializeDecoder(source, noop.New()),
+		decoder:    newDecoder(source),
 		stop:       make(chan struct{}, 1),
 		done:       make(chan struct{}, 1),
 	}
 }
 
+// newDecoder returns a decoder for the format of the source.
+// The syslog messages received over UDP are not octet-counted,
+// as every datagram holds a single message terminated by a newline.
+func newDecoder(source *config.LogSource) *decoder.Decoder {
+	switch {
+	case source.Config.Format != config.SyslogFormat:
+		return decoder.InitializeDecoder(source, noop.New())
+	case source.Config.Type == config.UDPType:
+		return decoder.NewDecoderWithFraming(source, syslog.New(), framer.UTF8Newline, nil)
+	default:
+		return decoder.NewDecoderWithFraming(source, syslog.New(), framer.SyslogOctetCounting, nil)
+	}
+}
+
 // Start prepares the tailer to read and decode data from the connection
 func (t *Tailer) Start() {
 	go t.forwardMessages()
@@ -64,9 +81,25 @@ func (t *Tailer) forwardMessages() {
 	}()
 	for output := range t.decoder.OutputChan {
 		if len(output.Content) > 0 {
-			t.outputChan <- message.NewMessageWithSource(output.Content, message.StatusInfo, t.source, output.IngestionTimestamp)
+			t.outputChan <- t.newMessage(output)
+		}
+	}
+}
+
+// newMessage returns a message holding the content and the metadata parsed from output.
+func (t *Tailer) newMessage(output *decoder.Message) *message.Message {
+	status := output.Status
+	if status == "" {
+		status = message.StatusInfo
+	}
+	msg := message.NewMessageWithSource(output.Content, status, t.source, output.IngestionTimestamp)
+	if output.Timestamp != "" {
+		if timestamp, err := time.Parse(config.DateFormat, output.Timestamp); err == nil {
+			msg.Timestamp = timestamp
 		}
 	}
+	msg.Hostname = output.Hostname
+	return msg
 }
 
 // readForever reads the data from conn.
@@ -7,8 +7,10 @@ package socket
 
 import (
 	"errors"
+	"fmt"
 	"net"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -39,6 +41,43 @@ func TestReadAndForwardShouldSucceedWithSuccessfulRead(t *testing.T) {
 	tailer.Stop()
 }
 
+func TestReadAndForwardShouldParseSyslogMessages(t *testing.T) {
+	msgChan := make(chan *message.Message)
+	r, w := net.Pipe()
+	tailer := NewTailer(config.NewLogSource("", &config.LogsConfig{Format: config.SyslogFormat}), r, msgChan, read)
+	tailer.Start()
+
+	syslogMsg := "<11>1 2021-01-01T00:00:00Z myhost app - - - boom"
+	w.Write([]byte(fmt.Sprintf("%d %s", len(syslogMsg), syslogMsg)))
+	msg := <-msgChan
+	assert.JSONEq(t, `{"message": "boom", "syslog": {"facility": 1, "severity": 3, "hostname": "myhost", "appname": "app"}}`, string(msg.Content))
+	assert.Equal(t, message.StatusError, msg.GetStatus())
+	assert.Equal(t, "myhost", msg.GetHostname())
+	assert.Equal(t, time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC), msg.Timestamp)
+
+	// messages which are not syslog messages are forwarded as is
+	w.Write([]byte("foo\n"))
+	msg = <-msgChan
+	assert.Equal(t, "foo", string(msg.Content))
+	assert.Equal(t, message.StatusInfo, msg.GetStatus())
+
+	tailer.Stop()
+}
+
+func TestReadAndForwardShouldNotCountOctetsOverUDP(t *testing.T) {
+	msgChan := make(chan *message.Message)
+	r, w := net.Pipe()
+	tailer := NewTailer(config.NewLogSource("", &config.LogsConfig{Type: config.UDPType, Format: config.SyslogFormat}), r, msgChan, read)
+	tailer.Start()
+
+	// every datagram holds a single message, a leading number is not a length
+	w.Write([]byte("3 little pigs\n"))
+	msg := <-msgChan
+	assert.Equal(t, "3 little pigs", string(msg.Content))
+
+	tailer.Stop()
+}
+
 func TestReadShouldFailWithError(t *testing.T) {
 	msgChan := make(chan *message.Message)
 	r, w := net.Pipe()
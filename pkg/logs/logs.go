@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"sync/atomic"
 
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery"
 	"github.com/DataDog/datadog-agent/pkg/logs/client/http"
 	"github.com/DataDog/datadog-agent/pkg/logs/internal/metrics"
@@ -54,13 +55,14 @@ var (
 // instead of directly using it.
 // The parameter serverless indicates whether or not this Logs Agent is running
 // in a serverless environment.
-func Start(getAC func() *autodiscovery.AutoConfig) (*Agent, error) {
-	return start(getAC, false)
+// demux receives the metrics extracted from the logs by the processing rules.
+func Start(getAC func() *autodiscovery.AutoConfig, demux aggregator.Demultiplexer) (*Agent, error) {
+	return start(getAC, demux, false)
 }
 
 // StartServerless starts a Serverless instance of the Logs Agent.
 func StartServerless(getAC func() *autodiscovery.AutoConfig) (*Agent, error) {
-	return start(getAC, true)
+	return start(getAC, nil, true)
 }
 
 // buildEndpoints builds endpoints for the logs agent
@@ -75,7 +77,7 @@ func buildEndpoints(serverless bool) (*config.Endpoints, error) {
 	return config.BuildEndpointsWithVectorOverride(httpConnectivity, intakeTrackType, AgentJSONIntakeProtocol, config.DefaultIntakeOrigin)
 }
 
-func start(getAC func() *autodiscovery.AutoConfig, serverless bool) (*Agent, error) {
+func start(getAC func() *autodiscovery.AutoConfig, demux aggregator.Demultiplexer, serverless bool) (*Agent, error) {
 	if IsAgentRunning() {
 		return agent, nil
 	}
@@ -118,7 +120,7 @@ func start(getAC func() *autodiscovery.AutoConfig, serverless bool) (*Agent, err
 	if !serverless {
 		// regular logs agent
 		log.Info("Starting logs-agent...")
-		agent = NewAgent(sources, services, processingRules, endpoints)
+		agent = NewAgent(sources, services, processingRules, endpoints, demux)
 	} else {
 		// serverless logs agent
 		log.Info("Starting a serverless logs-agent...")
@@ -37,6 +37,20 @@ type Message struct {
 	// Optional.
 	// Used in the Serverless Agent
 	Lambda *Lambda
+	// Optional.
+	// Set when the log carries the name of the host which emitted it, e.g. syslog
+	Hostname string
+	// Optional.
+	// Set when identical messages have been collapsed into this one
+	Repeat *Repeat
+}
+
+// Repeat holds the number of identical messages collapsed into a single message,
+// and the time at which the first and the last of them were received.
+type Repeat struct {
+	Count int
+	First time.Time
+	Last  time.Time
 }
 
 // Lambda is a struct storing information about the Lambda function and function execution.
@@ -94,6 +108,9 @@ func (m *Message) GetHostname() string {
 	if m.Lambda != nil {
 		return m.Lambda.ARN
 	}
+	if m.Hostname != "" {
+		return m.Hostname
+	}
 	hostname, err := util.GetHostname(context.TODO())
 	if err != nil {
 		// this scenario is not likely to happen since
@@ -9,6 +9,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
 	"github.com/DataDog/datadog-agent/pkg/logs/client"
 	"github.com/DataDog/datadog-agent/pkg/logs/client/http"
 	"github.com/DataDog/datadog-agent/pkg/logs/client/tcp"
@@ -34,7 +35,9 @@ func NewPipeline(outputChan chan *message.Payload,
 	destinationsContext *client.DestinationsContext,
 	diagnosticMessageReceiver diagnostic.MessageReceiver,
 	serverless bool,
-	pipelineID int) *Pipeline {
+	pipelineID int,
+	demux aggregator.Demultiplexer,
+	samplers *processor.Samplers) *Pipeline {
 
 	mainDestinations := getDestinations(endpoints, destinationsContext, pipelineID)
 
@@ -58,7 +61,7 @@ func NewPipeline(outputChan chan *message.Payload,
 	}
 
 	inputChan := make(chan *message.Message, config.ChanSize)
-	processor := processor.New(inputChan, strategyInput, processingRules, encoder, diagnosticMessageReceiver)
+	processor := processor.New(inputChan, strategyInput, processingRules, encoder, diagnosticMessageReceiver, demux, samplers)
 
 	return &Pipeline{
 		InputChan: inputChan,
@@ -84,6 +84,8 @@ func (b *Builder) getIntegrations() []Integration {
 		for _, source := range logSources {
 			sources = append(sources, Source{
 				BytesRead:          source.BytesRead.Value(),
+				RateLimited:        source.RateLimited.Value(),
+				SampledOut:         source.SampledOut.Value(),
 				AllTimeAvgLatency:  source.LatencyStats.AllTimeAvg() / int64(time.Millisecond),
 				AllTimePeakLatency: source.LatencyStats.AllTimePeak() / int64(time.Millisecond),
 				RecentAvgLatency:   source.LatencyStats.MovingAvg() / int64(time.Millisecond),
@@ -139,6 +141,9 @@ func (b *Builder) toDictionary(c *config.LogsConfig) map[string]interface{} {
 	switch c.Type {
 	case config.TCPType, config.UDPType:
 		dictionary["Port"] = c.Port
+	case config.OTLPType:
+		dictionary["Port"] = c.Port
+		dictionary["GRPCPort"] = c.GRPCPort
 	case config.FileType:
 		dictionary["Path"] = c.Path
 		dictionary["TailingMode"] = c.TailingMode
@@ -164,10 +169,12 @@ func (b *Builder) toDictionary(c *config.LogsConfig) map[string]interface{} {
 
 // getMetricsStatus exposes some aggregated metrics of the log agent on the agent status
 func (b *Builder) getMetricsStatus() map[string]int64 {
-	var metrics = make(map[string]int64, 2)
+	var metrics = make(map[string]int64, 6)
 	metrics["LogsProcessed"] = b.logsExpVars.Get("LogsProcessed").(*expvar.Int).Value()
 	metrics["LogsSent"] = b.logsExpVars.Get("LogsSent").(*expvar.Int).Value()
 	metrics["BytesSent"] = b.logsExpVars.Get("BytesSent").(*expvar.Int).Value()
 	metrics["EncodedBytesSent"] = b.logsExpVars.Get("EncodedBytesSent").(*expvar.Int).Value()
+	metrics["LogsRateLimited"] = b.logsExpVars.Get("LogsRateLimited").(*expvar.Int).Value()
+	metrics["LogsSampledOut"] = b.logsExpVars.Get("LogsSampledOut").(*expvar.Int).Value()
 	return metrics
 }
@@ -35,6 +35,8 @@ var (
 // Source provides some information about a logs source.
 type Source struct {
 	BytesRead          int64                  `json:"bytes_read"`
+	RateLimited        int64                  `json:"rate_limited"`
+	SampledOut         int64                  `json:"sampled_out"`
 	AllTimeAvgLatency  int64                  `json:"all_time_avg_latency"`
 	AllTimePeakLatency int64                  `json:"all_time_peak_latency"`
 	RecentAvgLatency   int64                  `json:"recent_avg_latency"`
@@ -0,0 +1,91 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"encoding/json"
+	"strings"
+	"unicode"
+)
+
+// awsKeepTags lists the "aws.*" tags which identify the request rather than carry its
+// parameters. Their values are kept as they are.
+var awsKeepTags = map[string]bool{
+	"aws.agent":               true,
+	"aws.operation":           true,
+	"aws.region":              true,
+	"aws.service":             true,
+	"aws.partition":           true,
+	"aws.request_id":          true,
+	"aws.requestid":           true,
+	"aws.retry_count":         true,
+	"aws.dynamodb.table_name": true,
+	"aws.s3.bucket_name":      true,
+	"aws.sqs.queue_name":      true,
+	"aws.sns.topic_name":      true,
+	"aws.kinesis.stream_name": true,
+}
+
+// ObfuscateAWSTag obfuscates the value v of the "aws.*" tag k, such as the parameters of an AWS
+// SDK request. The values of the tags identifying the request, such as "aws.operation", are kept.
+// JSON values have their literals replaced and other values, such as the query DSL of DynamoDB
+// or the representation of the SDK request structs, have all their values replaced but their
+// keys kept.
+func (o *Obfuscator) ObfuscateAWSTag(k, v string) string {
+	if awsKeepTags[k] || v == "" {
+		return v
+	}
+	if s := strings.TrimSpace(v); s != "" && (s[0] == '{' || s[0] == '[') && json.Valid([]byte(s)) {
+		out, _ := o.aws.obfuscate([]byte(v))
+		return out
+	}
+	return obfuscateKeyValues(v)
+}
+
+// obfuscateKeyValues obfuscates the values of the key/value string s, such as "name: john" or
+// "{Key: {id: {S: \"1\"}}}", replacing all the quoted strings and the words which are not keys,
+// that is not followed by a colon or an equal sign, with "?".
+func obfuscateKeyValues(s string) string {
+	var out strings.Builder
+	out.Grow(len(s))
+	for i := 0; i < len(s); {
+		switch c := s[i]; {
+		case isKeyValueSeparator(c):
+			out.WriteByte(c)
+			i++
+		case c == '"' || c == '\'':
+			for i++; i < len(s) && s[i] != c; i++ {
+				if s[i] == '\\' {
+					i++
+				}
+			}
+			i++
+			out.WriteByte('?')
+		default:
+			start := i
+			for i < len(s) && !isKeyValueSeparator(s[i]) && s[i] != '"' && s[i] != '\'' {
+				i++
+			}
+			next := strings.TrimLeftFunc(s[i:], unicode.IsSpace)
+			if next != "" && (next[0] == ':' || next[0] == '=') {
+				// the word is a key
+				out.WriteString(s[start:i])
+			} else {
+				out.WriteByte('?')
+			}
+		}
+	}
+	return out.String()
+}
+
+// isKeyValueSeparator reports whether c separates the keys and values of a key/value string.
+func isKeyValueSeparator(c byte) bool {
+	switch c {
+	case ' ', '\t', '\n', '\r', '{', '}', '[', ']', '(', ')', ',', ';', ':', '=', '&':
+		return true
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObfuscateAWSTag(t *testing.T) {
+	o := NewObfuscator(Config{})
+	for _, tt := range []struct {
+		k, v, out string
+	}{
+		{"aws.operation", "GetItem", "GetItem"},
+		{"aws.region", "us-east-1", "us-east-1"},
+		{"aws.request.key", "users/1234/avatar.png", "?"},
+		{"aws.request.body", "", ""},
+		{"aws.request.body", `{"Key": {"id": {"S": "1234"}}, "Limit": 10}`, `{"Key":{"id":{"S":"?"}},"Limit":"?"}`},
+		{"aws.request.params", `["a", 1]`, `["?","?"]`},
+		{
+			"aws.request.params",
+			"{\n  Key: {\n    id: {\n      S: \"1234\"\n    }\n  },\n  TableName: \"users\"\n}",
+			"{\n  Key: {\n    id: {\n      S: ?\n    }\n  },\n  TableName: ?\n}",
+		},
+		{"aws.dynamodb.query", "KeyConditionExpression = id = :v AND age > 30", "KeyConditionExpression = id = :? ? ? ? ?"},
+		{"aws.elasticsearch.query", "name:john AND age:[30 TO 40]", "name:? ? age:[? ? ?]"},
+		{"aws.request.params", "bucket=my-bucket&key='a b' ", "bucket=?&key=? "},
+	} {
+		t.Run(tt.k, func(t *testing.T) {
+			assert.Equal(t, tt.out, o.ObfuscateAWSTag(tt.k, tt.v))
+		})
+	}
+}
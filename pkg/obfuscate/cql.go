@@ -0,0 +1,27 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+// cqlCachePrefix prefixes the CQL queries in the query cache, so that they do not share
+// entries with the SQL queries which would be obfuscated differently.
+const cqlCachePrefix = "cql:"
+
+// ObfuscateCQLString quantizes and obfuscates the given Cassandra CQL query. On top of the
+// SQL literals, UUIDs and collection literals are replaced.
+func (o *Obfuscator) ObfuscateCQLString(in string) (*ObfuscatedQuery, error) {
+	key := cqlCachePrefix + in
+	if v, ok := o.queryCache.Get(key); ok {
+		return v.(*ObfuscatedQuery), nil
+	}
+	opts := o.opts.SQL
+	opts.DBMS = DBMSCassandra
+	oq, err := o.obfuscateSQLString(in, &opts)
+	if err != nil {
+		return oq, err
+	}
+	o.queryCache.Set(key, oq, oq.Cost())
+	return oq, nil
+}
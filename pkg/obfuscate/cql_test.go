@@ -0,0 +1,69 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObfuscateCQL(t *testing.T) {
+	for _, tt := range []struct {
+		in, out string
+	}{
+		{
+			"SELECT * FROM users WHERE id = 123e4567-e89b-12d3-a456-426614174000",
+			"SELECT * FROM users WHERE id = ?",
+		},
+		{
+			"SELECT * FROM users WHERE id IN (123e4567-e89b-12d3-a456-426614174000, a23e4567-e89b-12d3-a456-426614174000) AND score > 1.5",
+			"SELECT * FROM users WHERE id IN ( ? ) AND score > ?",
+		},
+		{
+			"INSERT INTO users (id, emails, prefs) VALUES (now(), {'a@b.com', 'c@d.com'}, {'theme': 'dark', 'nested': {1, 2}}) USING TTL 86400",
+			"INSERT INTO users ( id, emails, prefs ) VALUES ( now ( ), ? ) USING TTL ?",
+		},
+		{
+			"UPDATE users SET tags = tags + ['it''s', 'b'], ratio = NaN WHERE \"UserId\" = 12",
+			"UPDATE users SET tags = tags + [ ? ], ratio = ? WHERE \"UserId\" = ?",
+		},
+		{
+			"SELECT * FROM t WHERE k = ? AND c = :name AND b = 0xcafe",
+			"SELECT * FROM t WHERE k = ? AND c = :name AND b = ?",
+		},
+	} {
+		t.Run("", func(t *testing.T) {
+			oq, err := NewObfuscator(Config{}).ObfuscateCQLString(tt.in)
+			require.NoError(t, err)
+			assert.Equal(t, tt.out, oq.Query)
+		})
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		for _, in := range []string{
+			"INSERT INTO t (m) VALUES ({'a': 1)",
+			"INSERT INTO t (m) VALUES ({'a}')",
+		} {
+			_, err := NewObfuscator(Config{}).ObfuscateCQLString(in)
+			assert.Error(t, err, in)
+		}
+	})
+
+	t.Run("cache", func(t *testing.T) {
+		o := NewObfuscator(Config{SQL: SQLConfig{Cache: true}})
+		defer o.Stop()
+		query := `SELECT * FROM "Users" WHERE a = 'b'`
+		oq, err := o.ObfuscateSQLString(query)
+		require.NoError(t, err)
+		assert.Equal(t, "SELECT * FROM Users WHERE a = ?", oq.Query)
+		o.queryCache.Wait()
+		oq, err = o.ObfuscateCQLString(query)
+		require.NoError(t, err)
+		assert.Equal(t, `SELECT * FROM "Users" WHERE a = ?`, oq.Query)
+	})
+}
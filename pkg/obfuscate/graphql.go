@@ -0,0 +1,226 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import "strings"
+
+// ObfuscateGraphQLString obfuscates the GraphQL document in by replacing its literal values,
+// such as strings, numbers and enum values, with "?" while keeping the shape of the operation:
+// its fields, aliases, arguments names, variables, types, directives and fragments. Comments
+// are removed.
+func (*Obfuscator) ObfuscateGraphQLString(in string) string {
+	tokens := tokenizeGraphQL(in)
+	var (
+		out          strings.Builder
+		stack        []graphqlContext // the contexts opened by the brackets
+		defaultValue bool             // true within the default value of a variable definition
+		last         int              // the end of the last token written to out
+	)
+	out.Grow(len(in))
+	top := func() graphqlContext {
+		if len(stack) == 0 {
+			return graphqlSelection
+		}
+		return stack[len(stack)-1]
+	}
+	inValue := func() bool {
+		switch top() {
+		case graphqlArguments, graphqlObject, graphqlList:
+			return true
+		case graphqlVariables:
+			return defaultValue
+		}
+		return false
+	}
+	for i, t := range tokens {
+		writeGraphQLIgnored(&out, in[last:t.start])
+		last = t.end
+		replace := false
+		switch t.kind {
+		case graphqlLiteral:
+			replace = true
+		case graphqlName:
+			isKey := i+1 < len(tokens) && tokens[i+1].is(in, ":")
+			isVariable := i > 0 && tokens[i-1].is(in, "$")
+			replace = inValue() && !isKey && !isVariable
+		case graphqlPunctuator:
+			switch in[t.start] {
+			case '{':
+				if inValue() {
+					stack = append(stack, graphqlObject)
+				} else {
+					stack = append(stack, graphqlSelection)
+				}
+			case '[':
+				if inValue() {
+					stack = append(stack, graphqlList)
+				} else {
+					stack = append(stack, graphqlListType)
+				}
+			case '(':
+				if len(stack) == 0 && isGraphQLOperationHead(in, tokens[:i]) {
+					stack = append(stack, graphqlVariables)
+					defaultValue = false
+				} else {
+					stack = append(stack, graphqlArguments)
+				}
+			case ')', '}', ']':
+				if len(stack) > 0 {
+					stack = stack[:len(stack)-1]
+				}
+			case '=':
+				if top() == graphqlVariables {
+					defaultValue = true
+				}
+			case '$':
+				if top() == graphqlVariables {
+					defaultValue = false
+				}
+			}
+		}
+		if replace {
+			out.WriteByte('?')
+		} else {
+			out.WriteString(in[t.start:t.end])
+		}
+	}
+	writeGraphQLIgnored(&out, in[last:])
+	return out.String()
+}
+
+// graphqlContext specifies what the tokens enclosed in brackets are.
+type graphqlContext int
+
+const (
+	graphqlSelection graphqlContext = iota // a selection set, such as { user { name } }
+	graphqlArguments                       // arguments, such as (id: 4)
+	graphqlVariables                       // variable definitions, such as ($id: ID = 4)
+	graphqlObject                          // an input object value, such as {name: "john"}
+	graphqlList                            // a list value, such as [1, 2]
+	graphqlListType                        // a list type, such as [ID!]
+)
+
+// isGraphQLOperationHead reports whether the tokens end with an operation type, optionally followed
+// by the operation name, which would be followed by the variable definitions.
+func isGraphQLOperationHead(in string, tokens []graphqlToken) bool {
+	isOperationType := func(t graphqlToken) bool {
+		return t.is(in, "query") || t.is(in, "mutation") || t.is(in, "subscription")
+	}
+	n := len(tokens)
+	switch {
+	case n > 0 && isOperationType(tokens[n-1]):
+		return true
+	case n > 1 && tokens[n-1].kind == graphqlName && isOperationType(tokens[n-2]):
+		return true
+	}
+	return false
+}
+
+// writeGraphQLIgnored writes the ignored tokens s, such as white spaces and commas, to out,
+// removing the comments.
+func writeGraphQLIgnored(out *strings.Builder, s string) {
+	for {
+		i := strings.IndexByte(s, '#')
+		if i < 0 {
+			out.WriteString(s)
+			return
+		}
+		out.WriteString(s[:i])
+		j := strings.IndexAny(s[i:], "\r\n")
+		if j < 0 {
+			return
+		}
+		s = s[i+j:]
+	}
+}
+
+type graphqlTokenKind int
+
+const (
+	graphqlName graphqlTokenKind = iota
+	graphqlPunctuator
+	graphqlLiteral
+)
+
+// graphqlToken is a lexical token of a GraphQL document, see
+// https://spec.graphql.org/October2021/#sec-Language.Source-Text.Lexical-Tokens
+type graphqlToken struct {
+	kind       graphqlTokenKind
+	start, end int // the bounds of the token in the document
+}
+
+// is reports whether the token of the document in is s.
+func (t graphqlToken) is(in, s string) bool { return in[t.start:t.end] == s }
+
+// tokenizeGraphQL splits the GraphQL document in into tokens, skipping the ignored ones. Invalid
+// characters are returned as punctuators and unterminated strings end with the document.
+func tokenizeGraphQL(in string) []graphqlToken {
+	var tokens []graphqlToken
+	for i := 0; i < len(in); {
+		c := in[i]
+		start := i
+		kind := graphqlPunctuator
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			i++
+			continue
+		case c == '#':
+			for i < len(in) && in[i] != '\n' && in[i] != '\r' {
+				i++
+			}
+			continue
+		case strings.HasPrefix(in[i:], `"""`):
+			kind = graphqlLiteral
+			i = scanGraphQLBlockString(in, i+3)
+		case c == '"':
+			kind = graphqlLiteral
+			for i++; i < len(in) && in[i] != '"' && in[i] != '\n'; i++ {
+				if in[i] == '\\' {
+					i++
+				}
+			}
+			if i < len(in) && in[i] == '"' {
+				i++
+			}
+		case c == '-' || isDigit(rune(c)):
+			kind = graphqlLiteral
+			for i++; i < len(in) && (isDigit(rune(in[i])) || isGraphQLNameChar(in[i]) || in[i] == '.' || (in[i] == '+' || in[i] == '-') && (in[i-1] == 'e' || in[i-1] == 'E')); i++ {
+			}
+		case isGraphQLNameChar(c):
+			kind = graphqlName
+			for i++; i < len(in) && (isGraphQLNameChar(in[i]) || isDigit(rune(in[i]))); i++ {
+			}
+		case strings.HasPrefix(in[i:], "..."):
+			i += 3
+		default:
+			i++
+		}
+		if i > len(in) {
+			i = len(in)
+		}
+		tokens = append(tokens, graphqlToken{kind: kind, start: start, end: i})
+	}
+	return tokens
+}
+
+// scanGraphQLBlockString returns the end of the block string whose content starts at i.
+func scanGraphQLBlockString(in string, i int) int {
+	for i < len(in) {
+		switch {
+		case strings.HasPrefix(in[i:], `\"""`):
+			i += 4
+		case strings.HasPrefix(in[i:], `"""`):
+			return i + 3
+		default:
+			i++
+		}
+	}
+	return len(in)
+}
+
+func isGraphQLNameChar(c byte) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
@@ -0,0 +1,68 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObfuscateGraphQL(t *testing.T) {
+	o := NewObfuscator(Config{})
+	for _, tt := range []struct {
+		in, out string
+	}{
+		{
+			`{ user(id: 4) { name } }`,
+			`{ user(id: ?) { name } }`,
+		},
+		{
+			`query GetUser($id: ID!, $lang: Lang = FR, $limit: [Int!] = [10, 20]) {
+  user(id: $id, filter: {name: "john", age: -3.5e2, active: true, tags: ["a", "b"]}) @include(if: $show) {
+    alias: name(lang: $lang)
+    friends(first: 10, order: DESC) { ...friendFields }
+  }
+}`,
+			`query GetUser($id: ID!, $lang: Lang = ?, $limit: [Int!] = [?, ?]) {
+  user(id: $id, filter: {name: ?, age: ?, active: ?, tags: [?, ?]}) @include(if: $show) {
+    alias: name(lang: $lang)
+    friends(first: ?, order: ?) { ...friendFields }
+  }
+}`,
+		},
+		{
+			`mutation { createUser(input: {email: "a@b.com", password: """secret
+with "quotes" \""" inside"""}) { id } } # created by john@doe.com
+`,
+			"mutation { createUser(input: {email: ?, password: ?}) { id } } \n",
+		},
+		{
+			`fragment friendFields on User @dir(arg: 1) { id name }`,
+			`fragment friendFields on User @dir(arg: ?) { id name }`,
+		},
+		{
+			`subscription ($room: ID = "lobby") { messages(room: $room) { text } }`,
+			`subscription ($room: ID = ?) { messages(room: $room) { text } }`,
+		},
+		{
+			`{ query(text: "q") { mutation } }`,
+			`{ query(text: ?) { mutation } }`,
+		},
+		{
+			`{ search(q: "unterminated`,
+			`{ search(q: ?`,
+		},
+		{
+			`graphql.execute`,
+			`graphql.execute`,
+		},
+	} {
+		t.Run("", func(t *testing.T) {
+			assert.Equal(t, tt.out, o.ObfuscateGraphQLString(tt.in))
+		})
+	}
+}
@@ -27,6 +27,7 @@ type Obfuscator struct {
 	mongo                *jsonObfuscator // nil if disabled
 	sqlExecPlan          *jsonObfuscator // nil if disabled
 	sqlExecPlanNormalize *jsonObfuscator // nil if disabled
+	aws                  *jsonObfuscator // obfuscates the JSON values of the aws.* tags
 	// sqlLiteralEscapes reports whether we should treat escape characters literally or as escape characters.
 	// A non-zero value means 'yes'. Different SQL engines behave in different ways and the tokenizer needs
 	// to be generic.
@@ -190,6 +191,7 @@ func NewObfuscator(cfg Config) *Obfuscator {
 	if cfg.SQLExecPlanNormalize.Enabled {
 		o.sqlExecPlanNormalize = newJSONObfuscator(&cfg.SQLExecPlanNormalize, &o)
 	}
+	o.aws = newJSONObfuscator(&JSONConfig{Enabled: true}, &o)
 	if cfg.Statsd == nil {
 		cfg.Statsd = &statsd.NoOpClient{}
 	}
@@ -57,7 +57,7 @@ func (f *metadataFinderFilter) Filter(token, lastToken TokenKind, buffer []byte)
 			// SELECT ... FROM [tableName]
 			// DELETE FROM [tableName]
 			// ... JOIN [tableName]
-			if r, _ := utf8.DecodeRune(buffer); !unicode.IsLetter(r) {
+			if r, _ := utf8.DecodeRune(trimIdentifierQuotes(buffer)); !unicode.IsLetter(r) {
 				// first character in buffer is not a letter; we might have a nested
 				// query like SELECT * FROM (SELECT ...)
 				break
@@ -66,10 +66,11 @@ func (f *metadataFinderFilter) Filter(token, lastToken TokenKind, buffer []byte)
 		case Update, Into:
 			// UPDATE [tableName]
 			// INSERT INTO [tableName]
-			tableName := string(buffer)
+			name := trimIdentifierQuotes(buffer)
+			tableName := string(name)
 			if f.replaceDigits {
-				tableNameCopy := make([]byte, len(buffer))
-				copy(tableNameCopy, buffer)
+				tableNameCopy := make([]byte, len(name))
+				copy(tableNameCopy, name)
 				tableName = string(replaceDigits(tableNameCopy))
 			}
 			f.storeTableName(tableName)
@@ -79,6 +80,15 @@ func (f *metadataFinderFilter) Filter(token, lastToken TokenKind, buffer []byte)
 	return token, buffer, nil
 }
 
+// trimIdentifierQuotes returns the identifier in buffer without the backticks or double quotes
+// delimiting it, if any.
+func trimIdentifierQuotes(buffer []byte) []byte {
+	if n := len(buffer); n > 1 && (buffer[0] == '`' || buffer[0] == '"') && buffer[n-1] == buffer[0] {
+		return buffer[1 : n-1]
+	}
+	return buffer
+}
+
 func (f *metadataFinderFilter) storeTableName(name string) {
 	if _, ok := f.tablesSeen[name]; ok {
 		return
@@ -189,7 +199,7 @@ func (f *replaceFilter) Filter(token, lastToken TokenKind, buffer []byte) (token
 		}
 	}
 	switch token {
-	case DollarQuotedString, String, Number, Null, Variable, PreparedStatement, BooleanLiteral, EscapeSequence:
+	case DollarQuotedString, String, Number, Null, Variable, PreparedStatement, BooleanLiteral, EscapeSequence, Collection:
 		return markFilteredGroupable(token), questionMark, nil
 	case '?':
 		// Cases like 'ARRAY [ ?, ? ]' should be collapsed into 'ARRAY [ ? ]'
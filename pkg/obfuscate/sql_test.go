@@ -7,9 +7,13 @@ package obfuscate
 
 import (
 	"bytes"
+	"encoding/xml"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"sync/atomic"
 	"testing"
 
@@ -1188,6 +1192,63 @@ func TestObfuscatorDBMSBehavior(t *testing.T) {
 	}
 }
 
+// TestObfuscatorDialects runs the SQL obfuscation corpus of each DBMS found in
+// ./testdata/sql_<dbms>_tests.xml.
+func TestObfuscatorDialects(t *testing.T) {
+	for _, dbms := range []string{DBMSPostgres, DBMSMySQL, DBMSOracle} {
+		t.Run(dbms, func(t *testing.T) {
+			f, err := os.Open(filepath.Join("testdata", fmt.Sprintf("sql_%s_tests.xml", dbms)))
+			require.NoError(t, err)
+			defer f.Close()
+			var suite xmlObfuscateTests
+			require.NoError(t, xml.NewDecoder(f).Decode(&suite))
+			require.NotEmpty(t, suite.Tests)
+
+			cfg := SQLConfig{DBMS: dbms, TableNames: true}
+			for _, tt := range suite.Tests {
+				t.Run(tt.Tag, func(t *testing.T) {
+					oq, err := NewObfuscator(Config{SQL: cfg}).ObfuscateSQLString(strings.TrimSpace(tt.In))
+					require.NoError(t, err)
+					assert.Equal(t, strings.TrimSpace(tt.Out), oq.Query)
+				})
+			}
+		})
+	}
+}
+
+func TestObfuscatorDialectTableNames(t *testing.T) {
+	for _, tt := range []struct {
+		dbms, in, tables string
+	}{
+		{DBMSMySQL, "SELECT * FROM `users` JOIN `user roles` ON 1 = 1", "users,user roles"},
+		{DBMSMySQL, "INSERT INTO `logs` (`msg`) VALUES ('a')", "logs"},
+		{DBMSPostgres, `UPDATE "Accounts" SET "balance" = 0`, "Accounts"},
+	} {
+		t.Run(tt.dbms, func(t *testing.T) {
+			o := NewObfuscator(Config{SQL: SQLConfig{DBMS: tt.dbms, TableNames: true}})
+			oq, err := o.ObfuscateSQLString(tt.in)
+			require.NoError(t, err)
+			assert.Equal(t, tt.tables, oq.Metadata.TablesCSV)
+		})
+	}
+}
+
+func TestObfuscatorDialectErrors(t *testing.T) {
+	for _, tt := range []struct {
+		dbms, in string
+	}{
+		{DBMSOracle, "SELECT q'[unterminated' FROM dual"},
+		{DBMSOracle, "SELECT q' ' FROM dual"},
+		{DBMSMySQL, "SELECT `unterminated FROM dual"},
+		{DBMSPostgres, `SELECT "unterminated FROM dual`},
+	} {
+		t.Run(tt.dbms, func(t *testing.T) {
+			_, err := NewObfuscator(Config{SQL: SQLConfig{DBMS: tt.dbms}}).ObfuscateSQLString(tt.in)
+			assert.Error(t, err)
+		})
+	}
+}
+
 func TestSQLTokenizerIgnoreEscapeFalse(t *testing.T) {
 	cases := []sqlTokenizerTestCase{
 		{
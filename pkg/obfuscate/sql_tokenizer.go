@@ -73,6 +73,9 @@ const (
 	Join
 	TableName
 	ColonCast
+	PositionalParameter // a Postgres positional parameter, such as $1
+	JSONOp              // a JSON operator, such as ->> (Postgres and MySQL) or #> (Postgres)
+	Collection          // a CQL collection literal, such as {'a': 1}
 
 	// FilteredGroupable specifies that the given token has been discarded by one of the
 	// token filters and that it is groupable together with consecutive FilteredGroupable
@@ -136,6 +139,9 @@ var tokenKindStrings = map[TokenKind]string{
 	Join:                         "Join",
 	TableName:                    "TableName",
 	ColonCast:                    "ColonCast",
+	PositionalParameter:          "PositionalParameter",
+	JSONOp:                       "JSONOp",
+	Collection:                   "Collection",
 	FilteredGroupable:            "FilteredGroupable",
 	FilteredGroupableParenthesis: "FilteredGroupableParenthesis",
 	Filtered:                     "Filtered",
@@ -153,6 +159,14 @@ func (k TokenKind) String() string {
 const (
 	// DBMSSQLServer is a MS SQL Server
 	DBMSSQLServer = "mssql"
+	// DBMSPostgres is a PostgreSQL Server
+	DBMSPostgres = "postgresql"
+	// DBMSMySQL is a MySQL Server
+	DBMSMySQL = "mysql"
+	// DBMSOracle is an Oracle Server
+	DBMSOracle = "oracle"
+	// DBMSCassandra is an Apache Cassandra cluster, queried with CQL
+	DBMSCassandra = "cassandra"
 )
 
 const escapeCharacter = '\\'
@@ -170,6 +184,7 @@ type SQLTokenizer struct {
 
 	literalEscapes bool // indicates we should not treat backslashes as escape characters
 	seenEscape     bool // indicates whether this tokenizer has seen an escape character within a string
+	castType       bool // indicates that the next token is the type of a Postgres cast
 
 	cfg *SQLConfig
 }
@@ -194,6 +209,7 @@ func (tkn *SQLTokenizer) Reset(in string) {
 	tkn.buf = []byte(in)
 	tkn.off = 0
 	tkn.err = nil
+	tkn.castType = false
 }
 
 // keywords used to recognize string tokens
@@ -242,7 +258,24 @@ func (tkn *SQLTokenizer) Scan() (TokenKind, []byte) {
 	}
 	tkn.SkipBlank()
 
+	castType := tkn.castType
+	tkn.castType = false
+
 	switch ch := tkn.lastChar; {
+	case castType && isLeadingLetter(ch):
+		return tkn.scanCastType()
+	case tkn.cfg.DBMS == DBMSOracle && tkn.isOracleQuotedString():
+		return tkn.scanOracleQuotedString()
+	case tkn.cfg.DBMS == DBMSCassandra && digitVal(ch) < 16 && isUUID(tkn.buf[tkn.off-1:]):
+		for i := 0; i < uuidLen; i++ {
+			tkn.advance()
+		}
+		return Number, tkn.bytes()
+	case tkn.cfg.DBMS == DBMSPostgres && ch == '@' && tkn.peek() == '>':
+		// JSON containment operator
+		tkn.advance()
+		tkn.advance()
+		return JSONOp, tkn.bytes()
 	case isLeadingLetter(ch):
 		return tkn.scanIdentifier()
 	case isDigit(ch):
@@ -262,6 +295,7 @@ func (tkn *SQLTokenizer) Scan() (TokenKind, []byte) {
 		case ':':
 			if tkn.lastChar == ':' {
 				tkn.advance()
+				tkn.castType = tkn.cfg.DBMS == DBMSPostgres
 				return ColonCast, []byte("::")
 			}
 			if unicode.IsSpace(tkn.lastChar) {
@@ -280,7 +314,15 @@ func (tkn *SQLTokenizer) Scan() (TokenKind, []byte) {
 			default:
 				return TokenKind(ch), tkn.bytes()
 			}
-		case '=', ',', ';', '(', ')', '+', '*', '&', '|', '^', '[', ']', '?':
+		case '?':
+			if tkn.cfg.DBMS == DBMSPostgres && (tkn.lastChar == '&' || tkn.lastChar == '|' && tkn.peek() != '|') {
+				// JSON key existence operators ?& and ?|, as opposed to a placeholder
+				// followed by the concatenation operator ||
+				tkn.advance()
+				return JSONOp, tkn.bytes()
+			}
+			return TokenKind(ch), tkn.bytes()
+		case '=', ',', ';', '(', ')', '+', '*', '&', '|', '^', '[', ']':
 			return TokenKind(ch), tkn.bytes()
 		case '.':
 			if isDigit(tkn.lastChar) {
@@ -300,9 +342,17 @@ func (tkn *SQLTokenizer) Scan() (TokenKind, []byte) {
 			}
 		case '-':
 			switch {
-			case tkn.lastChar == '-':
+			case tkn.lastChar == '-' && (tkn.cfg.DBMS != DBMSMySQL || isMySQLCommentSpace(tkn.peek())):
+				// MySQL requires the -- comment sequence to be followed by a whitespace
 				tkn.advance()
 				return tkn.scanCommentType1("--")
+			case tkn.lastChar == '>' && (tkn.cfg.DBMS == DBMSPostgres || tkn.cfg.DBMS == DBMSMySQL):
+				// JSON operators -> and ->>
+				tkn.advance()
+				if tkn.lastChar == '>' {
+					tkn.advance()
+				}
+				return JSONOp, tkn.bytes()
 			case isDigit(tkn.lastChar):
 				tkn.advance()
 				kind, tokenBytes := tkn.scanNumber(false)
@@ -311,8 +361,23 @@ func (tkn *SQLTokenizer) Scan() (TokenKind, []byte) {
 				return TokenKind(ch), tkn.bytes()
 			}
 		case '#':
-			if tkn.cfg.DBMS == DBMSSQLServer {
+			switch tkn.cfg.DBMS {
+			case DBMSSQLServer, DBMSOracle:
 				return tkn.scanIdentifier()
+			case DBMSPostgres:
+				// # is the bitwise XOR operator, or starts the JSON operators #>, #>> and #-
+				switch tkn.lastChar {
+				case '>':
+					tkn.advance()
+					if tkn.lastChar == '>' {
+						tkn.advance()
+					}
+					return JSONOp, tkn.bytes()
+				case '-':
+					tkn.advance()
+					return JSONOp, tkn.bytes()
+				}
+				return TokenKind(ch), tkn.bytes()
 			}
 			tkn.advance()
 			return tkn.scanCommentType1("#")
@@ -330,6 +395,13 @@ func (tkn *SQLTokenizer) Scan() (TokenKind, []byte) {
 				default:
 					return LE, []byte("<=")
 				}
+			case '@':
+				if tkn.cfg.DBMS == DBMSPostgres {
+					// JSON containment operator
+					tkn.advance()
+					return JSONOp, []byte("<@")
+				}
+				return TokenKind(ch), tkn.bytes()
 			default:
 				return TokenKind(ch), tkn.bytes()
 			}
@@ -363,8 +435,19 @@ func (tkn *SQLTokenizer) Scan() (TokenKind, []byte) {
 		case '\'':
 			return tkn.scanString(ch, String)
 		case '"':
+			switch tkn.cfg.DBMS {
+			case DBMSPostgres, DBMSCassandra:
+				// double quotes delimit identifiers
+				return tkn.scanQuotedIdentifier(ch)
+			case DBMSMySQL:
+				// double quotes delimit strings, unless the ANSI_QUOTES mode is enabled
+				return tkn.scanString(ch, String)
+			}
 			return tkn.scanString(ch, DoubleQuotedString)
 		case '`':
+			if tkn.cfg.DBMS == DBMSMySQL {
+				return tkn.scanQuotedIdentifier(ch)
+			}
 			return tkn.scanString(ch, ID)
 		case '%':
 			if tkn.lastChar == '(' {
@@ -381,7 +464,12 @@ func (tkn *SQLTokenizer) Scan() (TokenKind, []byte) {
 				// TODO(gbbr): the first digit after $ does not necessarily guarantee
 				// that this isn't a dollar-quoted string constant. We might eventually
 				// want to cover for this use-case too (e.g. $1$some text$1$).
-				return tkn.scanPreparedStatement('$')
+				kind, tok := tkn.scanPreparedStatement('$')
+				if kind == PreparedStatement && tkn.cfg.DBMS == DBMSPostgres {
+					// positional parameters hold no value, keep them as they are
+					return PositionalParameter, tok
+				}
+				return kind, tok
 			}
 			kind, tok := tkn.scanDollarQuotedString()
 			if kind == DollarQuotedFunc {
@@ -396,6 +484,9 @@ func (tkn *SQLTokenizer) Scan() (TokenKind, []byte) {
 			}
 			return kind, tok
 		case '{':
+			if tkn.cfg.DBMS == DBMSCassandra {
+				return tkn.scanCollection()
+			}
 			if tkn.pos == 1 || tkn.curlys > 0 {
 				// Do not fully obfuscate top-level SQL escape sequences like {{[?=]call procedure-name[([parameter][,parameter]...)]}.
 				// We want these to display a bit more context than just a plain '?'
@@ -471,7 +562,7 @@ func toUpper(src, dst []byte) []byte {
 
 func (tkn *SQLTokenizer) scanIdentifier() (TokenKind, []byte) {
 	tkn.advance()
-	for isLetter(tkn.lastChar) || isDigit(tkn.lastChar) || tkn.lastChar == '.' || tkn.lastChar == '*' {
+	for tkn.isIdentifierChar(tkn.lastChar) {
 		tkn.advance()
 	}
 
@@ -484,9 +575,188 @@ func (tkn *SQLTokenizer) scanIdentifier() (TokenKind, []byte) {
 	if keywordID, found := keywords[string(upper)]; found {
 		return keywordID, t
 	}
+	if tkn.cfg.DBMS == DBMSCassandra && (string(upper) == "NAN" || string(upper) == "INFINITY") {
+		return Number, t
+	}
 	return ID, t
 }
 
+// isIdentifierChar reports whether ch may be part of an identifier.
+func (tkn *SQLTokenizer) isIdentifierChar(ch rune) bool {
+	if ch == '#' && (tkn.cfg.DBMS == DBMSMySQL || tkn.cfg.DBMS == DBMSPostgres) {
+		// # starts a comment in MySQL and is an operator in Postgres
+		return false
+	}
+	return isLetter(ch) || isDigit(ch) || ch == '.' || ch == '*'
+}
+
+// scanQuotedIdentifier scans an identifier delimited by delim, keeping the delimiters so that
+// the identifier remains valid when it contains spaces or is a reserved word.
+func (tkn *SQLTokenizer) scanQuotedIdentifier(delim rune) (TokenKind, []byte) {
+	for {
+		ch := tkn.lastChar
+		if ch == EndChar {
+			tkn.setErr("unexpected EOF in quoted identifier")
+			return LexError, tkn.bytes()
+		}
+		tkn.advance()
+		if ch == delim {
+			if tkn.lastChar != delim {
+				break
+			}
+			// doubling a delimiter embeds it within the identifier
+			tkn.advance()
+		}
+	}
+	return ID, tkn.bytes()
+}
+
+// scanCollection scans a CQL map or set literal, such as {'a': 1} or {1, 2}, including
+// the collections nested in it.
+func (tkn *SQLTokenizer) scanCollection() (TokenKind, []byte) {
+	depth := 1
+	for depth > 0 {
+		switch ch := tkn.lastChar; ch {
+		case EndChar:
+			tkn.setErr("unexpected EOF in collection")
+			return LexError, tkn.bytes()
+		case '{':
+			depth++
+			tkn.advance()
+		case '}':
+			depth--
+			tkn.advance()
+		case '\'':
+			tkn.advance()
+			for tkn.lastChar != EndChar {
+				c := tkn.lastChar
+				tkn.advance()
+				if c == '\'' {
+					if tkn.lastChar != '\'' {
+						break
+					}
+					// a doubled quote is embedded in the string
+					tkn.advance()
+				}
+			}
+		default:
+			tkn.advance()
+		}
+	}
+	return Collection, tkn.bytes()
+}
+
+// uuidLen is the length of a UUID literal, such as 123e4567-e89b-12d3-a456-426614174000.
+const uuidLen = 36
+
+// isUUID reports whether b starts with a UUID literal.
+func isUUID(b []byte) bool {
+	if len(b) < uuidLen || len(b) > uuidLen && (isLetter(rune(b[uuidLen])) || isDigit(rune(b[uuidLen]))) {
+		return false
+	}
+	for i, c := range b[:uuidLen] {
+		switch i {
+		case 8, 13, 18, 23:
+			if c != '-' {
+				return false
+			}
+		default:
+			if digitVal(rune(c)) >= 16 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// scanCastType scans the type following a Postgres cast operator, along with its modifiers
+// and array bounds (e.g. numeric(10,2) or text[]), so that they are not obfuscated as values.
+func (tkn *SQLTokenizer) scanCastType() (TokenKind, []byte) {
+	for isLeadingLetter(tkn.lastChar) || isDigit(tkn.lastChar) || tkn.lastChar == '.' {
+		tkn.advance()
+	}
+	if tkn.lastChar == '(' {
+		rest := tkn.buf[tkn.off:]
+		if n := bytes.IndexByte(rest, ')'); n > 0 && isCastModifiers(rest[:n]) {
+			// advance over the modifiers and both parentheses
+			for i := 0; i < n+2; i++ {
+				tkn.advance()
+			}
+		}
+	}
+	for tkn.lastChar == '[' && tkn.peek() == ']' {
+		tkn.advance()
+		tkn.advance()
+	}
+	return ID, tkn.bytes()
+}
+
+// isCastModifiers reports whether b is a list of type modifiers, such as "10, 2".
+func isCastModifiers(b []byte) bool {
+	for _, c := range b {
+		if !isDigit(rune(c)) && c != ',' && c != ' ' {
+			return false
+		}
+	}
+	return true
+}
+
+// isOracleQuotedString reports whether the tokenizer is at the start of an Oracle
+// alternative quoting string literal, such as q'[...]' or nq'[...]'.
+func (tkn *SQLTokenizer) isOracleQuotedString() bool {
+	rest := tkn.buf[tkn.off:]
+	switch tkn.lastChar {
+	case 'n', 'N':
+		return len(rest) > 1 && (rest[0] == 'q' || rest[0] == 'Q') && rest[1] == '\''
+	case 'q', 'Q':
+		return len(rest) > 0 && rest[0] == '\''
+	}
+	return false
+}
+
+// oracleQuoteDelimiters maps the opening delimiters of Oracle quoted strings to their closing
+// counter-part. Other delimiters close the string themselves.
+var oracleQuoteDelimiters = map[rune]rune{
+	'[': ']',
+	'{': '}',
+	'(': ')',
+	'<': '>',
+}
+
+// scanOracleQuotedString scans an Oracle alternative quoting string literal.
+// See: https://docs.oracle.com/en/database/oracle/oracle-database/19/sqlrf/Literals.html#GUID-1824CBAA-6E16-4921-B2A6-112FB02248DA
+func (tkn *SQLTokenizer) scanOracleQuotedString() (TokenKind, []byte) {
+	// skip the prefix and the opening quote
+	for tkn.lastChar != '\'' {
+		tkn.advance()
+	}
+	tkn.advance()
+	delim := tkn.lastChar
+	if delim == EndChar || unicode.IsSpace(delim) {
+		tkn.setErr("invalid delimiter in quoted string")
+		return LexError, tkn.bytes()
+	}
+	if closing, ok := oracleQuoteDelimiters[delim]; ok {
+		delim = closing
+	}
+	tkn.advance()
+	var buf bytes.Buffer
+	for {
+		ch := tkn.lastChar
+		if ch == EndChar {
+			tkn.setErr("unexpected EOF in quoted string")
+			return LexError, buf.Bytes()
+		}
+		tkn.advance()
+		if ch == delim && tkn.lastChar == '\'' {
+			tkn.advance()
+			break
+		}
+		buf.WriteRune(ch)
+	}
+	return String, buf.Bytes()
+}
+
 func (tkn *SQLTokenizer) scanVariableIdentifier(prefix rune) (TokenKind, []byte) {
 	for tkn.advance(); tkn.lastChar != ')' && tkn.lastChar != EndChar; tkn.advance() {
 	}
@@ -772,6 +1042,16 @@ func (tkn *SQLTokenizer) bytes() []byte {
 	return ret
 }
 
+// peek returns the rune following tkn.lastChar without advancing, or EndChar at the end
+// of the query.
+func (tkn *SQLTokenizer) peek() rune {
+	ch, n := utf8.DecodeRune(tkn.buf[tkn.off:])
+	if ch == utf8.RuneError && n < 2 {
+		return EndChar
+	}
+	return ch
+}
+
 // Position exports the tokenizer's current position in the query
 func (tkn *SQLTokenizer) Position() int {
 	return tkn.pos
@@ -799,6 +1079,11 @@ func digitVal(ch rune) int {
 
 func isDigit(ch rune) bool { return '0' <= ch && ch <= '9' }
 
+// isMySQLCommentSpace reports whether ch may follow the -- sequence of a MySQL comment.
+func isMySQLCommentSpace(ch rune) bool {
+	return ch == EndChar || unicode.IsSpace(ch) || unicode.IsControl(ch)
+}
+
 // runeBytes converts the given rune to a slice of bytes.
 func runeBytes(r rune) []byte {
 	buf := make([]byte, utf8.UTFMax)
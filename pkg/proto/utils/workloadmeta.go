@@ -0,0 +1,90 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+// Workloadmeta2PbEvent helper to convert a workloadmeta event collected from
+// the given source to its protobuf representation.
+func Workloadmeta2PbEvent(event workloadmeta.Event, source workloadmeta.Source) (*pb.WorkloadmetaEvent, error) {
+	var eventType pb.WorkloadmetaEventType
+	switch event.Type {
+	case workloadmeta.EventTypeSet:
+		eventType = pb.WorkloadmetaEventType_SET
+	case workloadmeta.EventTypeUnset:
+		eventType = pb.WorkloadmetaEventType_UNSET
+	default:
+		return nil, fmt.Errorf("invalid event type %d", event.Type)
+	}
+
+	entity, err := json.Marshal(event.Entity)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.WorkloadmetaEvent{
+		Type:   eventType,
+		Source: string(source),
+		Kind:   string(event.Entity.GetID().Kind),
+		Entity: entity,
+	}, nil
+}
+
+// Pb2WorkloadmetaEvent helper to convert a protobuf workloadmeta event to the
+// collector event it represents. The entity of an unset event is an EntityID.
+func Pb2WorkloadmetaEvent(event *pb.WorkloadmetaEvent) (workloadmeta.CollectorEvent, error) {
+	var (
+		eventType workloadmeta.EventType
+		entity    workloadmeta.Entity
+	)
+
+	kind := workloadmeta.Kind(event.Kind)
+	switch event.Type {
+	case pb.WorkloadmetaEventType_SET:
+		eventType = workloadmeta.EventTypeSet
+		switch kind {
+		case workloadmeta.KindContainer:
+			entity = &workloadmeta.Container{}
+		case workloadmeta.KindKubernetesPod:
+			entity = &workloadmeta.KubernetesPod{}
+		case workloadmeta.KindECSTask:
+			entity = &workloadmeta.ECSTask{}
+		case workloadmeta.KindProcess:
+			entity = &workloadmeta.Process{}
+		default:
+			return workloadmeta.CollectorEvent{}, fmt.Errorf("unknown entity kind %q", event.Kind)
+		}
+	case pb.WorkloadmetaEventType_UNSET:
+		eventType = workloadmeta.EventTypeUnset
+		entity = &workloadmeta.EntityID{}
+	default:
+		return workloadmeta.CollectorEvent{}, fmt.Errorf("invalid event type %q", event.Type)
+	}
+
+	if err := json.Unmarshal(event.Entity, entity); err != nil {
+		return workloadmeta.CollectorEvent{}, fmt.Errorf("cannot decode %s entity: %w", event.Kind, err)
+	}
+
+	if id, ok := entity.(*workloadmeta.EntityID); ok {
+		entity = *id
+	}
+
+	if entity.GetID().Kind != kind {
+		return workloadmeta.CollectorEvent{}, fmt.Errorf("entity of kind %q in a %s event", entity.GetID().Kind, event.Kind)
+	}
+
+	return workloadmeta.CollectorEvent{
+		Type:   eventType,
+		Source: workloadmeta.Source(event.Source),
+		Entity: entity,
+	}, nil
+}
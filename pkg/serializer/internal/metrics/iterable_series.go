@@ -12,6 +12,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/serializer/marshaler"
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
 )
 
 // IterableSeries is a serializer for metrics.IterableSeries
@@ -49,7 +50,7 @@ func (series IterableSeries) DescribeCurrentItem() string {
 
 // MarshalSplitCompress uses the stream compressor to marshal and compress series payloads.
 // If a compressed payload is larger than the max, a new payload will be generated. This method returns a slice of
-// compressed protobuf marshaled MetricPayload objects.
-func (series IterableSeries) MarshalSplitCompress(bufferContext *marshaler.BufferContext) ([]*[]byte, error) {
-	return marshalSplitCompress(series, bufferContext)
+// compressed protobuf marshaled MetricPayload objects, compressed with the compressor strategy.
+func (series IterableSeries) MarshalSplitCompress(bufferContext *marshaler.BufferContext, strategy compression.Compressor) ([]*[]byte, error) {
+	return marshalSplitCompress(series, bufferContext, strategy)
 }
@@ -0,0 +1,304 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package metrics
+
+import (
+	"bytes"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/golang/snappy"
+	"github.com/richardartoul/molecule"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/quantile"
+	"github.com/DataDog/datadog-agent/pkg/tagset"
+)
+
+// RemoteWriteLabel is a label of a Prometheus remote-write time series
+type RemoteWriteLabel struct {
+	Name  string
+	Value string
+}
+
+// RemoteWriteSample is a sample of a Prometheus remote-write time series
+type RemoteWriteSample struct {
+	Value     float64
+	Timestamp int64 // in milliseconds
+}
+
+// RemoteWriteTimeSeries is a Prometheus remote-write time series. Its labels are sorted by
+// name and include the metric name as the `__name__` label.
+type RemoteWriteTimeSeries struct {
+	Labels  []RemoteWriteLabel
+	Samples []RemoteWriteSample
+}
+
+// RemoteWriteTimeSeries converts the series to remote-write time series. The metric names and the
+// tags are sanitized to match the Prometheus data model: `key:value` tags become `key="value"`
+// labels, tags without a value become `tag="true"` labels, and the values of the tags sharing
+// the same key are joined with commas. The remote-write format has no metric types, so all the
+// series, including the counts and rates which hold the value of a flush interval, are sent as
+// gauges.
+func (series Series) RemoteWriteTimeSeries() []RemoteWriteTimeSeries {
+	timeSeries := make([]RemoteWriteTimeSeries, 0, len(series))
+	for _, serie := range series {
+		if len(serie.Points) == 0 {
+			continue
+		}
+		ts := RemoteWriteTimeSeries{
+			Labels:  remoteWriteLabels(serie.Name, serie.Host, serie.Device, serie.Tags, nil),
+			Samples: make([]RemoteWriteSample, 0, len(serie.Points)),
+		}
+		for _, p := range serie.Points {
+			ts.Samples = append(ts.Samples, RemoteWriteSample{Value: p.Value, Timestamp: int64(p.Ts * 1000)})
+		}
+		timeSeries = append(timeSeries, ts)
+	}
+	return timeSeries
+}
+
+// sketchTotalsTTL is the time after which the totals of a sketch context which was not sent
+// anymore are forgotten, in seconds
+const sketchTotalsTTL = 3600
+
+// SketchTotals holds the cumulative sum and count of the sketches of each context, as the
+// `_sum` and `_count` time series of Prometheus summaries are cumulative while the sketches
+// only hold the values of a flush interval.
+type SketchTotals struct {
+	mu     sync.Mutex
+	totals map[string]*sketchTotal
+}
+
+type sketchTotal struct {
+	sum    float64
+	count  float64
+	lastTs int64 // in seconds
+}
+
+// NewSketchTotals returns empty SketchTotals
+func NewSketchTotals() *SketchTotals {
+	return &SketchTotals{totals: make(map[string]*sketchTotal)}
+}
+
+// RemoteWriteTimeSeries converts the sketches to remote-write time series shaped as Prometheus
+// summaries: a `<name>{quantile="q"}` time series for each quantile and the `<name>_sum` and
+// `<name>_count` time series. The sums and counts are accumulated in totals across calls, the
+// totals of the contexts not sent for an hour are reset.
+func (sl SketchSeriesList) RemoteWriteTimeSeries(quantiles []float64, totals *SketchTotals) []RemoteWriteTimeSeries {
+	totals.mu.Lock()
+	defer totals.mu.Unlock()
+
+	var timeSeries []RemoteWriteTimeSeries
+	var lastTs int64
+	config := quantile.Default()
+	for _, ss := range sl {
+		if len(ss.Points) == 0 {
+			continue
+		}
+		for _, q := range quantiles {
+			quantileLabel := []RemoteWriteLabel{{Name: "quantile", Value: strconv.FormatFloat(q, 'g', -1, 64)}}
+			timeSeries = append(timeSeries, sketchTimeSeries(ss, ss.Name, quantileLabel, func(s *quantile.Sketch) float64 {
+				return s.Quantile(config, q)
+			}))
+		}
+
+		sum := sketchTimeSeries(ss, ss.Name+"_sum", nil, func(s *quantile.Sketch) float64 { return s.Basic.Sum })
+		count := sketchTimeSeries(ss, ss.Name+"_count", nil, func(s *quantile.Sketch) float64 { return float64(s.Basic.Cnt) })
+		key := remoteWriteContextKey(sum.Labels)
+		total, ok := totals.totals[key]
+		if !ok {
+			total = &sketchTotal{}
+			totals.totals[key] = total
+		}
+		for i, p := range ss.Points {
+			total.sum += sum.Samples[i].Value
+			total.count += count.Samples[i].Value
+			sum.Samples[i].Value = total.sum
+			count.Samples[i].Value = total.count
+			if p.Ts > total.lastTs {
+				total.lastTs = p.Ts
+			}
+		}
+		if total.lastTs > lastTs {
+			lastTs = total.lastTs
+		}
+		timeSeries = append(timeSeries, sum, count)
+	}
+
+	for key, total := range totals.totals {
+		if lastTs-total.lastTs >= sketchTotalsTTL {
+			delete(totals.totals, key)
+		}
+	}
+	return timeSeries
+}
+
+// remoteWriteContextKey returns a key identifying the time series having the given labels
+func remoteWriteContextKey(labels []RemoteWriteLabel) string {
+	var b strings.Builder
+	for _, l := range labels {
+		b.WriteString(l.Name)
+		b.WriteByte('=')
+		b.WriteString(l.Value)
+		b.WriteByte(0)
+	}
+	return b.String()
+}
+
+func sketchTimeSeries(ss metrics.SketchSeries, name string, extra []RemoteWriteLabel, value func(*quantile.Sketch) float64) RemoteWriteTimeSeries {
+	ts := RemoteWriteTimeSeries{
+		Labels:  remoteWriteLabels(name, ss.Host, "", ss.Tags, extra),
+		Samples: make([]RemoteWriteSample, 0, len(ss.Points)),
+	}
+	for _, p := range ss.Points {
+		ts.Samples = append(ts.Samples, RemoteWriteSample{Value: value(p.Sketch), Timestamp: p.Ts * 1000})
+	}
+	return ts
+}
+
+func remoteWriteLabels(name, host, device string, tags tagset.CompositeTags, extra []RemoteWriteLabel) []RemoteWriteLabel {
+	values := make(map[string][]string)
+	add := func(name, value string) {
+		name = sanitizeLabelName(name)
+		for _, v := range values[name] {
+			if v == value {
+				return
+			}
+		}
+		values[name] = append(values[name], value)
+	}
+	tags.ForEach(func(tag string) {
+		if i := strings.IndexByte(tag, ':'); i > 0 {
+			add(tag[:i], tag[i+1:])
+		} else {
+			add(tag, "true")
+		}
+	})
+	if host != "" {
+		if _, ok := values["host"]; !ok {
+			values["host"] = []string{host}
+		}
+	}
+	if device != "" {
+		if _, ok := values["device"]; !ok {
+			values["device"] = []string{device}
+		}
+	}
+	for _, l := range extra {
+		values[l.Name] = []string{l.Value}
+	}
+
+	labels := make([]RemoteWriteLabel, 0, len(values)+1)
+	labels = append(labels, RemoteWriteLabel{Name: "__name__", Value: sanitizeMetricName(name)})
+	for name, v := range values {
+		sort.Strings(v)
+		labels = append(labels, RemoteWriteLabel{Name: name, Value: strings.Join(v, ",")})
+	}
+	sort.Slice(labels, func(i, j int) bool { return labels[i].Name < labels[j].Name })
+	return labels
+}
+
+// sanitizeMetricName returns name with the characters not allowed in a Prometheus metric name
+// replaced by underscores, for instance `system.load.1` becomes `system_load_1`.
+func sanitizeMetricName(name string) string {
+	return sanitizeName(name, true)
+}
+
+// sanitizeLabelName returns name with the characters not allowed in a Prometheus label name
+// replaced by underscores. The names reserved by Prometheus, starting with `__`, are prefixed.
+func sanitizeLabelName(name string) string {
+	name = sanitizeName(name, false)
+	if strings.HasPrefix(name, "__") {
+		name = "tag" + name
+	}
+	return name
+}
+
+func sanitizeName(name string, allowColons bool) string {
+	if name == "" {
+		return "_"
+	}
+	b := []byte(name)
+	for i, c := range b {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '_':
+		case c >= '0' && c <= '9' && i > 0:
+		case c == ':' && allowColons:
+		default:
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
+
+// MarshalRemoteWrite marshals the time series into snappy compressed Prometheus remote-write
+// WriteRequest payloads of at most maxSeriesPerPayload time series each.
+func MarshalRemoteWrite(timeSeries []RemoteWriteTimeSeries, maxSeriesPerPayload int) ([]*[]byte, error) {
+	// constants for the protobuf data we will be writing, taken from WriteRequest in
+	// https://github.com/prometheus/prometheus/blob/main/prompb/remote.proto and
+	// https://github.com/prometheus/prometheus/blob/main/prompb/types.proto
+	const writeRequestTimeSeries = 1
+	const timeSeriesLabels = 1
+	const timeSeriesSamples = 2
+	const labelName = 1
+	const labelValue = 2
+	const sampleValue = 1
+	const sampleTimestamp = 2
+
+	if maxSeriesPerPayload <= 0 {
+		maxSeriesPerPayload = len(timeSeries)
+	}
+
+	var payloads []*[]byte
+	buf := bytes.NewBuffer(nil)
+	ps := molecule.NewProtoStream(buf)
+	for start := 0; start < len(timeSeries); start += maxSeriesPerPayload {
+		end := start + maxSeriesPerPayload
+		if end > len(timeSeries) {
+			end = len(timeSeries)
+		}
+
+		buf.Reset()
+		for _, ts := range timeSeries[start:end] {
+			err := ps.Embedded(writeRequestTimeSeries, func(ps *molecule.ProtoStream) error {
+				for _, l := range ts.Labels {
+					err := ps.Embedded(timeSeriesLabels, func(ps *molecule.ProtoStream) error {
+						if err := ps.String(labelName, l.Name); err != nil {
+							return err
+						}
+						return ps.String(labelValue, l.Value)
+					})
+					if err != nil {
+						return err
+					}
+				}
+				for _, s := range ts.Samples {
+					err := ps.Embedded(timeSeriesSamples, func(ps *molecule.ProtoStream) error {
+						if err := ps.Double(sampleValue, s.Value); err != nil {
+							return err
+						}
+						return ps.Int64(sampleTimestamp, s.Timestamp)
+					})
+					if err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		// remote-write payloads are compressed with the block format of snappy
+		payload := snappy.Encode(nil, buf.Bytes())
+		payloads = append(payloads, &payload)
+	}
+	return payloads, nil
+}
@@ -0,0 +1,193 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build test
+// +build test
+
+package metrics
+
+import (
+	"math"
+	"testing"
+
+	"github.com/golang/snappy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/tagset"
+)
+
+func TestSeriesRemoteWriteTimeSeries(t *testing.T) {
+	series := Series{
+		{
+			Name:   "system.load.1",
+			Points: []metrics.Point{{Ts: 1636629071, Value: 0.5}, {Ts: 1636629086.5, Value: 0.75}},
+			Tags:   tagset.CompositeTagsFromSlice([]string{"env:prod", "team:a", "team:b", "standalone", "__meta:x", "kube-namespace:default"}),
+			Host:   "my-host",
+			Device: "sda",
+		},
+		{
+			Name: "no.points",
+		},
+	}
+
+	timeSeries := series.RemoteWriteTimeSeries()
+	require.Len(t, timeSeries, 1)
+	assert.Equal(t, []RemoteWriteLabel{
+		{Name: "__name__", Value: "system_load_1"},
+		{Name: "device", Value: "sda"},
+		{Name: "env", Value: "prod"},
+		{Name: "host", Value: "my-host"},
+		{Name: "kube_namespace", Value: "default"},
+		{Name: "standalone", Value: "true"},
+		{Name: "tag__meta", Value: "x"},
+		{Name: "team", Value: "a,b"},
+	}, timeSeries[0].Labels)
+	assert.Equal(t, []RemoteWriteSample{
+		{Value: 0.5, Timestamp: 1636629071000},
+		{Value: 0.75, Timestamp: 1636629086500},
+	}, timeSeries[0].Samples)
+}
+
+func TestSketchSeriesListRemoteWriteTimeSeries(t *testing.T) {
+	sl := SketchSeriesList{Makeseries(0)}
+
+	totals := NewSketchTotals()
+	timeSeries := sl.RemoteWriteTimeSeries([]float64{0.5, 0.99}, totals)
+	require.Len(t, timeSeries, 4)
+
+	names := make([]string, 0, len(timeSeries))
+	for _, ts := range timeSeries {
+		names = append(names, ts.Labels[0].Value)
+		assert.Len(t, ts.Samples, len(sl[0].Points))
+		assert.Contains(t, ts.Labels, RemoteWriteLabel{Name: "host", Value: "host.0"})
+		assert.Contains(t, ts.Labels, RemoteWriteLabel{Name: "a", Value: "0"})
+	}
+	assert.Equal(t, []string{"name_0", "name_0", "name_0_sum", "name_0_count"}, names)
+	assert.Contains(t, timeSeries[0].Labels, RemoteWriteLabel{Name: "quantile", Value: "0.5"})
+	assert.Contains(t, timeSeries[1].Labels, RemoteWriteLabel{Name: "quantile", Value: "0.99"})
+
+	// the sums and counts are cumulative
+	var sum, count float64
+	for i, p := range sl[0].Points {
+		sum += p.Sketch.Basic.Sum
+		count += float64(p.Sketch.Basic.Cnt)
+		assert.Equal(t, RemoteWriteSample{Value: sum, Timestamp: p.Ts * 1000}, timeSeries[2].Samples[i])
+		assert.Equal(t, count, timeSeries[3].Samples[i].Value)
+	}
+
+	// across flushes
+	first := sl[0].Points[0].Sketch.Basic
+	timeSeries = sl.RemoteWriteTimeSeries(nil, totals)
+	require.Len(t, timeSeries, 2)
+	assert.Equal(t, sum+first.Sum, timeSeries[0].Samples[0].Value)
+	assert.Equal(t, count+float64(first.Cnt), timeSeries[1].Samples[0].Value)
+
+	// the totals of the contexts which are not sent anymore expire
+	later := Makeseries(1)
+	for i := range later.Points {
+		later.Points[i].Ts += sketchTotalsTTL
+	}
+	SketchSeriesList{later}.RemoteWriteTimeSeries(nil, totals)
+	assert.Len(t, totals.totals, 1)
+	timeSeries = sl.RemoteWriteTimeSeries(nil, totals)
+	assert.Equal(t, first.Sum, timeSeries[0].Samples[0].Value)
+}
+
+func TestMarshalRemoteWrite(t *testing.T) {
+	timeSeries := []RemoteWriteTimeSeries{
+		{
+			Labels:  []RemoteWriteLabel{{Name: "__name__", Value: "a"}, {Name: "host", Value: "h"}},
+			Samples: []RemoteWriteSample{{Value: 1.5, Timestamp: 1000}},
+		},
+		{
+			Labels:  []RemoteWriteLabel{{Name: "__name__", Value: "b"}},
+			Samples: []RemoteWriteSample{{Value: 2, Timestamp: 2000}, {Value: 3, Timestamp: 3000}},
+		},
+		{
+			Labels:  []RemoteWriteLabel{{Name: "__name__", Value: "c"}},
+			Samples: []RemoteWriteSample{{Value: 4, Timestamp: 4000}},
+		},
+	}
+
+	payloads, err := MarshalRemoteWrite(timeSeries, 2)
+	require.NoError(t, err)
+	require.Len(t, payloads, 2)
+
+	var decoded []RemoteWriteTimeSeries
+	for _, payload := range payloads {
+		raw, err := snappy.Decode(nil, *payload)
+		require.NoError(t, err)
+		decoded = append(decoded, decodeWriteRequest(t, raw)...)
+	}
+	assert.Equal(t, timeSeries, decoded)
+}
+
+// decodeWriteRequest decodes the time series of a remote-write WriteRequest
+func decodeWriteRequest(t *testing.T, b []byte) []RemoteWriteTimeSeries {
+	var timeSeries []RemoteWriteTimeSeries
+	for _, field := range decodeMessage(t, b) {
+		require.EqualValues(t, 1, field.num)
+		var ts RemoteWriteTimeSeries
+		for _, tsField := range decodeMessage(t, field.bytes) {
+			switch tsField.num {
+			case 1:
+				var l RemoteWriteLabel
+				for _, lField := range decodeMessage(t, tsField.bytes) {
+					if lField.num == 1 {
+						l.Name = string(lField.bytes)
+					} else {
+						l.Value = string(lField.bytes)
+					}
+				}
+				ts.Labels = append(ts.Labels, l)
+			case 2:
+				var s RemoteWriteSample
+				for _, sField := range decodeMessage(t, tsField.bytes) {
+					if sField.num == 1 {
+						s.Value = math.Float64frombits(sField.varint)
+					} else {
+						s.Timestamp = int64(sField.varint)
+					}
+				}
+				ts.Samples = append(ts.Samples, s)
+			}
+		}
+		timeSeries = append(timeSeries, ts)
+	}
+	return timeSeries
+}
+
+type protoField struct {
+	num    protowire.Number
+	bytes  []byte
+	varint uint64
+}
+
+func decodeMessage(t *testing.T, b []byte) []protoField {
+	var fields []protoField
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		require.GreaterOrEqual(t, n, 0)
+		b = b[n:]
+		f := protoField{num: num}
+		switch typ {
+		case protowire.BytesType:
+			f.bytes, n = protowire.ConsumeBytes(b)
+		case protowire.VarintType:
+			f.varint, n = protowire.ConsumeVarint(b)
+		case protowire.Fixed64Type:
+			f.varint, n = protowire.ConsumeFixed64(b)
+		default:
+			t.Fatalf("unexpected wire type %v", typ)
+		}
+		require.GreaterOrEqual(t, n, 0)
+		b = b[n:]
+		fields = append(fields, f)
+	}
+	return fields
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/serializer/internal/stream"
 	"github.com/DataDog/datadog-agent/pkg/serializer/marshaler"
 	"github.com/DataDog/datadog-agent/pkg/telemetry"
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
 )
 
 var (
@@ -104,9 +105,9 @@ func (series Series) SplitPayload(times int) ([]marshaler.AbstractMarshaler, err
 
 // MarshalSplitCompress uses the stream compressor to marshal and compress series payloads.
 // If a compressed payload is larger than the max, a new payload will be generated. This method returns a slice of
-// compressed protobuf marshaled MetricPayload objects.
-func (series Series) MarshalSplitCompress(bufferContext *marshaler.BufferContext) ([]*[]byte, error) {
-	return marshalSplitCompress(newSerieSliceIterator(series), bufferContext)
+// compressed protobuf marshaled MetricPayload objects, compressed with the compressor strategy.
+func (series Series) MarshalSplitCompress(bufferContext *marshaler.BufferContext, strategy compression.Compressor) ([]*[]byte, error) {
+	return marshalSplitCompress(newSerieSliceIterator(series), bufferContext, strategy)
 }
 
 type serieIterator interface {
@@ -140,7 +141,7 @@ func (s *serieSliceIterator) Current() *metrics.Serie {
 // MarshalSplitCompress uses the stream compressor to marshal and compress series payloads.
 // If a compressed payload is larger than the max, a new payload will be generated. This method returns a slice of
 // compressed protobuf marshaled MetricPayload objects.
-func marshalSplitCompress(iterator serieIterator, bufferContext *marshaler.BufferContext) ([]*[]byte, error) {
+func marshalSplitCompress(iterator serieIterator, bufferContext *marshaler.BufferContext, strategy compression.Compressor) ([]*[]byte, error) {
 	var err error
 	var compressor *stream.Compressor
 	buf := bufferContext.PrecompressionBuf
@@ -176,7 +177,7 @@ func marshalSplitCompress(iterator serieIterator, bufferContext *marshaler.Buffe
 		bufferContext.CompressorInput.Reset()
 		bufferContext.CompressorOutput.Reset()
 
-		compressor, err = stream.NewCompressor(bufferContext.CompressorInput, bufferContext.CompressorOutput, []byte{}, []byte{}, []byte{})
+		compressor, err = stream.NewCompressor(bufferContext.CompressorInput, bufferContext.CompressorOutput, []byte{}, []byte{}, []byte{}, strategy)
 		if err != nil {
 			return err
 		}
@@ -25,6 +25,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/serializer/internal/stream"
 	"github.com/DataDog/datadog-agent/pkg/serializer/marshaler"
 	"github.com/DataDog/datadog-agent/pkg/tagset"
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -375,7 +376,7 @@ func makeSeries(numItems, numPoints int) Series {
 func TestMarshalSplitCompress(t *testing.T) {
 	series := makeSeries(10000, 50)
 
-	payloads, err := series.MarshalSplitCompress(marshaler.DefaultBufferContext())
+	payloads, err := series.MarshalSplitCompress(marshaler.DefaultBufferContext(), compression.Default())
 	require.NoError(t, err)
 	// check that we got multiple payloads, so splitting occurred
 	require.Greater(t, len(payloads), 1)
@@ -396,7 +397,7 @@ func TestMarshalSplitCompressPointsLimit(t *testing.T) {
 	// ten series, each with 50 points, so two should fit in each payload
 	series := makeSeries(10, 50)
 
-	payloads, err := series.MarshalSplitCompress(marshaler.DefaultBufferContext())
+	payloads, err := series.MarshalSplitCompress(marshaler.DefaultBufferContext(), compression.Default())
 	require.NoError(t, err)
 	require.Equal(t, 5, len(payloads))
 }
@@ -408,7 +409,7 @@ func TestMarshalSplitCompressPointsLimitTooBig(t *testing.T) {
 	mockConfig.Set("serializer_max_series_points_per_payload", 1)
 
 	series := makeSeries(1, 2)
-	payloads, err := series.MarshalSplitCompress(marshaler.DefaultBufferContext())
+	payloads, err := series.MarshalSplitCompress(marshaler.DefaultBufferContext(), compression.Default())
 	require.NoError(t, err)
 	require.Len(t, payloads, 0)
 }
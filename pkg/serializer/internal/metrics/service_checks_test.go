@@ -21,6 +21,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/serializer/internal/stream"
 	"github.com/DataDog/datadog-agent/pkg/serializer/marshaler"
 	"github.com/DataDog/datadog-agent/pkg/serializer/split"
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
 )
 
 func TestMarshalJSONServiceChecks(t *testing.T) {
@@ -193,7 +194,7 @@ func benchmarkPayloadsServiceCheck(b *testing.B, numberOfItem int) {
 	b.ResetTimer()
 
 	for n := 0; n < b.N; n++ {
-		split.Payloads(serviceChecks, true, split.JSONMarshalFct)
+		split.Payloads(serviceChecks, true, split.JSONMarshalFct, compression.Default())
 	}
 }
 
@@ -19,6 +19,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/serializer/marshaler"
 	"github.com/DataDog/datadog-agent/pkg/tagset"
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -103,7 +104,7 @@ func TestSketchSeriesMarshalSplitCompressEmpty(t *testing.T) {
 
 	sl := SketchSeriesList{}
 	payload, _ := sl.Marshal()
-	payloads, err := sl.MarshalSplitCompress(marshaler.DefaultBufferContext())
+	payloads, err := sl.MarshalSplitCompress(marshaler.DefaultBufferContext(), compression.Default())
 
 	assert.Nil(t, err)
 
@@ -134,7 +135,7 @@ func TestSketchSeriesMarshalSplitCompressItemTooBigIsDropped(t *testing.T) {
 		Interval: 0,
 	}
 
-	payloads, err := sl.MarshalSplitCompress(marshaler.DefaultBufferContext())
+	payloads, err := sl.MarshalSplitCompress(marshaler.DefaultBufferContext(), compression.Default())
 
 	assert.Nil(t, err)
 
@@ -160,7 +161,7 @@ func TestSketchSeriesMarshalSplitCompress(t *testing.T) {
 	}
 
 	payload, _ := sl.Marshal()
-	payloads, err := sl.MarshalSplitCompress(marshaler.DefaultBufferContext())
+	payloads, err := sl.MarshalSplitCompress(marshaler.DefaultBufferContext(), compression.Default())
 	require.NoError(t, err)
 
 	reader := bytes.NewReader(*payloads[0])
@@ -205,7 +206,7 @@ func TestSketchSeriesMarshalSplitCompressSplit(t *testing.T) {
 		sl[i] = Makeseries(i)
 	}
 
-	payloads, err := sl.MarshalSplitCompress(marshaler.DefaultBufferContext())
+	payloads, err := sl.MarshalSplitCompress(marshaler.DefaultBufferContext(), compression.Default())
 	assert.Nil(t, err)
 
 	recoveredSketches := []gogen.SketchPayload{}
@@ -10,7 +10,6 @@ package stream
 
 import (
 	"bytes"
-	"compress/zlib"
 	"errors"
 	"expvar"
 
@@ -64,7 +63,8 @@ func init() {
 type Compressor struct {
 	input               *bytes.Buffer // temporary buffer for data that has not been compressed yet
 	compressed          *bytes.Buffer // output buffer containing the compressed payload
-	zipper              *zlib.Writer
+	strategy            compression.Compressor
+	zipper              compression.StreamCompressor
 	header              []byte // json header to print at the beginning of the payload
 	footer              []byte // json footer to append at the end of the payload
 	uncompressedWritten int    // uncompressed bytes written
@@ -77,7 +77,8 @@ type Compressor struct {
 	separator           []byte
 }
 
-func NewCompressor(input, output *bytes.Buffer, header, footer []byte, separator []byte) (*Compressor, error) {
+// NewCompressor returns a new Compressor compressing the items with the compressor strategy
+func NewCompressor(input, output *bytes.Buffer, header, footer []byte, separator []byte, strategy compression.Compressor) (*Compressor, error) {
 	// the backend accepts payloads up to 3MB compressed / 50MB uncompressed but
 	// prefers small uncompressed payloads of ~4MB
 	maxPayloadSize := config.Datadog.GetInt("serializer_max_payload_size")
@@ -91,11 +92,12 @@ func NewCompressor(input, output *bytes.Buffer, header, footer []byte, separator
 		maxPayloadSize:      maxPayloadSize,
 		maxUncompressedSize: maxUncompressedSize,
 		maxUnzippedItemSize: maxPayloadSize - len(footer) - len(header),
-		maxZippedItemSize:   maxUncompressedSize - compression.CompressBound(len(footer)+len(header)),
+		maxZippedItemSize:   maxUncompressedSize - strategy.CompressBound(len(footer)+len(header)),
 		separator:           separator,
+		strategy:            strategy,
 	}
 
-	c.zipper = zlib.NewWriter(c.compressed)
+	c.zipper = strategy.NewStreamCompressor(c.compressed)
 	n, err := c.zipper.Write(header)
 	c.uncompressedWritten += n
 
@@ -107,7 +109,7 @@ func NewCompressor(input, output *bytes.Buffer, header, footer []byte, separator
 // that could actually fit after compression. That said it is probably impossible
 // to have a 2MB+ item that is valid for the backend.
 func (c *Compressor) checkItemSize(data []byte) bool {
-	return len(data) < c.maxUnzippedItemSize && compression.CompressBound(len(data)) < c.maxZippedItemSize
+	return len(data) < c.maxUnzippedItemSize && c.strategy.CompressBound(len(data)) < c.maxZippedItemSize
 }
 
 // hasRoomForItem checks if the current payload has enough room to store the given item
@@ -116,7 +118,7 @@ func (c *Compressor) hasRoomForItem(item []byte) bool {
 	if !c.firstItem {
 		uncompressedDataSize += len(c.separator)
 	}
-	return compression.CompressBound(uncompressedDataSize) <= c.remainingSpace() && c.uncompressedWritten+uncompressedDataSize <= c.maxUncompressedSize
+	return c.strategy.CompressBound(uncompressedDataSize) <= c.remainingSpace() && c.uncompressedWritten+uncompressedDataSize <= c.maxUncompressedSize
 }
 
 // pack flushes the temporary uncompressed buffer input to the compression writer
@@ -189,7 +191,7 @@ func (c *Compressor) Close() ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
-	// Add zlib footer and close
+	// Add the compression footer and close
 	err = c.zipper.Close()
 	if err != nil {
 		return nil, err
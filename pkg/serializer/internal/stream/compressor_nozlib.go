@@ -12,6 +12,8 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
 )
 
 const (
@@ -31,7 +33,7 @@ var (
 type Compressor struct{}
 
 // NewCompressor not implemented
-func NewCompressor(input, output *bytes.Buffer, header, footer []byte, separator []byte) (*Compressor, error) {
+func NewCompressor(input, output *bytes.Buffer, header, footer []byte, separator []byte, strategy compression.Compressor) (*Compressor, error) {
 	return nil, fmt.Errorf("not implemented")
 }
 
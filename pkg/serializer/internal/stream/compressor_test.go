@@ -18,6 +18,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/serializer/marshaler"
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
 )
 
 var (
@@ -51,7 +52,7 @@ func payloadToString(payload []byte) string {
 }
 
 func TestCompressorSimple(t *testing.T) {
-	c, err := NewCompressor(&bytes.Buffer{}, &bytes.Buffer{}, []byte("{["), []byte("]}"), []byte(","))
+	c, err := NewCompressor(&bytes.Buffer{}, &bytes.Buffer{}, []byte("{["), []byte("]}"), []byte(","), compression.Default())
 	require.NoError(t, err)
 
 	for i := 0; i < 5; i++ {
@@ -130,3 +131,24 @@ func TestLockedCompressorProducesSamePayloads(t *testing.T) {
 
 	require.Equal(t, payloadToString(*payloads1[0]), payloadToString(*payloads2[0]))
 }
+
+func TestCompressorStrategies(t *testing.T) {
+	for _, kind := range []string{compression.NoneKind, compression.ZlibKind, compression.GzipKind} {
+		t.Run(kind, func(t *testing.T) {
+			strategy, err := compression.NewCompressor(kind, 0)
+			require.NoError(t, err)
+			c, err := NewCompressor(&bytes.Buffer{}, &bytes.Buffer{}, []byte("{["), []byte("]}"), []byte(","), strategy)
+			require.NoError(t, err)
+
+			for i := 0; i < 5; i++ {
+				require.NoError(t, c.AddItem([]byte("A")))
+			}
+
+			p, err := c.Close()
+			require.NoError(t, err)
+			p, err = strategy.Decompress(p)
+			require.NoError(t, err)
+			require.Equal(t, "{[A,A,A,A,A]}", string(p))
+		})
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/forwarder"
 	"github.com/DataDog/datadog-agent/pkg/serializer/marshaler"
 	"github.com/DataDog/datadog-agent/pkg/telemetry"
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
@@ -100,13 +101,15 @@ const (
 // Build serializes a metadata payload and sends it to the forwarder
 func (b *JSONPayloadBuilder) Build(m marshaler.StreamJSONMarshaler) (forwarder.Payloads, error) {
 	adapter := marshaler.NewIterableStreamJSONMarshalerAdapter(m)
-	return b.BuildWithOnErrItemTooBigPolicy(adapter, DropItemOnErrItemTooBig)
+	return b.BuildWithOnErrItemTooBigPolicy(adapter, DropItemOnErrItemTooBig, compression.Default())
 }
 
-// BuildWithOnErrItemTooBigPolicy serializes a metadata payload and sends it to the forwarder
+// BuildWithOnErrItemTooBigPolicy serializes a metadata payload, compressed with the compressor
+// strategy, and sends it to the forwarder
 func (b *JSONPayloadBuilder) BuildWithOnErrItemTooBigPolicy(
 	m marshaler.IterableStreamJSONMarshaler,
-	policy OnErrItemTooBigPolicy) (forwarder.Payloads, error) {
+	policy OnErrItemTooBigPolicy,
+	strategy compression.Compressor) (forwarder.Payloads, error) {
 	defer m.IterationStopped()
 	var input, output *bytes.Buffer
 	if b.shareAndLockBuffers {
@@ -151,7 +154,7 @@ func (b *JSONPayloadBuilder) BuildWithOnErrItemTooBigPolicy(
 		return nil, err
 	}
 
-	compressor, err := NewCompressor(input, output, header.Bytes(), footer.Bytes(), []byte(","))
+	compressor, err := NewCompressor(input, output, header.Bytes(), footer.Bytes(), []byte(","), strategy)
 	if err != nil {
 		return nil, err
 	}
@@ -182,7 +185,7 @@ func (b *JSONPayloadBuilder) BuildWithOnErrItemTooBigPolicy(
 			payloads = append(payloads, &payload)
 			input.Reset()
 			output.Reset()
-			compressor, err = NewCompressor(input, output, header.Bytes(), footer.Bytes(), []byte(","))
+			compressor, err = NewCompressor(input, output, header.Bytes(), footer.Bytes(), []byte(","), strategy)
 			if err != nil {
 				return nil, err
 			}
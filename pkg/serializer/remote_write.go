@@ -0,0 +1,75 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package serializer
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/forwarder"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	metricsserializer "github.com/DataDog/datadog-agent/pkg/serializer/internal/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+var remoteWriteExtraHeaders http.Header
+
+func init() {
+	remoteWriteExtraHeaders = make(http.Header)
+	remoteWriteExtraHeaders.Set("Content-Type", protobufContentType)
+	remoteWriteExtraHeaders.Set("Content-Encoding", "snappy")
+	remoteWriteExtraHeaders.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+}
+
+// RemoteWriteForwarder is the interface of the forwarder sending Prometheus remote-write payloads
+type RemoteWriteForwarder interface {
+	SubmitRemoteWrite(payload forwarder.Payloads, extra http.Header) error
+}
+
+// RemoteWriteSerializer serializes series and sketches to the Prometheus remote-write format
+// and routes the payloads to a remote-write forwarder
+type RemoteWriteSerializer struct {
+	forwarder           RemoteWriteForwarder
+	maxSeriesPerPayload int
+	sketchQuantiles     []float64
+	sketchTotals        *metricsserializer.SketchTotals
+}
+
+// NewRemoteWriteSerializer returns a new RemoteWriteSerializer initialized
+func NewRemoteWriteSerializer(forwarder RemoteWriteForwarder) *RemoteWriteSerializer {
+	quantiles, err := config.Datadog.GetFloat64SliceE("prometheus_remote_write.sketch_quantiles")
+	if err != nil {
+		log.Errorf("Invalid prometheus_remote_write.sketch_quantiles, only the sum and count of the sketches will be sent: %v", err)
+	}
+	return &RemoteWriteSerializer{
+		forwarder:           forwarder,
+		maxSeriesPerPayload: config.Datadog.GetInt("prometheus_remote_write.max_series_per_payload"),
+		sketchQuantiles:     quantiles,
+		sketchTotals:        metricsserializer.NewSketchTotals(),
+	}
+}
+
+// SendSeries serializes a list of series and sends the payloads to the remote-write forwarder
+func (s *RemoteWriteSerializer) SendSeries(series metrics.Series) error {
+	return s.send(metricsserializer.Series(series).RemoteWriteTimeSeries(), "series")
+}
+
+// SendSketch serializes a list of sketches as summaries and sends the payloads to the remote-write forwarder
+func (s *RemoteWriteSerializer) SendSketch(sketches metrics.SketchSeriesList) error {
+	return s.send(metricsserializer.SketchSeriesList(sketches).RemoteWriteTimeSeries(s.sketchQuantiles, s.sketchTotals), "sketch")
+}
+
+func (s *RemoteWriteSerializer) send(timeSeries []metricsserializer.RemoteWriteTimeSeries, kind string) error {
+	if len(timeSeries) == 0 {
+		return nil
+	}
+	payloads, err := metricsserializer.MarshalRemoteWrite(timeSeries, s.maxSeriesPerPayload)
+	if err != nil {
+		return fmt.Errorf("dropping remote-write %s payload: %s", kind, err)
+	}
+	return s.forwarder.SubmitRemoteWrite(payloads, remoteWriteExtraHeaders)
+}
@@ -0,0 +1,52 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build test
+// +build test
+
+package serializer
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/forwarder"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+type remoteWriteForwarderMock struct {
+	payloads forwarder.Payloads
+	headers  http.Header
+}
+
+func (f *remoteWriteForwarderMock) SubmitRemoteWrite(payload forwarder.Payloads, extra http.Header) error {
+	f.payloads = append(f.payloads, payload...)
+	f.headers = extra
+	return nil
+}
+
+func TestRemoteWriteSerializerSendSeries(t *testing.T) {
+	f := &remoteWriteForwarderMock{}
+	s := NewRemoteWriteSerializer(f)
+
+	require.NoError(t, s.SendSeries(metrics.Series{}))
+	assert.Empty(t, f.payloads)
+
+	err := s.SendSeries(metrics.Series{{Name: "system.load.1", Points: []metrics.Point{{Ts: 1, Value: 1}}}})
+	require.NoError(t, err)
+	assert.Len(t, f.payloads, 1)
+	assert.Equal(t, "snappy", f.headers.Get("Content-Encoding"))
+	assert.Equal(t, protobufContentType, f.headers.Get("Content-Type"))
+	assert.Equal(t, "0.1.0", f.headers.Get("X-Prometheus-Remote-Write-Version"))
+}
+
+func TestRemoteWriteSerializerSketchQuantiles(t *testing.T) {
+	s := NewRemoteWriteSerializer(&remoteWriteForwarderMock{})
+	assert.Equal(t, []float64{0.5, 0.75, 0.9, 0.95, 0.99}, s.sketchQuantiles)
+	assert.Equal(t, 2000, s.maxSeriesPerPayload)
+}
@@ -83,6 +83,61 @@ func initExtraHeaders() {
 	}
 }
 
+// payloadCompression is the compression strategy of a kind of payload along with the extra
+// headers advertising it to the intake
+type payloadCompression struct {
+	compressor      compression.Compressor
+	jsonHeaders     http.Header
+	protobufHeaders http.Header
+}
+
+func newPayloadCompression(compressor compression.Compressor) payloadCompression {
+	return payloadCompression{
+		compressor:      compressor,
+		jsonHeaders:     withContentEncoding(jsonExtraHeaders, compressor),
+		protobufHeaders: withContentEncoding(protobufExtraHeaders, compressor),
+	}
+}
+
+func withContentEncoding(base http.Header, compressor compression.Compressor) http.Header {
+	h := base.Clone()
+	if encoding := compressor.ContentEncoding(); encoding != "" {
+		h.Set("Content-Encoding", encoding)
+	}
+	return h
+}
+
+// newPayloadCompressions returns the compression of each kind of payload, as configured by
+// `serializer_compression_per_payload`, falling back to `serializer_compression_kind` and
+// `serializer_compression_level`. Invalid settings fall back to the compression the agent was built with.
+func newPayloadCompressions(kinds ...string) map[string]payloadCompression {
+	defaultCompressor, err := compression.NewCompressor(
+		config.Datadog.GetString("serializer_compression_kind"),
+		config.Datadog.GetInt("serializer_compression_level"))
+	if err != nil {
+		log.Errorf("Invalid serializer compression, using %s: %s", compression.DefaultKind, err)
+		defaultCompressor = compression.Default()
+	}
+
+	overrides := map[string]compression.Settings{}
+	if err := config.Datadog.UnmarshalKey("serializer_compression_per_payload", &overrides); err != nil {
+		log.Errorf("Could not parse serializer_compression_per_payload: %s", err)
+	}
+
+	compressions := make(map[string]payloadCompression, len(kinds))
+	for _, kind := range kinds {
+		compressor := defaultCompressor
+		if settings, ok := overrides[kind]; ok {
+			if compressor, err = compression.NewCompressor(settings.Kind, settings.Level); err != nil {
+				log.Errorf("Invalid compression of the %s payloads, using %s: %s", kind, compression.DefaultKind, err)
+				compressor = compression.Default()
+			}
+		}
+		compressions[kind] = newPayloadCompression(compressor)
+	}
+	return compressions
+}
+
 // MetricSerializer represents the interface of method needed by the aggregator to serialize its data
 type MetricSerializer interface {
 	SendEvents(e metrics.Events) error
@@ -122,6 +177,13 @@ type Serializer struct {
 	enableServiceChecksJSONStream bool
 	enableEventsJSONStream        bool
 	enableSketchProtobufStream    bool
+
+	// Compression of each kind of payload
+	seriesCompression        payloadCompression
+	sketchesCompression      payloadCompression
+	eventsCompression        payloadCompression
+	serviceChecksCompression payloadCompression
+	metadataCompression      payloadCompression
 }
 
 // NewSerializer returns a new Serializer initialized
@@ -142,6 +204,13 @@ func NewSerializer(forwarder forwarder.Forwarder, orchestratorForwarder, contlcy
 		enableSketchProtobufStream:    stream.Available && config.Datadog.GetBool("enable_sketch_stream_payload_serialization"),
 	}
 
+	compressions := newPayloadCompressions("series", "sketches", "events", "service_checks", "metadata")
+	s.seriesCompression = compressions["series"]
+	s.sketchesCompression = compressions["sketches"]
+	s.eventsCompression = compressions["events"]
+	s.serviceChecksCompression = compressions["service_checks"]
+	s.metadataCompression = compressions["metadata"]
+
 	if !s.enableEvents {
 		log.Warn("event payloads are disabled: all events will be dropped")
 	}
@@ -165,37 +234,38 @@ func (s Serializer) serializePayload(
 	jsonMarshaler marshaler.JSONMarshaler,
 	protoMarshaler marshaler.ProtoMarshaler,
 	compress bool,
-	useV1API bool) (forwarder.Payloads, http.Header, error) {
+	useV1API bool,
+	pc payloadCompression) (forwarder.Payloads, http.Header, error) {
 	if useV1API {
-		return s.serializePayloadJSON(jsonMarshaler, compress)
+		return s.serializePayloadJSON(jsonMarshaler, compress, pc)
 	}
-	return s.serializePayloadProto(protoMarshaler, compress)
+	return s.serializePayloadProto(protoMarshaler, compress, pc)
 }
 
-func (s Serializer) serializePayloadJSON(payload marshaler.JSONMarshaler, compress bool) (forwarder.Payloads, http.Header, error) {
+func (s Serializer) serializePayloadJSON(payload marshaler.JSONMarshaler, compress bool, pc payloadCompression) (forwarder.Payloads, http.Header, error) {
 	var extraHeaders http.Header
 
 	if compress {
-		extraHeaders = jsonExtraHeadersWithCompression
+		extraHeaders = pc.jsonHeaders
 	} else {
 		extraHeaders = jsonExtraHeaders
 	}
 
-	return s.serializePayloadInternal(payload, compress, extraHeaders, split.JSONMarshalFct)
+	return s.serializePayloadInternal(payload, compress, extraHeaders, split.JSONMarshalFct, pc.compressor)
 }
 
-func (s Serializer) serializePayloadProto(payload marshaler.ProtoMarshaler, compress bool) (forwarder.Payloads, http.Header, error) {
+func (s Serializer) serializePayloadProto(payload marshaler.ProtoMarshaler, compress bool, pc payloadCompression) (forwarder.Payloads, http.Header, error) {
 	var extraHeaders http.Header
 	if compress {
-		extraHeaders = protobufExtraHeadersWithCompression
+		extraHeaders = pc.protobufHeaders
 	} else {
 		extraHeaders = protobufExtraHeaders
 	}
-	return s.serializePayloadInternal(payload, compress, extraHeaders, split.ProtoMarshalFct)
+	return s.serializePayloadInternal(payload, compress, extraHeaders, split.ProtoMarshalFct, pc.compressor)
 }
 
-func (s Serializer) serializePayloadInternal(payload marshaler.AbstractMarshaler, compress bool, extraHeaders http.Header, marshalFct split.MarshalFct) (forwarder.Payloads, http.Header, error) {
-	payloads, err := split.Payloads(payload, compress, marshalFct)
+func (s Serializer) serializePayloadInternal(payload marshaler.AbstractMarshaler, compress bool, extraHeaders http.Header, marshalFct split.MarshalFct, compressor compression.Compressor) (forwarder.Payloads, http.Header, error) {
+	payloads, err := split.Payloads(payload, compress, marshalFct, compressor)
 
 	if err != nil {
 		return nil, nil, fmt.Errorf("could not split payload into small enough chunks: %s", err)
@@ -204,14 +274,14 @@ func (s Serializer) serializePayloadInternal(payload marshaler.AbstractMarshaler
 	return payloads, extraHeaders, nil
 }
 
-func (s Serializer) serializeStreamablePayload(payload marshaler.StreamJSONMarshaler, policy stream.OnErrItemTooBigPolicy) (forwarder.Payloads, http.Header, error) {
+func (s Serializer) serializeStreamablePayload(payload marshaler.StreamJSONMarshaler, policy stream.OnErrItemTooBigPolicy, pc payloadCompression) (forwarder.Payloads, http.Header, error) {
 	adapter := marshaler.NewIterableStreamJSONMarshalerAdapter(payload)
-	return s.serializeIterableStreamablePayload(adapter, policy)
+	return s.serializeIterableStreamablePayload(adapter, policy, pc)
 }
 
-func (s Serializer) serializeIterableStreamablePayload(payload marshaler.IterableStreamJSONMarshaler, policy stream.OnErrItemTooBigPolicy) (forwarder.Payloads, http.Header, error) {
-	payloads, err := s.seriesJSONPayloadBuilder.BuildWithOnErrItemTooBigPolicy(payload, policy)
-	return payloads, jsonExtraHeadersWithCompression, err
+func (s Serializer) serializeIterableStreamablePayload(payload marshaler.IterableStreamJSONMarshaler, policy stream.OnErrItemTooBigPolicy, pc payloadCompression) (forwarder.Payloads, http.Header, error) {
+	payloads, err := s.seriesJSONPayloadBuilder.BuildWithOnErrItemTooBigPolicy(payload, policy, pc.compressor)
+	return payloads, pc.jsonHeaders, err
 }
 
 // As events are gathered by SourceType, the serialization logic is more complex than for the other serializations.
@@ -226,7 +296,7 @@ func (s Serializer) serializeIterableStreamablePayload(payload marshaler.Iterabl
 func (s Serializer) serializeEventsStreamJSONMarshalerPayload(
 	eventsSerializer metricsserializer.Events, useV1API bool) (forwarder.Payloads, http.Header, error) {
 	marshaler := eventsSerializer.CreateSingleMarshaler()
-	eventPayloads, extraHeaders, err := s.serializeStreamablePayload(marshaler, stream.FailOnErrItemTooBig)
+	eventPayloads, extraHeaders, err := s.serializeStreamablePayload(marshaler, stream.FailOnErrItemTooBig, s.eventsCompression)
 
 	if err == stream.ErrItemTooBig {
 		expvarsSendEventsErrItemTooBigs.Add(1)
@@ -234,12 +304,12 @@ func (s Serializer) serializeEventsStreamJSONMarshalerPayload(
 		// Do not use CreateMarshalersBySourceType when there are too many source types (Performance issue).
 		if marshaler.Len() > maxItemCountForCreateMarshalersBySourceType {
 			expvarsSendEventsErrItemTooBigsFallback.Add(1)
-			eventPayloads, extraHeaders, err = s.serializePayload(eventsSerializer, eventsSerializer, true, useV1API)
+			eventPayloads, extraHeaders, err = s.serializePayload(eventsSerializer, eventsSerializer, true, useV1API, s.eventsCompression)
 		} else {
 			eventPayloads = nil
 			for _, v := range eventsSerializer.CreateMarshalersBySourceType() {
 				var eventPayloadsForSourceType forwarder.Payloads
-				eventPayloadsForSourceType, extraHeaders, err = s.serializeStreamablePayload(v, stream.DropItemOnErrItemTooBig)
+				eventPayloadsForSourceType, extraHeaders, err = s.serializeStreamablePayload(v, stream.DropItemOnErrItemTooBig, s.eventsCompression)
 				if err != nil {
 					return nil, nil, err
 				}
@@ -265,7 +335,7 @@ func (s *Serializer) SendEvents(events metrics.Events) error {
 	if s.enableEventsJSONStream {
 		eventPayloads, extraHeaders, err = s.serializeEventsStreamJSONMarshalerPayload(eventsSerializer, true)
 	} else {
-		eventPayloads, extraHeaders, err = s.serializePayload(eventsSerializer, eventsSerializer, true, true)
+		eventPayloads, extraHeaders, err = s.serializePayload(eventsSerializer, eventsSerializer, true, true, s.eventsCompression)
 	}
 	if err != nil {
 		return fmt.Errorf("dropping event payload: %s", err)
@@ -287,9 +357,9 @@ func (s *Serializer) SendServiceChecks(serviceChecks metrics.ServiceChecks) erro
 	var err error
 
 	if s.enableServiceChecksJSONStream {
-		serviceCheckPayloads, extraHeaders, err = s.serializeStreamablePayload(serviceChecksSerializer, stream.DropItemOnErrItemTooBig)
+		serviceCheckPayloads, extraHeaders, err = s.serializeStreamablePayload(serviceChecksSerializer, stream.DropItemOnErrItemTooBig, s.serviceChecksCompression)
 	} else {
-		serviceCheckPayloads, extraHeaders, err = s.serializePayloadJSON(serviceChecksSerializer, true)
+		serviceCheckPayloads, extraHeaders, err = s.serializePayloadJSON(serviceChecksSerializer, true, s.serviceChecksCompression)
 	}
 	if err != nil {
 		return fmt.Errorf("dropping service check payload: %s", err)
@@ -313,10 +383,10 @@ func (s *Serializer) SendIterableSeries(series *metrics.IterableSeries) error {
 	var err error
 
 	if useV1API {
-		seriesPayloads, extraHeaders, err = s.serializeIterableStreamablePayload(seriesSerializer, stream.DropItemOnErrItemTooBig)
+		seriesPayloads, extraHeaders, err = s.serializeIterableStreamablePayload(seriesSerializer, stream.DropItemOnErrItemTooBig, s.seriesCompression)
 	} else {
-		seriesPayloads, err = seriesSerializer.MarshalSplitCompress(marshaler.DefaultBufferContext())
-		extraHeaders = protobufExtraHeadersWithCompression
+		seriesPayloads, err = seriesSerializer.MarshalSplitCompress(marshaler.DefaultBufferContext(), s.seriesCompression.compressor)
+		extraHeaders = s.seriesCompression.protobufHeaders
 	}
 
 	if err != nil {
@@ -349,12 +419,12 @@ func (s *Serializer) SendSeries(series metrics.Series) error {
 	var err error
 
 	if useV1API && s.enableJSONStream {
-		seriesPayloads, extraHeaders, err = s.serializeStreamablePayload(seriesSerializer, stream.DropItemOnErrItemTooBig)
+		seriesPayloads, extraHeaders, err = s.serializeStreamablePayload(seriesSerializer, stream.DropItemOnErrItemTooBig, s.seriesCompression)
 	} else if useV1API && !s.enableJSONStream {
-		seriesPayloads, extraHeaders, err = s.serializePayloadJSON(seriesSerializer, true)
+		seriesPayloads, extraHeaders, err = s.serializePayloadJSON(seriesSerializer, true, s.seriesCompression)
 	} else {
-		seriesPayloads, err = seriesSerializer.MarshalSplitCompress(marshaler.DefaultBufferContext())
-		extraHeaders = protobufExtraHeadersWithCompression
+		seriesPayloads, err = seriesSerializer.MarshalSplitCompress(marshaler.DefaultBufferContext(), s.seriesCompression.compressor)
+		extraHeaders = s.seriesCompression.protobufHeaders
 	}
 
 	if err != nil {
@@ -375,16 +445,16 @@ func (s *Serializer) SendSketch(sketches metrics.SketchSeriesList) error {
 	}
 	sketchesSerializer := metricsserializer.SketchSeriesList(sketches)
 	if s.enableSketchProtobufStream {
-		payloads, err := sketchesSerializer.MarshalSplitCompress(marshaler.DefaultBufferContext())
+		payloads, err := sketchesSerializer.MarshalSplitCompress(marshaler.DefaultBufferContext(), s.sketchesCompression.compressor)
 		if err == nil {
-			return s.Forwarder.SubmitSketchSeries(payloads, protobufExtraHeadersWithCompression)
+			return s.Forwarder.SubmitSketchSeries(payloads, s.sketchesCompression.protobufHeaders)
 		}
 		log.Warnf("Error: %v trying to stream compress SketchSeriesList - falling back to split/compress method", err)
 	}
 
 	compress := true
 	useV1API := false // Sketches only have a v2 endpoint
-	splitSketches, extraHeaders, err := s.serializePayload(sketchesSerializer, sketchesSerializer, compress, useV1API)
+	splitSketches, extraHeaders, err := s.serializePayload(sketchesSerializer, sketchesSerializer, compress, useV1API, s.sketchesCompression)
 	if err != nil {
 		return fmt.Errorf("dropping sketch payload: %s", err)
 	}
@@ -408,7 +478,7 @@ func (s *Serializer) SendAgentchecksMetadata(m marshaler.JSONMarshaler) error {
 }
 
 func (s *Serializer) sendMetadata(m marshaler.JSONMarshaler, submit func(payload forwarder.Payloads, extra http.Header) error) error {
-	mustSplit, compressedPayload, payload, err := split.CheckSizeAndSerialize(m, true, split.JSONMarshalFct)
+	mustSplit, compressedPayload, payload, err := split.CheckSizeAndSerialize(m, true, split.JSONMarshalFct, s.metadataCompression.compressor)
 	if err != nil {
 		return fmt.Errorf("could not determine size of metadata payload: %s", err)
 	}
@@ -419,7 +489,7 @@ func (s *Serializer) sendMetadata(m marshaler.JSONMarshaler, submit func(payload
 		return fmt.Errorf("metadata payload was too big to send (%d bytes compressed, %d bytes uncompressed), metadata payloads cannot be split", len(compressedPayload), len(payload))
 	}
 
-	if err := submit(forwarder.Payloads{&compressedPayload}, jsonExtraHeadersWithCompression); err != nil {
+	if err := submit(forwarder.Payloads{&compressedPayload}, s.metadataCompression.jsonHeaders); err != nil {
 		return err
 	}
 
@@ -439,11 +509,11 @@ func (s *Serializer) SendProcessesMetadata(data interface{}) error {
 	if err != nil {
 		return fmt.Errorf("could not serialize processes metadata payload: %s", err)
 	}
-	compressedPayload, err := compression.Compress(nil, payload)
+	compressedPayload, err := s.metadataCompression.compressor.Compress(payload)
 	if err != nil {
 		return fmt.Errorf("could not compress processes metadata payload: %s", err)
 	}
-	if err := s.Forwarder.SubmitV1Intake(forwarder.Payloads{&compressedPayload}, jsonExtraHeadersWithCompression); err != nil {
+	if err := s.Forwarder.SubmitV1Intake(forwarder.Payloads{&compressedPayload}, s.metadataCompression.jsonHeaders); err != nil {
 		return err
 	}
 
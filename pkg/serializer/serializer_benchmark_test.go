@@ -19,6 +19,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/serializer/internal/stream"
 	"github.com/DataDog/datadog-agent/pkg/serializer/split"
 	"github.com/DataDog/datadog-agent/pkg/tagset"
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
 )
 
 func buildSeries(numberOfSeries int) metricsserializer.Series {
@@ -58,7 +59,7 @@ func benchmarkSplit(b *testing.B, numberOfSeries int) {
 	b.ResetTimer()
 
 	for n := 0; n < b.N; n++ {
-		results, _ = split.Payloads(series, true, split.JSONMarshalFct)
+		results, _ = split.Payloads(series, true, split.JSONMarshalFct, compression.Default())
 	}
 }
 
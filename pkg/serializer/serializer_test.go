@@ -111,9 +111,9 @@ type testPayload struct{}
 
 func (p *testPayload) MarshalJSON() ([]byte, error) { return jsonString, nil }
 func (p *testPayload) Marshal() ([]byte, error)     { return protobufString, nil }
-func (p *testPayload) MarshalSplitCompress(bufferContext *marshaler.BufferContext) ([]*[]byte, error) {
+func (p *testPayload) MarshalSplitCompress(bufferContext *marshaler.BufferContext, strategy compression.Compressor) ([]*[]byte, error) {
 	payloads := forwarder.Payloads{}
-	payload, err := compression.Compress(nil, protobufString)
+	payload, err := strategy.Compress(protobufString)
 	if err != nil {
 		return nil, err
 	}
@@ -350,6 +350,35 @@ func TestSendProcessesMetadata(t *testing.T) {
 	require.NotNil(t, err)
 }
 
+func TestSendMetadataWithCompressionPerPayload(t *testing.T) {
+	mockConfig := config.Mock()
+	mockConfig.Set("serializer_compression_per_payload", map[string]interface{}{
+		"metadata": map[string]interface{}{"kind": "gzip", "level": 9},
+	})
+	defer mockConfig.Set("serializer_compression_per_payload", nil)
+
+	gzipCompressor, err := compression.NewCompressor(compression.GzipKind, 0)
+	require.NoError(t, err)
+	matcher := mock.MatchedBy(func(payloads forwarder.Payloads) bool {
+		if len(payloads) != 1 {
+			return false
+		}
+		decompressed, err := gzipCompressor.Decompress(*payloads[0])
+		return err == nil && string(decompressed) == string(jsonString)
+	})
+	expectedHeaders := make(http.Header)
+	expectedHeaders.Set("Content-Type", jsonContentType)
+	expectedHeaders.Set("Content-Encoding", "gzip")
+
+	f := &forwarder.MockedForwarder{}
+	f.On("SubmitMetadata", matcher, expectedHeaders).Return(nil).Times(1)
+
+	s := NewSerializer(f, nil, nil)
+	err = s.SendMetadata(&testPayload{})
+	require.Nil(t, err)
+	f.AssertExpectations(t)
+}
+
 func TestSendWithDisabledKind(t *testing.T) {
 	mockConfig := config.Mock()
 
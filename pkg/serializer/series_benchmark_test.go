@@ -18,6 +18,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/serializer/internal/stream"
 	"github.com/DataDog/datadog-agent/pkg/serializer/marshaler"
 	"github.com/DataDog/datadog-agent/pkg/tagset"
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
 	"github.com/stretchr/testify/require"
 )
 
@@ -75,7 +76,7 @@ func BenchmarkSeries(b *testing.B) {
 	}
 	bufferContext := marshaler.DefaultBufferContext()
 	pb := func(series metricsserializer.Series) (forwarder.Payloads, error) {
-		return series.MarshalSplitCompress(bufferContext)
+		return series.MarshalSplitCompress(bufferContext, compression.Default())
 	}
 
 	payloadBuilder := stream.NewJSONPayloadBuilder(true)
@@ -133,6 +133,8 @@ func (c *WorkloadMetaCollector) processEvents(evBundle workloadmeta.EventBundle)
 				tagInfos = append(tagInfos, c.handleKubePod(ev)...)
 			case workloadmeta.KindECSTask:
 				tagInfos = append(tagInfos, c.handleECSTask(ev)...)
+			case workloadmeta.KindProcess:
+				tagInfos = append(tagInfos, c.handleProcess(ev)...)
 			default:
 				log.Errorf("cannot handle event for entity %q with kind %q", entityID.ID, entityID.Kind)
 			}
@@ -382,6 +384,30 @@ func (c *WorkloadMetaCollector) handleECSTask(ev workloadmeta.Event) []*TagInfo
 	return tagInfos
 }
 
+func (c *WorkloadMetaCollector) handleProcess(ev workloadmeta.Event) []*TagInfo {
+	process := ev.Entity.(*workloadmeta.Process)
+
+	tags := utils.NewTagList()
+	tags.AddLow("process_name", process.Name)
+	tags.AddLow("process_language", string(process.Language))
+	tags.AddHigh("container_id", process.ContainerID)
+
+	// standard tags from environment
+	c.extractFromMapWithFn(process.EnvVars, standardEnvKeys, tags.AddStandard)
+
+	low, orch, high, standard := tags.Compute()
+	return []*TagInfo{
+		{
+			Source:               processSource,
+			Entity:               buildTaggerEntityID(process.EntityID),
+			HighCardTags:         high,
+			OrchestratorCardTags: orch,
+			LowCardTags:          low,
+			StandardTags:         standard,
+		},
+	}
+}
+
 func (c *WorkloadMetaCollector) handleGardenContainer(container *workloadmeta.Container) []*TagInfo {
 	return []*TagInfo{
 		{
@@ -593,6 +619,8 @@ func buildTaggerEntityID(entityID workloadmeta.EntityID) string {
 		return kubelet.PodUIDToTaggerEntityName(entityID.ID)
 	case workloadmeta.KindECSTask:
 		return fmt.Sprintf("ecs_task://%s", entityID.ID)
+	case workloadmeta.KindProcess:
+		return fmt.Sprintf("process://%s", entityID.ID)
 	default:
 		log.Errorf("can't recognize entity %q with kind %q; trying %s://%s as tagger entity",
 			entityID.ID, entityID.Kind, entityID.ID, entityID.Kind)
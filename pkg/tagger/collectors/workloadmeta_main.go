@@ -25,6 +25,7 @@ const (
 	podSource       = workloadmetaCollectorName + "-" + string(workloadmeta.KindKubernetesPod)
 	taskSource      = workloadmetaCollectorName + "-" + string(workloadmeta.KindECSTask)
 	containerSource = workloadmetaCollectorName + "-" + string(workloadmeta.KindContainer)
+	processSource   = workloadmetaCollectorName + "-" + string(workloadmeta.KindProcess)
 )
 
 // CollectorPriorities holds collector priorities
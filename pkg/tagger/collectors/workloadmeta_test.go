@@ -905,6 +905,88 @@ func TestHandleContainer(t *testing.T) {
 	}
 }
 
+func TestHandleProcess(t *testing.T) {
+	entityID := workloadmeta.EntityID{
+		Kind: workloadmeta.KindProcess,
+		ID:   "1234",
+	}
+
+	taggerEntityID := fmt.Sprintf("process://%s", entityID.ID)
+
+	tests := []struct {
+		name     string
+		process  workloadmeta.Process
+		expected []*TagInfo
+	}{
+		{
+			name: "host process",
+			process: workloadmeta.Process{
+				EntityID: entityID,
+				Pid:      1234,
+				Name:     "java",
+				Language: workloadmeta.ProcessLanguageJava,
+				EnvVars: map[string]string{
+					"DD_ENV":     "production",
+					"DD_SERVICE": "billing",
+					"DD_VERSION": "1.2.3",
+				},
+			},
+			expected: []*TagInfo{
+				{
+					Source:               processSource,
+					Entity:               taggerEntityID,
+					HighCardTags:         []string{},
+					OrchestratorCardTags: []string{},
+					LowCardTags: []string{
+						"process_name:java",
+						"process_language:java",
+						"env:production",
+						"service:billing",
+						"version:1.2.3",
+					},
+					StandardTags: []string{
+						"env:production",
+						"service:billing",
+						"version:1.2.3",
+					},
+				},
+			},
+		},
+		{
+			name: "containerized process",
+			process: workloadmeta.Process{
+				EntityID:    entityID,
+				Pid:         1234,
+				Name:        "nginx",
+				ContainerID: "foobarquux",
+			},
+			expected: []*TagInfo{
+				{
+					Source:               processSource,
+					Entity:               taggerEntityID,
+					HighCardTags:         []string{"container_id:foobarquux"},
+					OrchestratorCardTags: []string{},
+					LowCardTags:          []string{"process_name:nginx"},
+					StandardTags:         []string{},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			collector := &WorkloadMetaCollector{}
+
+			actual := collector.handleProcess(workloadmeta.Event{
+				Type:   workloadmeta.EventTypeSet,
+				Entity: &tt.process,
+			})
+
+			assertTagInfoListEqual(t, tt.expected, actual)
+		})
+	}
+}
+
 func TestHandleDelete(t *testing.T) {
 	const (
 		podName       = "datadog-agent-foobar"
@@ -42,10 +42,12 @@ type Agent struct {
 	ClientStatsAggregator *stats.ClientStatsAggregator
 	Blacklister           *filters.Blacklister
 	Replacer              *filters.Replacer
+	SpanRules             *filters.SpanRules
 	PrioritySampler       *sampler.PrioritySampler
 	ErrorsSampler         *sampler.ErrorsSampler
 	RareSampler           *sampler.RareSampler
 	NoPrioritySampler     *sampler.NoPrioritySampler
+	TailSampler           *sampler.TailSampler // nil when tail-based sampling is disabled
 	EventProcessor        *event.Processor
 	TraceWriter           *writer.TraceWriter
 	StatsWriter           *writer.StatsWriter
@@ -84,6 +86,7 @@ func NewAgent(ctx context.Context, conf *config.AgentConfig) *Agent {
 		ClientStatsAggregator: stats.NewClientStatsAggregator(conf, statsChan),
 		Blacklister:           filters.NewBlacklister(conf.Ignore["resource"]),
 		Replacer:              filters.NewReplacer(conf.ReplaceTags),
+		SpanRules:             filters.NewSpanRules(),
 		PrioritySampler:       sampler.NewPrioritySampler(conf, dynConf),
 		ErrorsSampler:         sampler.NewErrorsSampler(conf),
 		RareSampler:           sampler.NewRareSampler(),
@@ -97,6 +100,17 @@ func NewAgent(ctx context.Context, conf *config.AgentConfig) *Agent {
 		conf:                  conf,
 		ctx:                   ctx,
 	}
+	if err := agnt.SpanRules.Reload(conf.SpanRules); err != nil {
+		log.Errorf("Span rules disabled: %v", err)
+	}
+	if conf.TailSampling != nil && conf.TailSampling.Enabled {
+		tailSampler, err := sampler.NewTailSampler(conf.TailSampling, agnt.writeTailDecision)
+		if err != nil {
+			log.Errorf("Tail-based sampling disabled: %v", err)
+		} else {
+			agnt.TailSampler = tailSampler
+		}
+	}
 	agnt.Receiver = api.NewHTTPReceiver(conf, dynConf, in, agnt)
 	agnt.OTLPReceiver = api.NewOTLPReceiver(in, conf)
 	return agnt
@@ -116,6 +130,9 @@ func (a *Agent) Run() {
 	} {
 		starter.Start()
 	}
+	if a.TailSampler != nil {
+		a.TailSampler.Start()
+	}
 
 	go a.TraceWriter.Run()
 	go a.StatsWriter.Run()
@@ -166,6 +183,10 @@ func (a *Agent) loop() {
 			if err := a.Receiver.Stop(); err != nil {
 				log.Error(err)
 			}
+			if a.TailSampler != nil {
+				// flush the buffered traces before stopping the writers
+				a.TailSampler.Stop()
+			}
 			for _, stopper := range []interface{ Stop() }{
 				a.Concentrator,
 				a.ClientStatsAggregator,
@@ -257,7 +278,29 @@ func (a *Agent) Process(p *api.Payload) {
 				traceutil.UpdateTracerTopLevel(span)
 			}
 		}
+		if !p.ClientComputedTopLevel {
+			// Figure out the top-level spans now as it involves modifying the Metrics map
+			// which is not thread-safe while samplers and Concentrator might modify it too.
+			// It is done before applying the span rules so that they can match on it.
+			traceutil.ComputeTopLevel(chunk.Spans)
+		}
 		a.Replacer.Replace(chunk.Spans)
+		res := a.SpanRules.Apply(chunk, root)
+		if res.DropTrace {
+			log.Debugf("Trace rejected by span rules. root: %v", root)
+			atomic.AddInt64(&ts.TracesFiltered, 1)
+			atomic.AddInt64(&ts.SpansFiltered, tracen)
+			p.RemoveChunk(i)
+			continue
+		}
+		atomic.AddInt64(&ts.SpansFiltered, int64(res.DroppedSpans))
+		if res.Keep {
+			chunk.Priority = int32(sampler.PriorityUserKeep)
+		}
+		if res.DroppedSpans > 0 && !p.ClientComputedTopLevel {
+			// the children of the dropped spans may have become top-level
+			traceutil.ComputeTopLevel(chunk.Spans)
+		}
 
 		{
 			// this section sets up any necessary tags on the root:
@@ -269,12 +312,6 @@ func (a *Agent) Process(p *api.Payload) {
 				sampler.SetPreSampleRate(root, rate)
 			}
 		}
-		if !p.ClientComputedTopLevel {
-			// Figure out the top-level spans now as it involves modifying the Metrics map
-			// which is not thread-safe while samplers and Concentrator might modify it too.
-			traceutil.ComputeTopLevel(chunk.Spans)
-		}
-
 		if p.TracerPayload.Hostname == "" {
 			// Older tracers set tracer hostname in the root span.
 			p.TracerPayload.Hostname = root.Meta[tagHostname]
@@ -298,6 +335,12 @@ func (a *Agent) Process(p *api.Payload) {
 			statsInput.Traces = append(statsInput.Traces, pt)
 		}
 
+		if a.TailSampler != nil {
+			a.tailSample(now, ts, p.TracerPayload, pt)
+			p.RemoveChunk(i)
+			continue
+		}
+
 		numEvents, keep, filteredChunk := a.sample(now, ts, pt)
 		if !keep {
 			if numEvents == 0 {
@@ -418,8 +461,15 @@ func (a *Agent) sample(now time.Time, ts *info.TagStats, pt traceutil.ProcessedT
 	}
 
 	sampled := a.runSamplers(now, pt, hasPriority)
+	numEvents, filteredChunk = a.extractEvents(ts, pt, sampled)
+
+	return numEvents, sampled, filteredChunk
+}
 
-	filteredChunk = pt.TraceChunk
+// extractEvents extracts the analyzed spans of pt. When pt was not sampled, the returned
+// chunk is a dropped copy of pt holding only the analyzed spans.
+func (a *Agent) extractEvents(ts *info.TagStats, pt traceutil.ProcessedTrace, sampled bool) (int64, *pb.TraceChunk) {
+	filteredChunk := pt.TraceChunk
 	if !sampled {
 		filteredChunk = new(pb.TraceChunk)
 		*filteredChunk = *pt.TraceChunk
@@ -430,7 +480,63 @@ func (a *Agent) sample(now time.Time, ts *info.TagStats, pt traceutil.ProcessedT
 	atomic.AddInt64(&ts.EventsExtracted, numExtracted)
 	atomic.AddInt64(&ts.EventsSampled, numEvents)
 
-	return numEvents, sampled, filteredChunk
+	return numEvents, filteredChunk
+}
+
+// tailSample runs the samplers on pt and buffers it in the TailSampler, along with the
+// fields of the payload it was received in, until the decision on its whole trace.
+func (a *Agent) tailSample(now time.Time, ts *info.TagStats, payload *pb.TracerPayload, pt traceutil.ProcessedTrace) {
+	numEvents, keep, filteredChunk := a.sample(now, ts, pt)
+	header := *payload
+	header.Chunks = nil
+	if priority, _ := sampler.GetSamplingPriority(pt.TraceChunk); priority < 0 {
+		// the trace was explicitly dropped by the user, no policy can keep it
+		// but its analyzed spans are still sent
+		numEvents, filteredChunk = a.extractEvents(ts, pt, false)
+		if numEvents > 0 {
+			a.writeTailDecision(false, []*sampler.TailChunk{{Chunk: pt.TraceChunk, Root: pt.Root, Payload: &header, Dropped: filteredChunk, Events: numEvents}})
+		}
+		return
+	}
+	chunk := &sampler.TailChunk{
+		Chunk:   pt.TraceChunk,
+		Root:    pt.Root,
+		Payload: &header,
+		Sampled: keep,
+		Events:  numEvents,
+	}
+	if !keep && numEvents > 0 {
+		chunk.Dropped = filteredChunk
+	}
+	a.TailSampler.Add(now, chunk)
+}
+
+// writeTailDecision sends the chunks of a trace decided on by the TailSampler to the TraceWriter.
+// The chunks of dropped traces are only sent when they hold analyzed spans.
+func (a *Agent) writeTailDecision(keep bool, chunks []*sampler.TailChunk) {
+	for _, c := range chunks {
+		chunk := c.Chunk
+		if !keep {
+			if c.Dropped == nil {
+				continue
+			}
+			chunk = c.Dropped
+		} else if priority, _ := sampler.GetSamplingPriority(chunk); !c.Sampled && priority <= 0 {
+			// the trace was kept by a tail sampling policy
+			chunk.Priority = int32(sampler.PriorityAutoKeep)
+		}
+		payload := *c.Payload
+		payload.Chunks = []*pb.TraceChunk{chunk}
+		ss := &writer.SampledChunks{
+			TracerPayload: &payload,
+			Size:          chunk.Msgsize(),
+			EventCount:    c.Events,
+		}
+		if !chunk.DroppedTrace {
+			ss.SpanCount = int64(len(chunk.Spans))
+		}
+		a.TraceWriter.In <- ss
+	}
 }
 
 // runSamplers runs all the agent's samplers on pt and returns the sampling decision
@@ -202,6 +202,68 @@ func TestProcess(t *testing.T) {
 		assert.Equal("unnamed_operation", span.Name)
 	})
 
+	t.Run("SpanRules", func(t *testing.T) {
+		cfg := config.New()
+		cfg.Endpoints[0].APIKey = "test"
+		cfg.SpanRules = []config.SpanRule{
+			{Match: `resource =~ "^GET /health"`, Action: "drop"},
+			{Match: `service == "cache"`, Action: "rename_resource", Resource: "GET"},
+			{Match: `meta["debug"] == "true"`, Action: "keep"},
+			{Match: `!metrics["_top_level"]`, Action: "add_tags", Tags: map[string]string{"child": "true"}},
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		agnt := NewAgent(ctx, cfg)
+		defer cancel()
+
+		now := time.Now()
+		newSpan := func(id, parent uint64, service, resource string) *pb.Span {
+			return &pb.Span{
+				TraceID:  1,
+				SpanID:   id,
+				ParentID: parent,
+				Service:  service,
+				Resource: resource,
+				Start:    now.Add(-time.Second).UnixNano(),
+				Duration: (500 * time.Millisecond).Nanoseconds(),
+			}
+		}
+		want := agnt.Receiver.Stats.GetTagStats(info.Tags{})
+		assert := assert.New(t)
+
+		debug := newSpan(3, 1, "web", "render")
+		debug.Meta = map[string]string{"debug": "true"}
+		chunk := testutil.TraceChunkWithSpans([]*pb.Span{
+			newSpan(1, 0, "web", "GET /users"),
+			newSpan(2, 1, "cache", "GET users:42"),
+			newSpan(4, 1, "web", "GET /health/db"),
+			debug,
+		})
+		chunk.Priority = int32(sampler.PriorityAutoDrop)
+		agnt.Process(&api.Payload{
+			TracerPayload: testutil.TracerPayloadWithChunks([]*pb.TraceChunk{
+				chunk,
+				testutil.TraceChunkWithSpan(newSpan(5, 0, "web", "GET /health")),
+			}),
+			Source: want,
+		})
+		assert.EqualValues(1, want.TracesFiltered)
+		assert.EqualValues(2, want.SpansFiltered)
+		select {
+		case ss := <-agnt.TraceWriter.In:
+			assert.Len(ss.TracerPayload.Chunks, 1)
+			got := ss.TracerPayload.Chunks[0]
+			assert.EqualValues(sampler.PriorityUserKeep, got.Priority)
+			assert.Len(got.Spans, 3)
+			assert.Equal("GET", got.Spans[1].Resource)
+			// the rules match on the top-level spans
+			assert.Equal("", got.Spans[0].Meta["child"])
+			assert.Equal("", got.Spans[1].Meta["child"])
+			assert.Equal("true", got.Spans[2].Meta["child"])
+		case <-time.After(2 * time.Second):
+			t.Fatal("timeout: Expected one valid trace, but none were received.")
+		}
+	})
+
 	t.Run("Stats/Priority", func(t *testing.T) {
 		cfg := config.New()
 		cfg.Endpoints[0].APIKey = "test"
@@ -892,6 +954,7 @@ func TestPartialSamplingFree(t *testing.T) {
 		Concentrator:      stats.NewConcentrator(cfg, statsChan, time.Now()),
 		Blacklister:       filters.NewBlacklister(cfg.Ignore["resource"]),
 		Replacer:          filters.NewReplacer(cfg.ReplaceTags),
+		SpanRules:         filters.NewSpanRules(),
 		NoPrioritySampler: sampler.NewNoPrioritySampler(cfg),
 		ErrorsSampler:     sampler.NewErrorsSampler(cfg),
 		PrioritySampler:   sampler.NewPrioritySampler(cfg, &sampler.DynamicConfig{}),
@@ -1494,3 +1557,75 @@ func TestSampleWithPriorityNone(t *testing.T) {
 	assert.True(t, keep) // Score Sampler should keep the trace.
 	assert.EqualValues(t, numEvents, 0)
 }
+
+func TestTailSampling(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cfg := config.New()
+	cfg.Endpoints[0].APIKey = "test"
+	cfg.DisableRareSampler = true
+	cfg.TailSampling = &config.TailSamplingConfig{
+		Enabled:             true,
+		DecisionWaitSeconds: 3600,
+		MaxSpans:            100,
+		Policies:            []config.TailSamplingPolicy{{Type: "tag", Tag: "customer.tier", Value: "gold"}},
+	}
+	agnt := NewAgent(ctx, cfg)
+	defer cancel()
+	require.NotNil(t, agnt.TailSampler)
+	agnt.TailSampler.Start()
+
+	root := &pb.Span{TraceID: 1, SpanID: 1, Service: "web", Name: "request", Start: time.Now().UnixNano(), Duration: 10}
+	child := &pb.Span{TraceID: 1, SpanID: 2, ParentID: 1, Service: "db", Name: "query", Start: time.Now().UnixNano(), Duration: 5,
+		Meta: map[string]string{"customer.tier": "gold"}}
+	dropped := &pb.Span{TraceID: 2, SpanID: 3, Service: "web", Name: "request", Start: time.Now().UnixNano(), Duration: 10}
+	for _, span := range []*pb.Span{root, child, dropped} {
+		agnt.Process(&api.Payload{
+			TracerPayload: testutil.TracerPayloadWithChunk(testutil.TraceChunkWithSpanAndPriority(span, 0)),
+			Source:        agnt.Receiver.Stats.GetTagStats(info.Tags{}),
+		})
+	}
+	// the chunks are buffered until the decision
+	assert.Len(t, agnt.TraceWriter.In, 0)
+
+	agnt.TailSampler.Stop()
+	require.Len(t, agnt.TraceWriter.In, 2)
+	for _, id := range []uint64{1, 2} {
+		ss := <-agnt.TraceWriter.In
+		require.Len(t, ss.TracerPayload.Chunks, 1)
+		chunk := ss.TracerPayload.Chunks[0]
+		assert.Equal(t, id, chunk.Spans[0].SpanID)
+		assert.EqualValues(t, sampler.PriorityAutoKeep, chunk.Priority)
+		assert.EqualValues(t, 1, ss.SpanCount)
+	}
+}
+
+func TestTailSamplingUserDropEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cfg := config.New()
+	cfg.Endpoints[0].APIKey = "test"
+	cfg.TailSampling = &config.TailSamplingConfig{
+		Enabled:             true,
+		DecisionWaitSeconds: 3600,
+		MaxSpans:            100,
+	}
+	agnt := NewAgent(ctx, cfg)
+	defer cancel()
+	agnt.TailSampler.Start()
+	defer agnt.TailSampler.Stop()
+
+	span := &pb.Span{TraceID: 1, SpanID: 1, Service: "web", Name: "request", Start: time.Now().UnixNano(), Duration: 10,
+		Metrics: map[string]float64{sampler.KeySamplingRateEventExtraction: 1}}
+	agnt.Process(&api.Payload{
+		TracerPayload: testutil.TracerPayloadWithChunk(testutil.TraceChunkWithSpanAndPriority(span, int32(sampler.PriorityUserDrop))),
+		Source:        agnt.Receiver.Stats.GetTagStats(info.Tags{}),
+	})
+
+	// the trace is not buffered but its analyzed spans are sent right away
+	require.Len(t, agnt.TraceWriter.In, 1)
+	ss := <-agnt.TraceWriter.In
+	require.Len(t, ss.TracerPayload.Chunks, 1)
+	chunk := ss.TracerPayload.Chunks[0]
+	assert.True(t, chunk.DroppedTrace)
+	assert.Len(t, chunk.Spans, 1)
+	assert.EqualValues(t, 1, ss.EventCount)
+}
@@ -22,6 +22,9 @@ const (
 	tagElasticBody      = "elasticsearch.body"
 	tagSQLQuery         = "sql.query"
 	tagHTTPURL          = "http.url"
+	tagGraphQLQuery     = "graphql.query"
+	tagGraphQLSource    = "graphql.source"
+	tagAWSPrefix        = "aws."
 )
 
 const (
@@ -35,7 +38,15 @@ func (a *Agent) obfuscateSpan(span *pb.Span) {
 		if span.Resource == "" {
 			return
 		}
-		oq, err := o.ObfuscateSQLString(span.Resource)
+		var (
+			oq  *obfuscate.ObfuscatedQuery
+			err error
+		)
+		if span.Type == "cassandra" {
+			oq, err = o.ObfuscateCQLString(span.Resource)
+		} else {
+			oq, err = o.ObfuscateSQLString(span.Resource)
+		}
 		if err != nil {
 			// we have an error, discard the SQL to avoid polluting user resources.
 			log.Debugf("Error parsing SQL query: %v. Resource: %q", err, span.Resource)
@@ -80,6 +91,10 @@ func (a *Agent) obfuscateSpan(span *pb.Span) {
 		if span.Meta == nil {
 			return
 		}
+		if span.Type == "http" {
+			// the AWS SDK integrations report HTTP client spans
+			a.obfuscateAWSTags(span)
+		}
 		v, ok := span.Meta[tagHTTPURL]
 		if !ok || v == "" {
 			return
@@ -97,6 +112,30 @@ func (a *Agent) obfuscateSpan(span *pb.Span) {
 			return
 		}
 		span.Meta[tagElasticBody] = o.ObfuscateElasticSearchString(v)
+	case "graphql":
+		if !a.conf.Obfuscation.GraphQL.Enabled {
+			return
+		}
+		span.Resource = o.ObfuscateGraphQLString(span.Resource)
+		for _, k := range []string{tagGraphQLQuery, tagGraphQLSource} {
+			if v, ok := span.Meta[k]; ok {
+				span.Meta[k] = o.ObfuscateGraphQLString(v)
+			}
+		}
+	case "aws":
+		a.obfuscateAWSTags(span)
+	}
+}
+
+// obfuscateAWSTags obfuscates the values of the "aws.*" tags of span, if enabled.
+func (a *Agent) obfuscateAWSTags(span *pb.Span) {
+	if !a.conf.Obfuscation.AWS.Enabled {
+		return
+	}
+	for k, v := range span.Meta {
+		if strings.HasPrefix(k, tagAWSPrefix) {
+			span.Meta[k] = a.obfuscator.ObfuscateAWSTag(k, v)
+		}
 	}
 }
 
@@ -104,7 +143,15 @@ func (a *Agent) obfuscateStatsGroup(b *pb.ClientGroupedStats) {
 	o := a.obfuscator
 	switch b.Type {
 	case "sql", "cassandra":
-		oq, err := o.ObfuscateSQLString(b.Resource)
+		var (
+			oq  *obfuscate.ObfuscatedQuery
+			err error
+		)
+		if b.Type == "cassandra" {
+			oq, err = o.ObfuscateCQLString(b.Resource)
+		} else {
+			oq, err = o.ObfuscateSQLString(b.Resource)
+		}
 		if err != nil {
 			log.Errorf("Error obfuscating stats group resource %q: %v", b.Resource, err)
 			b.Resource = textNonParsable
@@ -113,6 +160,10 @@ func (a *Agent) obfuscateStatsGroup(b *pb.ClientGroupedStats) {
 		}
 	case "redis":
 		b.Resource = o.QuantizeRedisString(b.Resource)
+	case "graphql":
+		if a.conf.Obfuscation.GraphQL.Enabled {
+			b.Resource = o.ObfuscateGraphQLString(b.Resource)
+		}
 	}
 }
 
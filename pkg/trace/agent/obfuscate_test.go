@@ -64,6 +64,8 @@ func TestObfuscateStatsGroup(t *testing.T) {
 		{statsGroup("sql", "SELECT 1 FROM db"), "SELECT ? FROM db"},
 		{statsGroup("sql", "SELECT 1\nFROM Blogs AS [b\nORDER BY [b]"), textNonParsable},
 		{statsGroup("redis", "ADD 1, 2"), "ADD"},
+		{statsGroup("cassandra", "SELECT * FROM users WHERE id = 5b6962dd-3f90-4c93-8f61-eabfa4a803e2"), "SELECT * FROM users WHERE id = ?"},
+		{statsGroup("graphql", "query { user(id: 4) { name } }"), "query { user(id: 4) { name } }"},
 		{statsGroup("other", "ADD 1, 2"), "ADD 1, 2"},
 	} {
 		agnt, stop := agentWithDefaults()
@@ -220,6 +222,54 @@ func TestObfuscateConfig(t *testing.T) {
 		"set key 0 0 0 noreply\r\nvalue",
 		&config.ObfuscationConfig{},
 	))
+
+	t.Run("graphql/enabled", testConfig(
+		"graphql",
+		"graphql.query",
+		`query { user(id: 4) { name } }`,
+		`query { user(id: ?) { name } }`,
+		&config.ObfuscationConfig{GraphQL: config.Enablable{Enabled: true}},
+	))
+
+	t.Run("graphql/disabled", testConfig(
+		"graphql",
+		"graphql.query",
+		`query { user(id: 4) { name } }`,
+		`query { user(id: 4) { name } }`,
+		&config.ObfuscationConfig{},
+	))
+
+	t.Run("aws/enabled", testConfig(
+		"aws",
+		"aws.request.body",
+		`{"Key": {"id": {"S": "1234"}}}`,
+		`{"Key":{"id":{"S":"?"}}}`,
+		&config.ObfuscationConfig{AWS: config.Enablable{Enabled: true}},
+	))
+
+	t.Run("aws/http", testConfig(
+		"http",
+		"aws.request.body",
+		`{"Key": {"id": {"S": "1234"}}}`,
+		`{"Key":{"id":{"S":"?"}}}`,
+		&config.ObfuscationConfig{AWS: config.Enablable{Enabled: true}},
+	))
+
+	t.Run("aws/operation", testConfig(
+		"aws",
+		"aws.operation",
+		"GetItem",
+		"GetItem",
+		&config.ObfuscationConfig{AWS: config.Enablable{Enabled: true}},
+	))
+
+	t.Run("aws/disabled", testConfig(
+		"aws",
+		"aws.request.body",
+		`{"Key": {"id": {"S": "1234"}}}`,
+		`{"Key": {"id": {"S": "1234"}}}`,
+		&config.ObfuscationConfig{},
+	))
 }
 
 func SQLSpan(query string) *pb.Span {
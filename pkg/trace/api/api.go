@@ -573,7 +573,11 @@ func (r *HTTPReceiver) handleTraces(v Version, w http.ResponseWriter, req *http.
 		ClientComputedStats:    req.Header.Get(headerComputedStats) != "",
 		ClientDroppedP0s:       droppedTracesFromHeader(req.Header, ts),
 	}
+	r.send(payload)
+}
 
+// send sends payload down the out channel, without blocking.
+func (r *HTTPReceiver) send(payload *Payload) {
 	select {
 	case r.out <- payload:
 		// ok
@@ -89,6 +89,16 @@ var endpoints = []Endpoint{
 		Pattern: "/v0.7/traces",
 		Handler: func(r *HTTPReceiver) http.Handler { return r.handleWithVersion(V07, r.handleTraces) },
 	},
+	{
+		Pattern: "/api/v2/spans",
+		Handler: func(r *HTTPReceiver) http.Handler { return r.handleTranslatedTraces(vZipkinV2, decodeZipkin) },
+		Hidden:  true,
+	},
+	{
+		Pattern: "/api/traces",
+		Handler: func(r *HTTPReceiver) http.Handler { return r.handleTranslatedTraces(vJaegerThrift, decodeJaeger) },
+		Hidden:  true,
+	},
 	{
 		Pattern: "/profiling/v1/input",
 		Handler: func(r *HTTPReceiver) http.Handler { return r.profileProxyHandler() },
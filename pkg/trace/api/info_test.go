@@ -164,7 +164,9 @@ func TestInfoHandler(t *testing.T) {
 			},
 			"remove_stack_traces": false,
 			"redis": true,
-			"memcached": false
+			"memcached": false,
+			"graphql": false,
+			"aws": false
 		}
 	}
 }`,
@@ -222,7 +224,9 @@ func TestInfoHandler(t *testing.T) {
 			},
 			"remove_stack_traces": false,
 			"redis": true,
-			"memcached": false
+			"memcached": false,
+			"graphql": false,
+			"aws": false
 		}
 	}
 }`,
@@ -0,0 +1,356 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb/otlppb"
+)
+
+// Jaeger tag value types.
+const (
+	jaegerTagString int32 = 0
+	jaegerTagDouble int32 = 1
+	jaegerTagBool   int32 = 2
+	jaegerTagLong   int32 = 3
+	jaegerTagBinary int32 = 4
+)
+
+// jaegerRefChildOf is the type of the references to the parent span.
+const jaegerRefChildOf int32 = 0
+
+// jaegerFlagDebug is set in the flags of the spans of debug traces.
+const jaegerFlagDebug int32 = 2
+
+// jaegerTag is a Tag of the Jaeger Thrift model, see
+// https://github.com/jaegertracing/jaeger-idl/blob/master/thrift/jaeger.thrift.
+type jaegerTag struct {
+	Key     string
+	VType   int32
+	VStr    string
+	VDouble float64
+	VBool   bool
+	VLong   int64
+	VBinary []byte
+}
+
+// String returns the value of the tag as a string.
+func (t *jaegerTag) String() string {
+	switch t.VType {
+	case jaegerTagDouble:
+		return strconv.FormatFloat(t.VDouble, 'f', -1, 64)
+	case jaegerTagBool:
+		return strconv.FormatBool(t.VBool)
+	case jaegerTagLong:
+		return strconv.FormatInt(t.VLong, 10)
+	case jaegerTagBinary:
+		return hex.EncodeToString(t.VBinary)
+	default:
+		return t.VStr
+	}
+}
+
+// otlp returns the tag as an OpenTelemetry attribute.
+func (t *jaegerTag) otlp() *otlppb.KeyValue {
+	var v otlppb.AnyValue
+	switch t.VType {
+	case jaegerTagDouble:
+		v.Value = &otlppb.AnyValue_DoubleValue{DoubleValue: t.VDouble}
+	case jaegerTagBool:
+		v.Value = &otlppb.AnyValue_BoolValue{BoolValue: t.VBool}
+	case jaegerTagLong:
+		v.Value = &otlppb.AnyValue_IntValue{IntValue: t.VLong}
+	default:
+		v.Value = &otlppb.AnyValue_StringValue{StringValue: t.String()}
+	}
+	return &otlppb.KeyValue{Key: t.Key, Value: &v}
+}
+
+type jaegerLog struct {
+	Timestamp int64 // in microseconds
+	Fields    []jaegerTag
+}
+
+type jaegerSpanRef struct {
+	RefType     int32
+	TraceIDLow  int64
+	TraceIDHigh int64
+	SpanID      int64
+}
+
+type jaegerSpan struct {
+	TraceIDLow    int64
+	TraceIDHigh   int64
+	SpanID        int64
+	ParentSpanID  int64
+	OperationName string
+	References    []jaegerSpanRef
+	Flags         int32
+	StartTime     int64 // in microseconds
+	Duration      int64 // in microseconds
+	Tags          []jaegerTag
+	Logs          []jaegerLog
+}
+
+type jaegerProcess struct {
+	ServiceName string
+	Tags        []jaegerTag
+}
+
+type jaegerBatch struct {
+	Process jaegerProcess
+	Spans   []jaegerSpan
+}
+
+// decodeJaeger decodes the Jaeger batch in body, encoded with the Thrift binary protocol as
+// sent by the Jaeger clients to the collector, and converts it into trace chunks.
+func decodeJaeger(req *http.Request, body []byte) ([]*pb.TraceChunk, error) {
+	if mt := getMediaType(req); mt != "application/x-thrift" && mt != "application/vnd.apache.thrift.binary" {
+		return nil, fmt.Errorf("unsupported media type %q", mt)
+	}
+	var batch jaegerBatch
+	r := thriftReader{b: body}
+	r.readBatch(&batch)
+	if r.err != nil {
+		return nil, r.err
+	}
+	spans := make([]*pb.Span, 0, len(batch.Spans))
+	debugTraces := make(map[uint64]bool)
+	for i := range batch.Spans {
+		span := convertJaegerSpan(&batch.Process, &batch.Spans[i])
+		if batch.Spans[i].Flags&jaegerFlagDebug != 0 {
+			debugTraces[span.TraceID] = true
+		}
+		spans = append(spans, span)
+	}
+	return traceChunksFromTranslatedSpans(spans, debugTraces), nil
+}
+
+// jaegerKinds maps the values of the Jaeger span.kind tag to the OpenTelemetry span kinds.
+var jaegerKinds = map[string]otlppb.Span_SpanKind{
+	"client":   otlppb.Span_SPAN_KIND_CLIENT,
+	"server":   otlppb.Span_SPAN_KIND_SERVER,
+	"producer": otlppb.Span_SPAN_KIND_PRODUCER,
+	"consumer": otlppb.Span_SPAN_KIND_CONSUMER,
+}
+
+// convertJaegerSpan converts the Jaeger span in, reported by process, to a Datadog span.
+func convertJaegerSpan(process *jaegerProcess, in *jaegerSpan) *pb.Span {
+	span := &pb.Span{
+		TraceID:  uint64(in.TraceIDLow),
+		SpanID:   uint64(in.SpanID),
+		ParentID: uint64(in.ParentSpanID),
+		Service:  process.ServiceName,
+		Resource: in.OperationName,
+		Start:    in.StartTime * 1000,
+		Duration: in.Duration * 1000,
+		Meta:     make(map[string]string, len(process.Tags)+len(in.Tags)+1),
+		Metrics:  map[string]float64{},
+	}
+	if span.ParentID == 0 {
+		for _, ref := range in.References {
+			if ref.RefType == jaegerRefChildOf && ref.TraceIDLow == in.TraceIDLow {
+				span.ParentID = uint64(ref.SpanID)
+				break
+			}
+		}
+	}
+	for _, tags := range [][]jaegerTag{process.Tags, in.Tags} {
+		for i := range tags {
+			t := &tags[i]
+			switch t.VType {
+			case jaegerTagDouble:
+				span.Metrics[t.Key] = t.VDouble
+			case jaegerTagLong:
+				span.Metrics[t.Key] = float64(t.VLong)
+			default:
+				span.Meta[t.Key] = t.String()
+			}
+		}
+	}
+	span.Meta["jaeger.trace_id"] = fmt.Sprintf("%016x%016x", uint64(in.TraceIDHigh), uint64(in.TraceIDLow))
+
+	kind, ok := jaegerKinds[span.Meta["span.kind"]]
+	if !ok {
+		kind = otlppb.Span_SPAN_KIND_INTERNAL
+	}
+	if v, ok := span.Meta["error"]; ok {
+		if v == "true" {
+			span.Error = 1
+		}
+		delete(span.Meta, "error")
+	}
+	if len(in.Logs) > 0 {
+		events := make([]*otlppb.Span_Event, 0, len(in.Logs))
+		for _, l := range in.Logs {
+			e := &otlppb.Span_Event{TimeUnixNano: uint64(l.Timestamp) * 1000}
+			fields := make(map[string]string, len(l.Fields))
+			for i := range l.Fields {
+				f := &l.Fields[i]
+				fields[f.Key] = f.String()
+				if f.Key == "event" {
+					e.Name = fields[f.Key]
+					continue
+				}
+				e.Attributes = append(e.Attributes, f.otlp())
+			}
+			events = append(events, e)
+			if e.Name == "error" {
+				// OpenTracing error logs, see https://github.com/opentracing/specification/blob/master/semantic_conventions.md#log-fields-table
+				span.Error = 1
+				if msg := fields["message"]; msg != "" {
+					span.Meta["error.msg"] = msg
+				} else if obj := fields["error.object"]; obj != "" {
+					span.Meta["error.msg"] = obj
+				}
+				if kind := fields["error.kind"]; kind != "" {
+					span.Meta["error.type"] = kind
+				}
+				if stack := fields["stack"]; stack != "" {
+					span.Meta["error.stack"] = stack
+				}
+			}
+		}
+		span.Meta["events"] = marshalEvents(events)
+	}
+	finishTranslatedSpan(span, "jaeger", kind)
+	return span
+}
+
+func (r *thriftReader) readBatch(b *jaegerBatch) {
+	r.readStruct(func(typ byte, id int16) bool {
+		switch {
+		case id == 1 && typ == thriftStruct:
+			r.readProcess(&b.Process)
+		case id == 2 && typ == thriftList:
+			r.readList(thriftStruct, func() {
+				var s jaegerSpan
+				r.readSpan(&s)
+				b.Spans = append(b.Spans, s)
+			})
+		default:
+			return false
+		}
+		return true
+	})
+}
+
+func (r *thriftReader) readProcess(p *jaegerProcess) {
+	r.readStruct(func(typ byte, id int16) bool {
+		switch {
+		case id == 1 && typ == thriftString:
+			p.ServiceName = r.string()
+		case id == 2 && typ == thriftList:
+			p.Tags = r.readTags()
+		default:
+			return false
+		}
+		return true
+	})
+}
+
+func (r *thriftReader) readSpan(s *jaegerSpan) {
+	r.readStruct(func(typ byte, id int16) bool {
+		switch {
+		case id == 1 && typ == thriftI64:
+			s.TraceIDLow = r.i64()
+		case id == 2 && typ == thriftI64:
+			s.TraceIDHigh = r.i64()
+		case id == 3 && typ == thriftI64:
+			s.SpanID = r.i64()
+		case id == 4 && typ == thriftI64:
+			s.ParentSpanID = r.i64()
+		case id == 5 && typ == thriftString:
+			s.OperationName = r.string()
+		case id == 6 && typ == thriftList:
+			r.readList(thriftStruct, func() {
+				var ref jaegerSpanRef
+				r.readSpanRef(&ref)
+				s.References = append(s.References, ref)
+			})
+		case id == 7 && typ == thriftI32:
+			s.Flags = r.i32()
+		case id == 8 && typ == thriftI64:
+			s.StartTime = r.i64()
+		case id == 9 && typ == thriftI64:
+			s.Duration = r.i64()
+		case id == 10 && typ == thriftList:
+			s.Tags = r.readTags()
+		case id == 11 && typ == thriftList:
+			r.readList(thriftStruct, func() {
+				var l jaegerLog
+				r.readStruct(func(typ byte, id int16) bool {
+					switch {
+					case id == 1 && typ == thriftI64:
+						l.Timestamp = r.i64()
+					case id == 2 && typ == thriftList:
+						l.Fields = r.readTags()
+					default:
+						return false
+					}
+					return true
+				})
+				s.Logs = append(s.Logs, l)
+			})
+		default:
+			return false
+		}
+		return true
+	})
+}
+
+func (r *thriftReader) readSpanRef(ref *jaegerSpanRef) {
+	r.readStruct(func(typ byte, id int16) bool {
+		switch {
+		case id == 1 && typ == thriftI32:
+			ref.RefType = r.i32()
+		case id == 2 && typ == thriftI64:
+			ref.TraceIDLow = r.i64()
+		case id == 3 && typ == thriftI64:
+			ref.TraceIDHigh = r.i64()
+		case id == 4 && typ == thriftI64:
+			ref.SpanID = r.i64()
+		default:
+			return false
+		}
+		return true
+	})
+}
+
+func (r *thriftReader) readTags() []jaegerTag {
+	var tags []jaegerTag
+	r.readList(thriftStruct, func() {
+		var t jaegerTag
+		r.readStruct(func(typ byte, id int16) bool {
+			switch {
+			case id == 1 && typ == thriftString:
+				t.Key = r.string()
+			case id == 2 && typ == thriftI32:
+				t.VType = r.i32()
+			case id == 3 && typ == thriftString:
+				t.VStr = r.string()
+			case id == 4 && typ == thriftDouble:
+				t.VDouble = r.double()
+			case id == 5 && typ == thriftBool:
+				t.VBool = r.bool()
+			case id == 6 && typ == thriftI64:
+				t.VLong = r.i64()
+			case id == 7 && typ == thriftString:
+				t.VBinary = r.binary()
+			default:
+				return false
+			}
+			return true
+		})
+		tags = append(tags, t)
+	})
+	return tags
+}
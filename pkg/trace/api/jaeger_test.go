@@ -0,0 +1,259 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/sampler"
+	"github.com/stretchr/testify/assert"
+)
+
+// thriftWriter encodes values with the Thrift binary protocol.
+type thriftWriter struct{ bytes.Buffer }
+
+func (w *thriftWriter) field(typ byte, id int16) {
+	w.WriteByte(typ)
+	binary.Write(w, binary.BigEndian, id) //nolint:errcheck
+}
+
+func (w *thriftWriter) stop() { w.WriteByte(thriftStop) }
+
+func (w *thriftWriter) i32(id int16, v int32) {
+	w.field(thriftI32, id)
+	binary.Write(w, binary.BigEndian, v) //nolint:errcheck
+}
+
+func (w *thriftWriter) i64(id int16, v int64) {
+	w.field(thriftI64, id)
+	binary.Write(w, binary.BigEndian, v) //nolint:errcheck
+}
+
+func (w *thriftWriter) string(id int16, v string) {
+	w.field(thriftString, id)
+	binary.Write(w, binary.BigEndian, int32(len(v))) //nolint:errcheck
+	w.WriteString(v)
+}
+
+func (w *thriftWriter) list(id int16, elem byte, n int) {
+	w.field(thriftList, id)
+	w.WriteByte(elem)
+	binary.Write(w, binary.BigEndian, int32(n)) //nolint:errcheck
+}
+
+func (w *thriftWriter) tags(id int16, tags []jaegerTag) {
+	w.list(id, thriftStruct, len(tags))
+	for _, t := range tags {
+		w.string(1, t.Key)
+		w.i32(2, t.VType)
+		switch t.VType {
+		case jaegerTagString:
+			w.string(3, t.VStr)
+		case jaegerTagDouble:
+			w.field(thriftDouble, 4)
+			binary.Write(w, binary.BigEndian, math.Float64bits(t.VDouble)) //nolint:errcheck
+		case jaegerTagBool:
+			w.field(thriftBool, 5)
+			if t.VBool {
+				w.WriteByte(1)
+			} else {
+				w.WriteByte(0)
+			}
+		case jaegerTagLong:
+			w.i64(6, t.VLong)
+		case jaegerTagBinary:
+			w.string(7, string(t.VBinary))
+		}
+		w.stop()
+	}
+}
+
+func encodeJaegerBatch(b *jaegerBatch) []byte {
+	var w thriftWriter
+	w.field(thriftStruct, 1)
+	w.string(1, b.Process.ServiceName)
+	w.tags(2, b.Process.Tags)
+	w.stop()
+	// unknown fields are skipped
+	w.field(thriftMap, 3)
+	w.WriteByte(thriftString)
+	w.WriteByte(thriftI32)
+	binary.Write(&w, binary.BigEndian, int32(1)) //nolint:errcheck
+	binary.Write(&w, binary.BigEndian, int32(1)) //nolint:errcheck
+	w.WriteString("k")
+	binary.Write(&w, binary.BigEndian, int32(1)) //nolint:errcheck
+	w.list(2, thriftStruct, len(b.Spans))
+	for _, s := range b.Spans {
+		w.i64(1, s.TraceIDLow)
+		w.i64(2, s.TraceIDHigh)
+		w.i64(3, s.SpanID)
+		w.i64(4, s.ParentSpanID)
+		w.string(5, s.OperationName)
+		w.list(6, thriftStruct, len(s.References))
+		for _, ref := range s.References {
+			w.i32(1, ref.RefType)
+			w.i64(2, ref.TraceIDLow)
+			w.i64(3, ref.TraceIDHigh)
+			w.i64(4, ref.SpanID)
+			w.stop()
+		}
+		w.i32(7, s.Flags)
+		w.i64(8, s.StartTime)
+		w.i64(9, s.Duration)
+		w.tags(10, s.Tags)
+		w.list(11, thriftStruct, len(s.Logs))
+		for _, l := range s.Logs {
+			w.i64(1, l.Timestamp)
+			w.tags(2, l.Fields)
+			w.stop()
+		}
+		w.stop()
+	}
+	w.stop()
+	return w.Bytes()
+}
+
+var jaegerTestBatch = jaegerBatch{
+	Process: jaegerProcess{
+		ServiceName: "frontend",
+		Tags: []jaegerTag{
+			{Key: "hostname", VType: jaegerTagString, VStr: "web-1"},
+			{Key: "service.version", VType: jaegerTagString, VStr: "1.2.3"},
+		},
+	},
+	Spans: []jaegerSpan{
+		{
+			TraceIDLow:    0xaaa,
+			TraceIDHigh:   0x5af7183fb1d4cf5f,
+			SpanID:        0xbbb,
+			OperationName: "HTTP GET",
+			StartTime:     1472470996199000,
+			Duration:      207000,
+			Tags: []jaegerTag{
+				{Key: "span.kind", VType: jaegerTagString, VStr: "server"},
+				{Key: "http.method", VType: jaegerTagString, VStr: "GET"},
+				{Key: "http.route", VType: jaegerTagString, VStr: "/api"},
+				{Key: "sampler.param", VType: jaegerTagBool, VBool: true},
+				{Key: "retries", VType: jaegerTagLong, VLong: 3},
+				{Key: "ratio", VType: jaegerTagDouble, VDouble: 0.5},
+				{Key: "blob", VType: jaegerTagBinary, VBinary: []byte{0xca, 0xfe}},
+				{Key: "error", VType: jaegerTagBool, VBool: true},
+			},
+			Logs: []jaegerLog{{
+				Timestamp: 1472470996238000,
+				Fields: []jaegerTag{
+					{Key: "event", VType: jaegerTagString, VStr: "error"},
+					{Key: "error.kind", VType: jaegerTagString, VStr: "Timeout"},
+					{Key: "message", VType: jaegerTagString, VStr: "deadline exceeded"},
+					{Key: "stack", VType: jaegerTagString, VStr: "1/2/3"},
+				},
+			}},
+		},
+		{
+			TraceIDLow:    0xaaa,
+			TraceIDHigh:   0x5af7183fb1d4cf5f,
+			SpanID:        0xccc,
+			OperationName: "select",
+			References:    []jaegerSpanRef{{RefType: jaegerRefChildOf, TraceIDLow: 0xaaa, TraceIDHigh: 0x5af7183fb1d4cf5f, SpanID: 0xbbb}},
+			StartTime:     1472470996200000,
+			Duration:      1000,
+			Tags: []jaegerTag{
+				{Key: "span.kind", VType: jaegerTagString, VStr: "client"},
+				{Key: "db.system", VType: jaegerTagString, VStr: "postgresql"},
+			},
+		},
+		{
+			TraceIDLow:    0xddd,
+			SpanID:        0xeee,
+			OperationName: "compute",
+			Flags:         jaegerFlagDebug | 1,
+			StartTime:     1472470996300000,
+			Duration:      10,
+		},
+	},
+}
+
+func TestDecodeJaeger(t *testing.T) {
+	assert := assert.New(t)
+	req, _ := http.NewRequest("POST", "/api/traces", nil)
+	req.Header.Set("Content-Type", "application/x-thrift")
+	body := encodeJaegerBatch(&jaegerTestBatch)
+
+	chunks, err := decodeJaeger(req, body)
+	assert.NoError(err)
+	assert.Len(chunks, 2)
+	assert.EqualValues(sampler.PriorityAutoKeep, chunks[0].Priority)
+	assert.EqualValues(sampler.PriorityUserKeep, chunks[1].Priority)
+
+	assert.Len(chunks[0].Spans, 2)
+	root, child := chunks[0].Spans[0], chunks[0].Spans[1]
+	assert.Equal(uint64(0xaaa), root.TraceID)
+	assert.Equal(uint64(0xbbb), root.SpanID)
+	assert.Equal(uint64(0), root.ParentID)
+	assert.Equal("frontend", root.Service)
+	assert.Equal("jaeger.server", root.Name)
+	assert.Equal("GET /api", root.Resource)
+	assert.Equal("web", root.Type)
+	assert.Equal(int64(1472470996199000000), root.Start)
+	assert.Equal(int64(207000000), root.Duration)
+	assert.Equal("web-1", root.Meta["hostname"])
+	assert.Equal("1.2.3", root.Meta["version"])
+	assert.Equal("true", root.Meta["sampler.param"])
+	assert.Equal("cafe", root.Meta["blob"])
+	assert.Equal(float64(3), root.Metrics["retries"])
+	assert.Equal(0.5, root.Metrics["ratio"])
+	assert.Equal("5af7183fb1d4cf5f0000000000000aaa", root.Meta["jaeger.trace_id"])
+	assert.Equal(int32(1), root.Error)
+	assert.NotContains(root.Meta, "error")
+	assert.Equal("deadline exceeded", root.Meta["error.msg"])
+	assert.Equal("Timeout", root.Meta["error.type"])
+	assert.Equal("1/2/3", root.Meta["error.stack"])
+	assert.Equal(`[{"time_unix_nano":1472470996238000000,"name":"error","attributes":{"error.kind":"Timeout","message":"deadline exceeded","stack":"1/2/3"}}]`, root.Meta["events"])
+
+	assert.Equal(uint64(0xbbb), child.ParentID)
+	assert.Equal("jaeger.client", child.Name)
+	assert.Equal("select", child.Resource)
+	assert.Equal(int32(0), child.Error)
+
+	local := chunks[1].Spans[0]
+	assert.Equal(uint64(0xddd), local.TraceID)
+	assert.Equal("jaeger.internal", local.Name)
+
+	t.Run("invalid", func(t *testing.T) {
+		for i := 0; i < len(body)-1; i += 7 {
+			_, err := decodeJaeger(req, body[:i])
+			assert.Error(err, i)
+		}
+		_, err := decodeJaeger(req, []byte{thriftList, 0, 2, thriftStruct, 0x7f, 0xff, 0xff, 0xff})
+		assert.Error(err)
+		_, err = decodeJaeger(req, []byte{7, 0, 1})
+		assert.Error(err)
+
+		req, _ := http.NewRequest("POST", "/api/traces", nil)
+		req.Header.Set("Content-Type", "application/json")
+		_, err = decodeJaeger(req, body)
+		assert.Error(err)
+	})
+}
+
+func TestJaegerEndpoint(t *testing.T) {
+	assert := assert.New(t)
+	r := newTestReceiverFromConfig(newTestReceiverConfig())
+	server := httptest.NewServer(r.handleTranslatedTraces(vJaegerThrift, decodeJaeger))
+	defer server.Close()
+
+	resp, err := http.Post(server.URL, "application/x-thrift", bytes.NewReader(encodeJaegerBatch(&jaegerTestBatch)))
+	assert.NoError(err)
+	resp.Body.Close()
+	assert.Equal(http.StatusAccepted, resp.StatusCode)
+	p := <-r.out
+	assert.Len(p.TracerPayload.Chunks, 2)
+}
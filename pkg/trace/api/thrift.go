@@ -0,0 +1,188 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Thrift types, as encoded by the binary protocol.
+const (
+	thriftStop   byte = 0
+	thriftBool   byte = 2
+	thriftByte   byte = 3
+	thriftDouble byte = 4
+	thriftI16    byte = 6
+	thriftI32    byte = 8
+	thriftI64    byte = 10
+	thriftString byte = 11
+	thriftStruct byte = 12
+	thriftMap    byte = 13
+	thriftSet    byte = 14
+	thriftList   byte = 15
+)
+
+// thriftMaxDepth is the maximum nesting of the skipped values.
+const thriftMaxDepth = 64
+
+// thriftReader reads values encoded with the Thrift binary protocol. The first error
+// encountered is kept in err, after which the reads return zero values.
+type thriftReader struct {
+	b   []byte
+	err error
+}
+
+func (r *thriftReader) read(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(r.b) {
+		r.err = io.ErrUnexpectedEOF
+		r.b = nil
+		return nil
+	}
+	v := r.b[:n]
+	r.b = r.b[n:]
+	return v
+}
+
+func (r *thriftReader) byte() byte {
+	if b := r.read(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (r *thriftReader) bool() bool {
+	return r.byte() != 0
+}
+
+func (r *thriftReader) i16() int16 {
+	if b := r.read(2); b != nil {
+		return int16(binary.BigEndian.Uint16(b))
+	}
+	return 0
+}
+
+func (r *thriftReader) i32() int32 {
+	if b := r.read(4); b != nil {
+		return int32(binary.BigEndian.Uint32(b))
+	}
+	return 0
+}
+
+func (r *thriftReader) i64() int64 {
+	if b := r.read(8); b != nil {
+		return int64(binary.BigEndian.Uint64(b))
+	}
+	return 0
+}
+
+func (r *thriftReader) double() float64 {
+	if b := r.read(8); b != nil {
+		return math.Float64frombits(binary.BigEndian.Uint64(b))
+	}
+	return 0
+}
+
+func (r *thriftReader) binary() []byte {
+	return r.read(int(r.i32()))
+}
+
+func (r *thriftReader) string() string {
+	return string(r.binary())
+}
+
+// readStruct reads the fields of a struct, calling field with the type and ID of each
+// of them. field reads the value and returns true, or returns false for the value to be
+// skipped.
+func (r *thriftReader) readStruct(field func(typ byte, id int16) bool) {
+	for r.err == nil {
+		typ := r.byte()
+		if typ == thriftStop {
+			return
+		}
+		id := r.i16()
+		if r.err == nil && !field(typ, id) {
+			r.skip(typ, 0)
+		}
+	}
+}
+
+// readList reads a list of elements of type elem, calling read for each of them. The
+// lists of other types are skipped.
+func (r *thriftReader) readList(elem byte, read func()) {
+	typ, n := r.listHeader()
+	for i := 0; i < n && r.err == nil; i++ {
+		if typ == elem {
+			read()
+		} else {
+			r.skip(typ, 0)
+		}
+	}
+}
+
+func (r *thriftReader) listHeader() (byte, int) {
+	typ := r.byte()
+	n := int(r.i32())
+	if r.err == nil && (n < 0 || n > len(r.b)) {
+		// each element takes at least one byte
+		r.err = fmt.Errorf("invalid thrift list size %d", n)
+	}
+	return typ, n
+}
+
+// skip skips a value of the type typ, at the given nesting depth.
+func (r *thriftReader) skip(typ byte, depth int) {
+	if depth > thriftMaxDepth {
+		r.err = errors.New("thrift value is nested too deeply")
+		return
+	}
+	switch typ {
+	case thriftBool, thriftByte:
+		r.read(1)
+	case thriftI16:
+		r.read(2)
+	case thriftI32:
+		r.read(4)
+	case thriftDouble, thriftI64:
+		r.read(8)
+	case thriftString:
+		r.binary()
+	case thriftStruct:
+		for r.err == nil {
+			typ := r.byte()
+			if typ == thriftStop {
+				return
+			}
+			r.i16()
+			r.skip(typ, depth+1)
+		}
+	case thriftMap:
+		ktyp, vtyp := r.byte(), r.byte()
+		n := int(r.i32())
+		if r.err == nil && (n < 0 || n > len(r.b)) {
+			r.err = fmt.Errorf("invalid thrift map size %d", n)
+		}
+		for i := 0; i < n && r.err == nil; i++ {
+			r.skip(ktyp, depth+1)
+			r.skip(vtyp, depth+1)
+		}
+	case thriftSet, thriftList:
+		typ, n := r.listHeader()
+		for i := 0; i < n && r.err == nil; i++ {
+			r.skip(typ, depth+1)
+		}
+	default:
+		if r.err == nil {
+			r.err = fmt.Errorf("invalid thrift type %d", typ)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/api/apiutil"
+	"github.com/DataDog/datadog-agent/pkg/trace/log"
+	"github.com/DataDog/datadog-agent/pkg/trace/metrics"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb/otlppb"
+	"github.com/DataDog/datadog-agent/pkg/trace/sampler"
+
+	semconv "go.opentelemetry.io/collector/model/semconv/v1.6.1"
+)
+
+// translateFunc decodes the spans of another tracing format found in body and converts
+// them into Datadog trace chunks.
+type translateFunc func(req *http.Request, body []byte) ([]*pb.TraceChunk, error)
+
+// handleTranslatedTraces returns a handler decoding the traces of another tracing format
+// using translate, which are then processed like the traces of the Datadog endpoints.
+func (r *HTTPReceiver) handleTranslatedTraces(v Version, translate translateFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ts := r.tagStats(v, req.Header)
+		start := time.Now()
+		body, err := readTranslatedBody(req, r.conf.MaxRequestBytes)
+		var chunks []*pb.TraceChunk
+		if err == nil {
+			chunks, err = translate(req, body)
+		}
+		defer func(err error) {
+			tags := append(ts.AsTags(), fmt.Sprintf("success:%v", err == nil))
+			metrics.Histogram("datadog.trace_agent.receiver.serve_traces_ms", float64(time.Since(start))/float64(time.Millisecond), tags, 1)
+		}(err)
+		if err != nil {
+			httpDecodingError(err, []string{"handler:traces", fmt.Sprintf("v:%s", v)}, w)
+			log.Errorf("Cannot decode %s traces payload: %v", v, err)
+			return
+		}
+		if r.rateLimited(int64(len(chunks))) {
+			w.WriteHeader(r.rateLimiterResponse)
+			atomic.AddInt64(&ts.PayloadRefused, 1)
+			return
+		}
+		runMetaHook(chunks)
+		w.WriteHeader(http.StatusAccepted)
+
+		atomic.AddInt64(&ts.TracesReceived, int64(len(chunks)))
+		atomic.AddInt64(&ts.TracesBytes, int64(len(body)))
+		atomic.AddInt64(&ts.PayloadAccepted, 1)
+
+		tp := &pb.TracerPayload{
+			Chunks:          chunks,
+			ContainerID:     req.Header.Get(headerContainerID),
+			LanguageName:    ts.Lang,
+			LanguageVersion: ts.LangVersion,
+			TracerVersion:   ts.TracerVersion,
+		}
+		if ctags := getContainerTags(r.conf.ContainerTags, tp.ContainerID); ctags != "" {
+			tp.Tags = map[string]string{
+				tagContainersTags: ctags,
+			}
+		}
+		r.send(&Payload{
+			Source:        ts,
+			TracerPayload: tp,
+		})
+	})
+}
+
+// readTranslatedBody reads the body of req, which may be compressed with gzip, up to maxBytes.
+func readTranslatedBody(req *http.Request, maxBytes int64) ([]byte, error) {
+	var rd io.Reader = apiutil.NewLimitedReader(req.Body, maxBytes)
+	if req.Header.Get("Content-Encoding") == "gzip" {
+		gzipr, err := gzip.NewReader(rd)
+		if err != nil {
+			return nil, err
+		}
+		defer gzipr.Close()
+		rd = apiutil.NewLimitedReader(gzipr, maxBytes)
+	}
+	return ioutil.ReadAll(rd)
+}
+
+// traceChunksFromTranslatedSpans groups spans by trace ID into chunks. The chunks are kept
+// as they were already chosen as keepers on the client side, and the chunks of the traces
+// in debugTraces are kept by the user.
+func traceChunksFromTranslatedSpans(spans []*pb.Span, debugTraces map[uint64]bool) []*pb.TraceChunk {
+	tracesByID := make(map[uint64]pb.Trace)
+	var order []uint64
+	for _, s := range spans {
+		if _, ok := tracesByID[s.TraceID]; !ok {
+			order = append(order, s.TraceID)
+		}
+		tracesByID[s.TraceID] = append(tracesByID[s.TraceID], s)
+	}
+	chunks := make([]*pb.TraceChunk, 0, len(tracesByID))
+	for _, id := range order {
+		priority := sampler.PriorityAutoKeep
+		if debugTraces[id] {
+			priority = sampler.PriorityUserKeep
+		}
+		chunks = append(chunks, &pb.TraceChunk{
+			Priority: int32(priority),
+			Spans:    tracesByID[id],
+		})
+	}
+	return chunks
+}
+
+// finishTranslatedSpan sets the name, type, resource, env and version of span, translated
+// from a span of the given kind of another tracing format whose name is prefixed by format.
+func finishTranslatedSpan(span *pb.Span, format string, kind otlppb.Span_SpanKind) {
+	span.Name = format + "." + spanKindName(kind)
+	if _, ok := span.Meta["version"]; !ok {
+		if ver := span.Meta[string(semconv.AttributeServiceVersion)]; ver != "" {
+			span.Meta["version"] = ver
+		}
+	}
+	if _, ok := span.Meta["env"]; !ok {
+		if env := span.Meta[string(semconv.AttributeDeploymentEnvironment)]; env != "" {
+			span.Meta["env"] = env
+		}
+	}
+	if r := resourceFromTags(span.Meta); r != "" {
+		span.Resource = r
+	}
+	span.Type = spanKind2Type(kind, span)
+}
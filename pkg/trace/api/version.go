@@ -81,4 +81,20 @@ const (
 	// Response: Service sampling rates.
 	//
 	V07 Version = "v0.7"
+
+	// vZipkinV2 API
+	//
+	// Content-Type: application/json or application/x-protobuf
+	// Payload: List of Zipkin v2 spans (https://zipkin.io/zipkin-api/)
+	// Response: 202 Accepted.
+	//
+	vZipkinV2 Version = "zipkin_v2"
+
+	// vJaegerThrift API
+	//
+	// Content-Type: application/x-thrift or application/vnd.apache.thrift.binary
+	// Payload: Jaeger Batch encoded with the Thrift binary protocol (jaeger.thrift)
+	// Response: 202 Accepted.
+	//
+	vJaegerThrift Version = "jaeger_thrift"
 )
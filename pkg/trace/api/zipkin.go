@@ -0,0 +1,349 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb/otlppb"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// zipkinSpan is a span of the Zipkin v2 API, see https://zipkin.io/zipkin-api/zipkin2-api.yaml.
+type zipkinSpan struct {
+	TraceID        string             `json:"traceId"`
+	ID             string             `json:"id"`
+	ParentID       string             `json:"parentId"`
+	Name           string             `json:"name"`
+	Kind           string             `json:"kind"`
+	Timestamp      uint64             `json:"timestamp"` // in microseconds
+	Duration       uint64             `json:"duration"`  // in microseconds
+	Debug          bool               `json:"debug"`
+	LocalEndpoint  *zipkinEndpoint    `json:"localEndpoint"`
+	RemoteEndpoint *zipkinEndpoint    `json:"remoteEndpoint"`
+	Annotations    []zipkinAnnotation `json:"annotations"`
+	Tags           map[string]string  `json:"tags"`
+}
+
+type zipkinEndpoint struct {
+	ServiceName string `json:"serviceName"`
+	IPv4        string `json:"ipv4"`
+	IPv6        string `json:"ipv6"`
+	Port        int32  `json:"port"`
+}
+
+type zipkinAnnotation struct {
+	Timestamp uint64 `json:"timestamp"` // in microseconds
+	Value     string `json:"value"`
+}
+
+// zipkinKinds maps the Zipkin span kinds to the OpenTelemetry ones. Spans without kind
+// are local spans.
+var zipkinKinds = map[string]otlppb.Span_SpanKind{
+	"":         otlppb.Span_SPAN_KIND_INTERNAL,
+	"CLIENT":   otlppb.Span_SPAN_KIND_CLIENT,
+	"SERVER":   otlppb.Span_SPAN_KIND_SERVER,
+	"PRODUCER": otlppb.Span_SPAN_KIND_PRODUCER,
+	"CONSUMER": otlppb.Span_SPAN_KIND_CONSUMER,
+}
+
+// decodeZipkin decodes the list of Zipkin v2 spans in body, encoded with JSON or protobuf
+// depending on the Content-Type of req, and converts it into trace chunks.
+func decodeZipkin(req *http.Request, body []byte) ([]*pb.TraceChunk, error) {
+	var (
+		in  []zipkinSpan
+		err error
+	)
+	switch getMediaType(req) {
+	case "application/x-protobuf", "application/protobuf":
+		in, err = unmarshalZipkinProto(body)
+	default:
+		err = json.Unmarshal(body, &in)
+	}
+	if err != nil {
+		return nil, err
+	}
+	spans := make([]*pb.Span, 0, len(in))
+	debugTraces := make(map[uint64]bool)
+	for i := range in {
+		span, err := convertZipkinSpan(&in[i])
+		if err != nil {
+			return nil, err
+		}
+		if in[i].Debug {
+			debugTraces[span.TraceID] = true
+		}
+		spans = append(spans, span)
+	}
+	return traceChunksFromTranslatedSpans(spans, debugTraces), nil
+}
+
+// convertZipkinSpan converts the Zipkin span in to a Datadog span.
+func convertZipkinSpan(in *zipkinSpan) (*pb.Span, error) {
+	kind, ok := zipkinKinds[in.Kind]
+	if !ok {
+		return nil, fmt.Errorf("invalid span kind %q", in.Kind)
+	}
+	traceID, err := parseZipkinID(in.TraceID, true)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trace ID %q: %v", in.TraceID, err)
+	}
+	spanID, err := parseZipkinID(in.ID, false)
+	if err != nil {
+		return nil, fmt.Errorf("invalid span ID %q: %v", in.ID, err)
+	}
+	var parentID uint64
+	if in.ParentID != "" {
+		if parentID, err = parseZipkinID(in.ParentID, false); err != nil {
+			return nil, fmt.Errorf("invalid parent ID %q: %v", in.ParentID, err)
+		}
+	}
+	span := &pb.Span{
+		TraceID:  traceID,
+		SpanID:   spanID,
+		ParentID: parentID,
+		Start:    int64(in.Timestamp) * 1000,
+		Duration: int64(in.Duration) * 1000,
+		Resource: in.Name,
+		Meta:     make(map[string]string, len(in.Tags)+1),
+		Metrics:  map[string]float64{},
+	}
+	for k, v := range in.Tags {
+		span.Meta[k] = v
+	}
+	span.Meta["zipkin.trace_id"] = in.TraceID
+	if in.LocalEndpoint != nil {
+		span.Service = in.LocalEndpoint.ServiceName
+	}
+	if svc := span.Meta["peer.service"]; svc != "" {
+		span.Service = svc
+	}
+	if e := in.RemoteEndpoint; e != nil {
+		if _, ok := span.Meta["peer.service"]; !ok && e.ServiceName != "" {
+			span.Meta["peer.service"] = e.ServiceName
+		}
+		if host := e.IPv4; host != "" || e.IPv6 != "" {
+			if host == "" {
+				host = e.IPv6
+			}
+			span.Meta["out.host"] = host
+		}
+		if e.Port != 0 {
+			span.Metrics["out.port"] = float64(e.Port)
+		}
+	}
+	if len(in.Annotations) > 0 {
+		events := make([]*otlppb.Span_Event, 0, len(in.Annotations))
+		for _, a := range in.Annotations {
+			events = append(events, &otlppb.Span_Event{TimeUnixNano: a.Timestamp * 1000, Name: a.Value})
+		}
+		span.Meta["events"] = marshalEvents(events)
+	}
+	if msg, ok := span.Meta["error"]; ok {
+		// Zipkin marks the errors with an "error" tag holding the error message, if any
+		span.Error = 1
+		delete(span.Meta, "error")
+		if msg != "" && msg != "true" {
+			span.Meta["error.msg"] = msg
+		}
+	}
+	finishTranslatedSpan(span, "zipkin", kind)
+	return span, nil
+}
+
+// parseZipkinID parses the hexadecimal Zipkin ID s. The 128-bit trace IDs are truncated
+// to their lower 64 bits, as Datadog trace IDs.
+func parseZipkinID(s string, trace bool) (uint64, error) {
+	if s == "" {
+		return 0, errors.New("empty ID")
+	}
+	if len(s) > 16 {
+		if !trace || len(s) > 32 {
+			return 0, errors.New("ID is too long")
+		}
+		s = s[len(s)-16:]
+	}
+	return strconv.ParseUint(s, 16, 64)
+}
+
+// unmarshalZipkinProto decodes the Zipkin ListOfSpans protobuf message in b, see
+// https://github.com/openzipkin/zipkin-api/blob/master/zipkin.proto. The IDs are
+// hex encoded to be converted as their JSON representation.
+func unmarshalZipkinProto(b []byte) ([]zipkinSpan, error) {
+	var spans []zipkinSpan
+	r := protoReader{b: b}
+	for num, typ, ok := r.next(); ok; num, typ, ok = r.next() {
+		if num != 1 || typ != protowire.BytesType {
+			r.skip(num, typ)
+			continue
+		}
+		var span zipkinSpan
+		if msg := r.bytes(); r.err == nil {
+			r.err = unmarshalZipkinProtoSpan(msg, &span)
+		}
+		spans = append(spans, span)
+	}
+	return spans, r.err
+}
+
+var zipkinProtoKinds = map[uint64]string{
+	1: "CLIENT",
+	2: "SERVER",
+	3: "PRODUCER",
+	4: "CONSUMER",
+}
+
+func unmarshalZipkinProtoSpan(b []byte, span *zipkinSpan) error {
+	r := protoReader{b: b}
+	for num, typ, ok := r.next(); ok; num, typ, ok = r.next() {
+		switch {
+		case num == 1 && typ == protowire.BytesType:
+			span.TraceID = hex.EncodeToString(r.bytes())
+		case num == 2 && typ == protowire.BytesType:
+			span.ParentID = hex.EncodeToString(r.bytes())
+		case num == 3 && typ == protowire.BytesType:
+			span.ID = hex.EncodeToString(r.bytes())
+		case num == 4 && typ == protowire.VarintType:
+			span.Kind = zipkinProtoKinds[r.varint()]
+		case num == 5 && typ == protowire.BytesType:
+			span.Name = string(r.bytes())
+		case num == 6 && typ == protowire.Fixed64Type:
+			span.Timestamp = r.fixed64()
+		case num == 7 && typ == protowire.VarintType:
+			span.Duration = r.varint()
+		case (num == 8 || num == 9) && typ == protowire.BytesType:
+			e := new(zipkinEndpoint)
+			if msg := r.bytes(); r.err == nil {
+				r.err = unmarshalZipkinProtoEndpoint(msg, e)
+			}
+			if num == 8 {
+				span.LocalEndpoint = e
+			} else {
+				span.RemoteEndpoint = e
+			}
+		case num == 10 && typ == protowire.BytesType:
+			var a zipkinAnnotation
+			ar := protoReader{b: r.bytes()}
+			for num, typ, ok := ar.next(); ok; num, typ, ok = ar.next() {
+				switch {
+				case num == 1 && typ == protowire.Fixed64Type:
+					a.Timestamp = ar.fixed64()
+				case num == 2 && typ == protowire.BytesType:
+					a.Value = string(ar.bytes())
+				default:
+					ar.skip(num, typ)
+				}
+			}
+			if r.err == nil {
+				r.err = ar.err
+			}
+			span.Annotations = append(span.Annotations, a)
+		case num == 11 && typ == protowire.BytesType:
+			// map entries are messages with the key as field 1 and the value as field 2
+			var k, v string
+			er := protoReader{b: r.bytes()}
+			for num, typ, ok := er.next(); ok; num, typ, ok = er.next() {
+				switch {
+				case num == 1 && typ == protowire.BytesType:
+					k = string(er.bytes())
+				case num == 2 && typ == protowire.BytesType:
+					v = string(er.bytes())
+				default:
+					er.skip(num, typ)
+				}
+			}
+			if r.err == nil {
+				r.err = er.err
+			}
+			if span.Tags == nil {
+				span.Tags = make(map[string]string)
+			}
+			span.Tags[k] = v
+		case num == 12 && typ == protowire.VarintType:
+			span.Debug = r.varint() != 0
+		default:
+			r.skip(num, typ)
+		}
+	}
+	return r.err
+}
+
+func unmarshalZipkinProtoEndpoint(b []byte, e *zipkinEndpoint) error {
+	r := protoReader{b: b}
+	for num, typ, ok := r.next(); ok; num, typ, ok = r.next() {
+		switch {
+		case num == 1 && typ == protowire.BytesType:
+			e.ServiceName = string(r.bytes())
+		case num == 2 && typ == protowire.BytesType:
+			e.IPv4 = net.IP(r.bytes()).String()
+		case num == 3 && typ == protowire.BytesType:
+			e.IPv6 = net.IP(r.bytes()).String()
+		case num == 4 && typ == protowire.VarintType:
+			e.Port = int32(r.varint())
+		default:
+			r.skip(num, typ)
+		}
+	}
+	return r.err
+}
+
+// protoReader reads the fields of a protobuf message. The first error encountered is
+// kept in err, after which no more field is read.
+type protoReader struct {
+	b   []byte
+	err error
+}
+
+// next reads the number and wire type of the next field. It returns false at the end of
+// the message or on error.
+func (r *protoReader) next() (protowire.Number, protowire.Type, bool) {
+	if r.err != nil || len(r.b) == 0 {
+		return 0, 0, false
+	}
+	num, typ, n := protowire.ConsumeTag(r.b)
+	r.advance(n)
+	return num, typ, r.err == nil
+}
+
+func (r *protoReader) bytes() []byte {
+	v, n := protowire.ConsumeBytes(r.b)
+	r.advance(n)
+	return v
+}
+
+func (r *protoReader) varint() uint64 {
+	v, n := protowire.ConsumeVarint(r.b)
+	r.advance(n)
+	return v
+}
+
+func (r *protoReader) fixed64() uint64 {
+	v, n := protowire.ConsumeFixed64(r.b)
+	r.advance(n)
+	return v
+}
+
+// skip skips the value of the field num of the wire type typ.
+func (r *protoReader) skip(num protowire.Number, typ protowire.Type) {
+	r.advance(protowire.ConsumeFieldValue(num, typ, r.b))
+}
+
+func (r *protoReader) advance(n int) {
+	if n < 0 {
+		r.err = protowire.ParseError(n)
+		r.b = nil
+		return
+	}
+	r.b = r.b[n:]
+}
@@ -0,0 +1,270 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/DataDog/datadog-agent/pkg/trace/sampler"
+	"github.com/stretchr/testify/assert"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const zipkinTestJSON = `[
+	{
+		"traceId": "5af7183fb1d4cf5f0000000000000aaa",
+		"id": "0000000000000bbb",
+		"name": "get /api",
+		"kind": "SERVER",
+		"timestamp": 1472470996199000,
+		"duration": 207000,
+		"localEndpoint": {"serviceName": "frontend", "ipv4": "127.0.0.1"},
+		"remoteEndpoint": {"ipv4": "192.168.99.101", "port": 9000},
+		"annotations": [{"timestamp": 1472470996238000, "value": "ws"}],
+		"tags": {"http.method": "GET", "http.route": "/api", "deployment.environment": "prod", "error": "timeout"}
+	},
+	{
+		"traceId": "5af7183fb1d4cf5f0000000000000aaa",
+		"parentId": "0000000000000bbb",
+		"id": "0000000000000ccc",
+		"name": "select",
+		"kind": "CLIENT",
+		"timestamp": 1472470996200000,
+		"duration": 1000,
+		"localEndpoint": {"serviceName": "frontend"},
+		"remoteEndpoint": {"serviceName": "postgres", "ipv4": "10.0.0.1", "port": 5432}
+	},
+	{
+		"traceId": "ddd",
+		"id": "eee",
+		"name": "compute",
+		"timestamp": 1472470996300000,
+		"duration": 10,
+		"debug": true,
+		"localEndpoint": {"serviceName": "backend"}
+	}
+]`
+
+func TestDecodeZipkin(t *testing.T) {
+	assert := assert.New(t)
+
+	check := func(chunks []*pb.TraceChunk) {
+		assert.Len(chunks, 2)
+		assert.EqualValues(sampler.PriorityAutoKeep, chunks[0].Priority)
+		assert.EqualValues(sampler.PriorityUserKeep, chunks[1].Priority)
+
+		assert.Len(chunks[0].Spans, 2)
+		root, child := chunks[0].Spans[0], chunks[0].Spans[1]
+		assert.Equal(uint64(0xaaa), root.TraceID)
+		assert.Equal(uint64(0xbbb), root.SpanID)
+		assert.Equal(uint64(0), root.ParentID)
+		assert.Equal("frontend", root.Service)
+		assert.Equal("zipkin.server", root.Name)
+		assert.Equal("GET /api", root.Resource)
+		assert.Equal("web", root.Type)
+		assert.Equal(int64(1472470996199000000), root.Start)
+		assert.Equal(int64(207000000), root.Duration)
+		assert.Equal(int32(1), root.Error)
+		assert.Equal("timeout", root.Meta["error.msg"])
+		assert.Equal("prod", root.Meta["env"])
+		assert.Equal("5af7183fb1d4cf5f0000000000000aaa", root.Meta["zipkin.trace_id"])
+		assert.Equal("192.168.99.101", root.Meta["out.host"])
+		assert.Equal(float64(9000), root.Metrics["out.port"])
+		assert.Equal(`[{"time_unix_nano":1472470996238000000,"name":"ws"}]`, root.Meta["events"])
+
+		assert.Equal(uint64(0xbbb), child.ParentID)
+		assert.Equal("zipkin.client", child.Name)
+		assert.Equal("select", child.Resource)
+		assert.Equal("postgres", child.Meta["peer.service"])
+		assert.Equal("10.0.0.1", child.Meta["out.host"])
+		assert.Equal(int32(0), child.Error)
+
+		local := chunks[1].Spans[0]
+		assert.Equal(uint64(0xddd), local.TraceID)
+		assert.Equal("backend", local.Service)
+		assert.Equal("zipkin.internal", local.Name)
+	}
+
+	t.Run("json", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/api/v2/spans", nil)
+		req.Header.Set("Content-Type", "application/json")
+		chunks, err := decodeZipkin(req, []byte(zipkinTestJSON))
+		assert.NoError(err)
+		check(chunks)
+	})
+
+	t.Run("proto", func(t *testing.T) {
+		endpoint := func(svc string, ipv4 []byte, port uint64) []byte {
+			var b []byte
+			if svc != "" {
+				b = protowire.AppendTag(b, 1, protowire.BytesType)
+				b = protowire.AppendString(b, svc)
+			}
+			if ipv4 != nil {
+				b = protowire.AppendTag(b, 2, protowire.BytesType)
+				b = protowire.AppendBytes(b, ipv4)
+			}
+			if port != 0 {
+				b = protowire.AppendTag(b, 4, protowire.VarintType)
+				b = protowire.AppendVarint(b, port)
+			}
+			return b
+		}
+		tag := func(k, v string) []byte {
+			var b []byte
+			b = protowire.AppendTag(b, 1, protowire.BytesType)
+			b = protowire.AppendString(b, k)
+			b = protowire.AppendTag(b, 2, protowire.BytesType)
+			return protowire.AppendString(b, v)
+		}
+		type protoSpan struct {
+			traceID, parentID, id []byte
+			kind                  uint64
+			name                  string
+			ts, duration          uint64
+			local, remote         []byte
+			annotation            string
+			annotationTS          uint64
+			tags                  [][]byte
+			debug                 bool
+		}
+		span := func(s protoSpan) []byte {
+			var b []byte
+			b = protowire.AppendTag(b, 1, protowire.BytesType)
+			b = protowire.AppendBytes(b, s.traceID)
+			if s.parentID != nil {
+				b = protowire.AppendTag(b, 2, protowire.BytesType)
+				b = protowire.AppendBytes(b, s.parentID)
+			}
+			b = protowire.AppendTag(b, 3, protowire.BytesType)
+			b = protowire.AppendBytes(b, s.id)
+			if s.kind != 0 {
+				b = protowire.AppendTag(b, 4, protowire.VarintType)
+				b = protowire.AppendVarint(b, s.kind)
+			}
+			b = protowire.AppendTag(b, 5, protowire.BytesType)
+			b = protowire.AppendString(b, s.name)
+			b = protowire.AppendTag(b, 6, protowire.Fixed64Type)
+			b = protowire.AppendFixed64(b, s.ts)
+			b = protowire.AppendTag(b, 7, protowire.VarintType)
+			b = protowire.AppendVarint(b, s.duration)
+			b = protowire.AppendTag(b, 8, protowire.BytesType)
+			b = protowire.AppendBytes(b, s.local)
+			if s.remote != nil {
+				b = protowire.AppendTag(b, 9, protowire.BytesType)
+				b = protowire.AppendBytes(b, s.remote)
+			}
+			if s.annotation != "" {
+				var a []byte
+				a = protowire.AppendTag(a, 1, protowire.Fixed64Type)
+				a = protowire.AppendFixed64(a, s.annotationTS)
+				a = protowire.AppendTag(a, 2, protowire.BytesType)
+				a = protowire.AppendString(a, s.annotation)
+				b = protowire.AppendTag(b, 10, protowire.BytesType)
+				b = protowire.AppendBytes(b, a)
+			}
+			for _, t := range s.tags {
+				b = protowire.AppendTag(b, 11, protowire.BytesType)
+				b = protowire.AppendBytes(b, t)
+			}
+			if s.debug {
+				b = protowire.AppendTag(b, 12, protowire.VarintType)
+				b = protowire.AppendVarint(b, 1)
+			}
+			// unknown fields are skipped
+			b = protowire.AppendTag(b, 99, protowire.VarintType)
+			return protowire.AppendVarint(b, 1)
+		}
+		var body []byte
+		for _, s := range []protoSpan{
+			{
+				traceID:      []byte{0x5a, 0xf7, 0x18, 0x3f, 0xb1, 0xd4, 0xcf, 0x5f, 0, 0, 0, 0, 0, 0, 0x0a, 0xaa},
+				id:           []byte{0, 0, 0, 0, 0, 0, 0x0b, 0xbb},
+				kind:         2,
+				name:         "get /api",
+				ts:           1472470996199000,
+				duration:     207000,
+				local:        endpoint("frontend", []byte{127, 0, 0, 1}, 0),
+				remote:       endpoint("", []byte{192, 168, 99, 101}, 9000),
+				annotation:   "ws",
+				annotationTS: 1472470996238000,
+				tags:         [][]byte{tag("http.method", "GET"), tag("http.route", "/api"), tag("deployment.environment", "prod"), tag("error", "timeout")},
+			},
+			{
+				traceID:  []byte{0x5a, 0xf7, 0x18, 0x3f, 0xb1, 0xd4, 0xcf, 0x5f, 0, 0, 0, 0, 0, 0, 0x0a, 0xaa},
+				parentID: []byte{0, 0, 0, 0, 0, 0, 0x0b, 0xbb},
+				id:       []byte{0, 0, 0, 0, 0, 0, 0x0c, 0xcc},
+				kind:     1,
+				name:     "select",
+				ts:       1472470996200000,
+				duration: 1000,
+				local:    endpoint("frontend", nil, 0),
+				remote:   endpoint("postgres", []byte{10, 0, 0, 1}, 5432),
+			},
+			{
+				traceID:  []byte{0, 0, 0, 0, 0, 0, 0x0d, 0xdd},
+				id:       []byte{0, 0, 0, 0, 0, 0, 0x0e, 0xee},
+				name:     "compute",
+				ts:       1472470996300000,
+				duration: 10,
+				local:    endpoint("backend", nil, 0),
+				debug:    true,
+			},
+		} {
+			body = protowire.AppendTag(body, 1, protowire.BytesType)
+			body = protowire.AppendBytes(body, span(s))
+		}
+
+		req, _ := http.NewRequest("POST", "/api/v2/spans", nil)
+		req.Header.Set("Content-Type", "application/x-protobuf")
+		chunks, err := decodeZipkin(req, body)
+		assert.NoError(err)
+		check(chunks)
+
+		_, err = decodeZipkin(req, body[:len(body)-3])
+		assert.Error(err)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/api/v2/spans", nil)
+		req.Header.Set("Content-Type", "application/json")
+		for _, body := range []string{
+			`{`,
+			`[{"traceId": "", "id": "1"}]`,
+			`[{"traceId": "1", "id": "zz"}]`,
+			`[{"traceId": "1", "id": "00000000000000001"}]`,
+			`[{"traceId": "1", "id": "2", "parentId": "x"}]`,
+			`[{"traceId": "1", "id": "2", "kind": "OTHER"}]`,
+		} {
+			_, err := decodeZipkin(req, []byte(body))
+			assert.Error(err, body)
+		}
+	})
+}
+
+func TestZipkinEndpoint(t *testing.T) {
+	assert := assert.New(t)
+	r := newTestReceiverFromConfig(newTestReceiverConfig())
+	server := httptest.NewServer(r.handleTranslatedTraces(vZipkinV2, decodeZipkin))
+	defer server.Close()
+
+	resp, err := http.Post(server.URL, "application/json", strings.NewReader(zipkinTestJSON))
+	assert.NoError(err)
+	resp.Body.Close()
+	assert.Equal(http.StatusAccepted, resp.StatusCode)
+	p := <-r.out
+	assert.Len(p.TracerPayload.Chunks, 2)
+
+	resp, err = http.Post(server.URL, "application/json", strings.NewReader("["))
+	assert.NoError(err)
+	resp.Body.Close()
+	assert.Equal(http.StatusBadRequest, resp.StatusCode)
+}
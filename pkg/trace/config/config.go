@@ -85,6 +85,14 @@ type ObfuscationConfig struct {
 	// for spans of type "memcached".
 	Memcached Enablable `mapstructure:"memcached"`
 
+	// GraphQL holds the configuration for obfuscating the literals of the "graphql.query"
+	// and "graphql.source" tags and of the resource of spans of type "graphql".
+	GraphQL Enablable `mapstructure:"graphql"`
+
+	// AWS holds the configuration for obfuscating the values of the "aws.*" tags
+	// for spans of type "aws" and "http".
+	AWS Enablable `mapstructure:"aws"`
+
 	// CreditCards holds the configuration for obfuscating credit cards.
 	CreditCards CreditCardsConfig `mapstructure:"credit_cards"`
 }
@@ -223,6 +231,84 @@ type WriterConfig struct {
 	// FlushPeriodSeconds specifies the frequency at which the writer's buffer
 	// will be flushed to the sender, in seconds. Fractions are permitted.
 	FlushPeriodSeconds float64 `mapstructure:"flush_period_seconds"`
+
+	// DiskBufferPath specifies the directory where the payloads which could not
+	// be kept in the sender's queue are spilled to, to be sent later.
+	DiskBufferPath string `mapstructure:"disk_buffer_path"`
+
+	// DiskBufferMaxSize specifies the maximum number of bytes used by the payloads
+	// spilled to disk. When it is surpassed, oldest payloads get removed to make
+	// room for new ones. A value of 0 disables the disk buffer.
+	DiskBufferMaxSize int64 `mapstructure:"disk_buffer_max_size"`
+}
+
+// TailSamplingConfig specifies the configuration of the tail-based sampling. When enabled,
+// the chunks of a trace are buffered until the decision wait is over, then the whole trace
+// is kept if any of the policies matches it or if any of its chunks was kept by the samplers.
+type TailSamplingConfig struct {
+	// Enabled reports whether the tail-based sampling is enabled.
+	Enabled bool `mapstructure:"enabled"`
+
+	// DecisionWaitSeconds specifies for how long the chunks of a trace are buffered
+	// before deciding whether to keep it, in seconds. Fractions are permitted.
+	DecisionWaitSeconds float64 `mapstructure:"decision_wait_seconds"`
+
+	// MaxSpans specifies the maximum number of spans buffered. When it is reached,
+	// the decision is made early for the oldest traces. It also bounds the number
+	// of decisions remembered for the late chunks of the traces.
+	MaxSpans int `mapstructure:"max_spans"`
+
+	// Policies specifies the policies keeping the traces.
+	Policies []TailSamplingPolicy `mapstructure:"policies"`
+}
+
+// TailSamplingPolicy specifies a policy keeping the traces matching it.
+type TailSamplingPolicy struct {
+	// Name is the name of the policy, used in the telemetry.
+	Name string `mapstructure:"name"`
+
+	// Type is the type of the policy:
+	// • "error" keeps the traces having a span with an error.
+	// • "duration" keeps the traces whose root span lasts at least MinDurationMs.
+	// • "tag" keeps the traces having a span with the Tag tag, set to Value if not empty.
+	Type string `mapstructure:"type"`
+
+	// MinDurationMs is the minimum duration of the root span of the "duration" policy, in milliseconds.
+	MinDurationMs float64 `mapstructure:"min_duration_ms"`
+
+	// Tag is the tag key of the "tag" policy.
+	Tag string `mapstructure:"tag"`
+
+	// Value is the tag value of the "tag" policy, any value matches when empty.
+	Value string `mapstructure:"value"`
+}
+
+// SpanRule specifies an action applied to the spans matching an expression.
+type SpanRule struct {
+	// Name is the name of the rule, used in the logs.
+	Name string `mapstructure:"name"`
+
+	// Match is the expression the spans must match. It compares the service, name,
+	// resource, meta["key"] and metrics["key"] fields of the span, for example:
+	// service == "web" && (meta["http.url"] =~ "^/health" || !metrics["_top_level"])
+	Match string `mapstructure:"match"`
+
+	// Action is the action applied to the matching spans:
+	// • "drop" drops the span, or the whole chunk when it is the root span.
+	// • "keep" forces the trace to be kept.
+	// • "add_tags" sets the Tags on the span.
+	// • "remove_tags" removes the TagKeys from the meta and metrics of the span.
+	// • "rename_resource" sets the resource of the span to Resource.
+	Action string `mapstructure:"action"`
+
+	// Tags are the tags set by the "add_tags" action.
+	Tags map[string]string `mapstructure:"tags"`
+
+	// TagKeys are the tag keys removed by the "remove_tags" action.
+	TagKeys []string `mapstructure:"tag_keys"`
+
+	// Resource is the resource set by the "rename_resource" action.
+	Resource string `mapstructure:"resource"`
 }
 
 // FargateOrchestratorName is a Fargate orchestrator name.
@@ -280,6 +366,11 @@ type AgentConfig struct {
 	// Concentrator
 	BucketInterval   time.Duration // the size of our pre-aggregation per bucket
 	ExtraAggregators []string
+	// StatsExtraTags specifies span meta keys used as additional stats aggregation dimensions.
+	StatsExtraTags []string
+	// StatsExtraTagsMaxValues is the maximum number of distinct values of each of the
+	// StatsExtraTags aggregated per stats bucket. Other values are aggregated together.
+	StatsExtraTagsMaxValues int
 
 	// Sampler configuration
 	ExtraSampleRate    float64
@@ -288,6 +379,7 @@ type AgentConfig struct {
 	DisableRareSampler bool
 	MaxEPS             float64
 	MaxRemoteTPS       float64
+	TailSampling       *TailSamplingConfig
 
 	// Receiver
 	ReceiverHost    string
@@ -336,6 +428,10 @@ type AgentConfig struct {
 	// It maps tag keys to a set of replacements. Only supported in A6.
 	ReplaceTags []*ReplaceRule
 
+	// SpanRules are applied in order to the spans of each chunk. They are reloaded from
+	// the configuration file when the trace-agent receives a SIGHUP.
+	SpanRules []SpanRule
+
 	// GlobalTags list metadata that will be added to all spans
 	GlobalTags map[string]string
 
@@ -414,13 +510,18 @@ func New() *AgentConfig {
 		Site:                "datadoghq.com",
 		MaxCatalogEntries:   5000,
 
-		BucketInterval: time.Duration(10) * time.Second,
+		BucketInterval:          time.Duration(10) * time.Second,
+		StatsExtraTagsMaxValues: 100,
 
 		ExtraSampleRate: 1.0,
 		TargetTPS:       10,
 		ErrorTPS:        10,
 		MaxEPS:          200,
 		MaxRemoteTPS:    100,
+		TailSampling: &TailSamplingConfig{
+			DecisionWaitSeconds: 10,
+			MaxSpans:            100000,
+		},
 
 		ReceiverHost:           "localhost",
 		ReceiverPort:           8126,
@@ -0,0 +1,339 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package filters
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+)
+
+// spanMatcher reports whether a span matches an expression.
+type spanMatcher func(s *pb.Span) bool
+
+// compileExpr compiles the span rule expression expr. The grammar is:
+//
+//	expr       = and { "||" and }
+//	and        = unary { "&&" unary }
+//	unary      = "!" unary | "(" expr ")" | comparison
+//	comparison = field [ operator literal ]
+//	field      = "service" | "name" | "resource" | "meta" "[" string "]" | "metrics" "[" string "]"
+//
+// The service, name, resource and meta fields are compared to strings using ==, !=, =~
+// and !~, the last two matching regular expressions. The metrics are compared to numbers
+// using ==, !=, <, <=, > and >=. A meta or metrics field without operator checks that the
+// tag is set. Strings are double quoted or, to avoid escaping regular expressions, back quoted.
+func compileExpr(expr string) (spanMatcher, error) {
+	tokens, err := lexExpr(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{tokens: tokens}
+	m, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", t.text, t.pos)
+	}
+	return m, nil
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenOperator
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// operators lists the operators of the expressions, the longest ones first.
+var operators = []string{"==", "!=", "=~", "!~", "<=", ">=", "&&", "||", "!", "<", ">", "(", ")", "[", "]"}
+
+// lexExpr splits expr into tokens.
+func lexExpr(expr string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '"' || c == '`':
+			j := i + 1
+			for ; j < len(expr) && expr[j] != c; j++ {
+				if c == '"' && expr[j] == '\\' {
+					j++
+				}
+			}
+			if j >= len(expr) {
+				return nil, fmt.Errorf("unterminated string at position %d", i)
+			}
+			s, err := strconv.Unquote(expr[i : j+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid string at position %d: %v", i, err)
+			}
+			tokens = append(tokens, token{kind: tokenString, text: s, pos: i})
+			i = j + 1
+		case isLetter(c):
+			j := i + 1
+			for j < len(expr) && (isLetter(expr[j]) || isDigit(expr[j])) {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: expr[i:j], pos: i})
+			i = j
+		case isDigit(c) || c == '-' || c == '.':
+			j := i + 1
+			for j < len(expr) && (isDigit(expr[j]) || strings.IndexByte(".eE", expr[j]) >= 0 ||
+				((expr[j] == '+' || expr[j] == '-') && (expr[j-1] == 'e' || expr[j-1] == 'E'))) {
+				j++
+			}
+			if _, err := strconv.ParseFloat(expr[i:j], 64); err != nil {
+				return nil, fmt.Errorf("invalid number %q at position %d", expr[i:j], i)
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: expr[i:j], pos: i})
+			i = j
+		default:
+			op := ""
+			for _, o := range operators {
+				if strings.HasPrefix(expr[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected character %q at position %d", c, i)
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: op, pos: i})
+			i += len(op)
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, text: "end of expression", pos: len(expr)}), nil
+}
+
+func isLetter(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// exprParser is a recursive descent parser of span rule expressions.
+type exprParser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *exprParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *exprParser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// accept consumes the next token if it is the operator op.
+func (p *exprParser) accept(op string) bool {
+	if t := p.peek(); t.kind == tokenOperator && t.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *exprParser) expect(kind tokenKind, what string) (token, error) {
+	t := p.next()
+	if t.kind != kind {
+		return t, fmt.Errorf("expected %s at position %d, got %q", what, t.pos, t.text)
+	}
+	return t, nil
+}
+
+func (p *exprParser) parseOr() (spanMatcher, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(s *pb.Span) bool { return l(s) || right(s) }
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseAnd() (spanMatcher, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(s *pb.Span) bool { return l(s) && right(s) }
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseUnary() (spanMatcher, error) {
+	if p.accept("!") {
+		m, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(s *pb.Span) bool { return !m(s) }, nil
+	}
+	if p.accept("(") {
+		m, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			t := p.peek()
+			return nil, fmt.Errorf("expected \")\" at position %d, got %q", t.pos, t.text)
+		}
+		return m, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *exprParser) parseComparison() (spanMatcher, error) {
+	field, err := p.expect(tokenIdent, "a field")
+	if err != nil {
+		return nil, err
+	}
+	switch field.text {
+	case "service":
+		return p.parseStringComparison(field, func(s *pb.Span) (string, bool) { return s.Service, true })
+	case "name":
+		return p.parseStringComparison(field, func(s *pb.Span) (string, bool) { return s.Name, true })
+	case "resource":
+		return p.parseStringComparison(field, func(s *pb.Span) (string, bool) { return s.Resource, true })
+	case "meta", "metrics":
+		key, err := p.parseKey()
+		if err != nil {
+			return nil, err
+		}
+		if t := p.peek(); t.kind != tokenOperator || !isComparisonOperator(t.text) {
+			// no operator, check that the tag is set
+			if field.text == "meta" {
+				return func(s *pb.Span) bool { _, ok := s.Meta[key]; return ok }, nil
+			}
+			return func(s *pb.Span) bool { _, ok := s.Metrics[key]; return ok }, nil
+		}
+		if field.text == "meta" {
+			return p.parseStringComparison(field, func(s *pb.Span) (string, bool) { v, ok := s.Meta[key]; return v, ok })
+		}
+		return p.parseNumberComparison(field, key)
+	}
+	return nil, fmt.Errorf("unknown field %q at position %d, must be one of service, name, resource, meta or metrics", field.text, field.pos)
+}
+
+// parseKey parses the ["key"] following the meta and metrics fields.
+func (p *exprParser) parseKey() (string, error) {
+	if t := p.next(); t.kind != tokenOperator || t.text != "[" {
+		return "", fmt.Errorf("expected \"[\" at position %d, got %q", t.pos, t.text)
+	}
+	key, err := p.expect(tokenString, "a tag key")
+	if err != nil {
+		return "", err
+	}
+	if t := p.next(); t.kind != tokenOperator || t.text != "]" {
+		return "", fmt.Errorf("expected \"]\" at position %d, got %q", t.pos, t.text)
+	}
+	return key.text, nil
+}
+
+func isComparisonOperator(op string) bool {
+	switch op {
+	case "==", "!=", "=~", "!~", "<", "<=", ">", ">=":
+		return true
+	}
+	return false
+}
+
+// parseStringComparison parses the operator and string compared to the field returned by get.
+func (p *exprParser) parseStringComparison(field token, get func(s *pb.Span) (string, bool)) (spanMatcher, error) {
+	op := p.next()
+	if op.kind != tokenOperator || !isComparisonOperator(op.text) {
+		return nil, fmt.Errorf("expected an operator after %q at position %d, got %q", field.text, op.pos, op.text)
+	}
+	var regex bool
+	switch op.text {
+	case "==", "!=":
+	case "=~", "!~":
+		regex = true
+	default:
+		return nil, fmt.Errorf("operator %q at position %d is not supported on %s", op.text, op.pos, field.text)
+	}
+	value, err := p.expect(tokenString, "a string")
+	if err != nil {
+		return nil, err
+	}
+	m := func(s *pb.Span) bool { v, ok := get(s); return ok && v == value.text }
+	if regex {
+		re, err := regexp.Compile(value.text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression at position %d: %v", value.pos, err)
+		}
+		m = func(s *pb.Span) bool { v, ok := get(s); return ok && re.MatchString(v) }
+	}
+	if op.text == "!=" || op.text == "!~" {
+		return func(s *pb.Span) bool { return !m(s) }, nil
+	}
+	return m, nil
+}
+
+// parseNumberComparison parses the operator and number compared to the metric key.
+func (p *exprParser) parseNumberComparison(field token, key string) (spanMatcher, error) {
+	op := p.next()
+	var cmp func(v, n float64) bool
+	switch op.text {
+	case "==", "!=":
+		cmp = func(v, n float64) bool { return v == n }
+	case "<":
+		cmp = func(v, n float64) bool { return v < n }
+	case "<=":
+		cmp = func(v, n float64) bool { return v <= n }
+	case ">":
+		cmp = func(v, n float64) bool { return v > n }
+	case ">=":
+		cmp = func(v, n float64) bool { return v >= n }
+	default:
+		return nil, fmt.Errorf("operator %q at position %d is not supported on %s", op.text, op.pos, field.text)
+	}
+	value, err := p.expect(tokenNumber, "a number")
+	if err != nil {
+		return nil, err
+	}
+	n, _ := strconv.ParseFloat(value.text, 64) // validated by lexExpr
+	m := func(s *pb.Span) bool { v, ok := s.Metrics[key]; return ok && cmp(v, n) }
+	if op.text == "!=" {
+		return func(s *pb.Span) bool { return !m(s) }, nil
+	}
+	return m, nil
+}
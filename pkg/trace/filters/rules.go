@@ -0,0 +1,165 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package filters
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/DataDog/datadog-agent/pkg/trace/traceutil"
+)
+
+// SpanRules applies a list of rules to the spans of the chunks. Each rule matches the
+// spans using an expression and drops them, keeps their trace, or rewrites their tags
+// or resource. The rules can be replaced at any time using Reload.
+type SpanRules struct {
+	mu    sync.RWMutex
+	rules []spanRule
+}
+
+// spanRule is a compiled config.SpanRule.
+type spanRule struct {
+	name   string
+	match  spanMatcher
+	action string
+	apply  func(s *pb.Span) // the action modifying the span, if any
+}
+
+// SpanRulesResult reports the outcome of applying the rules to a chunk.
+type SpanRulesResult struct {
+	// DroppedSpans is the number of spans dropped from the chunk.
+	DroppedSpans int
+	// DropTrace reports whether the root span was dropped, in which case the whole
+	// chunk must be dropped.
+	DropTrace bool
+	// Keep reports whether a rule asks for the trace to be kept.
+	Keep bool
+}
+
+// NewSpanRules returns SpanRules applying no rule until Reload is called.
+func NewSpanRules() *SpanRules {
+	return &SpanRules{}
+}
+
+// Reload replaces the rules by rules. When any of them is invalid, an error is
+// returned and the current rules are left unchanged.
+func (r *SpanRules) Reload(rules []config.SpanRule) error {
+	compiled := make([]spanRule, 0, len(rules))
+	for i, conf := range rules {
+		rule, err := newSpanRule(conf)
+		if err != nil {
+			name := conf.Name
+			if name == "" {
+				name = fmt.Sprintf("#%d", i)
+			}
+			return fmt.Errorf("invalid span rule %s: %v", name, err)
+		}
+		compiled = append(compiled, rule)
+	}
+	r.mu.Lock()
+	r.rules = compiled
+	r.mu.Unlock()
+	return nil
+}
+
+func newSpanRule(conf config.SpanRule) (spanRule, error) {
+	if conf.Match == "" {
+		return spanRule{}, fmt.Errorf("match expression is empty")
+	}
+	match, err := compileExpr(conf.Match)
+	if err != nil {
+		return spanRule{}, fmt.Errorf("invalid match expression %q: %v", conf.Match, err)
+	}
+	rule := spanRule{name: conf.Name, match: match, action: conf.Action}
+	switch conf.Action {
+	case "drop", "keep":
+	case "add_tags":
+		if len(conf.Tags) == 0 {
+			return spanRule{}, fmt.Errorf("add_tags action must have tags")
+		}
+		rule.apply = func(s *pb.Span) {
+			for k, v := range conf.Tags {
+				traceutil.SetMeta(s, k, v)
+			}
+		}
+	case "remove_tags":
+		if len(conf.TagKeys) == 0 {
+			return spanRule{}, fmt.Errorf("remove_tags action must have tag_keys")
+		}
+		rule.apply = func(s *pb.Span) {
+			for _, k := range conf.TagKeys {
+				delete(s.Meta, k)
+				delete(s.Metrics, k)
+			}
+		}
+	case "rename_resource":
+		if conf.Resource == "" {
+			return spanRule{}, fmt.Errorf("rename_resource action must have a resource")
+		}
+		rule.apply = func(s *pb.Span) {
+			s.Resource = conf.Resource
+		}
+	default:
+		return spanRule{}, fmt.Errorf("unknown action %q, must be one of drop, keep, add_tags, remove_tags or rename_resource", conf.Action)
+	}
+	return rule, nil
+}
+
+// Len returns the number of rules.
+func (r *SpanRules) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.rules)
+}
+
+// Apply applies the rules in order to each span of chunk, removing the dropped spans
+// from it. A span stops being matched by the rules following the one dropping it.
+func (r *SpanRules) Apply(chunk *pb.TraceChunk, root *pb.Span) SpanRulesResult {
+	var res SpanRulesResult
+	r.mu.RLock()
+	rules := r.rules
+	r.mu.RUnlock()
+	if len(rules) == 0 {
+		return res
+	}
+	kept := chunk.Spans[:0]
+	for _, s := range chunk.Spans {
+		if applySpanRules(rules, s, &res) {
+			kept = append(kept, s)
+			continue
+		}
+		res.DroppedSpans++
+		if s == root {
+			res.DropTrace = true
+		}
+	}
+	for i := len(kept); i < len(chunk.Spans); i++ {
+		// release the dropped spans
+		chunk.Spans[i] = nil
+	}
+	chunk.Spans = kept
+	return res
+}
+
+// applySpanRules applies rules to s and returns false if it must be dropped.
+func applySpanRules(rules []spanRule, s *pb.Span, res *SpanRulesResult) bool {
+	for _, rule := range rules {
+		if !rule.match(s) {
+			continue
+		}
+		switch rule.action {
+		case "drop":
+			return false
+		case "keep":
+			res.Keep = true
+		default:
+			rule.apply(s)
+		}
+	}
+	return true
+}
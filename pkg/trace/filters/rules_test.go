@@ -0,0 +1,151 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package filters
+
+import (
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompileExpr(t *testing.T) {
+	span := &pb.Span{
+		Service:  "web",
+		Name:     "http.request",
+		Resource: "GET /health",
+		Meta:     map[string]string{"http.status_code": "200", "env": "prod"},
+		Metrics:  map[string]float64{"_top_level": 1, "db.rows": 42},
+	}
+	for expr, want := range map[string]bool{
+		`service == "web"`:                                           true,
+		`service != "web"`:                                           false,
+		`name == "http.request" && service == "db"`:                  false,
+		`name == "http.request" || service == "db"`:                  true,
+		`resource =~ "^GET /health"`:                                 true,
+		"resource =~ `^GET /\\w+$`":                                  true,
+		`resource !~ "health"`:                                       false,
+		`meta["env"] == "prod"`:                                      true,
+		`meta["env"]`:                                                true,
+		`meta["missing"]`:                                            false,
+		`!meta["missing"]`:                                           true,
+		`meta["missing"] != "prod"`:                                  true,
+		`meta["missing"] =~ ".*"`:                                    false,
+		`metrics["db.rows"] > 40`:                                    true,
+		`metrics["db.rows"] >= 42.0`:                                 true,
+		`metrics["db.rows"] < 4.2e1`:                                 false,
+		`metrics["db.rows"] != -1`:                                   true,
+		`metrics["missing"] < 1`:                                     false,
+		`metrics["_top_level"]`:                                      true,
+		`!(service == "web" && metrics["_top_level"])`:               false,
+		`service == "db" || name == "x" && meta["env"]`:              false,
+		`(service == "db" || name == "http.request") && meta["env"]`: true,
+	} {
+		t.Run(expr, func(t *testing.T) {
+			m, err := compileExpr(expr)
+			require.NoError(t, err)
+			assert.Equal(t, want, m(span))
+		})
+	}
+}
+
+func TestCompileExprErrors(t *testing.T) {
+	for _, expr := range []string{
+		``,
+		`service`,
+		`service ==`,
+		`service == web`,
+		`service > "web"`,
+		`service == "web" &&`,
+		`type == "web"`,
+		`meta[env] == "prod"`,
+		`meta["env" == "prod"`,
+		`metrics["rows"] == "42"`,
+		`metrics["rows"] =~ "4"`,
+		`resource =~ "("`,
+		`(service == "web"`,
+		`service == "web")`,
+		`service == "web`,
+		`service = "web"`,
+		`metrics["rows"] > 1.2.3`,
+	} {
+		t.Run(expr, func(t *testing.T) {
+			_, err := compileExpr(expr)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestSpanRules(t *testing.T) {
+	rules := NewSpanRules()
+	require.NoError(t, rules.Reload([]config.SpanRule{
+		{Match: `resource == "GET /health"`, Action: "drop"},
+		{Match: `meta["user.email"]`, Action: "remove_tags", TagKeys: []string{"user.email", "user.score"}},
+		{Match: `service == "db"`, Action: "add_tags", Tags: map[string]string{"team": "storage"}},
+		{Match: `name == "sql.query"`, Action: "rename_resource", Resource: "query"},
+		{Match: `metrics["_dd.debug"] == 1`, Action: "keep"},
+	}))
+	assert.Equal(t, 5, rules.Len())
+
+	t.Run("spans", func(t *testing.T) {
+		root := &pb.Span{SpanID: 1, Service: "web", Resource: "GET /users", Meta: map[string]string{"user.email": "a@b.c"}, Metrics: map[string]float64{"user.score": 3}}
+		health := &pb.Span{SpanID: 2, ParentID: 1, Service: "web", Resource: "GET /health"}
+		query := &pb.Span{SpanID: 3, ParentID: 1, Service: "db", Name: "sql.query", Resource: "SELECT 1"}
+		chunk := &pb.TraceChunk{Spans: []*pb.Span{root, health, query}}
+
+		res := rules.Apply(chunk, root)
+		assert.Equal(t, SpanRulesResult{DroppedSpans: 1}, res)
+		assert.Equal(t, []*pb.Span{root, query}, chunk.Spans)
+		assert.Empty(t, root.Meta)
+		assert.Empty(t, root.Metrics)
+		assert.Equal(t, "storage", query.Meta["team"])
+		assert.Equal(t, "query", query.Resource)
+	})
+
+	t.Run("root", func(t *testing.T) {
+		root := &pb.Span{SpanID: 1, Resource: "GET /health"}
+		chunk := &pb.TraceChunk{Spans: []*pb.Span{root, {SpanID: 2, ParentID: 1}}}
+		res := rules.Apply(chunk, root)
+		assert.True(t, res.DropTrace)
+		assert.Equal(t, 1, res.DroppedSpans)
+	})
+
+	t.Run("keep", func(t *testing.T) {
+		root := &pb.Span{SpanID: 1}
+		chunk := &pb.TraceChunk{Spans: []*pb.Span{root, {SpanID: 2, ParentID: 1, Metrics: map[string]float64{"_dd.debug": 1}}}}
+		res := rules.Apply(chunk, root)
+		assert.Equal(t, SpanRulesResult{Keep: true}, res)
+		assert.Len(t, chunk.Spans, 2)
+	})
+
+	t.Run("reload", func(t *testing.T) {
+		// an invalid rule leaves the rules unchanged
+		assert.Error(t, rules.Reload([]config.SpanRule{{Match: `service == "web"`, Action: "drop"}, {Match: `service ==`, Action: "drop"}}))
+		assert.Equal(t, 5, rules.Len())
+
+		require.NoError(t, rules.Reload(nil))
+		root := &pb.Span{SpanID: 1, Resource: "GET /health"}
+		chunk := &pb.TraceChunk{Spans: []*pb.Span{root}}
+		assert.Equal(t, SpanRulesResult{}, rules.Apply(chunk, root))
+		assert.Len(t, chunk.Spans, 1)
+	})
+}
+
+func TestSpanRulesInvalid(t *testing.T) {
+	for name, rule := range map[string]config.SpanRule{
+		"match":           {Action: "drop"},
+		"action":          {Match: `service == "web"`, Action: "unknown"},
+		"add_tags":        {Match: `service == "web"`, Action: "add_tags"},
+		"remove_tags":     {Match: `service == "web"`, Action: "remove_tags"},
+		"rename_resource": {Match: `service == "web"`, Action: "rename_resource"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Error(t, NewSpanRules().Reload([]config.SpanRule{rule}))
+		})
+	}
+}
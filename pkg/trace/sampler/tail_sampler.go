@@ -0,0 +1,341 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package sampler
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/metrics"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/DataDog/datadog-agent/pkg/trace/traceutil"
+)
+
+const (
+	// minTailFlushPeriod is the minimum period between two checks of the decision wait.
+	minTailFlushPeriod = 100 * time.Millisecond
+)
+
+// TailChunk is a chunk buffered by the TailSampler.
+type TailChunk struct {
+	// Chunk is the complete chunk.
+	Chunk *pb.TraceChunk
+	// Root is the root span of the chunk.
+	Root *pb.Span
+	// Payload holds the fields of the payload the chunk was received in, without its chunks.
+	Payload *pb.TracerPayload
+	// Sampled reports whether the chunk was kept by the samplers.
+	Sampled bool
+	// Dropped is the chunk to send when the trace is dropped, it holds the analyzed spans
+	// extracted from Chunk. It is nil when there is nothing to send.
+	Dropped *pb.TraceChunk
+	// Events is the number of analyzed spans extracted from Chunk.
+	Events int64
+}
+
+// TailDecisionFunc receives the chunks of a trace once the TailSampler has decided whether to keep it.
+type TailDecisionFunc func(keep bool, chunks []*TailChunk)
+
+// tailPolicy keeps the traces it matches.
+type tailPolicy struct {
+	name    string
+	matches func(spans []*pb.Span) bool
+}
+
+// tailTrace holds the buffered chunks of a trace.
+type tailTrace struct {
+	id        uint64
+	firstSeen time.Time
+	chunks    []*TailChunk
+	spans     int
+	elem      *list.Element
+}
+
+// TailSampler buffers the chunks of the traces by trace ID for a decision wait, then keeps
+// the whole trace if any of its chunks was kept by the samplers or if it matches one of the
+// policies. The number of buffered spans is bounded: once it is reached, the decision is made
+// early for the oldest traces. The decisions are remembered for another decision wait so
+// that the late chunks of a trace follow its decision, up to as many decisions as buffered
+// spans, the oldest decisions being forgotten first.
+type TailSampler struct {
+	decisionWait time.Duration
+	maxSpans     int
+	policies     []tailPolicy
+	decide       TailDecisionFunc
+
+	mu        sync.Mutex
+	traces    map[uint64]*tailTrace
+	order     *list.List // traces ordered by arrival of their first chunk
+	spans     int
+	decisions map[uint64]*tailDecision
+	decided   *list.List // decisions ordered by time
+
+	kept    map[string]int64 // kept traces count by policy
+	dropped int64
+	evicted int64
+
+	exit chan struct{}
+	done chan struct{}
+}
+
+type tailDecision struct {
+	id   uint64
+	keep bool
+	at   time.Time
+	elem *list.Element
+}
+
+// NewTailSampler returns a TailSampler calling decide with the chunks of each trace it decides on.
+func NewTailSampler(conf *config.TailSamplingConfig, decide TailDecisionFunc) (*TailSampler, error) {
+	if conf.DecisionWaitSeconds <= 0 {
+		return nil, fmt.Errorf("tail sampling decision wait must be positive, got %v", conf.DecisionWaitSeconds)
+	}
+	if conf.MaxSpans <= 0 {
+		return nil, fmt.Errorf("tail sampling max spans must be positive, got %d", conf.MaxSpans)
+	}
+	policies := make([]tailPolicy, 0, len(conf.Policies))
+	for i, p := range conf.Policies {
+		policy, err := newTailPolicy(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tail sampling policy %d: %v", i, err)
+		}
+		policies = append(policies, policy)
+	}
+	return &TailSampler{
+		decisionWait: time.Duration(conf.DecisionWaitSeconds * float64(time.Second)),
+		maxSpans:     conf.MaxSpans,
+		policies:     policies,
+		decide:       decide,
+		traces:       make(map[uint64]*tailTrace),
+		order:        list.New(),
+		decisions:    make(map[uint64]*tailDecision),
+		decided:      list.New(),
+		kept:         make(map[string]int64),
+		exit:         make(chan struct{}),
+		done:         make(chan struct{}),
+	}, nil
+}
+
+func newTailPolicy(conf config.TailSamplingPolicy) (tailPolicy, error) {
+	name := conf.Name
+	if name == "" {
+		name = conf.Type
+	}
+	switch conf.Type {
+	case "error":
+		return tailPolicy{name: name, matches: func(spans []*pb.Span) bool {
+			for _, s := range spans {
+				if s.Error != 0 {
+					return true
+				}
+			}
+			return false
+		}}, nil
+	case "duration":
+		if conf.MinDurationMs <= 0 {
+			return tailPolicy{}, fmt.Errorf("duration policy %q must have a positive min_duration_ms", name)
+		}
+		minDuration := int64(conf.MinDurationMs * float64(time.Millisecond))
+		return tailPolicy{name: name, matches: func(spans []*pb.Span) bool {
+			root := traceutil.GetRoot(spans)
+			return root != nil && root.Duration >= minDuration
+		}}, nil
+	case "tag":
+		if conf.Tag == "" {
+			return tailPolicy{}, fmt.Errorf("tag policy %q must have a tag", name)
+		}
+		return tailPolicy{name: name, matches: func(spans []*pb.Span) bool {
+			for _, s := range spans {
+				if v, ok := s.Meta[conf.Tag]; ok && (conf.Value == "" || v == conf.Value) {
+					return true
+				}
+				if _, ok := s.Metrics[conf.Tag]; ok && conf.Value == "" {
+					return true
+				}
+			}
+			return false
+		}}, nil
+	}
+	return tailPolicy{}, fmt.Errorf("unknown policy type %q, must be one of error, duration or tag", conf.Type)
+}
+
+// Start starts deciding on the traces whose decision wait is over.
+func (s *TailSampler) Start() {
+	period := s.decisionWait / 10
+	if period < minTailFlushPeriod {
+		period = minTailFlushPeriod
+	}
+	go func() {
+		defer close(s.done)
+		flushTicker := time.NewTicker(period)
+		defer flushTicker.Stop()
+		statsTicker := time.NewTicker(10 * time.Second)
+		defer statsTicker.Stop()
+		for {
+			select {
+			case now := <-flushTicker.C:
+				s.flush(now, false)
+			case <-statsTicker.C:
+				s.report()
+			case <-s.exit:
+				s.flush(time.Now(), true)
+				s.report()
+				return
+			}
+		}
+	}()
+}
+
+// Stop decides on all the buffered traces and stops the sampler.
+func (s *TailSampler) Stop() {
+	close(s.exit)
+	<-s.done
+}
+
+// Add buffers chunk until its trace is decided on.
+func (s *TailSampler) Add(now time.Time, chunk *TailChunk) {
+	if len(chunk.Chunk.Spans) == 0 {
+		return
+	}
+	id := chunk.Chunk.Spans[0].TraceID
+
+	s.mu.Lock()
+	if d, ok := s.decisions[id]; ok {
+		s.mu.Unlock()
+		s.decide(d.keep, []*TailChunk{chunk})
+		return
+	}
+	t, ok := s.traces[id]
+	if !ok {
+		t = &tailTrace{id: id, firstSeen: now}
+		t.elem = s.order.PushBack(t)
+		s.traces[id] = t
+	}
+	t.chunks = append(t.chunks, chunk)
+	t.spans += len(chunk.Chunk.Spans)
+	s.spans += len(chunk.Chunk.Spans)
+
+	var evicted []*tailTrace
+	for s.spans > s.maxSpans && s.order.Len() > 0 {
+		evicted = append(evicted, s.remove(s.order.Front().Value.(*tailTrace)))
+	}
+	s.evicted += int64(len(evicted))
+	decisions := s.decideLocked(now, evicted)
+	s.mu.Unlock()
+
+	s.emit(evicted, decisions)
+}
+
+// flush decides on the traces whose decision wait is over, or on all of them if all is true.
+func (s *TailSampler) flush(now time.Time, all bool) {
+	s.mu.Lock()
+	var ready []*tailTrace
+	for s.order.Len() > 0 {
+		t := s.order.Front().Value.(*tailTrace)
+		if !all && now.Sub(t.firstSeen) < s.decisionWait {
+			break
+		}
+		ready = append(ready, s.remove(t))
+	}
+	for s.decided.Len() > 0 {
+		d := s.decided.Front().Value.(*tailDecision)
+		if now.Sub(d.at) < s.decisionWait {
+			break
+		}
+		s.forget(d)
+	}
+	decisions := s.decideLocked(now, ready)
+	s.mu.Unlock()
+
+	s.emit(ready, decisions)
+}
+
+// remove stops buffering t.
+func (s *TailSampler) remove(t *tailTrace) *tailTrace {
+	s.order.Remove(t.elem)
+	delete(s.traces, t.id)
+	s.spans -= t.spans
+	return t
+}
+
+// decideLocked returns whether to keep each of traces and remembers the decisions.
+func (s *TailSampler) decideLocked(now time.Time, traces []*tailTrace) []bool {
+	decisions := make([]bool, len(traces))
+	for i, t := range traces {
+		keep, policy := s.match(t)
+		if keep {
+			s.kept[policy]++
+		} else {
+			s.dropped++
+		}
+		decisions[i] = keep
+		s.remember(tailDecision{id: t.id, keep: keep, at: now})
+	}
+	return decisions
+}
+
+// remember remembers d, forgetting the oldest decisions past maxSpans decisions.
+func (s *TailSampler) remember(d tailDecision) {
+	if old, ok := s.decisions[d.id]; ok {
+		s.forget(old)
+	}
+	for s.decided.Len() >= s.maxSpans {
+		s.forget(s.decided.Front().Value.(*tailDecision))
+	}
+	d.elem = s.decided.PushBack(&d)
+	s.decisions[d.id] = &d
+}
+
+func (s *TailSampler) forget(d *tailDecision) {
+	s.decided.Remove(d.elem)
+	delete(s.decisions, d.id)
+}
+
+// match returns whether t must be kept and the name of the policy keeping it,
+// or "sampler" if it was kept by the samplers.
+func (s *TailSampler) match(t *tailTrace) (bool, string) {
+	for _, c := range t.chunks {
+		if c.Sampled {
+			return true, "sampler"
+		}
+	}
+	if len(s.policies) == 0 {
+		return false, ""
+	}
+	spans := make([]*pb.Span, 0, t.spans)
+	for _, c := range t.chunks {
+		spans = append(spans, c.Chunk.Spans...)
+	}
+	for _, p := range s.policies {
+		if p.matches(spans) {
+			return true, p.name
+		}
+	}
+	return false, ""
+}
+
+func (s *TailSampler) emit(traces []*tailTrace, decisions []bool) {
+	for i, t := range traces {
+		s.decide(decisions[i], t.chunks)
+	}
+}
+
+func (s *TailSampler) report() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for policy, n := range s.kept {
+		metrics.Count("datadog.trace_agent.tail_sampler.kept", n, []string{"policy:" + policy}, 1)
+		delete(s.kept, policy)
+	}
+	metrics.Count("datadog.trace_agent.tail_sampler.dropped", s.dropped, nil, 1)
+	metrics.Count("datadog.trace_agent.tail_sampler.evicted", s.evicted, nil, 1)
+	metrics.Gauge("datadog.trace_agent.tail_sampler.buffered_spans", float64(s.spans), nil, 1)
+	metrics.Gauge("datadog.trace_agent.tail_sampler.buffered_traces", float64(len(s.traces)), nil, 1)
+	s.dropped, s.evicted = 0, 0
+}
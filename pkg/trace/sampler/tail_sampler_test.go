@@ -0,0 +1,156 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package sampler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tailDecisions map[uint64][]bool
+
+func (d tailDecisions) record(keep bool, chunks []*TailChunk) {
+	for _, c := range chunks {
+		id := c.Chunk.Spans[0].TraceID
+		d[id] = append(d[id], keep)
+	}
+}
+
+func newTailChunk(sampled bool, spans ...*pb.Span) *TailChunk {
+	return &TailChunk{
+		Chunk:   &pb.TraceChunk{Spans: spans, Priority: int32(PriorityAutoDrop)},
+		Root:    spans[0],
+		Payload: &pb.TracerPayload{},
+		Sampled: sampled,
+	}
+}
+
+func newTestTailSampler(t *testing.T, conf *config.TailSamplingConfig) (*TailSampler, tailDecisions) {
+	decisions := make(tailDecisions)
+	s, err := NewTailSampler(conf, decisions.record)
+	require.NoError(t, err)
+	return s, decisions
+}
+
+func TestTailSamplerPolicies(t *testing.T) {
+	s, decisions := newTestTailSampler(t, &config.TailSamplingConfig{
+		DecisionWaitSeconds: 10,
+		MaxSpans:            100,
+		Policies: []config.TailSamplingPolicy{
+			{Type: "error"},
+			{Name: "slow", Type: "duration", MinDurationMs: 500},
+			{Type: "tag", Tag: "customer.tier", Value: "gold"},
+		},
+	})
+	now := time.Now()
+
+	// the error is in the second chunk of the trace
+	s.Add(now, newTailChunk(false, &pb.Span{TraceID: 1, SpanID: 1, Duration: 10}))
+	s.Add(now, newTailChunk(false, &pb.Span{TraceID: 1, SpanID: 2, ParentID: 1, Error: 1}))
+	// the root span is slow
+	s.Add(now, newTailChunk(false, &pb.Span{TraceID: 2, SpanID: 3, ParentID: 4}))
+	s.Add(now, newTailChunk(false, &pb.Span{TraceID: 2, SpanID: 4, Duration: int64(time.Second)}))
+	// a span has the tag
+	s.Add(now, newTailChunk(false, &pb.Span{TraceID: 3, SpanID: 5, Meta: map[string]string{"customer.tier": "gold"}}))
+	// no policy matches
+	s.Add(now, newTailChunk(false, &pb.Span{TraceID: 4, SpanID: 6, Meta: map[string]string{"customer.tier": "silver"}}))
+	// a chunk was kept by the samplers
+	s.Add(now, newTailChunk(true, &pb.Span{TraceID: 5, SpanID: 7}))
+	s.Add(now, newTailChunk(false, &pb.Span{TraceID: 5, SpanID: 8, ParentID: 7}))
+
+	s.flush(now.Add(5*time.Second), false)
+	assert.Empty(t, decisions)
+
+	s.flush(now.Add(10*time.Second), false)
+	assert.Equal(t, tailDecisions{
+		1: {true, true},
+		2: {true, true},
+		3: {true},
+		4: {false},
+		5: {true, true},
+	}, decisions)
+
+	// late chunks follow the decision of their trace
+	s.Add(now.Add(12*time.Second), newTailChunk(false, &pb.Span{TraceID: 4, SpanID: 9, Error: 1}))
+	assert.Equal(t, []bool{false, false}, decisions[4])
+
+	// the decisions are forgotten after another decision wait
+	s.flush(now.Add(25*time.Second), false)
+	s.Add(now.Add(25*time.Second), newTailChunk(false, &pb.Span{TraceID: 4, SpanID: 10, Error: 1}))
+	s.flush(now.Add(35*time.Second), false)
+	assert.Equal(t, []bool{false, false, true}, decisions[4])
+}
+
+func TestTailSamplerMaxSpans(t *testing.T) {
+	s, decisions := newTestTailSampler(t, &config.TailSamplingConfig{
+		DecisionWaitSeconds: 10,
+		MaxSpans:            3,
+		Policies:            []config.TailSamplingPolicy{{Type: "error"}},
+	})
+	now := time.Now()
+
+	s.Add(now, newTailChunk(false, &pb.Span{TraceID: 1, SpanID: 1}, &pb.Span{TraceID: 1, SpanID: 2, ParentID: 1}))
+	s.Add(now, newTailChunk(false, &pb.Span{TraceID: 2, SpanID: 3}))
+	assert.Empty(t, decisions)
+
+	// the oldest trace is decided early
+	s.Add(now, newTailChunk(false, &pb.Span{TraceID: 3, SpanID: 4, Error: 1}))
+	assert.Equal(t, tailDecisions{1: {false}}, decisions)
+	assert.Equal(t, 2, s.spans)
+	assert.Equal(t, int64(1), s.evicted)
+}
+
+func TestTailSamplerMaxDecisions(t *testing.T) {
+	s, decisions := newTestTailSampler(t, &config.TailSamplingConfig{
+		DecisionWaitSeconds: 10,
+		MaxSpans:            2,
+	})
+	now := time.Now()
+
+	for id := uint64(1); id <= 3; id++ {
+		s.Add(now, newTailChunk(id == 1, &pb.Span{TraceID: id, SpanID: id}))
+		s.flush(now.Add(10*time.Second), false)
+	}
+	assert.Len(t, s.decisions, 2)
+
+	// the oldest decision is forgotten first
+	s.Add(now.Add(11*time.Second), newTailChunk(false, &pb.Span{TraceID: 1, SpanID: 4}))
+	assert.Equal(t, []bool{true}, decisions[1])
+	s.Add(now.Add(11*time.Second), newTailChunk(false, &pb.Span{TraceID: 3, SpanID: 5}))
+	assert.Equal(t, []bool{false, false}, decisions[3])
+}
+
+func TestTailSamplerStop(t *testing.T) {
+	s, decisions := newTestTailSampler(t, &config.TailSamplingConfig{
+		DecisionWaitSeconds: 3600,
+		MaxSpans:            100,
+		Policies:            []config.TailSamplingPolicy{{Type: "error"}},
+	})
+	s.Start()
+	s.Add(time.Now(), newTailChunk(false, &pb.Span{TraceID: 1, SpanID: 1, Error: 1}))
+	s.Stop()
+	assert.Equal(t, tailDecisions{1: {true}}, decisions)
+}
+
+func TestTailSamplerInvalidConfig(t *testing.T) {
+	for name, conf := range map[string]*config.TailSamplingConfig{
+		"decision wait": {MaxSpans: 1},
+		"max spans":     {DecisionWaitSeconds: 1},
+		"policy type":   {DecisionWaitSeconds: 1, MaxSpans: 1, Policies: []config.TailSamplingPolicy{{Type: "unknown"}}},
+		"duration":      {DecisionWaitSeconds: 1, MaxSpans: 1, Policies: []config.TailSamplingPolicy{{Type: "duration"}}},
+		"tag":           {DecisionWaitSeconds: 1, MaxSpans: 1, Policies: []config.TailSamplingPolicy{{Type: "tag"}}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewTailSampler(conf, func(bool, []*TailChunk) {})
+			assert.Error(t, err)
+		})
+	}
+}
@@ -6,10 +6,13 @@
 package stats
 
 import (
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/trace/log"
+	"github.com/DataDog/datadog-agent/pkg/trace/metrics"
 	"github.com/DataDog/datadog-agent/pkg/trace/pb"
 	"github.com/DataDog/datadog-agent/pkg/trace/traceutil"
 )
@@ -17,6 +20,14 @@ import (
 const (
 	tagStatusCode = "http.status_code"
 	tagSynthetics = "synthetics"
+
+	// extraTagOverflow replaces the values of an extra tag past its maximum number of values.
+	extraTagOverflow = "overflow"
+	// extraTagsSeparator separates the extra tags in BucketsAggregationKey.ExtraTags.
+	extraTagsSeparator = ","
+	// extraTagsTTL is the duration after which a value of an extra tag that was not seen
+	// anymore is forgotten, making room for a new one.
+	extraTagsTTL = 10 * time.Minute
 )
 
 // Aggregation contains all the dimension on which we aggregate statistics.
@@ -33,6 +44,9 @@ type BucketsAggregationKey struct {
 	Type       string
 	StatusCode uint32
 	Synthetics bool
+	// ExtraTags holds the values of the configured extra aggregation tags, as
+	// key:value pairs joined with extraTagsSeparator.
+	ExtraTags string
 }
 
 // PayloadAggregationKey specifies the key by which a payload is aggregated.
@@ -58,6 +72,10 @@ func getStatusCode(s *pb.Span) uint32 {
 
 // NewAggregationFromSpan creates a new aggregation from the provided span and env
 func NewAggregationFromSpan(s *pb.Span, origin string, aggKey PayloadAggregationKey) Aggregation {
+	return newAggregationFromSpan(s, origin, aggKey, nil)
+}
+
+func newAggregationFromSpan(s *pb.Span, origin string, aggKey PayloadAggregationKey, extraTags *extraTagsGuard) Aggregation {
 	synthetics := strings.HasPrefix(origin, tagSynthetics)
 	return Aggregation{
 		PayloadAggregationKey: aggKey,
@@ -68,6 +86,7 @@ func NewAggregationFromSpan(s *pb.Span, origin string, aggKey PayloadAggregation
 			Type:       s.Type,
 			StatusCode: getStatusCode(s),
 			Synthetics: synthetics,
+			ExtraTags:  extraTags.fromSpan(s),
 		},
 	}
 }
@@ -81,6 +100,145 @@ func NewAggregationFromGroup(g pb.ClientGroupedStats) Aggregation {
 			Name:       g.Name,
 			StatusCode: g.HTTPStatusCode,
 			Synthetics: g.Synthetics,
+			ExtraTags:  strings.Join(g.ExtraTags, extraTagsSeparator),
 		},
 	}
 }
+
+// splitExtraTags returns the key:value pairs of BucketsAggregationKey.ExtraTags.
+func splitExtraTags(tags string) []string {
+	if tags == "" {
+		return nil
+	}
+	return strings.Split(tags, extraTagsSeparator)
+}
+
+// extraTagsGuard computes the extra aggregation tags of spans and client stats. It bounds
+// the cardinality of the stats by replacing the values of each tag past maxValues
+// with extraTagOverflow. The values are remembered across flushes so that the tags of
+// a context are stable, until they are not seen for extraTagsTTL.
+type extraTagsGuard struct {
+	keys      []string // sorted and deduplicated
+	maxValues int
+
+	values   map[string]map[string]time.Time // last time each distinct value was seen, by key
+	overflow map[string]int64                // overflowing values count by key since the last flush
+	now      time.Time                       // time of the last flush
+}
+
+// newExtraTagsGuard returns an extraTagsGuard for the given span meta keys. It returns
+// nil if there are no keys, which is safe to use.
+func newExtraTagsGuard(keys []string, maxValues int) *extraTagsGuard {
+	seen := make(map[string]struct{}, len(keys))
+	var sorted []string
+	for _, k := range keys {
+		k = strings.TrimSpace(k)
+		if _, ok := seen[k]; ok || k == "" {
+			continue
+		}
+		seen[k] = struct{}{}
+		sorted = append(sorted, k)
+	}
+	if len(sorted) == 0 {
+		return nil
+	}
+	sort.Strings(sorted)
+	if maxValues <= 0 {
+		log.Warnf("Invalid maximum number of values %d for the stats extra tags, using %d.", maxValues, defaultExtraTagsMaxValues)
+		maxValues = defaultExtraTagsMaxValues
+	}
+	return &extraTagsGuard{
+		keys:      sorted,
+		maxValues: maxValues,
+		values:    make(map[string]map[string]time.Time, len(sorted)),
+		overflow:  make(map[string]int64),
+		now:       time.Now(),
+	}
+}
+
+// defaultExtraTagsMaxValues is used when the configured maximum number of values is invalid.
+const defaultExtraTagsMaxValues = 100
+
+// fromSpan returns the extra tags of s, joined with extraTagsSeparator.
+func (g *extraTagsGuard) fromSpan(s *pb.Span) string {
+	if g == nil {
+		return ""
+	}
+	var b strings.Builder
+	for _, k := range g.keys {
+		v, ok := s.Meta[k]
+		if !ok || v == "" {
+			continue
+		}
+		g.appendTag(&b, k, v)
+	}
+	return b.String()
+}
+
+// fromTags returns the key:value pairs of tags having one of the configured keys,
+// joined with extraTagsSeparator.
+func (g *extraTagsGuard) fromTags(tags []string) string {
+	if g == nil || len(tags) == 0 {
+		return ""
+	}
+	values := make(map[string]string, len(tags))
+	for _, t := range tags {
+		if i := strings.IndexByte(t, ':'); i > 0 {
+			values[t[:i]] = t[i+1:]
+		}
+	}
+	var b strings.Builder
+	for _, k := range g.keys {
+		v, ok := values[k]
+		if !ok || v == "" {
+			continue
+		}
+		g.appendTag(&b, k, v)
+	}
+	return b.String()
+}
+
+func (g *extraTagsGuard) appendTag(b *strings.Builder, k, v string) {
+	// the separator can't be part of a value
+	v = strings.ReplaceAll(v, extraTagsSeparator, "_")
+	values, ok := g.values[k]
+	if !ok {
+		values = make(map[string]time.Time)
+		g.values[k] = values
+	}
+	if _, ok := values[v]; ok || len(values) < g.maxValues {
+		values[v] = g.now
+	} else {
+		g.overflow[k]++
+		v = extraTagOverflow
+	}
+	if b.Len() > 0 {
+		b.WriteString(extraTagsSeparator)
+	}
+	b.WriteString(k)
+	b.WriteByte(':')
+	b.WriteString(v)
+}
+
+// flush reports the number of overflowing values since the last flush and forgets the
+// values which were not seen for extraTagsTTL.
+func (g *extraTagsGuard) flush(now time.Time) {
+	if g == nil {
+		return
+	}
+	for k, n := range g.overflow {
+		metrics.Count("datadog.trace_agent.stats.extra_tags_overflow", n, []string{"tag:" + k}, 1)
+		delete(g.overflow, k)
+	}
+	for k, values := range g.values {
+		for v, seen := range values {
+			if now.Sub(seen) >= extraTagsTTL {
+				delete(values, v)
+			}
+		}
+		if len(values) == 0 {
+			delete(g.values, k)
+		}
+	}
+	g.now = now
+}
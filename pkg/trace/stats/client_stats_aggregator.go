@@ -6,6 +6,7 @@
 package stats
 
 import (
+	"strings"
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/trace/config"
@@ -45,6 +46,8 @@ type ClientStatsAggregator struct {
 	agentEnv      string
 	agentHostname string
 
+	extraTags *extraTagsGuard
+
 	exit chan struct{}
 	done chan struct{}
 }
@@ -59,6 +62,7 @@ func NewClientStatsAggregator(conf *config.AgentConfig, out chan pb.StatsPayload
 		agentEnv:      conf.DefaultEnv,
 		agentHostname: conf.Hostname,
 		oldestTs:      alignAggTs(time.Now().Add(bucketDuration - oldestBucketStart)),
+		extraTags:     newExtraTagsGuard(conf.StatsExtraTags, conf.StatsExtraTagsMaxValues),
 		exit:          make(chan struct{}),
 		done:          make(chan struct{}),
 	}
@@ -99,6 +103,7 @@ func (a *ClientStatsAggregator) flushOnTime(now time.Time) {
 		}
 	}
 	a.oldestTs = flushTs
+	a.extraTags.flush(now)
 }
 
 func (a *ClientStatsAggregator) flushAll() {
@@ -122,6 +127,10 @@ func (a *ClientStatsAggregator) getAggregationBucketTime(now, bs time.Time) (tim
 
 func (a *ClientStatsAggregator) add(now time.Time, p pb.ClientStatsPayload) {
 	for _, clientBucket := range p.Stats {
+		for i := range clientBucket.Stats {
+			// only keep the configured extra tags, bounding their cardinality
+			clientBucket.Stats[i].ExtraTags = splitExtraTags(a.extraTags.fromTags(clientBucket.Stats[i].ExtraTags))
+		}
 		clientBucketStart := time.Unix(0, int64(clientBucket.Start))
 		ts, shifted := a.getAggregationBucketTime(now, clientBucketStart)
 		if shifted {
@@ -236,6 +245,7 @@ func (b *bucket) aggregationToPayloads() []pb.ClientStatsPayload {
 				HTTPStatusCode: aggrKey.StatusCode,
 				Type:           aggrKey.Type,
 				Synthetics:     aggrKey.Synthetics,
+				ExtraTags:      splitExtraTags(aggrKey.ExtraTags),
 				Hits:           counts.hits,
 				Errors:         counts.errors,
 				Duration:       counts.duration,
@@ -270,6 +280,7 @@ func newBucketAggregationKey(b pb.ClientGroupedStats) BucketsAggregationKey {
 		Type:       b.Type,
 		Synthetics: b.Synthetics,
 		StatusCode: b.HTTPStatusCode,
+		ExtraTags:  strings.Join(b.ExtraTags, extraTagsSeparator),
 	}
 }
 
@@ -6,6 +6,7 @@
 package stats
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -70,6 +71,10 @@ func getTestStatsWithStart(start time.Time) pb.ClientStatsPayload {
 	b := pb.ClientStatsBucket{}
 	fuzzer.Fuzz(&b)
 	b.Start = uint64(start.UnixNano())
+	for i := range b.Stats {
+		// extra tags are not configured, the aggregator drops them
+		b.Stats[i].ExtraTags = nil
+	}
 	p := pb.ClientStatsPayload{}
 	fuzzer.Fuzz(&p)
 	p.Tags = nil
@@ -368,3 +373,43 @@ func deepCopyGroupedStats(s []pb.ClientGroupedStats) []pb.ClientGroupedStats {
 	}
 	return new
 }
+
+func TestExtraTagsAggregation(t *testing.T) {
+	assert := assert.New(t)
+	a := NewClientStatsAggregator(&config.AgentConfig{
+		DefaultEnv:              "agentEnv",
+		Hostname:                "agentHostname",
+		StatsExtraTags:          []string{"region"},
+		StatsExtraTagsMaxValues: 1,
+	}, make(chan pb.StatsPayload, 100))
+	testTime := time.Unix(time.Now().Unix(), 0)
+
+	withTags := func(hits uint64, tags ...string) pb.ClientStatsPayload {
+		p := payloadWithCounts(testTime, BucketsAggregationKey{Service: "s"}, hits, 0, 0)
+		p.Stats[0].Stats[0].ExtraTags = tags
+		return p
+	}
+	a.add(testTime, withTags(1, "region:us-east-1", "version:1"))
+	a.add(testTime, withTags(2, "region:us-east-1"))
+	a.add(testTime, withTags(4, "region:eu-west-1"))
+	a.add(testTime, withTags(8))
+	// the distributions are sent with the filtered tags
+	first := <-a.out
+	assert.Len(first.Stats, 2)
+	assert.Equal([]string{"region:us-east-1"}, first.Stats[0].Stats[0].Stats[0].ExtraTags)
+	assert.Equal([]string{"region:us-east-1"}, first.Stats[1].Stats[0].Stats[0].ExtraTags)
+	assert.Equal([]string{"region:overflow"}, (<-a.out).Stats[0].Stats[0].Stats[0].ExtraTags)
+	assert.Nil((<-a.out).Stats[0].Stats[0].Stats[0].ExtraTags)
+
+	a.flushOnTime(testTime.Add(oldestBucketStart + time.Nanosecond))
+	aggCounts := <-a.out
+	hits := make(map[string]uint64)
+	for _, g := range aggCounts.Stats[0].Stats[0].Stats {
+		hits[strings.Join(g.ExtraTags, ",")] += g.Hits
+	}
+	assert.Equal(map[string]uint64{
+		"region:us-east-1": 3,
+		"region:overflow":  4,
+		"":                 8,
+	}, hits)
+}
@@ -46,6 +46,7 @@ type Concentrator struct {
 	mu            sync.Mutex
 	agentEnv      string
 	agentHostname string
+	extraTags     *extraTagsGuard // guarded by mu
 }
 
 // NewConcentrator initializes a new concentrator ready to be started
@@ -64,6 +65,7 @@ func NewConcentrator(conf *config.AgentConfig, out chan pb.StatsPayload, now tim
 		exit:          make(chan struct{}),
 		agentEnv:      conf.DefaultEnv,
 		agentHostname: conf.Hostname,
+		extraTags:     newExtraTagsGuard(conf.StatsExtraTags, conf.StatsExtraTagsMaxValues),
 	}
 	return &c
 }
@@ -177,6 +179,7 @@ func (c *Concentrator) addNow(pt *traceutil.ProcessedTrace, containerID string)
 		b, ok := c.buckets[btime]
 		if !ok {
 			b = NewRawBucket(uint64(btime), uint64(c.bsize))
+			b.extraTags = c.extraTags
 			c.buckets[btime] = b
 		}
 		b.HandleSpan(s, weight, isTop, pt.TraceChunk.Origin, aggKey)
@@ -212,6 +215,7 @@ func (c *Concentrator) flushNow(now int64) pb.StatsPayload {
 		log.Debugf("update oldestTs to %d", newOldestTs)
 		c.oldestTs = newOldestTs
 	}
+	c.extraTags.flush(time.Unix(0, now))
 	c.mu.Unlock()
 	sb := make([]pb.ClientStatsPayload, 0, len(m))
 	for k, s := range m {
@@ -510,3 +510,47 @@ func TestDistributions(t *testing.T) {
 		}
 	})
 }
+
+// TestConcentratorExtraTags tests that the configured extra tags are used as aggregation
+// dimensions, with a bounded number of values.
+func TestConcentratorExtraTags(t *testing.T) {
+	assert := assert.New(t)
+	now := time.Now()
+	cfg := config.AgentConfig{
+		BucketInterval:          time.Duration(testBucketInterval),
+		DefaultEnv:              "env",
+		Hostname:                "hostname",
+		StatsExtraTags:          []string{"region"},
+		StatsExtraTagsMaxValues: 2,
+	}
+	c := NewConcentrator(&cfg, make(chan pb.StatsPayload), now)
+
+	spans := []*pb.Span{
+		testSpan(1, 0, 50, 5, "A1", "resource1", 0),
+		testSpan(2, 0, 40, 5, "A1", "resource1", 0),
+		testSpan(3, 0, 30, 5, "A1", "resource1", 0),
+		testSpan(4, 0, 20, 5, "A1", "resource1", 0),
+		testSpan(5, 0, 10, 5, "A1", "resource1", 0),
+	}
+	for i, region := range []string{"us-east-1", "eu-west-1", "ap-south-1", "us-east-1"} {
+		spans[i].Meta = map[string]string{"region": region}
+	}
+	traceutil.ComputeTopLevel(spans)
+	c.addNow(toProcessedTrace(spans, "none", ""), "")
+
+	stats := c.flushNow(now.UnixNano() + int64(c.bufferLen)*testBucketInterval)
+	hits := make(map[string]uint64)
+	for _, g := range stats.Stats[0].Stats[0].Stats {
+		hits[fmt.Sprint(g.ExtraTags)] += g.Hits
+	}
+	assert.Equal(map[string]uint64{
+		"[region:us-east-1]": 2,
+		"[region:eu-west-1]": 1,
+		"[region:overflow]":  1,
+		"[]":                 1,
+	}, hits)
+	// the values are remembered across flushes
+	assert.Len(c.extraTags.values["region"], 2)
+	c.flushNow(now.Add(extraTagsTTL).UnixNano() + int64(c.bufferLen)*testBucketInterval)
+	assert.Empty(c.extraTags.values)
+}
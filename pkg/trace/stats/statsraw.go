@@ -72,6 +72,7 @@ func (s *groupedStats) export(a Aggregation) (pb.ClientGroupedStats, error) {
 		OkSummary:      okSummary,
 		ErrorSummary:   errSummary,
 		Synthetics:     a.Synthetics,
+		ExtraTags:      splitExtraTags(a.ExtraTags),
 	}, nil
 }
 
@@ -101,6 +102,9 @@ type RawBucket struct {
 
 	// this should really remain private as it's subject to refactoring
 	data map[Aggregation]*groupedStats
+
+	// extraTags computes the extra aggregation tags of the spans, it may be nil.
+	extraTags *extraTagsGuard
 }
 
 // NewRawBucket opens a new calculation bucket for time ts and initializes it properly
@@ -148,7 +152,7 @@ func (sb *RawBucket) HandleSpan(s *pb.Span, weight float64, isTop bool, origin s
 	if aggKey.Env == "" {
 		panic("env should never be empty")
 	}
-	aggr := NewAggregationFromSpan(s, origin, aggKey)
+	aggr := newAggregationFromSpan(s, origin, aggKey, sb.extraTags)
 	sb.add(s, weight, isTop, aggr)
 }
 
@@ -298,3 +298,42 @@ var benchSpans = []*pb.Span{
 		Type:     "lamar",
 	},
 }
+
+func TestGrainWithConfiguredExtraTags(t *testing.T) {
+	assert := assert.New(t)
+	g := newExtraTagsGuard([]string{"region", "customer_tier", "region", " "}, 2)
+	assert.Equal([]string{"customer_tier", "region"}, g.keys)
+
+	span := func(meta map[string]string) *pb.Span {
+		return &pb.Span{Service: "thing", Name: "other", Resource: "yo", Meta: meta}
+	}
+	extraTags := func(s *pb.Span) string {
+		return newAggregationFromSpan(s, "", PayloadAggregationKey{Env: "default"}, g).ExtraTags
+	}
+	assert.Equal("customer_tier:gold,region:us-east-1", extraTags(span(map[string]string{"region": "us-east-1", "customer_tier": "gold", "other": "x"})))
+	// the separator can't be part of a value
+	assert.Equal("region:eu_west", extraTags(span(map[string]string{"region": "eu,west"})))
+	assert.Equal("", extraTags(span(nil)))
+	// past the maximum number of values, values are aggregated together
+	assert.Equal("region:overflow", extraTags(span(map[string]string{"region": "ap-south-1"})))
+	assert.Equal("region:us-east-1", extraTags(span(map[string]string{"region": "us-east-1"})))
+	assert.Equal(int64(1), g.overflow["region"])
+
+	// the values seen recently are kept across flushes
+	g.flush(g.now.Add(extraTagsTTL / 2))
+	assert.Empty(g.overflow)
+	assert.Equal("region:overflow", extraTags(span(map[string]string{"region": "ap-south-1"})))
+	assert.Equal("region:us-east-1", extraTags(span(map[string]string{"region": "us-east-1"})))
+
+	// the values not seen for extraTagsTTL are forgotten
+	g.flush(g.now.Add(extraTagsTTL / 2))
+	assert.Equal("region:ap-south-1", extraTags(span(map[string]string{"region": "ap-south-1"})))
+	assert.Equal("region:overflow", extraTags(span(map[string]string{"region": "eu-west-1"})))
+
+	// client stats tags are filtered on the configured keys
+	assert.Equal("customer_tier:gold", g.fromTags([]string{"customer_tier:gold", "version:1", "invalid"}))
+
+	// no extra tags configured
+	assert.Nil(newExtraTagsGuard(nil, 10))
+	assert.Equal("", newAggregationFromSpan(span(map[string]string{"region": "eu"}), "", PayloadAggregationKey{Env: "default"}, nil).ExtraTags)
+}
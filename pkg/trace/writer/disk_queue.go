@@ -0,0 +1,222 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package writer
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/log"
+)
+
+const (
+	// diskPayloadExtension is the extension of the payload files in the disk queue.
+	diskPayloadExtension = ".payload"
+	// diskTempExtension is the extension of the payload files being written.
+	diskTempExtension = ".tmp"
+)
+
+// diskPayload is the representation of a payload on disk.
+type diskPayload struct {
+	Headers map[string]string
+	Body    []byte
+}
+
+// diskBuffer bounds the total size of the disk queues of the senders of a writer,
+// each sender spilling its payloads to its own queue.
+type diskBuffer struct {
+	maxSize int64
+
+	mu     sync.Mutex // guards below and the state of the queues
+	queues []*diskQueue
+	size   int64 // total size of the files of all the queues
+}
+
+// newDiskBuffer returns a new diskBuffer storing at most maxSize bytes of payloads.
+func newDiskBuffer(maxSize int64) (*diskBuffer, error) {
+	if maxSize <= 0 {
+		return nil, fmt.Errorf("disk buffer max size must be positive, got %d", maxSize)
+	}
+	return &diskBuffer{maxSize: maxSize}, nil
+}
+
+// diskQueue is a FIFO queue of payloads stored as files in a directory. When the
+// maximum size of its buffer is reached, the oldest payloads of all the queues of
+// the buffer are removed to make room for new ones. The payloads left in the
+// directory by a previous run are loaded when the queue is created.
+type diskQueue struct {
+	dir string
+	buf *diskBuffer
+
+	files []string // payload files, oldest first
+	sizes map[string]int64
+}
+
+// newQueue returns a new diskQueue storing its payloads in dir.
+func (b *diskBuffer) newQueue(dir string) (*diskQueue, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	q := &diskQueue{
+		dir:   dir,
+		buf:   b,
+		sizes: make(map[string]int64),
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err := q.reload(); err != nil {
+		return nil, err
+	}
+	b.queues = append(b.queues, q)
+	if err := b.evict(0); err != nil {
+		log.Warnf("Error removing payload files from the disk buffer: %v", err)
+	}
+	return q, nil
+}
+
+// reload loads the payload files found in the queue's directory and removes the
+// ones which were not completely written. It must be called with q.buf.mu held.
+func (q *diskQueue) reload() error {
+	entries, err := ioutil.ReadDir(q.dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		path := filepath.Join(q.dir, e.Name())
+		switch filepath.Ext(path) {
+		case diskTempExtension:
+			if err := os.Remove(path); err != nil {
+				log.Warnf("Error removing incomplete payload file %s: %v", path, err)
+			}
+		case diskPayloadExtension:
+			q.files = append(q.files, path)
+			q.sizes[path] = e.Size()
+			q.buf.size += e.Size()
+		}
+	}
+	// file names start with the time at which they were written
+	sort.Strings(q.files)
+	return nil
+}
+
+// put stores p at the end of the queue, removing the oldest payloads if needed.
+func (q *diskQueue) put(p *payload) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(diskPayload{Headers: p.headers, Body: p.body.Bytes()}); err != nil {
+		return err
+	}
+	size := int64(buf.Len())
+	if size > q.buf.maxSize {
+		return fmt.Errorf("payload is too big for the disk buffer (%d > %d bytes)", size, q.buf.maxSize)
+	}
+
+	q.buf.mu.Lock()
+	defer q.buf.mu.Unlock()
+	if err := q.buf.evict(size); err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(q.dir, fmt.Sprintf("%020d-*%s", time.Now().UnixNano(), diskTempExtension))
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	path := strings.TrimSuffix(tmp, diskTempExtension) + diskPayloadExtension
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	q.files = append(q.files, path)
+	q.sizes[path] = size
+	q.buf.size += size
+	return nil
+}
+
+// pop removes the oldest payload from the queue and returns it. It returns a nil
+// payload when the queue is empty.
+func (q *diskQueue) pop() (*payload, error) {
+	q.buf.mu.Lock()
+	defer q.buf.mu.Unlock()
+	if len(q.files) == 0 {
+		return nil, nil
+	}
+	path := q.files[0]
+	data, err := ioutil.ReadFile(path)
+	// remove the file even when it can't be read, so that it isn't retried forever
+	if err := q.remove(); err != nil {
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	var dp diskPayload
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&dp); err != nil {
+		return nil, fmt.Errorf("error decoding payload file %s: %v", path, err)
+	}
+	p := newPayload(dp.Headers)
+	p.body.Write(dp.Body)
+	return p, nil
+}
+
+// len returns the number of payloads in the queue.
+func (q *diskQueue) len() int {
+	q.buf.mu.Lock()
+	defer q.buf.mu.Unlock()
+	return len(q.files)
+}
+
+// evict removes the oldest payloads of all the queues until there is room for
+// size more bytes. It must be called with b.mu held.
+func (b *diskBuffer) evict(size int64) error {
+	for b.size+size > b.maxSize {
+		var oldest *diskQueue
+		for _, q := range b.queues {
+			if len(q.files) > 0 && (oldest == nil || filepath.Base(q.files[0]) < filepath.Base(oldest.files[0])) {
+				oldest = q
+			}
+		}
+		if oldest == nil {
+			return nil
+		}
+		log.Warnf("Maximum disk buffer size reached. Removing %s", oldest.files[0])
+		if err := oldest.remove(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// remove removes the oldest payload file. It must be called with q.buf.mu held.
+func (q *diskQueue) remove() error {
+	path := q.files[0]
+	q.files = q.files[1:]
+	q.buf.size -= q.sizes[path]
+	delete(q.sizes, path)
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
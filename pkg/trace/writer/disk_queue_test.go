@@ -0,0 +1,114 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package writer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newDiskPayload(body string) *payload {
+	p := newPayload(map[string]string{"Content-Type": "application/msgpack"})
+	p.body.WriteString(body)
+	return p
+}
+
+func newTestDiskQueue(t *testing.T, dir string, maxSize int64) *diskQueue {
+	buf, err := newDiskBuffer(maxSize)
+	require.NoError(t, err)
+	q, err := buf.newQueue(dir)
+	require.NoError(t, err)
+	return q
+}
+
+func TestDiskQueue(t *testing.T) {
+	t.Run("fifo", func(t *testing.T) {
+		q := newTestDiskQueue(t, t.TempDir(), 1024*1024)
+		for _, body := range []string{"first", "second", "third"} {
+			require.NoError(t, q.put(newDiskPayload(body)))
+		}
+		assert.Equal(t, 3, q.len())
+		for _, body := range []string{"first", "second", "third"} {
+			p, err := q.pop()
+			require.NoError(t, err)
+			assert.Equal(t, body, p.body.String())
+			assert.Equal(t, "application/msgpack", p.headers["Content-Type"])
+		}
+		p, err := q.pop()
+		assert.NoError(t, err)
+		assert.Nil(t, p)
+		assert.Zero(t, q.buf.size)
+	})
+
+	t.Run("max-size", func(t *testing.T) {
+		dir := t.TempDir()
+		q := newTestDiskQueue(t, dir, 1024*1024)
+		require.NoError(t, q.put(newDiskPayload("first")))
+		// leave room for a single payload
+		q.buf.maxSize = q.buf.size
+		require.NoError(t, q.put(newDiskPayload("other")))
+		assert.Equal(t, 1, q.len())
+		p, err := q.pop()
+		require.NoError(t, err)
+		assert.Equal(t, "other", p.body.String())
+
+		assert.Error(t, q.put(newDiskPayload(string(make([]byte, q.buf.maxSize)))))
+		files, err := ioutil.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Empty(t, files)
+	})
+
+	t.Run("reload", func(t *testing.T) {
+		dir := t.TempDir()
+		q := newTestDiskQueue(t, dir, 1024*1024)
+		require.NoError(t, q.put(newDiskPayload("first")))
+		require.NoError(t, q.put(newDiskPayload("second")))
+		// a payload which was being written when the agent stopped
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "0-123"+diskTempExtension), []byte("partial"), 0600))
+
+		q = newTestDiskQueue(t, dir, 1024*1024)
+		assert.Equal(t, 2, q.len())
+		p, err := q.pop()
+		require.NoError(t, err)
+		assert.Equal(t, "first", p.body.String())
+		_, err = os.Stat(filepath.Join(dir, "0-123"+diskTempExtension))
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("corrupted", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "0-123"+diskPayloadExtension), []byte("garbage"), 0600))
+		q := newTestDiskQueue(t, dir, 1024*1024)
+		_, err := q.pop()
+		assert.Error(t, err)
+		assert.Zero(t, q.len())
+	})
+
+	t.Run("shared-max-size", func(t *testing.T) {
+		buf, err := newDiskBuffer(1024 * 1024)
+		require.NoError(t, err)
+		first, err := buf.newQueue(t.TempDir())
+		require.NoError(t, err)
+		second, err := buf.newQueue(t.TempDir())
+		require.NoError(t, err)
+
+		require.NoError(t, first.put(newDiskPayload("first")))
+		require.NoError(t, second.put(newDiskPayload("second")))
+		// leave room for two payloads across the queues
+		buf.maxSize = buf.size
+		require.NoError(t, second.put(newDiskPayload("third")))
+
+		// the oldest payload of all the queues was removed
+		assert.Zero(t, first.len())
+		assert.Equal(t, 2, second.len())
+		assert.LessOrEqual(t, buf.size, buf.maxSize)
+	})
+}
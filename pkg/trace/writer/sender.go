@@ -7,6 +7,8 @@ package writer
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
@@ -16,6 +18,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 	"strconv"
 	"sync"
 	"sync/atomic"
@@ -27,11 +30,20 @@ import (
 )
 
 // newSenders returns a list of senders based on the given agent configuration, using climit
-// as the maximum number of concurrent outgoing connections, writing to path.
-func newSenders(cfg *config.AgentConfig, r eventRecorder, path string, climit, qsize int) []*sender {
+// as the maximum number of concurrent outgoing connections, writing to path. When wcfg has a
+// disk buffer, each sender spills the payloads it can't keep in memory to its own directory,
+// the size of the disk buffer being shared by all the senders.
+func newSenders(cfg *config.AgentConfig, wcfg *config.WriterConfig, r eventRecorder, path string, climit, qsize int) []*sender {
 	if e := cfg.Endpoints; len(e) == 0 || e[0].Host == "" || e[0].APIKey == "" {
 		panic(errors.New("config was not properly validated"))
 	}
+	var buf *diskBuffer
+	if wcfg.DiskBufferMaxSize > 0 {
+		var err error
+		if buf, err = newDiskBuffer(wcfg.DiskBufferMaxSize); err != nil {
+			log.Errorf("Error creating disk buffer, payloads will not be spilled to disk: %v", err)
+		}
+	}
 	// spread out the the maximum connection limit (climit) between senders
 	maxConns := math.Max(1, float64(climit/len(cfg.Endpoints)))
 	senders := make([]*sender, len(cfg.Endpoints))
@@ -41,6 +53,14 @@ func newSenders(cfg *config.AgentConfig, r eventRecorder, path string, climit, q
 			log.Criticalf("Invalid host endpoint: %q", endpoint.Host)
 			os.Exit(1)
 		}
+		var disk *diskQueue
+		if buf != nil {
+			dir := filepath.Join(wcfg.DiskBufferPath, diskQueueName(url, endpoint.APIKey))
+			if disk, err = buf.newQueue(dir); err != nil {
+				log.Errorf("Error creating disk buffer in %s, payloads will not be spilled to disk: %v", dir, err)
+				disk = nil
+			}
+		}
 		senders[i] = newSender(&senderConfig{
 			client:    cfg.NewHTTPClient(),
 			maxConns:  int(maxConns),
@@ -48,11 +68,19 @@ func newSenders(cfg *config.AgentConfig, r eventRecorder, path string, climit, q
 			url:       url,
 			apiKey:    endpoint.APIKey,
 			recorder:  r,
+			disk:      disk,
 		})
 	}
 	return senders
 }
 
+// diskQueueName returns the name of the directory of the disk queue of the endpoint
+// at u using apiKey. Endpoints may share a host, e.g. when sending to several orgs.
+func diskQueueName(u *url.URL, apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return u.Hostname() + "-" + hex.EncodeToString(sum[:8])
+}
+
 // eventRecorder implementations are able to take note of events happening in
 // the sender.
 type eventRecorder interface {
@@ -74,6 +102,12 @@ const (
 	// eventTypeDropped specifies that a payload had to be dropped to make room
 	// in the queue.
 	eventTypeDropped
+	// eventTypeSpilled specifies that a payload was written to the disk buffer
+	// instead of being dropped.
+	eventTypeSpilled
+	// eventTypeReplayed specifies that a payload was read back from the disk
+	// buffer and queued for sending.
+	eventTypeReplayed
 )
 
 var eventTypeStrings = map[eventType]string{
@@ -81,6 +115,8 @@ var eventTypeStrings = map[eventType]string{
 	eventTypeSent:     "eventTypeSent",
 	eventTypeRejected: "eventTypeRejected",
 	eventTypeDropped:  "eventTypeDropped",
+	eventTypeSpilled:  "eventTypeSpilled",
+	eventTypeReplayed: "eventTypeReplayed",
 }
 
 // String implements fmt.Stringer.
@@ -125,15 +161,21 @@ type senderConfig struct {
 	// recorder specifies the eventRecorder to use when reporting events occurring
 	// in the sender.
 	recorder eventRecorder
+	// disk specifies the queue to which payloads are spilled instead of being
+	// dropped. When nil, payloads are dropped.
+	disk *diskQueue
 }
 
 // sender is responsible for sending payloads to a given URL. It uses a size-limited
-// retry queue with a backoff mechanism in case of retriable errors.
+// retry queue with a backoff mechanism in case of retriable errors. When a disk queue
+// is configured, the payloads which don't fit in the retry queue are spilled to disk
+// and replayed once the destination is reachable again, or on the next start.
 type sender struct {
 	cfg *senderConfig
 
 	queue    chan *payload // payload queue
 	climit   chan struct{} // semaphore for limiting concurrent connections
+	replay   chan struct{} // signals that spilled payloads may be replayed
 	inflight int32         // inflight payloads
 	attempt  int32         // active retry attempt
 
@@ -149,6 +191,12 @@ func newSender(cfg *senderConfig) *sender {
 		climit: make(chan struct{}, cfg.maxConns),
 	}
 	go s.loop()
+	if cfg.disk != nil {
+		s.replay = make(chan struct{}, 1)
+		go s.replayLoop()
+		// replay the payloads spilled by a previous run
+		s.triggerReplay()
+	}
 	return &s
 }
 
@@ -164,6 +212,70 @@ func (s *sender) loop() {
 	}
 }
 
+// replayLoop queues the payloads spilled to disk while the destination is healthy
+// and the queue has room for them.
+func (s *sender) replayLoop() {
+	for range s.replay {
+		for atomic.LoadInt32(&s.attempt) == 0 && 2*len(s.queue) < cap(s.queue) {
+			p, err := s.cfg.disk.pop()
+			if err != nil {
+				log.Errorf("Error reading payload from disk buffer: %v", err)
+				continue
+			}
+			if p == nil {
+				// disk buffer is empty
+				break
+			}
+			if !s.enqueue(p) {
+				// sender is stopped or the queue filled up in the meantime
+				if err := s.cfg.disk.put(p); err != nil {
+					log.Errorf("Error writing payload back to disk buffer: %v", err)
+				}
+				ppool.Put(p)
+				break
+			}
+			s.recordEvent(eventTypeReplayed, &eventData{
+				bytes: p.body.Len(),
+				count: 1,
+			})
+		}
+	}
+}
+
+// triggerReplay signals the replay loop that spilled payloads may be replayed.
+func (s *sender) triggerReplay() {
+	if s.replay == nil {
+		return
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return
+	}
+	select {
+	case s.replay <- struct{}{}:
+	default:
+		// a replay is already pending
+	}
+}
+
+// enqueue pushes p onto the queue without dropping any payload. It reports
+// whether p was queued.
+func (s *sender) enqueue(p *payload) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return false
+	}
+	select {
+	case s.queue <- p:
+		atomic.AddInt32(&s.inflight, 1)
+		return true
+	default:
+		return false
+	}
+}
+
 // backoff triggers a sleep period proportional to the retry attempt, if any.
 func (s *sender) backoff() {
 	attempt := atomic.LoadInt32(&s.attempt)
@@ -175,12 +287,27 @@ func (s *sender) backoff() {
 }
 
 // Stop stops the sender. It attempts to wait for all inflight payloads to complete
-// with a timeout of 5 seconds.
+// with a timeout of 5 seconds. When a disk queue is configured, the payloads left in
+// the queue are spilled to disk to be sent on the next start.
 func (s *sender) Stop() {
 	s.WaitForInflight()
 	s.mu.Lock()
 	s.closed = true
 	s.mu.Unlock()
+	if s.cfg.disk != nil {
+		for len(s.queue) > 0 {
+			select {
+			case p := <-s.queue:
+				s.drop(p, &eventData{
+					bytes: p.body.Len(),
+					count: 1,
+				})
+			default:
+				// drained by the loop
+			}
+		}
+		close(s.replay)
+	}
 	close(s.queue)
 }
 
@@ -214,7 +341,7 @@ func (s *sender) Push(p *payload) {
 			// drop the oldest item in the queue to make room
 			select {
 			case p := <-s.queue:
-				s.releasePayload(p, eventTypeDropped, &eventData{
+				s.drop(p, &eventData{
 					bytes: p.body.Len(),
 					count: 1,
 				})
@@ -248,7 +375,10 @@ func (s *sender) sendPayload(p *payload) {
 		s.mu.RLock()
 		defer s.mu.RUnlock()
 		if s.closed {
-			// sender is stopped
+			// sender is stopped; keep the payload for the next start if possible
+			if s.cfg.disk != nil {
+				s.drop(p, stats)
+			}
 			return
 		}
 		atomic.AddInt32(&s.attempt, 1)
@@ -265,7 +395,7 @@ func (s *sender) sendPayload(p *payload) {
 			return
 		default:
 			// queue is full; since this is the oldest payload, we drop it
-			s.releasePayload(p, eventTypeDropped, stats)
+			s.drop(p, stats)
 		}
 	case nil:
 		// request was successful; the retry queue may have grown large - we should
@@ -278,6 +408,8 @@ func (s *sender) sendPayload(p *payload) {
 			}
 		}
 		s.releasePayload(p, eventTypeSent, stats)
+		// the destination is reachable; send what was spilled to disk
+		s.triggerReplay()
 	default:
 		// this is a fatal error, we have to drop this payload
 		s.releasePayload(p, eventTypeRejected, stats)
@@ -305,6 +437,20 @@ func (s *sender) releasePayload(p *payload, t eventType, data *eventData) {
 	atomic.AddInt32(&s.inflight, -1)
 }
 
+// drop releases the payload p after spilling it to the disk queue or, if there is
+// no disk queue or spilling fails, after dropping it.
+func (s *sender) drop(p *payload, data *eventData) {
+	if s.cfg.disk != nil {
+		err := s.cfg.disk.put(p)
+		if err == nil {
+			s.releasePayload(p, eventTypeSpilled, data)
+			return
+		}
+		log.Errorf("Error spilling payload to disk buffer: %v", err)
+	}
+	s.releasePayload(p, eventTypeDropped, data)
+}
+
 // recordEvent records the occurrence of the given event type t. It additionally
 // passes on the data and augments it with additional information.
 func (s *sender) recordEvent(t eventType, data *eventData) {
@@ -13,6 +13,7 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"os"
+	"path/filepath"
 	"strconv"
 	"sync"
 	"testing"
@@ -127,6 +128,46 @@ func TestSender(t *testing.T) {
 		assert.Empty(t, s.queue)
 	})
 
+	t.Run("spill", func(t *testing.T) {
+		q := newTestDiskQueue(t, t.TempDir(), 1024*1024)
+		s := &sender{cfg: &senderConfig{disk: q}, queue: make(chan *payload, 2)}
+		p := func(n string) *payload {
+			p := newPayload(nil)
+			p.body.WriteString(n)
+			return p
+		}
+
+		s.Push(p("1"))
+		s.Push(p("2"))
+		s.Push(p("3"))
+		s.Push(p("4"))
+
+		assert.Equal(t, "3", (<-s.queue).body.String())
+		assert.Equal(t, "4", (<-s.queue).body.String())
+		assert.Equal(t, 2, q.len())
+		spilled, err := q.pop()
+		assert.NoError(t, err)
+		assert.Equal(t, "1", spilled.body.String())
+	})
+
+	t.Run("replay", func(t *testing.T) {
+		server := newTestServer()
+		defer server.Close()
+
+		q := newTestDiskQueue(t, t.TempDir(), 1024*1024)
+		for i := 0; i < 5; i++ {
+			assert.NoError(t, q.put(expectResponses(200)))
+		}
+		cfg := testSenderConfig(server.URL)
+		cfg.disk = q
+		s := newSender(cfg)
+		assert.Eventually(t, func() bool { return server.Accepted() == 5 }, 5*time.Second, 10*time.Millisecond)
+		s.Stop()
+
+		assert.Equal(t, 5, server.Total(), "total")
+		assert.Zero(t, q.len())
+	})
+
 	t.Run("failed", func(t *testing.T) {
 		assert := assert.New(t)
 		server := newTestServer()
@@ -254,6 +295,32 @@ func TestSender(t *testing.T) {
 	})
 }
 
+func TestNewSendersDiskBuffer(t *testing.T) {
+	server := newTestServer()
+	defer server.Close()
+
+	cfg := config.New()
+	// additional endpoints on the same host, under other API keys
+	cfg.Endpoints = []*config.Endpoint{
+		{Host: server.URL, APIKey: "key1"},
+		{Host: server.URL, APIKey: "key2"},
+	}
+	dir := t.TempDir()
+	wcfg := &config.WriterConfig{DiskBufferPath: dir, DiskBufferMaxSize: 1024 * 1024}
+	senders := newSenders(cfg, wcfg, &mockRecorder{}, pathTraces, 10, 10)
+	defer stopSenders(senders)
+
+	first, second := senders[0].cfg.disk, senders[1].cfg.disk
+	if !assert.NotNil(t, first) || !assert.NotNil(t, second) {
+		return
+	}
+	assert.NotEqual(t, first.dir, second.dir)
+	assert.Equal(t, dir, filepath.Dir(first.dir))
+	assert.Equal(t, dir, filepath.Dir(second.dir))
+	// the maximum size applies to the payloads of all the endpoints
+	assert.Same(t, first.buf, second.buf)
+}
+
 func TestPayload(t *testing.T) {
 	expectBody := bytes.NewBufferString("body")
 	bodyLength := strconv.Itoa(expectBody.Len())
@@ -306,8 +373,8 @@ func useBackoffDuration(d time.Duration) func() {
 
 // mockRecorder is a mock eventRecorder which records all calls to recordEvent.
 type mockRecorder struct {
-	mu                             sync.RWMutex
-	retry, sent, dropped, rejected []*eventData
+	mu                                                sync.RWMutex
+	retry, sent, dropped, rejected, spilled, replayed []*eventData
 }
 
 // data returns all call data for the given eventType.
@@ -323,6 +390,10 @@ func (r *mockRecorder) data(t eventType) []*eventData {
 		return r.dropped
 	case eventTypeRejected:
 		return r.rejected
+	case eventTypeSpilled:
+		return r.spilled
+	case eventTypeReplayed:
+		return r.replayed
 	default:
 		panic("unknown event")
 	}
@@ -341,5 +412,9 @@ func (r *mockRecorder) recordEvent(t eventType, data *eventData) {
 		r.dropped = append(r.dropped, data)
 	case eventTypeRejected:
 		r.rejected = append(r.rejected, data)
+	case eventTypeSpilled:
+		r.spilled = append(r.spilled, data)
+	case eventTypeReplayed:
+		r.replayed = append(r.replayed, data)
 	}
 }
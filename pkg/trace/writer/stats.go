@@ -83,7 +83,7 @@ func NewStatsWriter(cfg *config.AgentConfig, in <-chan pb.StatsPayload) *StatsWr
 		qsize = int(math.Max(1, maxmem/payloadSize))
 	}
 	log.Debugf("Stats writer initialized (climit=%d qsize=%d)", climit, qsize)
-	sw.senders = newSenders(cfg, sw, pathStats, climit, qsize)
+	sw.senders = newSenders(cfg, cfg.StatsWriter, sw, pathStats, climit, qsize)
 	return sw
 }
 
@@ -352,5 +352,15 @@ func (w *StatsWriter) recordEvent(t eventType, data *eventData) {
 		w.easylog.Warn("Stats writer queue full. Payload dropped (%.2fKB).", float64(data.bytes)/1024)
 		metrics.Count("datadog.trace_agent.stats_writer.dropped", 1, nil, 1)
 		metrics.Count("datadog.trace_agent.stats_writer.dropped_bytes", int64(data.bytes), nil, 1)
+
+	case eventTypeSpilled:
+		w.easylog.Warn("Stats writer payload spilled to disk (%.2fKB).", float64(data.bytes)/1024)
+		metrics.Count("datadog.trace_agent.stats_writer.spilled", 1, nil, 1)
+		metrics.Count("datadog.trace_agent.stats_writer.spilled_bytes", int64(data.bytes), nil, 1)
+
+	case eventTypeReplayed:
+		log.Debugf("Replaying stats payload from disk (%d bytes)", data.bytes)
+		metrics.Count("datadog.trace_agent.stats_writer.replayed", 1, nil, 1)
+		metrics.Count("datadog.trace_agent.stats_writer.replayed_bytes", int64(data.bytes), nil, 1)
 	}
 }
@@ -106,7 +106,7 @@ func NewTraceWriter(cfg *config.AgentConfig) *TraceWriter {
 		tw.tick = time.Duration(s*1000) * time.Millisecond
 	}
 	log.Debugf("Trace writer initialized (climit=%d qsize=%d)", climit, qsize)
-	tw.senders = newSenders(cfg, tw, pathTraces, climit, qsize)
+	tw.senders = newSenders(cfg, cfg.TraceWriter, tw, pathTraces, climit, qsize)
 	return tw
 }
 
@@ -308,5 +308,15 @@ func (w *TraceWriter) recordEvent(t eventType, data *eventData) {
 		w.easylog.Warn("Trace writer queue full. Payload dropped (%.2fKB).", float64(data.bytes)/1024)
 		metrics.Count("datadog.trace_agent.trace_writer.dropped", 1, nil, 1)
 		metrics.Count("datadog.trace_agent.trace_writer.dropped_bytes", int64(data.bytes), nil, 1)
+
+	case eventTypeSpilled:
+		w.easylog.Warn("Trace writer payload spilled to disk (%.2fKB).", float64(data.bytes)/1024)
+		metrics.Count("datadog.trace_agent.trace_writer.spilled", 1, nil, 1)
+		metrics.Count("datadog.trace_agent.trace_writer.spilled_bytes", int64(data.bytes), nil, 1)
+
+	case eventTypeReplayed:
+		log.Debugf("Replaying trace payload from disk (%d bytes)", data.bytes)
+		metrics.Count("datadog.trace_agent.trace_writer.replayed", 1, nil, 1)
+		metrics.Count("datadog.trace_agent.trace_writer.replayed_bytes", int64(data.bytes), nil, 1)
 	}
 }
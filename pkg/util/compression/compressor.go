@@ -0,0 +1,206 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package compression
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// Kinds of compression which can be selected at runtime
+const (
+	NoneKind = "none"
+	ZlibKind = "zlib"
+	GzipKind = "gzip"
+	ZstdKind = "zstd"
+)
+
+// Compressor is a compression strategy, that is a compression algorithm with a compression level.
+type Compressor interface {
+	// Compress returns the compressed src
+	Compress(src []byte) ([]byte, error)
+	// Decompress returns the decompressed src
+	Decompress(src []byte) ([]byte, error)
+	// CompressBound returns the worst case size needed for a destination buffer
+	CompressBound(sourceLen int) int
+	// ContentEncoding returns the HTTP header value associated with the compression method,
+	// empty if the payloads are not compressed
+	ContentEncoding() string
+	// NewStreamCompressor returns a StreamCompressor writing the compressed data to output
+	NewStreamCompressor(output *bytes.Buffer) StreamCompressor
+}
+
+// StreamCompressor compresses the data written to it
+type StreamCompressor interface {
+	io.WriteCloser
+	// Flush writes the pending compressed data to the output
+	Flush() error
+}
+
+// Settings are the settings of a compressor
+type Settings struct {
+	Kind  string `mapstructure:"kind"`
+	Level int    `mapstructure:"level"`
+}
+
+// NewCompressor returns the compressor of the given kind with the given level. The empty kind
+// selects the compression the agent was built with, and the level 0 the default level of the
+// compression.
+func NewCompressor(kind string, level int) (Compressor, error) {
+	if kind == "" {
+		kind = DefaultKind
+	}
+	switch kind {
+	case NoneKind:
+		return noneCompressor{}, nil
+	case ZlibKind, GzipKind:
+		if level == 0 {
+			level = flate.DefaultCompression
+		}
+		if level < flate.HuffmanOnly || level > flate.BestCompression {
+			return nil, fmt.Errorf("invalid %s compression level %d", kind, level)
+		}
+		if kind == GzipKind {
+			return gzipCompressor{level: level}, nil
+		}
+		return zlibCompressor{level: level}, nil
+	case ZstdKind:
+		return newZstdCompressor(level)
+	}
+	return nil, fmt.Errorf("unknown compression kind %q", kind)
+}
+
+// Default returns the compressor of the compression the agent was built with
+func Default() Compressor {
+	c, _ := NewCompressor(DefaultKind, 0)
+	return c
+}
+
+// ForContentEncoding returns a compressor able to decompress the payloads compressed with the
+// HTTP content encoding, or false if the encoding is unknown.
+func ForContentEncoding(encoding string) (Compressor, bool) {
+	var kind string
+	switch encoding {
+	case "":
+		kind = NoneKind
+	case "deflate":
+		kind = ZlibKind
+	case "gzip":
+		kind = GzipKind
+	case "zstd":
+		kind = ZstdKind
+	default:
+		return nil, false
+	}
+	c, err := NewCompressor(kind, 0)
+	return c, err == nil
+}
+
+// noneCompressor does not compress anything
+type noneCompressor struct{}
+
+func (noneCompressor) Compress(src []byte) ([]byte, error)   { return src, nil }
+func (noneCompressor) Decompress(src []byte) ([]byte, error) { return src, nil }
+func (noneCompressor) CompressBound(sourceLen int) int       { return sourceLen }
+func (noneCompressor) ContentEncoding() string               { return "" }
+
+func (noneCompressor) NewStreamCompressor(output *bytes.Buffer) StreamCompressor {
+	return nopStreamCompressor{output}
+}
+
+type nopStreamCompressor struct {
+	*bytes.Buffer
+}
+
+func (nopStreamCompressor) Flush() error { return nil }
+func (nopStreamCompressor) Close() error { return nil }
+
+// zlibCompressor compresses with zlib
+type zlibCompressor struct {
+	level int
+}
+
+func (c zlibCompressor) Compress(src []byte) ([]byte, error) {
+	var b bytes.Buffer
+	w, err := zlib.NewWriterLevel(&b, c.level)
+	if err != nil {
+		return nil, err
+	}
+	return writeAndClose(w, &b, src)
+}
+
+func (zlibCompressor) Decompress(src []byte) ([]byte, error) {
+	r, err := zlib.NewReader(bytes.NewReader(src))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ioutil.ReadAll(r)
+}
+
+func (zlibCompressor) CompressBound(sourceLen int) int {
+	// From https://code.woboq.org/gcc/zlib/compress.c.html#compressBound
+	return sourceLen + (sourceLen >> 12) + (sourceLen >> 14) + (sourceLen >> 25) + 13
+}
+
+func (zlibCompressor) ContentEncoding() string { return "deflate" }
+
+func (c zlibCompressor) NewStreamCompressor(output *bytes.Buffer) StreamCompressor {
+	// the level is validated by NewCompressor
+	w, _ := zlib.NewWriterLevel(output, c.level)
+	return w
+}
+
+// gzipCompressor compresses with gzip
+type gzipCompressor struct {
+	level int
+}
+
+func (c gzipCompressor) Compress(src []byte) ([]byte, error) {
+	var b bytes.Buffer
+	w, err := gzip.NewWriterLevel(&b, c.level)
+	if err != nil {
+		return nil, err
+	}
+	return writeAndClose(w, &b, src)
+}
+
+func (gzipCompressor) Decompress(src []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(src))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ioutil.ReadAll(r)
+}
+
+func (gzipCompressor) CompressBound(sourceLen int) int {
+	// the gzip header and trailer are 12 bytes longer than the zlib ones
+	return zlibCompressor{}.CompressBound(sourceLen) + 12
+}
+
+func (gzipCompressor) ContentEncoding() string { return "gzip" }
+
+func (c gzipCompressor) NewStreamCompressor(output *bytes.Buffer) StreamCompressor {
+	// the level is validated by NewCompressor
+	w, _ := gzip.NewWriterLevel(output, c.level)
+	return w
+}
+
+func writeAndClose(w io.WriteCloser, b *bytes.Buffer, src []byte) ([]byte, error) {
+	if _, err := w.Write(src); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
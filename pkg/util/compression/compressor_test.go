@@ -0,0 +1,72 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package compression
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompressors(t *testing.T) {
+	for kind, encoding := range map[string]string{
+		NoneKind: "",
+		ZlibKind: "deflate",
+		GzipKind: "gzip",
+	} {
+		t.Run(kind, func(t *testing.T) {
+			testCompressor(t, kind, encoding)
+		})
+	}
+}
+
+func testCompressor(t *testing.T, kind, encoding string) {
+	payload := []byte(`{"series":[{"metric":"system.load.1","points":[[1636629071,0.5]]}]}`)
+	c, err := NewCompressor(kind, 0)
+	require.NoError(t, err)
+	assert.Equal(t, encoding, c.ContentEncoding())
+
+	compressed, err := c.Compress(payload)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, len(compressed), c.CompressBound(len(payload)))
+
+	var b bytes.Buffer
+	w := c.NewStreamCompressor(&b)
+	_, err = w.Write(payload)
+	require.NoError(t, err)
+	require.NoError(t, w.Flush())
+	require.NoError(t, w.Close())
+
+	decoder, ok := ForContentEncoding(encoding)
+	require.True(t, ok)
+	for _, src := range [][]byte{compressed, b.Bytes()} {
+		decompressed, err := decoder.Decompress(src)
+		require.NoError(t, err)
+		assert.Equal(t, payload, decompressed)
+	}
+}
+
+func TestNewCompressorErrors(t *testing.T) {
+	_, err := NewCompressor("lz4", 0)
+	assert.Error(t, err)
+	_, err = NewCompressor(ZlibKind, 10)
+	assert.Error(t, err)
+	_, err = NewCompressor(GzipKind, -3)
+	assert.Error(t, err)
+	_, err = NewCompressor(ZstdKind, 21)
+	assert.Error(t, err)
+
+	c, err := NewCompressor("", 0)
+	require.NoError(t, err)
+	assert.Equal(t, Default(), c)
+}
+
+func TestForContentEncodingUnknown(t *testing.T) {
+	_, ok := ForContentEncoding("br")
+	assert.False(t, ok)
+}
@@ -8,6 +8,9 @@
 
 package compression
 
+// DefaultKind is the kind of compression selected when none is configured
+const DefaultKind = NoneKind
+
 // ContentEncoding describes the HTTP header value associated with the compression method
 // empty here since there's no compression
 // var instead of const to ease testing
@@ -0,0 +1,15 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build !zstd
+// +build !zstd
+
+package compression
+
+import "fmt"
+
+func newZstdCompressor(int) (Compressor, error) {
+	return nil, fmt.Errorf("%s compression is not available in this build of the agent", ZstdKind)
+}
@@ -14,6 +14,9 @@ import (
 	"io/ioutil"
 )
 
+// DefaultKind is the kind of compression selected when none is configured
+const DefaultKind = ZlibKind
+
 // ContentEncoding describes the HTTP header value associated with the compression method
 // var instead of const to ease testing
 var ContentEncoding = "deflate"
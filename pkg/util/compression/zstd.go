@@ -9,12 +9,18 @@
 package compression
 
 import (
+	"bytes"
+	"fmt"
+
 	zstd_0 "github.com/DataDog/zstd_0"
 )
 
 // TODO: the intake still uses a pre-v1 (unstable) version of the zstd compression format.
 // The agent shouldn't use zstd compression until the intake supports a stable v1 format.
 
+// DefaultKind is the kind of compression selected when none is configured
+const DefaultKind = ZstdKind
+
 // ContentEncoding describes the HTTP header value associated with the compression method
 // var instead of const to ease testing
 var ContentEncoding = "zstd"
@@ -33,3 +39,43 @@ func Decompress(dst []byte, src []byte) ([]byte, error) {
 func CompressBound(sourceLen int) int {
 	return zstd_0.CompressBound(sourceLen)
 }
+
+// zstdCompressor compresses with the pre-v1 version of zstd supported by the intake
+type zstdCompressor struct {
+	level int
+}
+
+func newZstdCompressor(level int) (Compressor, error) {
+	if level == 0 {
+		level = zstd_0.DefaultCompression
+	}
+	if level < 1 || level > zstd_0.BestCompression {
+		return nil, fmt.Errorf("invalid %s compression level %d", ZstdKind, level)
+	}
+	return zstdCompressor{level: level}, nil
+}
+
+func (c zstdCompressor) Compress(src []byte) ([]byte, error) {
+	return zstd_0.CompressLevel(nil, src, c.level)
+}
+
+func (zstdCompressor) Decompress(src []byte) ([]byte, error) {
+	return zstd_0.Decompress(nil, src)
+}
+
+func (zstdCompressor) CompressBound(sourceLen int) int {
+	return zstd_0.CompressBound(sourceLen)
+}
+
+func (zstdCompressor) ContentEncoding() string { return "zstd" }
+
+func (c zstdCompressor) NewStreamCompressor(output *bytes.Buffer) StreamCompressor {
+	return zstdStreamCompressor{zstd_0.NewWriterLevel(output, c.level)}
+}
+
+type zstdStreamCompressor struct {
+	*zstd_0.Writer
+}
+
+// Flush does nothing as the writer compresses and writes out every write
+func (zstdStreamCompressor) Flush() error { return nil }
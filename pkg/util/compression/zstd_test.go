@@ -0,0 +1,31 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build zstd
+// +build zstd
+
+package compression
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestZstdCompressor(t *testing.T) {
+	testCompressor(t, ZstdKind, "zstd")
+}
+
+func TestZstdCompressorIntakeFormat(t *testing.T) {
+	// the payloads must be readable by the pre-v1 zstd of the intake
+	payload := []byte(`{"series":[]}`)
+	c, err := NewCompressor(ZstdKind, 0)
+	assert.NoError(t, err)
+	compressed, err := c.Compress(payload)
+	assert.NoError(t, err)
+	decompressed, err := Decompress(nil, compressed)
+	assert.NoError(t, err)
+	assert.Equal(t, payload, decompressed)
+}
@@ -20,12 +20,13 @@ type Collector interface {
 	Pull(context.Context) error
 }
 
-type collectorFactory func() Collector
+// CollectorFactory builds a new instance of a collector.
+type CollectorFactory func() Collector
 
-var collectorCatalog = make(map[string]collectorFactory)
+var collectorCatalog = make(map[string]CollectorFactory)
 
 // RegisterCollector registers a new collector, identified by an id for logging
 // and telemetry purposes, to be used by the store.
-func RegisterCollector(id string, c collectorFactory) {
+func RegisterCollector(id string, c CollectorFactory) {
 	collectorCatalog[id] = c
 }
@@ -18,4 +18,5 @@ import (
 	_ "github.com/DataDog/datadog-agent/pkg/workloadmeta/collectors/internal/kubelet"
 	_ "github.com/DataDog/datadog-agent/pkg/workloadmeta/collectors/internal/kubemetadata"
 	_ "github.com/DataDog/datadog-agent/pkg/workloadmeta/collectors/internal/podman"
+	_ "github.com/DataDog/datadog-agent/pkg/workloadmeta/collectors/internal/process"
 )
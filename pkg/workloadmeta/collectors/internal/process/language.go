@@ -0,0 +1,47 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package process
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+// detectLanguage guesses the language of a process from the name of its
+// executable, falling back to the first argument of its command line.
+func detectLanguage(exe string, cmdline []string) workloadmeta.ProcessLanguage {
+	candidates := make([]string, 0, 2)
+	if exe != "" {
+		candidates = append(candidates, filepath.Base(exe))
+	}
+	if len(cmdline) > 0 {
+		candidates = append(candidates, filepath.Base(cmdline[0]))
+	}
+
+	for _, name := range candidates {
+		switch {
+		case name == "java":
+			return workloadmeta.ProcessLanguageJava
+		case name == "node" || name == "nodejs":
+			return workloadmeta.ProcessLanguageNode
+		case name == "dotnet":
+			return workloadmeta.ProcessLanguageDotnet
+		case strings.HasPrefix(name, "python"):
+			return workloadmeta.ProcessLanguagePython
+		case strings.HasPrefix(name, "ruby"):
+			return workloadmeta.ProcessLanguageRuby
+		case strings.HasPrefix(name, "php"):
+			return workloadmeta.ProcessLanguagePHP
+		}
+	}
+
+	return workloadmeta.ProcessLanguageUnknown
+}
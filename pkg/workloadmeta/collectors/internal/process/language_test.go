@@ -0,0 +1,61 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package process
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		name     string
+		exe      string
+		cmdline  []string
+		expected workloadmeta.ProcessLanguage
+	}{
+		{
+			name:     "java",
+			exe:      "/usr/lib/jvm/java-11-openjdk/bin/java",
+			cmdline:  []string{"java", "-jar", "app.jar"},
+			expected: workloadmeta.ProcessLanguageJava,
+		},
+		{
+			name:     "versioned python",
+			exe:      "/usr/bin/python3.9",
+			cmdline:  []string{"/usr/local/bin/gunicorn", "app:app"},
+			expected: workloadmeta.ProcessLanguagePython,
+		},
+		{
+			name:     "unreadable executable",
+			cmdline:  []string{"/usr/local/bin/node", "server.js"},
+			expected: workloadmeta.ProcessLanguageNode,
+		},
+		{
+			name:     "php-fpm",
+			exe:      "/usr/sbin/php-fpm7.4",
+			expected: workloadmeta.ProcessLanguagePHP,
+		},
+		{
+			name:     "unknown",
+			exe:      "/usr/sbin/nginx",
+			cmdline:  []string{"nginx: master process"},
+			expected: workloadmeta.ProcessLanguageUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, detectLanguage(tt.exe, tt.cmdline))
+		})
+	}
+}
@@ -0,0 +1,162 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package process
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/mdlayher/netlink/nlenc"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+const (
+	// tcpListen is the state of a listening TCP socket
+	tcpListen = "0A"
+	// udpClose is the state of an unconnected UDP socket
+	udpClose = "07"
+)
+
+// socketTables are the files of /proc/<pid>/net holding the sockets of a
+// network namespace, with the protocol and state of their listening sockets.
+var socketTables = []struct {
+	file     string
+	protocol string
+	state    string
+}{
+	{file: "tcp", protocol: "tcp", state: tcpListen},
+	{file: "tcp6", protocol: "tcp", state: tcpListen},
+	{file: "udp", protocol: "udp", state: udpClose},
+	{file: "udp6", protocol: "udp", state: udpClose},
+}
+
+// readListeningSockets returns the listening sockets of the network namespace
+// whose socket tables are in netDir, by inode.
+func readListeningSockets(netDir string) map[uint64]workloadmeta.ContainerPort {
+	sockets := make(map[uint64]workloadmeta.ContainerPort)
+
+	for _, table := range socketTables {
+		f, err := os.Open(filepath.Join(netDir, table.file))
+		if err != nil {
+			continue
+		}
+
+		err = parseListeningSockets(f, table.protocol, table.state, sockets)
+		f.Close()
+
+		if err != nil {
+			log.Debugf("cannot parse socket table %s: %s", filepath.Join(netDir, table.file), err)
+		}
+	}
+
+	return sockets
+}
+
+// parseListeningSockets adds the sockets of a /proc/net/{tcp,udp}[6] table in
+// the given state to sockets.
+func parseListeningSockets(r io.Reader, protocol, state string, sockets map[uint64]workloadmeta.ContainerPort) error {
+	scanner := bufio.NewScanner(r)
+
+	// skip the header
+	scanner.Scan()
+
+	for scanner.Scan() {
+		// sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode ...
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 || fields[3] != state {
+			continue
+		}
+
+		i := strings.LastIndexByte(fields[1], ':')
+		if i < 0 {
+			continue
+		}
+
+		port, err := strconv.ParseUint(fields[1][i+1:], 16, 16)
+		if err != nil {
+			continue
+		}
+
+		inode, err := strconv.ParseUint(fields[9], 10, 64)
+		if err != nil || inode == 0 {
+			continue
+		}
+
+		sockets[inode] = workloadmeta.ContainerPort{
+			Port:     int(port),
+			Protocol: protocol,
+			HostIP:   parseSocketAddress(fields[1][:i]),
+		}
+	}
+
+	return scanner.Err()
+}
+
+// parseSocketAddress parses the hexadecimal address of a socket table entry,
+// printed as 32-bit words in host byte order, and returns an empty string if
+// it cannot be parsed.
+func parseSocketAddress(addr string) string {
+	b, err := hex.DecodeString(addr)
+	if err != nil || (len(b) != net.IPv4len && len(b) != net.IPv6len) {
+		return ""
+	}
+
+	ip := make(net.IP, len(b))
+	for i := 0; i < len(b); i += 4 {
+		nlenc.NativeEndian().PutUint32(ip[i:i+4], binary.BigEndian.Uint32(b[i:i+4]))
+	}
+
+	return ip.String()
+}
+
+// socketInodes returns the inodes of the sockets opened by a process, from
+// its /proc/<pid>/fd directory.
+func socketInodes(fdDir string) []uint64 {
+	entries, err := os.ReadDir(fdDir)
+	if err != nil {
+		return nil
+	}
+
+	var inodes []uint64
+	for _, entry := range entries {
+		link, err := os.Readlink(filepath.Join(fdDir, entry.Name()))
+		if err != nil {
+			continue
+		}
+
+		if inode, ok := parseSocketLink(link); ok {
+			inodes = append(inodes, inode)
+		}
+	}
+
+	return inodes
+}
+
+// parseSocketLink parses the inode of a socket:[<inode>] file descriptor link.
+func parseSocketLink(link string) (uint64, bool) {
+	if !strings.HasPrefix(link, "socket:[") || !strings.HasSuffix(link, "]") {
+		return 0, false
+	}
+
+	inode, err := strconv.ParseUint(link[len("socket:["):len(link)-1], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return inode, true
+}
@@ -0,0 +1,66 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package process
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+func TestParseListeningSockets(t *testing.T) {
+	tcp := `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 0100007F:1F90 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 21513 1 0000000000000000 100 0 0 10 0
+   1: 0100007F:A2C4 0100007F:1F90 01 00000000:00000000 00:00000000 00000000  1000        0 33470 1 0000000000000000 20 4 30 10 -1
+   2: 00000000:0050 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 0 1 0000000000000000 100 0 0 10 0
+`
+	tcp6 := `  sl  local_address                         remote_address                        st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000000000000000000000000000:1F91 00000000000000000000000000000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 21600 1 0000000000000000 100 0 0 10 0
+`
+
+	sockets := make(map[uint64]workloadmeta.ContainerPort)
+	require.NoError(t, parseListeningSockets(strings.NewReader(tcp), "tcp", tcpListen, sockets))
+	require.NoError(t, parseListeningSockets(strings.NewReader(tcp6), "tcp", tcpListen, sockets))
+
+	assert.Equal(t, map[uint64]workloadmeta.ContainerPort{
+		21513: {Port: 8080, Protocol: "tcp", HostIP: "127.0.0.1"},
+		21600: {Port: 8081, Protocol: "tcp", HostIP: "::"},
+	}, sockets)
+}
+
+func TestParseSocketAddress(t *testing.T) {
+	for addr, expected := range map[string]string{
+		"0100007F":                         "127.0.0.1",
+		"00000000":                         "0.0.0.0",
+		"0500000A":                         "10.0.0.5",
+		"00000000000000000000000001000000": "::1",
+		"0000000000000000FFFF00000100007F": "127.0.0.1",
+		"B80D0120000000000000000001000000": "2001:db8::1",
+		"0100007":                          "",
+		"0100007F00":                       "",
+	} {
+		assert.Equal(t, expected, parseSocketAddress(addr), addr)
+	}
+}
+
+func TestParseSocketLink(t *testing.T) {
+	inode, ok := parseSocketLink("socket:[21513]")
+	assert.True(t, ok)
+	assert.EqualValues(t, 21513, inode)
+
+	_, ok = parseSocketLink("pipe:[21513]")
+	assert.False(t, ok)
+
+	_, ok = parseSocketLink("/dev/null")
+	assert.False(t, ok)
+}
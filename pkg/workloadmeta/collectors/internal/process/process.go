@@ -0,0 +1,283 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package process
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"os"
+	"os/user"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	dderrors "github.com/DataDog/datadog-agent/pkg/errors"
+	"github.com/DataDog/datadog-agent/pkg/process/procutil"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+	"github.com/DataDog/datadog-agent/pkg/util/containers/v2/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+const (
+	collectorID   = "process"
+	componentName = "workloadmeta-process"
+
+	containerIDCacheValidity = 10 * time.Second
+)
+
+// unifiedServiceTaggingEnvVars are the only environment variables collected
+// from the processes
+var unifiedServiceTaggingEnvVars = map[string]struct{}{
+	"DD_ENV":     {},
+	"DD_SERVICE": {},
+	"DD_VERSION": {},
+}
+
+type collector struct {
+	store workloadmeta.Store
+	probe procutil.Probe
+
+	// processes holds the processes last notified to the store, by PID
+	processes map[int32]*workloadmeta.Process
+
+	// usernames caches the usernames resolved from UIDs
+	usernames map[int32]string
+}
+
+func init() {
+	workloadmeta.RegisterCollector(collectorID, func() workloadmeta.Collector {
+		return &collector{
+			processes: make(map[int32]*workloadmeta.Process),
+			usernames: make(map[int32]string),
+		}
+	})
+}
+
+func (c *collector) Start(_ context.Context, store workloadmeta.Store) error {
+	if !config.Datadog.GetBool("workloadmeta.process_collector.enabled") {
+		return dderrors.NewDisabled(componentName, "process collection not enabled")
+	}
+
+	c.store = store
+	c.probe = procutil.NewProcessProbe()
+
+	return nil
+}
+
+// Pull scans procfs and only notifies the store with the processes that were
+// started, updated or stopped since the previous pull.
+func (c *collector) Pull(_ context.Context) error {
+	procs, err := c.probe.ProcessesByPID(time.Now(), false)
+	if err != nil {
+		return err
+	}
+
+	ports := listeningPorts(procs)
+
+	var events []workloadmeta.CollectorEvent
+
+	for pid, proc := range procs {
+		previous := c.processes[pid]
+
+		var process *workloadmeta.Process
+		if previous != nil && previous.CreationTime.Equal(creationTime(proc)) && previous.Exe == proc.Exe {
+			// metadata that cannot change during the lifetime of
+			// the process is only collected once
+			cp := *previous
+			process = &cp
+			process.Name = proc.Name
+			process.Cmdline = proc.Cmdline
+			process.Cwd = proc.Cwd
+			process.Uids = proc.Uids
+			process.Gids = proc.Gids
+			process.Username = c.username(proc.Uids)
+		} else {
+			process = c.buildProcess(proc)
+		}
+		process.Ports = ports[pid]
+
+		if previous != nil && reflect.DeepEqual(previous, process) {
+			continue
+		}
+
+		c.processes[pid] = process
+		events = append(events, workloadmeta.CollectorEvent{
+			Type:   workloadmeta.EventTypeSet,
+			Source: workloadmeta.SourceHost,
+			Entity: process,
+		})
+	}
+
+	for pid, process := range c.processes {
+		if _, ok := procs[pid]; ok {
+			continue
+		}
+
+		delete(c.processes, pid)
+		events = append(events, workloadmeta.CollectorEvent{
+			Type:   workloadmeta.EventTypeUnset,
+			Source: workloadmeta.SourceHost,
+			Entity: process.EntityID,
+		})
+	}
+
+	if len(events) > 0 {
+		c.store.Notify(events)
+	}
+
+	return nil
+}
+
+func (c *collector) buildProcess(proc *procutil.Process) *workloadmeta.Process {
+	containerID, err := metrics.GetProvider().GetMetaCollector().GetContainerIDForPID(int(proc.Pid), containerIDCacheValidity)
+	if err != nil {
+		log.Debugf("cannot get container ID of process %d: %s", proc.Pid, err)
+	}
+
+	return &workloadmeta.Process{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindProcess,
+			ID:   strconv.Itoa(int(proc.Pid)),
+		},
+		Pid:          proc.Pid,
+		Ppid:         proc.Ppid,
+		NsPid:        proc.NsPid,
+		Name:         proc.Name,
+		Exe:          proc.Exe,
+		Cwd:          proc.Cwd,
+		Cmdline:      proc.Cmdline,
+		Username:     c.username(proc.Uids),
+		Uids:         proc.Uids,
+		Gids:         proc.Gids,
+		CreationTime: creationTime(proc),
+		ContainerID:  containerID,
+		Language:     detectLanguage(proc.Exe, proc.Cmdline),
+		EnvVars:      readEnvVars(proc.Pid),
+	}
+}
+
+// username returns the name of the real user of a process, or an empty
+// string if it cannot be resolved.
+func (c *collector) username(uids []int32) string {
+	if len(uids) == 0 {
+		return ""
+	}
+
+	uid := uids[0]
+	if name, ok := c.usernames[uid]; ok {
+		return name
+	}
+
+	var name string
+	u, err := user.LookupId(strconv.Itoa(int(uid)))
+	if err == nil {
+		name = u.Username
+	}
+
+	c.usernames[uid] = name
+
+	return name
+}
+
+// listeningPorts returns the ports the processes are listening on, by PID.
+// The sockets tables are read once per network namespace.
+func listeningPorts(procs map[int32]*procutil.Process) map[int32][]workloadmeta.ContainerPort {
+	sockets := make(map[string]map[uint64]workloadmeta.ContainerPort)
+	ports := make(map[int32][]workloadmeta.ContainerPort)
+
+	for pid := range procs {
+		pidStr := strconv.Itoa(int(pid))
+
+		netns, err := os.Readlink(util.HostProc(pidStr, "ns", "net"))
+		if err != nil {
+			continue
+		}
+
+		nsSockets, ok := sockets[netns]
+		if !ok {
+			nsSockets = readListeningSockets(util.HostProc(pidStr, "net"))
+			sockets[netns] = nsSockets
+		}
+
+		if len(nsSockets) == 0 {
+			continue
+		}
+
+		seen := make(map[workloadmeta.ContainerPort]int)
+		for _, inode := range socketInodes(util.HostProc(pidStr, "fd")) {
+			port, ok := nsSockets[inode]
+			if !ok {
+				continue
+			}
+
+			// a port can be bound by both an IPv4 and an IPv6 socket,
+			// the IPv4 address is kept as it is the most likely to be
+			// reachable from the checks
+			key := workloadmeta.ContainerPort{Port: port.Port, Protocol: port.Protocol}
+			if i, ok := seen[key]; ok {
+				if isIPv6(ports[pid][i].HostIP) && !isIPv6(port.HostIP) {
+					ports[pid][i] = port
+				}
+				continue
+			}
+
+			seen[key] = len(ports[pid])
+			ports[pid] = append(ports[pid], port)
+		}
+	}
+
+	return ports
+}
+
+// isIPv6 returns whether addr is an IPv6 address which is not IPv4-mapped.
+func isIPv6(addr string) bool {
+	ip := net.ParseIP(addr)
+	return ip != nil && ip.To4() == nil
+}
+
+// readEnvVars returns the unified service tagging environment variables of a
+// process.
+func readEnvVars(pid int32) map[string]string {
+	content, err := os.ReadFile(util.HostProc(strconv.Itoa(int(pid)), "environ"))
+	if err != nil {
+		return nil
+	}
+
+	var envVars map[string]string
+
+	for _, kv := range bytes.Split(content, []byte{0}) {
+		i := bytes.IndexByte(kv, '=')
+		if i < 0 {
+			continue
+		}
+
+		name := string(kv[:i])
+		if _, ok := unifiedServiceTaggingEnvVars[name]; !ok {
+			continue
+		}
+
+		if envVars == nil {
+			envVars = make(map[string]string)
+		}
+		envVars[name] = string(kv[i+1:])
+	}
+
+	return envVars
+}
+
+func creationTime(proc *procutil.Process) time.Time {
+	if proc.Stats == nil {
+		return time.Time{}
+	}
+
+	return time.Unix(0, proc.Stats.CreateTime*int64(time.Millisecond))
+}
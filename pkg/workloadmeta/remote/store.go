@@ -0,0 +1,233 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package remote implements a workloadmeta store holding the entities of the
+// workloadmeta store of the core agent, streamed over its gRPC API. It allows
+// the other agents to get workload metadata without collecting it themselves.
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cenkalti/backoff"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/DataDog/datadog-agent/pkg/api/security"
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo"
+	pbutils "github.com/DataDog/datadog-agent/pkg/proto/utils"
+	grpcutil "github.com/DataDog/datadog-agent/pkg/util/grpc"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+const (
+	collectorID       = "remote"
+	streamRecvTimeout = 10 * time.Minute
+)
+
+var errStreamNotStarted = errors.New("workloadmeta stream not started")
+
+// NewStore returns a workloadmeta store whose entities are streamed from the
+// core agent. Call Start to start the store and connect to the core agent.
+func NewStore() workloadmeta.Store {
+	return workloadmeta.NewStore(map[string]workloadmeta.CollectorFactory{
+		collectorID: func() workloadmeta.Collector {
+			return newCollector()
+		},
+	})
+}
+
+// entityKey identifies an entity collected from a source
+type entityKey struct {
+	source workloadmeta.Source
+	id     workloadmeta.EntityID
+}
+
+// collector streams the entities of the workloadmeta store of the core agent
+// and notifies the local store with them.
+type collector struct {
+	store  workloadmeta.Store
+	client pb.AgentSecureClient
+
+	// entities holds the entities set in the local store, to unset the ones
+	// that were removed from the remote store while the stream was down
+	entities map[entityKey]struct{}
+
+	// synced is false until the first response of a stream, holding all the
+	// entities of the remote store, has been processed
+	synced bool
+}
+
+func newCollector() *collector {
+	return &collector{
+		entities: make(map[entityKey]struct{}),
+	}
+}
+
+// Start connects to the core agent and starts streaming its entities.
+func (c *collector) Start(ctx context.Context, store workloadmeta.Store) error {
+	client, err := grpcutil.GetDDAgentSecureClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	c.store = store
+	c.client = client
+
+	go c.run(ctx)
+
+	return nil
+}
+
+// Pull is a no-op, the entities are streamed.
+func (c *collector) Pull(_ context.Context) error {
+	return nil
+}
+
+func (c *collector) run(ctx context.Context) {
+	for {
+		stream, cancel, err := c.startStream(ctx)
+		if err != nil {
+			// startStream only returns an error when the
+			// collector has been stopped
+			return
+		}
+
+		// the contents of the local store need to be resynced with
+		// the first response of the new stream
+		c.synced = false
+
+		err = c.recvAll(stream)
+		cancel()
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		log.Warnf("error received from remote workloadmeta: %s", err)
+	}
+}
+
+// recvAll processes the responses of the stream until it is aborted.
+func (c *collector) recvAll(stream pb.AgentSecure_WorkloadmetaStreamEntitiesClient) error {
+	for {
+		var response *pb.WorkloadmetaStreamResponse
+		err := grpcutil.DoWithTimeout(func() error {
+			var err error
+			response, err = stream.Recv()
+			return err
+		}, streamRecvTimeout)
+
+		if err != nil {
+			return err
+		}
+
+		c.processResponse(response)
+	}
+}
+
+// processResponse notifies the local store with the events of a response. The
+// first response of a stream holds all the entities of the remote store, so
+// the entities that are not part of it are unset.
+func (c *collector) processResponse(response *pb.WorkloadmetaStreamResponse) {
+	events := make([]workloadmeta.CollectorEvent, 0, len(response.Events))
+	for _, ev := range response.Events {
+		event, err := pbutils.Pb2WorkloadmetaEvent(ev)
+		if err != nil {
+			log.Warnf("error processing event received from remote workloadmeta: %s", err)
+			continue
+		}
+
+		events = append(events, event)
+	}
+
+	if !c.synced {
+		current := make(map[entityKey]struct{}, len(events))
+		for _, event := range events {
+			if event.Type == workloadmeta.EventTypeSet {
+				current[entityKey{source: event.Source, id: event.Entity.GetID()}] = struct{}{}
+			}
+		}
+
+		for key := range c.entities {
+			if _, ok := current[key]; !ok {
+				events = append(events, workloadmeta.CollectorEvent{
+					Type:   workloadmeta.EventTypeUnset,
+					Source: key.source,
+					Entity: key.id,
+				})
+			}
+		}
+
+		c.synced = true
+	}
+
+	for _, event := range events {
+		key := entityKey{source: event.Source, id: event.Entity.GetID()}
+		if event.Type == workloadmeta.EventTypeSet {
+			c.entities[key] = struct{}{}
+		} else {
+			delete(c.entities, key)
+		}
+	}
+
+	c.store.Notify(events)
+}
+
+// startStream tries to establish a stream with the remote gRPC endpoint,
+// retrying with an exponential backoff until the collector is stopped.
+func (c *collector) startStream(ctx context.Context) (pb.AgentSecure_WorkloadmetaStreamEntitiesClient, context.CancelFunc, error) {
+	expBackoff := backoff.NewExponentialBackOff()
+	expBackoff.InitialInterval = 500 * time.Millisecond
+	expBackoff.MaxInterval = 5 * time.Minute
+	expBackoff.MaxElapsedTime = 0
+
+	var (
+		stream       pb.AgentSecure_WorkloadmetaStreamEntitiesClient
+		streamCancel context.CancelFunc
+	)
+
+	err := backoff.Retry(func() error {
+		select {
+		case <-ctx.Done():
+			return &backoff.PermanentError{Err: errStreamNotStarted}
+		default:
+		}
+
+		token, err := security.FetchAuthToken()
+		if err != nil {
+			err = fmt.Errorf("unable to fetch authentication token: %w", err)
+			log.Infof("unable to establish stream, will possibly retry: %s", err)
+			return err
+		}
+
+		var streamCtx context.Context
+		streamCtx, streamCancel = context.WithCancel(
+			metadata.NewOutgoingContext(ctx, metadata.MD{
+				"authorization": []string{fmt.Sprintf("Bearer %s", token)},
+			}),
+		)
+
+		stream, err = c.client.WorkloadmetaStreamEntities(streamCtx, &pb.WorkloadmetaStreamRequest{})
+		if err != nil {
+			streamCancel()
+			log.Infof("unable to establish stream, will possibly retry: %s", err)
+			return err
+		}
+
+		log.Info("workloadmeta stream established successfully")
+
+		return nil
+	}, expBackoff)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return stream, streamCancel, nil
+}
@@ -0,0 +1,91 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package remote
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo"
+	pbutils "github.com/DataDog/datadog-agent/pkg/proto/utils"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+func TestProcessResponse(t *testing.T) {
+	container := &workloadmeta.Container{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindContainer,
+			ID:   "foo",
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name:   "foo-name",
+			Labels: map[string]string{"app": "foo"},
+		},
+		Runtime: workloadmeta.ContainerRuntimeDocker,
+		State: workloadmeta.ContainerState{
+			Running:   true,
+			StartedAt: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	pod := &workloadmeta.KubernetesPod{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindKubernetesPod,
+			ID:   "bar",
+		},
+		Containers: []workloadmeta.OrchestratorContainer{{ID: "foo"}},
+	}
+
+	store := workloadmeta.NewMockStore()
+	c := newCollector()
+	c.store = store
+
+	c.processResponse(response(t,
+		workloadmeta.Event{Type: workloadmeta.EventTypeSet, Entity: container}, workloadmeta.SourceRuntime,
+		workloadmeta.Event{Type: workloadmeta.EventTypeSet, Entity: pod}, workloadmeta.SourceNodeOrchestrator,
+	))
+
+	got, err := store.GetContainer("foo")
+	require.NoError(t, err)
+	assert.Equal(t, container, got)
+
+	gotPod, err := store.GetKubernetesPodForContainer("foo")
+	require.NoError(t, err)
+	assert.Equal(t, pod, gotPod)
+
+	// the pod is removed while the stream is down, the first response of
+	// the new stream only holds the container
+	c.synced = false
+	c.processResponse(response(t,
+		workloadmeta.Event{Type: workloadmeta.EventTypeSet, Entity: container}, workloadmeta.SourceRuntime,
+	))
+
+	_, err = store.GetContainer("foo")
+	assert.NoError(t, err)
+	_, err = store.GetKubernetesPod("bar")
+	assert.Error(t, err)
+
+	c.processResponse(response(t,
+		workloadmeta.Event{Type: workloadmeta.EventTypeUnset, Entity: container.EntityID}, workloadmeta.SourceRuntime,
+	))
+
+	_, err = store.GetContainer("foo")
+	assert.Error(t, err)
+	assert.Empty(t, c.entities)
+}
+
+// response builds a stream response from pairs of events and sources
+func response(t *testing.T, eventsAndSources ...interface{}) *pb.WorkloadmetaStreamResponse {
+	resp := &pb.WorkloadmetaStreamResponse{}
+	for i := 0; i < len(eventsAndSources); i += 2 {
+		ev, err := pbutils.Workloadmeta2PbEvent(eventsAndSources[i].(workloadmeta.Event), eventsAndSources[i+1].(workloadmeta.Source))
+		require.NoError(t, err)
+		resp.Events = append(resp.Events, ev)
+	}
+	return resp
+}
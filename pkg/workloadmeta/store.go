@@ -7,7 +7,9 @@ package workloadmeta
 
 import (
 	"context"
+	"fmt"
 	"sort"
+	"strconv"
 	"sync"
 	"time"
 
@@ -59,11 +61,11 @@ var _ Store = &store{}
 // NewStore creates a new workload metadata store, building a new instance of
 // each collector in the catalog. Call Start to start the store and its
 // collectors.
-func NewStore(catalog map[string]collectorFactory) Store {
+func NewStore(catalog map[string]CollectorFactory) Store {
 	return newStore(catalog)
 }
 
-func newStore(catalog map[string]collectorFactory) *store {
+func newStore(catalog map[string]CollectorFactory) *store {
 	candidates := make(map[string]Collector)
 	for id, c := range catalog {
 		candidates[id] = c()
@@ -302,6 +304,31 @@ func (s *store) GetECSTask(id string) (*ECSTask, error) {
 	return entity.(*ECSTask), nil
 }
 
+// GetProcess implements Store#GetProcess
+func (s *store) GetProcess(pid int32) (*Process, error) {
+	entity, err := s.getEntityByKind(KindProcess, strconv.Itoa(int(pid)))
+	if err != nil {
+		return nil, err
+	}
+
+	return entity.(*Process), nil
+}
+
+// ListProcesses implements Store#ListProcesses
+func (s *store) ListProcesses() ([]*Process, error) {
+	entities, err := s.listEntitiesByKind(KindProcess)
+	if err != nil {
+		return nil, err
+	}
+
+	processes := make([]*Process, 0, len(entities))
+	for _, entity := range entities {
+		processes = append(processes, entity.(*Process))
+	}
+
+	return processes, nil
+}
+
 // Notify implements Store#Notify
 func (s *store) Notify(events []CollectorEvent) {
 	if len(events) > 0 {
@@ -524,6 +551,22 @@ func notifyChannel(name string, ch chan EventBundle, events []Event, wait bool)
 	}
 }
 
+// SetGlobalStore sets the global instance of the workloadmeta store, for
+// instance to use a remote store in the agents other than the core agent. It
+// returns an error if the global store was already initialized, by
+// GetGlobalStore or a previous call.
+func SetGlobalStore(s Store) error {
+	set := false
+	initOnce.Do(func() {
+		globalStore = s
+		set = true
+	})
+	if !set {
+		return fmt.Errorf("the global workloadmeta store is already initialized")
+	}
+	return nil
+}
+
 // GetGlobalStore returns a global instance of the workloadmeta store,
 // creating one if it doesn't exist. Start() needs to be called before any data
 // collection happens.
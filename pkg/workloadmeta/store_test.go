@@ -466,3 +466,13 @@ func newTestStore() *store {
 		store: make(map[Kind]map[string]*cachedEntity),
 	}
 }
+
+func TestSetGlobalStore(t *testing.T) {
+	s := newTestStore()
+	assert.NilError(t, SetGlobalStore(s))
+	assert.Equal(t, Store(s), GetGlobalStore())
+
+	// the global store can't be replaced once initialized
+	assert.ErrorContains(t, SetGlobalStore(newTestStore()), "already initialized")
+	assert.Equal(t, Store(s), GetGlobalStore())
+}
@@ -70,6 +70,14 @@ type Store interface {
 	// kind KindECSTask and the given ID.
 	GetECSTask(id string) (*ECSTask, error)
 
+	// GetProcess returns metadata about a process.  It fetches the entity
+	// with kind KindProcess and the given PID.
+	GetProcess(pid int32) (*Process, error)
+
+	// ListProcesses returns metadata about all known processes, equivalent
+	// to all entities with kind KindProcess.
+	ListProcesses() ([]*Process, error)
+
 	// Notify notifies the store with a slice of events.  It should only be
 	// used by workloadmeta collectors.
 	Notify(events []CollectorEvent)
@@ -86,6 +94,7 @@ const (
 	KindContainer     Kind = "container"
 	KindKubernetesPod Kind = "kubernetes_pod"
 	KindECSTask       Kind = "ecs_task"
+	KindProcess       Kind = "process"
 )
 
 // Source is the source name of an entity.
@@ -111,6 +120,10 @@ const (
 	// the central component of an orchestrator, or the Datadog Cluster
 	// Agent.  `kube_metadata` and `cloudfoundry` use this.
 	SourceClusterOrchestrator Source = "cluster_orchestrator"
+
+	// SourceHost represents entities detected on the host itself, outside
+	// of any container runtime or orchestrator. `process` uses this.
+	SourceHost Source = "host"
 )
 
 // ContainerRuntime is the container runtime used by a container.
@@ -340,6 +353,8 @@ type ContainerPort struct {
 	Name     string
 	Port     int
 	Protocol string
+	// HostIP is the address the port is bound to, when known
+	HostIP string
 }
 
 // String returns a string representation of ContainerPort.
@@ -350,6 +365,9 @@ func (c ContainerPort) String(verbose bool) string {
 	if verbose {
 		_, _ = fmt.Fprintln(&sb, "Name:", c.Name)
 		_, _ = fmt.Fprintln(&sb, "Protocol:", c.Protocol)
+		if c.HostIP != "" {
+			_, _ = fmt.Fprintln(&sb, "Host IP:", c.HostIP)
+		}
 	}
 
 	return sb.String()
@@ -604,6 +622,102 @@ func (t ECSTask) String(verbose bool) string {
 
 var _ Entity = &ECSTask{}
 
+// ProcessLanguage is the language a process is written in, as guessed from
+// its executable and command line.
+type ProcessLanguage string
+
+// Defined ProcessLanguages
+const (
+	ProcessLanguageUnknown ProcessLanguage = ""
+	ProcessLanguageDotnet  ProcessLanguage = "dotnet"
+	ProcessLanguageJava    ProcessLanguage = "java"
+	ProcessLanguageNode    ProcessLanguage = "node"
+	ProcessLanguagePHP     ProcessLanguage = "php"
+	ProcessLanguagePython  ProcessLanguage = "python"
+	ProcessLanguageRuby    ProcessLanguage = "ruby"
+)
+
+// Process is an Entity representing a process running on the host.  Its
+// EntityID.ID is the PID of the process.
+type Process struct {
+	EntityID
+	Pid          int32
+	Ppid         int32
+	NsPid        int32
+	Name         string
+	Exe          string
+	Cwd          string
+	Cmdline      []string
+	Username     string
+	Uids         []int32
+	Gids         []int32
+	CreationTime time.Time
+	// ContainerID is the ID of the container running the process, empty
+	// if it runs directly on the host
+	ContainerID string
+	// Ports are the ports the process is listening on
+	Ports    []ContainerPort
+	Language ProcessLanguage
+	// EnvVars only holds the environment variables used for unified
+	// service tagging
+	EnvVars map[string]string
+}
+
+// GetID implements Entity#GetID.
+func (p Process) GetID() EntityID {
+	return p.EntityID
+}
+
+// Merge implements Entity#Merge.
+func (p *Process) Merge(e Entity) error {
+	pp, ok := e.(*Process)
+	if !ok {
+		return fmt.Errorf("cannot merge Process with different kind %T", e)
+	}
+
+	return merge(p, pp)
+}
+
+// DeepCopy implements Entity#DeepCopy.
+func (p Process) DeepCopy() Entity {
+	cp := deepcopy.Copy(p).(Process)
+	return &cp
+}
+
+// String implements Entity#String.
+func (p Process) String(verbose bool) string {
+	var sb strings.Builder
+	_, _ = fmt.Fprintln(&sb, "----------- Entity ID -----------")
+	_, _ = fmt.Fprint(&sb, p.EntityID.String(verbose))
+
+	_, _ = fmt.Fprintln(&sb, "----------- Process Info -----------")
+	_, _ = fmt.Fprintln(&sb, "Name:", p.Name)
+	_, _ = fmt.Fprintln(&sb, "Command Line:", sliceToString(p.Cmdline))
+	_, _ = fmt.Fprintln(&sb, "Language:", p.Language)
+	_, _ = fmt.Fprintln(&sb, "Container ID:", p.ContainerID)
+
+	if verbose {
+		_, _ = fmt.Fprintln(&sb, "PPID:", p.Ppid)
+		_, _ = fmt.Fprintln(&sb, "Namespaced PID:", p.NsPid)
+		_, _ = fmt.Fprintln(&sb, "Executable:", p.Exe)
+		_, _ = fmt.Fprintln(&sb, "Working Directory:", p.Cwd)
+		_, _ = fmt.Fprintln(&sb, "Username:", p.Username)
+		_, _ = fmt.Fprintln(&sb, "Creation Time:", p.CreationTime)
+		_, _ = fmt.Fprintln(&sb, "Env Variables:", mapToString(p.EnvVars))
+	}
+
+	if len(p.Ports) > 0 && verbose {
+		_, _ = fmt.Fprintln(&sb, "----------- Ports -----------")
+		for _, port := range p.Ports {
+			_, _ = fmt.Fprint(&sb, port.String(verbose))
+		}
+	}
+
+	return sb.String()
+}
+
+var _ Entity = &Process{}
+
 // CollectorEvent is an event generated by a metadata collector, to be handled
 // by the metadata store.
 type CollectorEvent struct {